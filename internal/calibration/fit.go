@@ -0,0 +1,215 @@
+package calibration
+
+import (
+	"math"
+	"sort"
+
+	"github.com/proth1/text-moderator/internal/classifier"
+	"github.com/proth1/text-moderator/internal/models"
+)
+
+// DefaultMinSamples is the minimum number of labelled points required to fit a
+// provider/category calibration when the request does not specify one.
+const DefaultMinSamples = 50
+
+// Sample is one reviewed decision: the provider's raw scores and whether the
+// reviewer judged the content harmful.
+type Sample struct {
+	Provider string
+	Scores   models.CategoryScores
+	Harmful  bool
+}
+
+// point is a single (score, label) observation for one provider and category.
+type point struct {
+	x, y float64
+}
+
+// FitConfig fits a calibration per provider and category using the given method.
+// A harmful verdict is attributed to the sample's highest-scoring category only,
+// since the review does not say which category made the content harmful; a
+// harmless verdict is a negative for every category. Provider/category pairs
+// with fewer than minSamples points, or without both outcomes, are skipped.
+// It returns the fitted config and the number of samples from fitted providers.
+func FitConfig(samples []Sample, method string, minSamples int) (classifier.CalibrationConfig, int) {
+	if minSamples <= 0 {
+		minSamples = DefaultMinSamples
+	}
+
+	type key struct{ provider, category string }
+	points := make(map[key][]point)
+	for _, s := range samples {
+		values := s.Scores.ToMap()
+		top := topCategory(values)
+		for _, cat := range models.Categories {
+			y := 0.0
+			if s.Harmful {
+				if cat != top {
+					continue
+				}
+				y = 1.0
+			}
+			k := key{s.Provider, cat}
+			points[k] = append(points[k], point{x: values[cat], y: y})
+		}
+	}
+
+	config := make(classifier.CalibrationConfig)
+	for k, pts := range points {
+		if len(pts) < minSamples || !hasBothClasses(pts) {
+			continue
+		}
+
+		var params classifier.CalibrationParams
+		switch method {
+		case classifier.CalibrationIsotonic:
+			xs, ys := fitIsotonic(pts)
+			params = classifier.CalibrationParams{Method: classifier.CalibrationIsotonic, X: xs, Y: ys}
+		default:
+			a, b := fitPlatt(pts)
+			params = classifier.CalibrationParams{Method: classifier.CalibrationPlatt, A: a, B: b}
+		}
+
+		pc, ok := config[k.provider]
+		if !ok {
+			pc = classifier.ProviderCalibration{Categories: make(map[string]classifier.CalibrationParams)}
+			config[k.provider] = pc
+		}
+		pc.Categories[k.category] = params
+	}
+
+	used := 0
+	for _, s := range samples {
+		if _, ok := config[s.Provider]; ok {
+			used++
+		}
+	}
+	return config, used
+}
+
+// fitPlatt fits p(harmful) = 1 / (1 + exp(-(a*x + b))) by Newton's method on
+// the log-likelihood, using Platt's smoothed targets to avoid overfitting.
+func fitPlatt(pts []point) (a, b float64) {
+	var pos, neg float64
+	for _, p := range pts {
+		if p.y > 0.5 {
+			pos++
+		} else {
+			neg++
+		}
+	}
+	hi := (pos + 1) / (pos + 2)
+	lo := 1 / (neg + 2)
+
+	targets := make([]float64, len(pts))
+	for i, p := range pts {
+		if p.y > 0.5 {
+			targets[i] = hi
+		} else {
+			targets[i] = lo
+		}
+	}
+
+	const (
+		maxIterations = 100
+		ridge         = 1e-6
+		tolerance     = 1e-9
+	)
+	a, b = 0, math.Log((pos+1)/(neg+1))
+	for iter := 0; iter < maxIterations; iter++ {
+		var ga, gb, haa, hab, hbb float64
+		for i, p := range pts {
+			q := sigmoid(a*p.x + b)
+			d := q - targets[i]
+			w := q * (1 - q)
+			ga += d * p.x
+			gb += d
+			haa += w * p.x * p.x
+			hab += w * p.x
+			hbb += w
+		}
+		haa += ridge
+		hbb += ridge
+
+		det := haa*hbb - hab*hab
+		if det == 0 {
+			break
+		}
+		da := (hbb*ga - hab*gb) / det
+		db := (haa*gb - hab*ga) / det
+		a -= da
+		b -= db
+		if math.Abs(da) < tolerance && math.Abs(db) < tolerance {
+			break
+		}
+	}
+	return a, b
+}
+
+// fitIsotonic fits a non-decreasing step function with the pool-adjacent-violators
+// algorithm and returns its block means as interpolation points.
+func fitIsotonic(pts []point) (xs, ys []float64) {
+	sorted := make([]point, len(pts))
+	copy(sorted, pts)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].x < sorted[j].x })
+
+	type block struct {
+		sumX, sumY, n float64
+	}
+	var blocks []block
+	for i := 0; i < len(sorted); {
+		// Pool identical scores first so interpolation points are strictly increasing.
+		b := block{}
+		x := sorted[i].x
+		for ; i < len(sorted) && sorted[i].x == x; i++ {
+			b.sumX += sorted[i].x
+			b.sumY += sorted[i].y
+			b.n++
+		}
+		blocks = append(blocks, b)
+		for len(blocks) > 1 {
+			last, prev := blocks[len(blocks)-1], blocks[len(blocks)-2]
+			if prev.sumY/prev.n <= last.sumY/last.n {
+				break
+			}
+			blocks = blocks[:len(blocks)-2]
+			blocks = append(blocks, block{prev.sumX + last.sumX, prev.sumY + last.sumY, prev.n + last.n})
+		}
+	}
+
+	xs = make([]float64, len(blocks))
+	ys = make([]float64, len(blocks))
+	for i, b := range blocks {
+		xs[i] = b.sumX / b.n
+		ys[i] = b.sumY / b.n
+	}
+	return xs, ys
+}
+
+func sigmoid(z float64) float64 {
+	return 1 / (1 + math.Exp(-z))
+}
+
+func hasBothClasses(pts []point) bool {
+	var pos, neg bool
+	for _, p := range pts {
+		if p.y > 0.5 {
+			pos = true
+		} else {
+			neg = true
+		}
+	}
+	return pos && neg
+}
+
+// topCategory returns the highest-scoring category, preferring earlier
+// categories on ties.
+func topCategory(values map[string]float64) string {
+	top := models.Categories[0]
+	for _, cat := range models.Categories[1:] {
+		if values[cat] > values[top] {
+			top = cat
+		}
+	}
+	return top
+}
@@ -0,0 +1,129 @@
+package calibration
+
+import (
+	"testing"
+
+	"github.com/proth1/text-moderator/internal/classifier"
+	"github.com/proth1/text-moderator/internal/models"
+)
+
+func TestFitPlatt_Monotonic(t *testing.T) {
+	var pts []point
+	for i := 0; i < 100; i++ {
+		x := float64(i) / 100
+		y := 0.0
+		if x >= 0.6 {
+			y = 1.0
+		}
+		pts = append(pts, point{x, y})
+	}
+	// Some noise so the fit is not perfectly separable
+	pts = append(pts, point{0.7, 0}, point{0.3, 1})
+
+	a, b := fitPlatt(pts)
+	if a <= 0 {
+		t.Fatalf("slope a = %f, want positive", a)
+	}
+	low, high := sigmoid(a*0.1+b), sigmoid(a*0.9+b)
+	if low > 0.2 || high < 0.8 {
+		t.Errorf("got p(0.1) = %f, p(0.9) = %f, want < 0.2 and > 0.8", low, high)
+	}
+}
+
+func TestFitIsotonic(t *testing.T) {
+	pts := []point{{0.1, 0}, {0.2, 1}, {0.3, 0}, {0.4, 1}, {0.4, 1}, {0.5, 1}}
+
+	xs, ys := fitIsotonic(pts)
+	if len(xs) != len(ys) || len(xs) == 0 {
+		t.Fatalf("got %d xs and %d ys", len(xs), len(ys))
+	}
+	for i := 1; i < len(xs); i++ {
+		if xs[i] <= xs[i-1] {
+			t.Errorf("xs not strictly increasing: %v", xs)
+		}
+		if ys[i] < ys[i-1] {
+			t.Errorf("ys not non-decreasing: %v", ys)
+		}
+	}
+	// 0.2 and 0.3 violate monotonicity and are pooled to 0.5
+	want := []float64{0, 0.5, 1, 1}
+	if len(ys) != len(want) {
+		t.Fatalf("got ys %v, want %v", ys, want)
+	}
+	for i := range want {
+		if ys[i] != want[i] {
+			t.Errorf("ys[%d] = %f, want %f", i, ys[i], want[i])
+		}
+	}
+}
+
+func TestFitConfig(t *testing.T) {
+	var samples []Sample
+	for i := 0; i < 60; i++ {
+		score := float64(i) / 60
+		samples = append(samples, Sample{
+			Provider: "huggingface",
+			Scores:   models.CategoryScores{Toxicity: score, Hate: 0.01},
+			Harmful:  score > 0.5,
+		})
+	}
+	// Too few samples to fit
+	for i := 0; i < 10; i++ {
+		samples = append(samples, Sample{Provider: "openai", Scores: models.CategoryScores{Toxicity: 0.9}, Harmful: i%2 == 0})
+	}
+
+	config, used := FitConfig(samples, classifier.CalibrationPlatt, 50)
+
+	if _, ok := config["openai"]; ok {
+		t.Error("openai should be skipped with fewer than min samples")
+	}
+	hf, ok := config["huggingface"]
+	if !ok {
+		t.Fatal("expected huggingface calibration")
+	}
+	tox, ok := hf.Categories["toxicity"]
+	if !ok || tox.Method != classifier.CalibrationPlatt || tox.A <= 0 {
+		t.Errorf("toxicity: got %+v, want a positive Platt slope", tox)
+	}
+	// Harmful verdicts are attributed to toxicity, so hate never sees a positive
+	if _, ok := hf.Categories["hate"]; ok {
+		t.Error("hate should be skipped without positive samples")
+	}
+	if used != 60 {
+		t.Errorf("used = %d, want 60", used)
+	}
+}
+
+func TestFitConfig_Isotonic(t *testing.T) {
+	var samples []Sample
+	for i := 0; i < 20; i++ {
+		score := float64(i) / 20
+		samples = append(samples, Sample{Provider: "p", Scores: models.CategoryScores{Spam: score}, Harmful: score >= 0.5})
+	}
+
+	config, _ := FitConfig(samples, classifier.CalibrationIsotonic, 10)
+
+	spam := config["p"].Categories["spam"]
+	if spam.Method != classifier.CalibrationIsotonic || len(spam.X) == 0 || len(spam.X) != len(spam.Y) {
+		t.Errorf("spam: got %+v, want isotonic points", spam)
+	}
+}
+
+func TestTopCategory(t *testing.T) {
+	tests := []struct {
+		name   string
+		scores models.CategoryScores
+		want   string
+	}{
+		{"highest wins", models.CategoryScores{Toxicity: 0.2, Violence: 0.8}, "violence"},
+		{"tie prefers earlier", models.CategoryScores{Hate: 0.5, Spam: 0.5}, "hate"},
+		{"all zero", models.CategoryScores{}, "toxicity"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := topCategory(tt.scores.ToMap()); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
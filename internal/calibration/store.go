@@ -0,0 +1,237 @@
+package calibration
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/proth1/text-moderator/internal/classifier"
+	"github.com/proth1/text-moderator/internal/feedback"
+	"github.com/proth1/text-moderator/internal/models"
+	"go.uber.org/zap"
+)
+
+// DefaultWindowDays is how far back review outcomes are read when fitting.
+const DefaultWindowDays = 30
+
+// ErrInsufficientData is returned when no provider/category has enough
+// reviewed samples to fit a calibration.
+var ErrInsufficientData = errors.New("not enough reviewed decisions to fit a calibration")
+
+// Store fits and persists versioned score calibrations.
+// Control: MOD-005 (Multi-Provider Classification Orchestration)
+type Store struct {
+	db     *pgxpool.Pool
+	logger *zap.Logger
+}
+
+// NewStore creates a new calibration store.
+func NewStore(db *pgxpool.Pool, logger *zap.Logger) *Store {
+	return &Store{db: db, logger: logger}
+}
+
+// Fit reads reviewed decisions from calibration_data, fits a calibration per
+// provider and category, and stores it as the next version. The new version is
+// activated immediately when req.Activate is set.
+func (s *Store) Fit(ctx context.Context, req *models.FitCalibrationRequest, createdBy uuid.UUID) (*models.CalibrationVersion, error) {
+	windowDays := req.WindowDays
+	if windowDays <= 0 {
+		windowDays = DefaultWindowDays
+	}
+
+	samples, err := s.loadSamples(ctx, windowDays)
+	if err != nil {
+		return nil, err
+	}
+
+	config, used := FitConfig(samples, req.Method, req.MinSamples)
+	if len(config) == 0 {
+		return nil, ErrInsufficientData
+	}
+	params, err := json.Marshal(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode calibration params: %w", err)
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Serialise version allocation between concurrent fits.
+	if _, err := tx.Exec(ctx, `LOCK TABLE calibration_versions IN SHARE ROW EXCLUSIVE MODE`); err != nil {
+		return nil, fmt.Errorf("failed to lock calibration versions: %w", err)
+	}
+	var maxVersion int
+	if err := tx.QueryRow(ctx, `SELECT COALESCE(MAX(version), 0) FROM calibration_versions`).Scan(&maxVersion); err != nil {
+		return nil, fmt.Errorf("failed to check existing calibration versions: %w", err)
+	}
+
+	v := &models.CalibrationVersion{
+		ID:          uuid.New(),
+		Version:     maxVersion + 1,
+		Method:      req.Method,
+		Params:      params,
+		SampleCount: used,
+		WindowDays:  windowDays,
+		CreatedBy:   &createdBy,
+	}
+	err = tx.QueryRow(ctx, `
+		INSERT INTO calibration_versions (id, version, method, params, sample_count, window_days, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING created_at
+	`, v.ID, v.Version, v.Method, v.Params, v.SampleCount, v.WindowDays, v.CreatedBy).Scan(&v.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create calibration version: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit calibration version: %w", err)
+	}
+
+	s.logger.Info("calibration fitted",
+		zap.Int("version", v.Version),
+		zap.String("method", v.Method),
+		zap.Int("providers", len(config)),
+		zap.Int("samples", used),
+	)
+
+	if req.Activate {
+		return s.Activate(ctx, v.ID)
+	}
+	return v, nil
+}
+
+// loadSamples reads conclusive review outcomes with the provider's uncalibrated
+// scores. Decisions calibrated without recording raw scores are skipped, since
+// refitting on already-calibrated scores would compound the correction.
+func (s *Store) loadSamples(ctx context.Context, windowDays int) ([]Sample, error) {
+	rows, err := s.db.Query(ctx, `
+		SELECT c.provider_name, COALESCE(d.raw_category_scores, c.category_scores), c.automated_action, c.review_outcome
+		FROM calibration_data c
+		LEFT JOIN moderation_decisions d ON d.id = c.decision_id
+		WHERE c.created_at >= NOW() - make_interval(days => $1)
+		  AND c.review_outcome IN ('agree', 'disagree')
+		  AND (d.calibration_version IS NULL OR d.raw_category_scores IS NOT NULL)
+	`, windowDays)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query calibration data: %w", err)
+	}
+	defer rows.Close()
+
+	var samples []Sample
+	for rows.Next() {
+		var sample Sample
+		var action models.PolicyAction
+		var outcome string
+		if err := rows.Scan(&sample.Provider, &sample.Scores, &action, &outcome); err != nil {
+			return nil, fmt.Errorf("failed to scan calibration data: %w", err)
+		}
+		sample.Harmful = feedback.IsHarmful(action, outcome)
+		samples = append(samples, sample)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read calibration data: %w", err)
+	}
+	return samples, nil
+}
+
+// List returns all calibration versions, newest first.
+func (s *Store) List(ctx context.Context) ([]models.CalibrationVersion, error) {
+	rows, err := s.db.Query(ctx, `
+		SELECT id, version, method, params, sample_count, window_days, active, created_by, created_at, activated_at
+		FROM calibration_versions
+		ORDER BY version DESC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query calibration versions: %w", err)
+	}
+	defer rows.Close()
+
+	var versions []models.CalibrationVersion
+	for rows.Next() {
+		v, err := scanVersion(rows)
+		if err != nil {
+			return nil, err
+		}
+		versions = append(versions, *v)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read calibration versions: %w", err)
+	}
+	return versions, nil
+}
+
+// Get returns a calibration version by ID.
+func (s *Store) Get(ctx context.Context, id uuid.UUID) (*models.CalibrationVersion, error) {
+	row := s.db.QueryRow(ctx, `
+		SELECT id, version, method, params, sample_count, window_days, active, created_by, created_at, activated_at
+		FROM calibration_versions
+		WHERE id = $1
+	`, id)
+	return scanVersion(row)
+}
+
+// Active returns the active calibration version, or nil if none is active.
+func (s *Store) Active(ctx context.Context) (*models.CalibrationVersion, error) {
+	row := s.db.QueryRow(ctx, `
+		SELECT id, version, method, params, sample_count, window_days, active, created_by, created_at, activated_at
+		FROM calibration_versions
+		WHERE active
+	`)
+	v, err := scanVersion(row)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	return v, err
+}
+
+// Activate makes the given version the active calibration, deactivating any other.
+func (s *Store) Activate(ctx context.Context, id uuid.UUID) (*models.CalibrationVersion, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var version int
+	if err := tx.QueryRow(ctx, `SELECT version FROM calibration_versions WHERE id = $1 FOR UPDATE`, id).Scan(&version); err != nil {
+		return nil, fmt.Errorf("failed to query calibration version: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `UPDATE calibration_versions SET active = FALSE WHERE active AND id <> $1`, id); err != nil {
+		return nil, fmt.Errorf("failed to deactivate previous calibration: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `UPDATE calibration_versions SET active = TRUE, activated_at = NOW() WHERE id = $1`, id); err != nil {
+		return nil, fmt.Errorf("failed to activate calibration: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit calibration activation: %w", err)
+	}
+
+	s.logger.Info("calibration activated", zap.String("calibration_id", id.String()), zap.Int("version", version))
+
+	return s.Get(ctx, id)
+}
+
+// Calibrator builds a versioned classifier.Calibrator from a stored version.
+func Calibrator(v *models.CalibrationVersion) (*classifier.Calibrator, error) {
+	var config classifier.CalibrationConfig
+	if err := json.Unmarshal(v.Params, &config); err != nil {
+		return nil, fmt.Errorf("failed to decode calibration version %d: %w", v.Version, err)
+	}
+	return classifier.NewVersionedCalibrator(config, v.Version), nil
+}
+
+func scanVersion(row pgx.Row) (*models.CalibrationVersion, error) {
+	var v models.CalibrationVersion
+	err := row.Scan(&v.ID, &v.Version, &v.Method, &v.Params, &v.SampleCount, &v.WindowDays, &v.Active, &v.CreatedBy, &v.CreatedAt, &v.ActivatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan calibration version: %w", err)
+	}
+	return &v, nil
+}
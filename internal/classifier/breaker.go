@@ -0,0 +1,351 @@
+package classifier
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/sony/gobreaker"
+	"go.uber.org/zap"
+)
+
+// BreakerMode is an operator override applied on top of a provider's circuit breaker.
+type BreakerMode string
+
+const (
+	// BreakerAuto lets the circuit breaker trip and recover on its own.
+	BreakerAuto BreakerMode = "auto"
+	// BreakerForcedOpen rejects every call to the provider, as if the breaker had tripped.
+	BreakerForcedOpen BreakerMode = "open"
+	// BreakerForcedClosed sends every call to the provider, ignoring failures.
+	BreakerForcedClosed BreakerMode = "closed"
+)
+
+// ValidBreakerMode reports whether mode is a recognised breaker override.
+func ValidBreakerMode(mode BreakerMode) bool {
+	switch mode {
+	case BreakerAuto, BreakerForcedOpen, BreakerForcedClosed:
+		return true
+	}
+	return false
+}
+
+// BreakerTransition describes a change in a provider's effective breaker state.
+type BreakerTransition struct {
+	Provider string
+	From     string
+	To       string
+	Forced   bool // true when the change came from an operator override
+}
+
+// BreakerCounts mirrors gobreaker.Counts for the current breaker interval.
+type BreakerCounts struct {
+	Requests             uint32 `json:"requests"`
+	TotalSuccesses       uint32 `json:"total_successes"`
+	TotalFailures        uint32 `json:"total_failures"`
+	ConsecutiveSuccesses uint32 `json:"consecutive_successes"`
+	ConsecutiveFailures  uint32 `json:"consecutive_failures"`
+}
+
+// ProviderStatus is a point-in-time view of a registered provider.
+type ProviderStatus struct {
+	Name         string        `json:"name"`
+	Priority     int           `json:"priority"`
+	Weight       int           `json:"weight"`
+	Enabled      bool          `json:"enabled"`
+	Shadow       bool          `json:"shadow"`
+	Healthy      bool          `json:"healthy"`
+	BreakerState string        `json:"breaker_state"`
+	BreakerMode  BreakerMode   `json:"breaker_mode"`
+	Counts       BreakerCounts `json:"counts"`
+}
+
+// ErrUnknownProvider is returned when a breaker override names an unregistered provider.
+var ErrUnknownProvider = errors.New("provider not registered")
+
+// defaultBreakerConfig matches the settings used before breakers were configurable.
+var defaultBreakerConfig = BreakerConfig{
+	MaxRequests:         1,
+	Interval:            0,
+	Timeout:             60 * time.Second,
+	ConsecutiveFailures: 5,
+}
+
+// withBreakerDefaults fills unset fields of cfg from defaultBreakerConfig.
+// Interval keeps its zero value, which never clears counts while closed.
+func withBreakerDefaults(cfg *BreakerConfig) BreakerConfig {
+	if cfg == nil {
+		return defaultBreakerConfig
+	}
+	result := *cfg
+	if result.MaxRequests == 0 {
+		result.MaxRequests = defaultBreakerConfig.MaxRequests
+	}
+	if result.Timeout <= 0 {
+		result.Timeout = defaultBreakerConfig.Timeout
+	}
+	if result.ConsecutiveFailures == 0 {
+		result.ConsecutiveFailures = defaultBreakerConfig.ConsecutiveFailures
+	}
+	return result
+}
+
+// newBreaker builds the circuit breaker for a provider. Caller must hold o.mu.
+func (o *Orchestrator) newBreaker(name string) *gobreaker.CircuitBreaker {
+	var cfg *BreakerConfig
+	for _, pcfg := range o.config.Providers {
+		if pcfg.Name == name {
+			cfg = pcfg.Breaker
+			break
+		}
+	}
+	settings := withBreakerDefaults(cfg)
+
+	return gobreaker.NewCircuitBreaker(gobreaker.Settings{
+		Name:        "classifier-" + name,
+		MaxRequests: settings.MaxRequests, // requests allowed in half-open state
+		Interval:    settings.Interval,    // 0 = don't clear counts in closed state
+		Timeout:     settings.Timeout,     // open -> half-open delay
+		ReadyToTrip: func(counts gobreaker.Counts) bool {
+			return counts.ConsecutiveFailures >= settings.ConsecutiveFailures
+		},
+		// Hedged attempts that lose the race are cancelled; don't count them against the provider.
+		IsSuccessful: func(err error) bool {
+			return err == nil || errors.Is(err, context.Canceled)
+		},
+		OnStateChange: func(breakerName string, from gobreaker.State, to gobreaker.State) {
+			o.logger.Warn("circuit breaker state change",
+				zap.String("breaker", breakerName),
+				zap.String("from", from.String()),
+				zap.String("to", to.String()),
+			)
+			o.notifyBreaker(BreakerTransition{Provider: name, From: from.String(), To: to.String()})
+		},
+	})
+}
+
+// OnBreakerStateChange registers a callback invoked whenever a provider's effective
+// breaker state changes, including operator overrides. The callback runs
+// synchronously on the classification path and must not block.
+func (o *Orchestrator) OnBreakerStateChange(fn func(BreakerTransition)) {
+	o.listenerMu.Lock()
+	defer o.listenerMu.Unlock()
+	o.breakerListener = fn
+}
+
+func (o *Orchestrator) notifyBreaker(t BreakerTransition) {
+	o.listenerMu.RLock()
+	fn := o.breakerListener
+	o.listenerMu.RUnlock()
+	if fn != nil {
+		fn(t)
+	}
+}
+
+// SetBreakerMode forces a provider's breaker open or closed, or returns it to
+// automatic operation. Returning to auto starts from a fresh, closed breaker.
+func (o *Orchestrator) SetBreakerMode(name string, mode BreakerMode) error {
+	if !ValidBreakerMode(mode) {
+		return fmt.Errorf("invalid breaker mode %q", mode)
+	}
+
+	o.mu.Lock()
+	breaker, exists := o.breakers[name]
+	if !exists {
+		o.mu.Unlock()
+		return fmt.Errorf("%s: %w", name, ErrUnknownProvider)
+	}
+	prev := o.breakerModes[name]
+	if prev == "" {
+		prev = BreakerAuto
+	}
+	if prev == mode {
+		o.mu.Unlock()
+		return nil
+	}
+	if mode == BreakerAuto {
+		delete(o.breakerModes, name)
+		if prev != BreakerAuto {
+			o.breakers[name] = o.newBreaker(name)
+		}
+	} else {
+		o.breakerModes[name] = mode
+	}
+	current := o.breakers[name]
+	o.mu.Unlock()
+
+	// Breaker state is read outside o.mu: State() may fire OnStateChange.
+	from := effectiveBreakerState(prev, breaker)
+	to := effectiveBreakerState(mode, current)
+
+	o.logger.Warn("circuit breaker mode changed",
+		zap.String("provider", name),
+		zap.String("mode", string(mode)),
+	)
+	if from != to {
+		o.notifyBreaker(BreakerTransition{Provider: name, From: from, To: to, Forced: true})
+	}
+	return nil
+}
+
+// effectiveBreakerState returns the state callers observe once mode is applied.
+func effectiveBreakerState(mode BreakerMode, breaker *gobreaker.CircuitBreaker) string {
+	switch mode {
+	case BreakerForcedOpen:
+		return gobreaker.StateOpen.String()
+	case BreakerForcedClosed:
+		return gobreaker.StateClosed.String()
+	}
+	if breaker == nil {
+		return gobreaker.StateClosed.String()
+	}
+	return breaker.State().String()
+}
+
+// BreakerStates returns the effective breaker state of every registered
+// provider. Unlike ProviderStatuses it makes no health checks.
+func (o *Orchestrator) BreakerStates() map[string]string {
+	o.mu.RLock()
+	guards := make(map[string]providerBreaker, len(o.providers))
+	for name := range o.providers {
+		guards[name] = o.breakerFor(name)
+	}
+	o.mu.RUnlock()
+
+	// Breaker state is read outside o.mu: State() may fire OnStateChange.
+	states := make(map[string]string, len(guards))
+	for name, guard := range guards {
+		mode := guard.mode
+		if mode == "" {
+			mode = BreakerAuto
+		}
+		states[name] = effectiveBreakerState(mode, guard.breaker)
+	}
+	return states
+}
+
+// ProviderStatuses returns health, breaker state and counts for every registered
+// provider, sorted by priority then name.
+func (o *Orchestrator) ProviderStatuses(ctx context.Context) []ProviderStatus {
+	type entry struct {
+		status   ProviderStatus
+		provider Provider
+		breaker  *gobreaker.CircuitBreaker
+	}
+
+	o.mu.RLock()
+	configs := make(map[string]ProviderConfig, len(o.config.Providers))
+	for _, pcfg := range o.config.Providers {
+		configs[pcfg.Name] = pcfg
+	}
+	entries := make([]entry, 0, len(o.providers))
+	for name, provider := range o.providers {
+		mode := o.breakerModes[name]
+		if mode == "" {
+			mode = BreakerAuto
+		}
+		pcfg := configs[name]
+		entries = append(entries, entry{
+			status: ProviderStatus{
+				Name:        name,
+				Priority:    pcfg.Priority,
+				Weight:      pcfg.Weight,
+				Enabled:     pcfg.Enabled,
+				Shadow:      pcfg.Shadow,
+				BreakerMode: mode,
+			},
+			provider: provider,
+			breaker:  o.breakers[name],
+		})
+	}
+	o.mu.RUnlock()
+
+	statuses := make([]ProviderStatus, len(entries))
+	for i, e := range entries {
+		s := e.status
+		s.BreakerState = effectiveBreakerState(s.BreakerMode, e.breaker)
+		if e.breaker != nil {
+			counts := e.breaker.Counts()
+			s.Counts = BreakerCounts{
+				Requests:             counts.Requests,
+				TotalSuccesses:       counts.TotalSuccesses,
+				TotalFailures:        counts.TotalFailures,
+				ConsecutiveSuccesses: counts.ConsecutiveSuccesses,
+				ConsecutiveFailures:  counts.ConsecutiveFailures,
+			}
+		}
+		if err := e.provider.Health(ctx); err != nil {
+			o.logger.Warn("provider health check failed", zap.String("provider", s.Name), zap.Error(err))
+		} else {
+			s.Healthy = true
+		}
+		statuses[i] = s
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		if statuses[i].Priority != statuses[j].Priority {
+			return statuses[i].Priority < statuses[j].Priority
+		}
+		return statuses[i].Name < statuses[j].Name
+	})
+	return statuses
+}
+
+// breakerOverride is the JSON form of BreakerConfig, with durations as strings.
+type breakerOverride struct {
+	MaxRequests         *uint32 `json:"max_requests,omitempty"`
+	Interval            string  `json:"interval,omitempty"`
+	Timeout             string  `json:"timeout,omitempty"`
+	ConsecutiveFailures *uint32 `json:"consecutive_failures,omitempty"`
+}
+
+// ApplyBreakerJSON applies per-provider circuit breaker settings from a JSON
+// object keyed by provider name, e.g. to trip OpenAI sooner and retry it faster:
+//
+//	{"openai":{"consecutive_failures":3,"timeout":"20s","max_requests":2}}
+//
+// Unset fields keep their defaults. Configs are returned unchanged if the JSON is empty.
+func ApplyBreakerJSON(configs []ProviderConfig, breakerJSON string) ([]ProviderConfig, error) {
+	if breakerJSON == "" {
+		return configs, nil
+	}
+	var overrides map[string]breakerOverride
+	if err := json.Unmarshal([]byte(breakerJSON), &overrides); err != nil {
+		return configs, fmt.Errorf("invalid circuit breaker config: %w", err)
+	}
+
+	result := make([]ProviderConfig, len(configs))
+	copy(result, configs)
+	for i := range result {
+		o, ok := overrides[result[i].Name]
+		if !ok {
+			continue
+		}
+		cfg := withBreakerDefaults(result[i].Breaker)
+		if o.MaxRequests != nil {
+			cfg.MaxRequests = *o.MaxRequests
+		}
+		if o.ConsecutiveFailures != nil {
+			cfg.ConsecutiveFailures = *o.ConsecutiveFailures
+		}
+		if o.Interval != "" {
+			d, err := time.ParseDuration(o.Interval)
+			if err != nil || d < 0 {
+				return configs, fmt.Errorf("provider %s: invalid breaker interval %q", result[i].Name, o.Interval)
+			}
+			cfg.Interval = d
+		}
+		if o.Timeout != "" {
+			d, err := time.ParseDuration(o.Timeout)
+			if err != nil || d <= 0 {
+				return configs, fmt.Errorf("provider %s: invalid breaker timeout %q", result[i].Name, o.Timeout)
+			}
+			cfg.Timeout = d
+		}
+		result[i].Breaker = &cfg
+	}
+	return result, nil
+}
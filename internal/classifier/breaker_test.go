@@ -0,0 +1,247 @@
+package classifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/proth1/text-moderator/internal/models"
+	"github.com/sony/gobreaker"
+	"go.uber.org/zap"
+)
+
+func TestWithBreakerDefaults(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  *BreakerConfig
+		want BreakerConfig
+	}{
+		{"nil", nil, defaultBreakerConfig},
+		{"zero", &BreakerConfig{}, defaultBreakerConfig},
+		{
+			"partial",
+			&BreakerConfig{ConsecutiveFailures: 2, Interval: time.Minute},
+			BreakerConfig{MaxRequests: 1, Interval: time.Minute, Timeout: 60 * time.Second, ConsecutiveFailures: 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := withBreakerDefaults(tt.cfg); got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyBreakerJSON(t *testing.T) {
+	configs := []ProviderConfig{{Name: "huggingface"}, {Name: "openai"}}
+
+	got, err := ApplyBreakerJSON(configs, `{"openai":{"consecutive_failures":3,"timeout":"20s"}}`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got[0].Breaker != nil {
+		t.Errorf("huggingface breaker = %+v, want nil", got[0].Breaker)
+	}
+	want := BreakerConfig{MaxRequests: 1, Timeout: 20 * time.Second, ConsecutiveFailures: 3}
+	if got[1].Breaker == nil || *got[1].Breaker != want {
+		t.Errorf("openai breaker = %+v, want %+v", got[1].Breaker, want)
+	}
+	if configs[1].Breaker != nil {
+		t.Error("input configs were modified")
+	}
+
+	for _, bad := range []string{`not json`, `{"openai":{"timeout":"soon"}}`, `{"openai":{"interval":"-1s"}}`} {
+		if _, err := ApplyBreakerJSON(configs, bad); err == nil {
+			t.Errorf("expected error for %s", bad)
+		}
+	}
+}
+
+func TestBreakerConfig_TripsAfterConfiguredFailures(t *testing.T) {
+	broken := &fakeProvider{name: "broken", err: fmt.Errorf("down")}
+	o := NewOrchestrator(OrchestratorConfig{
+		Providers: []ProviderConfig{
+			{Name: "broken", Priority: 1, Enabled: true, Breaker: &BreakerConfig{ConsecutiveFailures: 2}},
+		},
+	}, zap.NewNop())
+	o.RegisterProvider(broken)
+
+	for i := 0; i < 3; i++ {
+		o.Classify(context.Background(), "hello")
+	}
+	if calls := broken.calls.Load(); calls != 2 {
+		t.Errorf("provider called %d times, want 2 before the breaker opened", calls)
+	}
+	if state := o.breakers["broken"].State(); state != gobreaker.StateOpen {
+		t.Errorf("breaker is %s, want open", state)
+	}
+}
+
+func TestSetBreakerMode(t *testing.T) {
+	primary := &fakeProvider{name: "primary"}
+	backup := &fakeProvider{name: "backup"}
+	o := newChainTestOrchestrator(OrchestratorConfig{}, primary, backup)
+
+	var mu sync.Mutex
+	var transitions []BreakerTransition
+	o.OnBreakerStateChange(func(bt BreakerTransition) {
+		mu.Lock()
+		defer mu.Unlock()
+		transitions = append(transitions, bt)
+	})
+
+	if err := o.SetBreakerMode("primary", BreakerForcedOpen); err != nil {
+		t.Fatalf("force open failed: %v", err)
+	}
+	result, err := o.Classify(context.Background(), "hello")
+	if err != nil {
+		t.Fatalf("classify failed: %v", err)
+	}
+	if result.ProviderName != "backup" || primary.calls.Load() != 0 {
+		t.Errorf("got provider %s with %d primary calls, want backup and 0", result.ProviderName, primary.calls.Load())
+	}
+
+	if err := o.SetBreakerMode("primary", BreakerAuto); err != nil {
+		t.Fatalf("reset failed: %v", err)
+	}
+	result, err = o.Classify(context.Background(), "hello")
+	if err != nil {
+		t.Fatalf("classify failed: %v", err)
+	}
+	if result.ProviderName != "primary" {
+		t.Errorf("got provider %s after reset, want primary", result.ProviderName)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	want := []BreakerTransition{
+		{Provider: "primary", From: "closed", To: "open", Forced: true},
+		{Provider: "primary", From: "open", To: "closed", Forced: true},
+	}
+	if len(transitions) != len(want) {
+		t.Fatalf("got %d transitions, want %d: %+v", len(transitions), len(want), transitions)
+	}
+	for i := range want {
+		if transitions[i] != want[i] {
+			t.Errorf("transition %d = %+v, want %+v", i, transitions[i], want[i])
+		}
+	}
+}
+
+func TestSetBreakerMode_ForcedClosedIgnoresFailures(t *testing.T) {
+	flaky := &fakeProvider{name: "flaky", err: fmt.Errorf("down")}
+	o := NewOrchestrator(OrchestratorConfig{
+		Providers: []ProviderConfig{
+			{Name: "flaky", Priority: 1, Enabled: true, Breaker: &BreakerConfig{ConsecutiveFailures: 1}},
+		},
+	}, zap.NewNop())
+	o.RegisterProvider(flaky)
+
+	if err := o.SetBreakerMode("flaky", BreakerForcedClosed); err != nil {
+		t.Fatalf("force closed failed: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		_, err := o.Classify(context.Background(), "hello")
+		if errors.Is(err, gobreaker.ErrOpenState) {
+			t.Fatal("forced-closed breaker rejected a call")
+		}
+	}
+	if calls := flaky.calls.Load(); calls != 3 {
+		t.Errorf("provider called %d times, want 3", calls)
+	}
+}
+
+func TestSetBreakerMode_ForcedOpenStopsLanguageAndContextTraffic(t *testing.T) {
+	english := &fakeProvider{name: "english"}
+	spanish := &fakeLanguageProvider{fakeProvider: fakeProvider{name: "spanish"}, languages: []string{"es"}}
+	aware := &fakeContextProvider{fakeProvider: fakeProvider{name: "aware"}, contextScores: models.CategoryScores{Harassment: 0.9}}
+	o := newLanguageTestOrchestrator(spanish, aware, english)
+
+	for _, name := range []string{"spanish", "aware"} {
+		if err := o.SetBreakerMode(name, BreakerForcedOpen); err != nil {
+			t.Fatalf("force open %s failed: %v", name, err)
+		}
+	}
+
+	result, err := o.ClassifyWithLanguage(context.Background(), "hola", []string{"es"})
+	if err != nil {
+		t.Fatalf("classify failed: %v", err)
+	}
+	if result.ProviderName == "spanish" || spanish.calls.Load() != 0 {
+		t.Errorf("got provider %s with %d spanish calls, want the forced-open provider skipped", result.ProviderName, spanish.calls.Load())
+	}
+
+	history := []models.ConversationMessage{{AuthorID: "a", Content: "earlier"}}
+	result, err = o.ClassifyWithContext(context.Background(), models.ConversationMessage{AuthorID: "a", Content: "hello"}, history, []string{"en"})
+	if err != nil {
+		t.Fatalf("classify failed: %v", err)
+	}
+	if result.UsedContext || aware.history != nil {
+		t.Errorf("got provider %s (used context %v), want the forced-open context provider skipped", result.ProviderName, result.UsedContext)
+	}
+}
+
+func TestSetBreakerMode_Errors(t *testing.T) {
+	o := newChainTestOrchestrator(OrchestratorConfig{}, &fakeProvider{name: "primary"})
+
+	if err := o.SetBreakerMode("missing", BreakerForcedOpen); !errors.Is(err, ErrUnknownProvider) {
+		t.Errorf("got %v, want ErrUnknownProvider", err)
+	}
+	if err := o.SetBreakerMode("primary", BreakerMode("sideways")); err == nil {
+		t.Error("expected error for invalid mode")
+	}
+}
+
+func TestProviderStatuses(t *testing.T) {
+	o := NewOrchestrator(OrchestratorConfig{
+		Providers: []ProviderConfig{
+			{Name: "b", Priority: 2, Enabled: true},
+			{Name: "a", Priority: 1, Weight: 50, Enabled: true},
+		},
+	}, zap.NewNop())
+	o.RegisterProvider(&fakeProvider{name: "a"})
+	o.RegisterProvider(&fakeProvider{name: "b"})
+	o.Classify(context.Background(), "hello")
+	o.SetBreakerMode("b", BreakerForcedOpen)
+
+	statuses := o.ProviderStatuses(context.Background())
+	if len(statuses) != 2 {
+		t.Fatalf("got %d statuses, want 2", len(statuses))
+	}
+
+	a, b := statuses[0], statuses[1]
+	if a.Name != "a" || b.Name != "b" {
+		t.Fatalf("statuses not sorted by priority: %s, %s", a.Name, b.Name)
+	}
+	if !a.Healthy || a.BreakerState != "closed" || a.BreakerMode != BreakerAuto || a.Weight != 50 {
+		t.Errorf("a: got %+v", a)
+	}
+	if a.Counts.Requests != 1 || a.Counts.TotalSuccesses != 1 {
+		t.Errorf("a counts: got %+v, want 1 request / 1 success", a.Counts)
+	}
+	if b.BreakerState != "open" || b.BreakerMode != BreakerForcedOpen {
+		t.Errorf("b: got state %s mode %s, want open / open", b.BreakerState, b.BreakerMode)
+	}
+}
+
+func TestBreakerStates(t *testing.T) {
+	o := newChainTestOrchestrator(OrchestratorConfig{}, &fakeProvider{name: "a"}, &fakeProvider{name: "b"}, &fakeProvider{name: "c"})
+	o.SetBreakerMode("b", BreakerForcedOpen)
+	o.SetBreakerMode("c", BreakerForcedClosed)
+
+	got := o.BreakerStates()
+	want := map[string]string{"a": "closed", "b": "open", "c": "closed"}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for name, state := range want {
+		if got[name] != state {
+			t.Errorf("%s: got %s, want %s", name, got[name], state)
+		}
+	}
+}
@@ -4,15 +4,30 @@ import (
 	"context"
 	"encoding/json"
 	"math"
+	"sort"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/proth1/text-moderator/internal/models"
 )
 
-// CalibrationParams holds the offset and scale for a single category.
+// Calibration methods. Hand-written configs default to CalibrationLinear.
+const (
+	CalibrationLinear   = "linear"
+	CalibrationPlatt    = "platt"
+	CalibrationIsotonic = "isotonic"
+)
+
+// CalibrationParams holds the calibration for a single category.
+// Linear uses Offset and Scale; Platt uses A and B; isotonic interpolates
+// between the fitted (X, Y) points.
 type CalibrationParams struct {
-	Offset float64 `json:"offset"`
-	Scale  float64 `json:"scale"`
+	Method string    `json:"method,omitempty"`
+	Offset float64   `json:"offset"`
+	Scale  float64   `json:"scale"`
+	A      float64   `json:"a,omitempty"`
+	B      float64   `json:"b,omitempty"`
+	X      []float64 `json:"x,omitempty"`
+	Y      []float64 `json:"y,omitempty"`
 }
 
 // ProviderCalibration holds calibration parameters per category for a provider.
@@ -25,7 +40,8 @@ type CalibrationConfig map[string]ProviderCalibration
 
 // Calibrator normalizes raw provider scores to a unified 0-1 scale.
 type Calibrator struct {
-	config CalibrationConfig
+	config  CalibrationConfig
+	version *int // fitted calibration version; nil for hand-written configs
 }
 
 // NewCalibrator creates a Calibrator from the given config.
@@ -33,6 +49,19 @@ func NewCalibrator(config CalibrationConfig) *Calibrator {
 	return &Calibrator{config: config}
 }
 
+// NewVersionedCalibrator creates a Calibrator for a fitted calibration version.
+func NewVersionedCalibrator(config CalibrationConfig, version int) *Calibrator {
+	return &Calibrator{config: config, version: &version}
+}
+
+// Version returns the fitted calibration version, or nil for hand-written configs.
+func (c *Calibrator) Version() *int {
+	if c == nil {
+		return nil
+	}
+	return c.version
+}
+
 // NewCalibratorFromJSON creates a Calibrator from a JSON string.
 // Returns nil if the JSON is empty or invalid.
 func NewCalibratorFromJSON(configJSON string) *Calibrator {
@@ -47,7 +76,9 @@ func NewCalibratorFromJSON(configJSON string) *Calibrator {
 }
 
 // Calibrate applies calibration to raw scores from a named provider.
-// Formula: calibrated = clamp((raw + offset) * scale, 0.0, 1.0)
+// Linear: calibrated = clamp((raw + offset) * scale, 0.0, 1.0)
+// Platt: calibrated = 1 / (1 + exp(-(a*raw + b)))
+// Isotonic: calibrated = piecewise-linear interpolation over the fitted points
 // If no calibration exists for the provider, scores are returned unchanged.
 func (c *Calibrator) Calibrate(providerName string, scores *models.CategoryScores) *models.CategoryScores {
 	if c == nil || c.config == nil {
@@ -59,18 +90,13 @@ func (c *Calibrator) Calibrate(providerName string, scores *models.CategoryScore
 		return scores
 	}
 
-	calibrated := *scores // copy
-
-	calibrated.Toxicity = c.calibrateValue(providerCal, "toxicity", scores.Toxicity)
-	calibrated.Hate = c.calibrateValue(providerCal, "hate", scores.Hate)
-	calibrated.Harassment = c.calibrateValue(providerCal, "harassment", scores.Harassment)
-	calibrated.SexualContent = c.calibrateValue(providerCal, "sexual_content", scores.SexualContent)
-	calibrated.Violence = c.calibrateValue(providerCal, "violence", scores.Violence)
-	calibrated.Profanity = c.calibrateValue(providerCal, "profanity", scores.Profanity)
-	calibrated.SelfHarm = c.calibrateValue(providerCal, "self_harm", scores.SelfHarm)
-	calibrated.Spam = c.calibrateValue(providerCal, "spam", scores.Spam)
-	calibrated.PII = c.calibrateValue(providerCal, "pii", scores.PII)
+	values := scores.ToMap()
+	for category, raw := range values {
+		values[category] = c.calibrateValue(providerCal, category, raw)
+	}
 
+	calibrated := *scores // copy
+	calibrated.FromMap(values)
 	return &calibrated
 }
 
@@ -79,9 +105,35 @@ func (c *Calibrator) calibrateValue(providerCal ProviderCalibration, category st
 	if !exists {
 		return raw
 	}
+	switch params.Method {
+	case CalibrationPlatt:
+		return 1.0 / (1.0 + math.Exp(-(params.A*raw + params.B)))
+	case CalibrationIsotonic:
+		return clamp(interpolate(params.X, params.Y, raw), 0.0, 1.0)
+	}
 	return clamp((raw+params.Offset)*params.Scale, 0.0, 1.0)
 }
 
+// interpolate evaluates the piecewise-linear function through points (xs, ys),
+// which must be sorted by x, holding the end values constant outside the range.
+func interpolate(xs, ys []float64, x float64) float64 {
+	if len(xs) == 0 || len(xs) != len(ys) {
+		return x
+	}
+	i := sort.SearchFloat64s(xs, x)
+	if i == 0 {
+		return ys[0]
+	}
+	if i == len(xs) {
+		return ys[len(ys)-1]
+	}
+	x0, x1 := xs[i-1], xs[i]
+	if x1 == x0 {
+		return ys[i]
+	}
+	return ys[i-1] + (ys[i]-ys[i-1])*(x-x0)/(x1-x0)
+}
+
 // UpdateFromFeedback dynamically updates calibration offsets from feedback data.
 func (c *Calibrator) UpdateFromFeedback(providerName string, category string, offset float64, scale float64) {
 	if c.config == nil {
@@ -106,3 +106,65 @@ func TestUpdateFromFeedback(t *testing.T) {
 		t.Errorf("after feedback update, toxicity = %f, want %f", result.Toxicity, expected)
 	}
 }
+
+func TestCalibrate_Platt(t *testing.T) {
+	config := CalibrationConfig{
+		"test": ProviderCalibration{
+			Categories: map[string]CalibrationParams{
+				"toxicity": {Method: CalibrationPlatt, A: 10, B: -5},
+			},
+		},
+	}
+	c := NewCalibrator(config)
+
+	// sigmoid(10*0.5 - 5) = sigmoid(0) = 0.5
+	result := c.Calibrate("test", &models.CategoryScores{Toxicity: 0.5})
+	if result.Toxicity < 0.499 || result.Toxicity > 0.501 {
+		t.Errorf("calibrated toxicity = %f, want 0.5", result.Toxicity)
+	}
+	low := c.Calibrate("test", &models.CategoryScores{Toxicity: 0.1})
+	high := c.Calibrate("test", &models.CategoryScores{Toxicity: 0.9})
+	if low.Toxicity >= 0.5 || high.Toxicity <= 0.5 {
+		t.Errorf("got low %f, high %f, want below and above 0.5", low.Toxicity, high.Toxicity)
+	}
+}
+
+func TestCalibrate_Isotonic(t *testing.T) {
+	config := CalibrationConfig{
+		"test": ProviderCalibration{
+			Categories: map[string]CalibrationParams{
+				"toxicity": {Method: CalibrationIsotonic, X: []float64{0.2, 0.6}, Y: []float64{0.1, 0.9}},
+			},
+		},
+	}
+	c := NewCalibrator(config)
+
+	tests := []struct {
+		raw, want float64
+	}{
+		{0.0, 0.1}, // below range clamps to first point
+		{0.2, 0.1},
+		{0.4, 0.5}, // midway interpolates
+		{0.6, 0.9},
+		{1.0, 0.9}, // above range clamps to last point
+	}
+	for _, tt := range tests {
+		result := c.Calibrate("test", &models.CategoryScores{Toxicity: tt.raw})
+		if result.Toxicity < tt.want-0.001 || result.Toxicity > tt.want+0.001 {
+			t.Errorf("raw %f: got %f, want %f", tt.raw, result.Toxicity, tt.want)
+		}
+	}
+}
+
+func TestCalibratorVersion(t *testing.T) {
+	var nilCal *Calibrator
+	if nilCal.Version() != nil {
+		t.Error("nil calibrator should have no version")
+	}
+	if NewCalibrator(CalibrationConfig{}).Version() != nil {
+		t.Error("hand-written calibrator should have no version")
+	}
+	if v := NewVersionedCalibrator(CalibrationConfig{}, 3).Version(); v == nil || *v != 3 {
+		t.Errorf("got %v, want 3", v)
+	}
+}
@@ -0,0 +1,308 @@
+package classifier
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/proth1/text-moderator/internal/models"
+)
+
+// Chunk aggregation strategies.
+const (
+	ChunkAggregateMax      = "max"
+	ChunkAggregateTopKMean = "topk_mean"
+)
+
+// Chunk is one window of content. Start and End are rune offsets into the
+// text the window was cut from.
+type Chunk struct {
+	Index int
+	Start int
+	End   int
+	Text  string
+}
+
+// piece is a word, or part of an over-long word, with its estimated token cost.
+// start and end are byte offsets.
+type piece struct {
+	start, end int
+	tokens     int
+}
+
+// estimateTokens approximates the subword tokens a word costs. Byte-level BPE
+// vocabularies average about four ASCII characters per token, while other
+// scripts often cost a token or more per character, so each non-ASCII rune is
+// counted as a whole token to stay on the safe side.
+func estimateTokens(word string) int {
+	ascii, other := 0, 0
+	for _, r := range word {
+		if r < utf8.RuneSelf {
+			ascii++
+		} else {
+			other++
+		}
+	}
+	return tokenCost(ascii, other)
+}
+
+func tokenCost(ascii, other int) int {
+	n := (ascii+3)/4 + other
+	if n < 1 {
+		n = 1
+	}
+	return n
+}
+
+// splitPieces splits text into whitespace-separated words, breaking any word
+// that alone exceeds maxTokens (e.g. unspaced CJK text or long URLs).
+func splitPieces(text string, maxTokens int) []piece {
+	var pieces []piece
+	addWord := func(start, end int) {
+		s, ascii, other := start, 0, 0
+		for i, r := range text[start:end] {
+			pos := start + i
+			a, o := ascii, other
+			if r < utf8.RuneSelf {
+				a++
+			} else {
+				o++
+			}
+			if pos > s && tokenCost(a, o) > maxTokens {
+				pieces = append(pieces, piece{s, pos, tokenCost(ascii, other)})
+				s, a, o = pos, 0, 0
+				if r < utf8.RuneSelf {
+					a = 1
+				} else {
+					o = 1
+				}
+			}
+			ascii, other = a, o
+		}
+		pieces = append(pieces, piece{s, end, tokenCost(ascii, other)})
+	}
+
+	start := -1
+	for i, r := range text {
+		if unicode.IsSpace(r) {
+			if start >= 0 {
+				addWord(start, i)
+				start = -1
+			}
+		} else if start < 0 {
+			start = i
+		}
+	}
+	if start >= 0 {
+		addWord(start, len(text))
+	}
+	return pieces
+}
+
+// SplitChunks splits text into overlapping windows of at most maxTokens
+// estimated tokens, breaking only at whitespace where possible. maxTokens
+// should leave headroom below the smallest provider limit (512 for the
+// RoBERTa models), since token counts are estimates. Consecutive
+// windows share up to overlapTokens so content straddling a boundary is seen
+// whole by at least one window. Windows are cut from the start of the text,
+// so an edit only changes the windows from the edit onwards. Text that fits
+// in one window, or a maxTokens of 0 or less, yields a single chunk.
+func SplitChunks(text string, maxTokens, overlapTokens int) []Chunk {
+	whole := []Chunk{{Index: 0, Start: 0, End: utf8.RuneCountInString(text), Text: text}}
+	if maxTokens <= 0 {
+		return whole
+	}
+	if overlapTokens < 0 || overlapTokens >= maxTokens {
+		overlapTokens = 0
+	}
+
+	pieces := splitPieces(text, maxTokens)
+	total := 0
+	for _, p := range pieces {
+		total += p.tokens
+	}
+	if total <= maxTokens {
+		return whole
+	}
+
+	var chunks []Chunk
+	for i := 0; i < len(pieces); {
+		j, tokens := i, 0
+		for j < len(pieces) && (j == i || tokens+pieces[j].tokens <= maxTokens) {
+			tokens += pieces[j].tokens
+			j++
+		}
+
+		start, end := pieces[i].start, pieces[j-1].end
+		runeStart := utf8.RuneCountInString(text[:start])
+		chunks = append(chunks, Chunk{
+			Index: len(chunks),
+			Start: runeStart,
+			End:   runeStart + utf8.RuneCountInString(text[start:end]),
+			Text:  text[start:end],
+		})
+		if j == len(pieces) {
+			break
+		}
+
+		// Step back over the overlap, always moving forward by at least one piece
+		next, overlap := j, 0
+		for next-1 > i && overlap+pieces[next-1].tokens <= overlapTokens {
+			next--
+			overlap += pieces[next].tokens
+		}
+		i = next
+	}
+	return chunks
+}
+
+// AggregateChunks combines per-window scores into one set of category scores
+// using strategy: "max" takes each category's highest window score, and
+// "topk_mean" averages its k highest. scores[i] belongs to chunks[i]. It also
+// reports, for each category any window scored above zero, the window with
+// the highest score, which drove the aggregated value.
+func AggregateChunks(chunks []Chunk, scores []*models.CategoryScores, strategy string, k int) (*models.CategoryScores, map[string]models.ChunkAttribution) {
+	attributions := make(map[string]models.ChunkAttribution)
+	if len(scores) == 0 {
+		return &models.CategoryScores{}, attributions
+	}
+	if k < 1 {
+		k = 1
+	}
+
+	perChunk := make([]map[string]float64, len(scores))
+	for i, s := range scores {
+		perChunk[i] = s.ToMap()
+	}
+
+	combined := make(map[string]float64, len(models.Categories))
+	for _, cat := range models.Categories {
+		values := make([]float64, len(scores))
+		driver := 0
+		for i := range scores {
+			values[i] = perChunk[i][cat]
+			if values[i] > values[driver] {
+				driver = i
+			}
+		}
+		if values[driver] > 0 {
+			c := chunks[driver]
+			attributions[cat] = models.ChunkAttribution{Index: c.Index, Start: c.Start, End: c.End, Score: values[driver]}
+		}
+
+		switch strategy {
+		case ChunkAggregateTopKMean:
+			sort.Sort(sort.Reverse(sort.Float64Slice(values)))
+			if k < len(values) {
+				values = values[:k]
+			}
+			combined[cat] = averageValues(values)
+		default:
+			combined[cat] = maxValues(values)
+		}
+	}
+
+	aggregated := &models.CategoryScores{}
+	aggregated.FromMap(combined)
+	return aggregated, attributions
+}
+
+// MergeEnsembleChunks folds per-window ensemble results into one result with
+// the given aggregated scores. The content counts as disputed if any window
+// was, and the provider results are those of the first window.
+func MergeEnsembleChunks(results []*EnsembleResult, combined *models.CategoryScores) *EnsembleResult {
+	merged := &EnsembleResult{CombinedScores: combined}
+	disagreed := make(map[string]bool)
+	for i, r := range results {
+		if i == 0 {
+			merged.ProviderResults = r.ProviderResults
+			merged.AgreementScores = r.AgreementScores
+		}
+		merged.HasDisagreement = merged.HasDisagreement || r.HasDisagreement
+		merged.EarlyExit = merged.EarlyExit || r.EarlyExit
+		for _, cat := range r.DisagreedCategories {
+			if !disagreed[cat] {
+				disagreed[cat] = true
+				merged.DisagreedCategories = append(merged.DisagreedCategories, cat)
+			}
+		}
+	}
+	sort.Strings(merged.DisagreedCategories)
+	return merged
+}
+
+// ClassifyChunks classifies each chunk with ClassifyWithLanguage, at most
+// concurrency at a time. Results are in chunk order; the first failure
+// cancels the remaining chunks and is returned.
+func (o *Orchestrator) ClassifyChunks(ctx context.Context, chunks []Chunk, langs []string, concurrency int) ([]*ClassificationResult, error) {
+	results := make([]*ClassificationResult, len(chunks))
+	err := forEachChunk(ctx, len(chunks), concurrency, func(ctx context.Context, i int) error {
+		result, err := o.ClassifyWithLanguage(ctx, chunks[i].Text, langs)
+		results[i] = result
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// ClassifyEnsembleChunks is ClassifyChunks for ensemble mode.
+func (o *Orchestrator) ClassifyEnsembleChunks(ctx context.Context, chunks []Chunk, concurrency int) ([]*EnsembleResult, error) {
+	results := make([]*EnsembleResult, len(chunks))
+	err := forEachChunk(ctx, len(chunks), concurrency, func(ctx context.Context, i int) error {
+		result, err := o.ClassifyEnsemble(ctx, chunks[i].Text)
+		results[i] = result
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// forEachChunk runs fn for indexes 0..n-1 with at most concurrency running at
+// once, stopping at the first error.
+func forEachChunk(ctx context.Context, n, concurrency int, fn func(ctx context.Context, i int) error) error {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	var once sync.Once
+	var firstErr error
+
+	for i := 0; i < n; i++ {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			if err := fn(ctx, i); err != nil {
+				once.Do(func() {
+					firstErr = err
+					cancel()
+				})
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+	return ctx.Err()
+}
@@ -0,0 +1,187 @@
+package classifier
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/proth1/text-moderator/internal/models"
+)
+
+func TestEstimateTokens(t *testing.T) {
+	tests := []struct {
+		word string
+		want int
+	}{
+		{"a", 1},
+		{"word", 1},
+		{"words", 2},
+		{"日本語", 3},
+		{"", 1},
+	}
+	for _, tt := range tests {
+		if got := estimateTokens(tt.word); got != tt.want {
+			t.Errorf("estimateTokens(%q) = %d, want %d", tt.word, got, tt.want)
+		}
+	}
+}
+
+func TestSplitChunks_Short(t *testing.T) {
+	chunks := SplitChunks("a short comment", 10, 2)
+	if len(chunks) != 1 {
+		t.Fatalf("got %d chunks, want 1", len(chunks))
+	}
+	if chunks[0].Text != "a short comment" || chunks[0].Start != 0 || chunks[0].End != 15 {
+		t.Errorf("got chunk %+v", chunks[0])
+	}
+
+	if got := SplitChunks(strings.Repeat("word ", 100), 0, 0); len(got) != 1 {
+		t.Errorf("maxTokens 0 gave %d chunks, want 1", len(got))
+	}
+}
+
+func TestSplitChunks_Windows(t *testing.T) {
+	words := make([]string, 30)
+	for i := range words {
+		words[i] = "w" + string(rune('a'+i%26))
+	}
+	text := strings.Join(words, " ")
+
+	chunks := SplitChunks(text, 10, 3)
+	if len(chunks) < 3 {
+		t.Fatalf("got %d chunks, want at least 3", len(chunks))
+	}
+
+	runes := []rune(text)
+	for i, c := range chunks {
+		if c.Index != i {
+			t.Errorf("chunk %d has index %d", i, c.Index)
+		}
+		if n := len(strings.Fields(c.Text)); n > 10 {
+			t.Errorf("chunk %d has %d tokens, want at most 10", i, n)
+		}
+		if string(runes[c.Start:c.End]) != c.Text {
+			t.Errorf("chunk %d offsets [%d,%d) do not match its text %q", i, c.Start, c.End, c.Text)
+		}
+		if i > 0 && c.Start >= chunks[i-1].End {
+			t.Errorf("chunk %d does not overlap the previous chunk", i)
+		}
+	}
+	if chunks[0].Start != 0 || chunks[len(chunks)-1].End != len(runes) {
+		t.Error("chunks do not cover the whole text")
+	}
+}
+
+func TestSplitChunks_EditReusesEarlierWindows(t *testing.T) {
+	text := strings.Repeat("calm words here ", 20)
+	edited := text + "and a new insult at the end"
+
+	before := SplitChunks(text, 12, 2)
+	after := SplitChunks(edited, 12, 2)
+
+	for i := 0; i < len(before)-1; i++ {
+		if before[i].Text != after[i].Text {
+			t.Errorf("chunk %d changed after an edit at the end: %q != %q", i, before[i].Text, after[i].Text)
+		}
+	}
+}
+
+func TestSplitChunks_LongWord(t *testing.T) {
+	text := strings.Repeat("漢", 25)
+
+	chunks := SplitChunks(text, 10, 0)
+	if len(chunks) != 3 {
+		t.Fatalf("got %d chunks, want 3", len(chunks))
+	}
+	var joined strings.Builder
+	for _, c := range chunks {
+		joined.WriteString(c.Text)
+	}
+	if joined.String() != text {
+		t.Errorf("chunks do not reassemble the text")
+	}
+}
+
+func TestAggregateChunks(t *testing.T) {
+	chunks := []Chunk{
+		{Index: 0, Start: 0, End: 10},
+		{Index: 1, Start: 8, End: 20},
+		{Index: 2, Start: 18, End: 30},
+	}
+	scores := []*models.CategoryScores{
+		{Toxicity: 0.2, Spam: 0.9},
+		{Toxicity: 0.8, Spam: 0.1},
+		{Toxicity: 0.6, Spam: 0.3},
+	}
+
+	maxScores, attributions := AggregateChunks(chunks, scores, ChunkAggregateMax, 0)
+	if maxScores.Toxicity != 0.8 || maxScores.Spam != 0.9 {
+		t.Errorf("max got %+v", maxScores)
+	}
+	if got := attributions["toxicity"]; got.Index != 1 || got.Start != 8 || got.End != 20 || got.Score != 0.8 {
+		t.Errorf("toxicity attribution = %+v, want window 1 [8,20) at 0.8", got)
+	}
+	if got := attributions["spam"]; got.Index != 0 {
+		t.Errorf("spam attribution = %+v, want window 0", got)
+	}
+	if _, ok := attributions["hate"]; ok {
+		t.Error("attribution reported for a category no window scored")
+	}
+
+	mean, _ := AggregateChunks(chunks, scores, ChunkAggregateTopKMean, 2)
+	if !almostEqual(mean.Toxicity, 0.7) || !almostEqual(mean.Spam, 0.6) {
+		t.Errorf("topk_mean got %+v, want toxicity 0.7 and spam 0.6", mean)
+	}
+}
+
+func TestMergeEnsembleChunks(t *testing.T) {
+	combined := &models.CategoryScores{Toxicity: 0.5}
+	results := []*EnsembleResult{
+		{ProviderResults: []ClassificationResult{{ProviderName: "a"}}},
+		{HasDisagreement: true, DisagreedCategories: []string{"toxicity"}},
+		{HasDisagreement: true, DisagreedCategories: []string{"hate", "toxicity"}},
+	}
+
+	merged := MergeEnsembleChunks(results, combined)
+	if merged.CombinedScores != combined {
+		t.Error("combined scores not used")
+	}
+	if !merged.HasDisagreement {
+		t.Error("disagreement in a later window was lost")
+	}
+	if strings.Join(merged.DisagreedCategories, ",") != "hate,toxicity" {
+		t.Errorf("disagreed categories = %v, want [hate toxicity]", merged.DisagreedCategories)
+	}
+	if len(merged.ProviderResults) != 1 || merged.ProviderResults[0].ProviderName != "a" {
+		t.Errorf("provider results = %+v, want the first window's", merged.ProviderResults)
+	}
+}
+
+func TestForEachChunk(t *testing.T) {
+	var calls atomic.Int32
+	err := forEachChunk(context.Background(), 5, 2, func(ctx context.Context, i int) error {
+		calls.Add(1)
+		return nil
+	})
+	if err != nil || calls.Load() != 5 {
+		t.Errorf("got err %v after %d calls, want nil after 5", err, calls.Load())
+	}
+
+	boom := errors.New("boom")
+	err = forEachChunk(context.Background(), 5, 1, func(ctx context.Context, i int) error {
+		if i == 1 {
+			return boom
+		}
+		return nil
+	})
+	if !errors.Is(err, boom) {
+		t.Errorf("got err %v, want %v", err, boom)
+	}
+}
+
+func almostEqual(a, b float64) bool {
+	d := a - b
+	return d < 1e-9 && d > -1e-9
+}
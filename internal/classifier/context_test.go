@@ -0,0 +1,81 @@
+package classifier
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/proth1/text-moderator/internal/models"
+)
+
+// fakeContextProvider is a fakeProvider that also scores messages with context.
+type fakeContextProvider struct {
+	fakeProvider
+	contextScores models.CategoryScores
+	contextErr    error
+	history       []models.ConversationMessage
+}
+
+func (p *fakeContextProvider) ClassifyWithContext(ctx context.Context, message models.ConversationMessage, history []models.ConversationMessage) (*models.CategoryScores, error) {
+	p.history = history
+	if p.contextErr != nil {
+		return nil, p.contextErr
+	}
+	s := p.contextScores
+	return &s, nil
+}
+
+func TestClassifyWithContext_UsesContextAwareProvider(t *testing.T) {
+	plain := &fakeProvider{name: "plain", scores: models.CategoryScores{Harassment: 0.1}}
+	o := newChainTestOrchestrator(OrchestratorConfig{}, plain)
+	aware := &fakeContextProvider{
+		fakeProvider:  fakeProvider{name: "aware"},
+		contextScores: models.CategoryScores{Harassment: 0.9},
+	}
+	o.config.Providers = append(o.config.Providers, ProviderConfig{Name: "aware", Priority: 2, Enabled: true})
+	o.RegisterProvider(aware)
+
+	history := []models.ConversationMessage{{AuthorID: "a", Content: "you are worthless"}}
+	result, err := o.ClassifyWithContext(context.Background(), models.ConversationMessage{AuthorID: "a", Content: "you know what you are"}, history, []string{"en"})
+	if err != nil {
+		t.Fatalf("classify failed: %v", err)
+	}
+	if result.ProviderName != "aware" || !result.UsedContext {
+		t.Errorf("got provider %s (used context %v), want aware with context", result.ProviderName, result.UsedContext)
+	}
+	if result.Scores.Harassment != 0.9 {
+		t.Errorf("harassment = %v, want 0.9", result.Scores.Harassment)
+	}
+	if len(aware.history) != 1 {
+		t.Errorf("provider got %d context messages, want 1", len(aware.history))
+	}
+}
+
+func TestClassifyWithContext_FallsBackWithoutContext(t *testing.T) {
+	plain := &fakeProvider{name: "plain", scores: models.CategoryScores{Harassment: 0.1}}
+	aware := &fakeContextProvider{
+		fakeProvider: fakeProvider{name: "aware"},
+		contextErr:   errors.New("unavailable"),
+	}
+	o := newChainTestOrchestrator(OrchestratorConfig{}, plain)
+	o.config.Providers = append(o.config.Providers, ProviderConfig{Name: "aware", Priority: 2, Enabled: true})
+	o.RegisterProvider(aware)
+
+	result, err := o.ClassifyWithContext(context.Background(), models.ConversationMessage{AuthorID: "a", Content: "hi"}, nil, []string{"en"})
+	if err != nil {
+		t.Fatalf("classify failed: %v", err)
+	}
+	if result.ProviderName != "plain" || result.UsedContext {
+		t.Errorf("got provider %s (used context %v), want plain without context", result.ProviderName, result.UsedContext)
+	}
+}
+
+func TestMergeContextScores(t *testing.T) {
+	primary := &models.CategoryScores{Toxicity: 0.6, Harassment: 0.1}
+	contextual := &models.CategoryScores{Toxicity: 0.2, Harassment: 0.8}
+
+	got := MergeContextScores(primary, contextual)
+	if got.Toxicity != 0.6 || got.Harassment != 0.8 {
+		t.Errorf("got %+v, want toxicity 0.6 and harassment 0.8", got)
+	}
+}
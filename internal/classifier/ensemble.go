@@ -18,6 +18,36 @@ type EnsembleResult struct {
 	AgreementScores    map[string]float64 // per-category agreement (0-1)
 	HasDisagreement    bool
 	DisagreedCategories []string
+	EarlyExit          bool // returned before every provider responded
+}
+
+// EnsembleWeights holds per-provider, per-category weights for the "weighted"
+// strategy, keyed by provider name then category. Missing entries weigh 1.0.
+type EnsembleWeights map[string]map[string]float64
+
+// weight returns the weight for a provider's category score.
+func (w EnsembleWeights) weight(provider, category string) float64 {
+	if cats, ok := w[provider]; ok {
+		if v, ok := cats[category]; ok {
+			return v
+		}
+	}
+	return 1.0
+}
+
+// SetEnsembleWeights replaces the weights used by the "weighted" strategy.
+func (o *Orchestrator) SetEnsembleWeights(w EnsembleWeights) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.ensembleWeights = w
+}
+
+// SetDecisionThresholds sets the per-category policy thresholds the ensemble
+// keeps clear of before exiting early, keyed by category.
+func (o *Orchestrator) SetDecisionThresholds(t map[string][]float64) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.decisionThresholds = t
 }
 
 // ClassifyEnsemble runs multiple providers in parallel and combines results.
@@ -25,11 +55,15 @@ func (o *Orchestrator) ClassifyEnsemble(ctx context.Context, text string) (*Ense
 	o.mu.RLock()
 	ordered := o.orderedProviders()
 	providers := make(map[string]Provider, len(o.providers))
+	guards := make(map[string]providerBreaker, len(o.providers))
 	for k, v := range o.providers {
 		providers[k] = v
+		guards[k] = o.breakerFor(k)
 	}
 	calibrator := o.calibrator
 	ensembleCfg := o.config.Ensemble
+	weights := o.ensembleWeights
+	thresholds := o.decisionThresholds
 	o.mu.RUnlock()
 
 	if ensembleCfg == nil {
@@ -46,8 +80,18 @@ func (o *Orchestrator) ClassifyEnsemble(ctx context.Context, text string) (*Ense
 		index  int
 	}
 
+	// Cancelled on return so providers still running after an early exit stop.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	agreementThreshold := ensembleCfg.AgreementThreshold
+	if agreementThreshold == 0 {
+		agreementThreshold = 0.3
+	}
+
 	results := make(chan providerResult, len(ordered))
 	var wg sync.WaitGroup
+	launched := 0
 
 	for i, pcfg := range ordered {
 		provider, exists := providers[pcfg.Name]
@@ -55,28 +99,35 @@ func (o *Orchestrator) ClassifyEnsemble(ctx context.Context, text string) (*Ense
 			continue
 		}
 
+		launched++
 		wg.Add(1)
-		go func(idx int, p Provider, name string) {
+		go func(idx int, p Provider, name string, guard providerBreaker) {
 			defer wg.Done()
-			scores, err := p.Classify(ctx, text)
+			scores, _, err := guard.execute(name, func() (*models.CategoryScores, []TextSpan, error) {
+				scores, err := p.Classify(ctx, text)
+				return scores, nil, err
+			})
 			if err != nil {
 				results <- providerResult{err: err, index: idx}
 				return
 			}
+			rawScores := scores
 			if calibrator != nil {
 				scores = calibrator.Calibrate(name, scores)
 			}
 			modelName, modelVersion := p.ModelInfo()
 			results <- providerResult{
 				result: ClassificationResult{
-					Scores:       scores,
-					ProviderName: name,
-					ModelName:    modelName,
-					ModelVersion: modelVersion,
+					Scores:             scores,
+					RawScores:          rawScores,
+					ProviderName:       name,
+					ModelName:          modelName,
+					ModelVersion:       modelVersion,
+					CalibrationVersion: calibrator.Version(),
 				},
 				index: idx,
 			}
-		}(i, provider, pcfg.Name)
+		}(i, provider, pcfg.Name, guards[pcfg.Name])
 	}
 
 	// Close results channel after all goroutines complete
@@ -86,7 +137,9 @@ func (o *Orchestrator) ClassifyEnsemble(ctx context.Context, text string) (*Ense
 	}()
 
 	var successful []ClassificationResult
+	received, earlyExit := 0, false
 	for pr := range results {
+		received++
 		if pr.err != nil {
 			o.logger.Warn("ensemble provider failed",
 				zap.Error(pr.err),
@@ -94,6 +147,11 @@ func (o *Orchestrator) ClassifyEnsemble(ctx context.Context, text string) (*Ense
 			continue
 		}
 		successful = append(successful, pr.result)
+
+		if received < launched && canExitEarly(successful, ensembleCfg, agreementThreshold, thresholds, weights) {
+			earlyExit = true
+			break
+		}
 	}
 
 	if len(successful) < ensembleCfg.MinProviders {
@@ -101,13 +159,9 @@ func (o *Orchestrator) ClassifyEnsemble(ctx context.Context, text string) (*Ense
 	}
 
 	// Combine scores based on strategy
-	combined := combineScores(successful, ensembleCfg.Strategy)
+	combined := combineScores(successful, ensembleCfg.Strategy, weights)
 
 	// Compute agreement scores
-	agreementThreshold := ensembleCfg.AgreementThreshold
-	if agreementThreshold == 0 {
-		agreementThreshold = 0.3
-	}
 	agreement, disagreedCategories := computeAgreement(successful, agreementThreshold)
 
 	return &EnsembleResult{
@@ -116,52 +170,78 @@ func (o *Orchestrator) ClassifyEnsemble(ctx context.Context, text string) (*Ense
 		AgreementScores:    agreement,
 		HasDisagreement:    len(disagreedCategories) > 0,
 		DisagreedCategories: disagreedCategories,
+		EarlyExit:          earlyExit,
 	}, nil
 }
 
-func combineScores(results []ClassificationResult, strategy string) *models.CategoryScores {
+// canExitEarly reports whether the providers that have responded so far agree
+// within the agreement threshold and their combined scores sit at least
+// EarlyExitMargin away from every known policy threshold, so waiting for slower
+// providers could not change the outcome.
+func canExitEarly(successful []ClassificationResult, cfg *EnsembleConfig, agreementThreshold float64, thresholds map[string][]float64, weights EnsembleWeights) bool {
+	if cfg.EarlyExitMargin <= 0 || len(thresholds) == 0 {
+		return false
+	}
+	minProviders := cfg.MinProviders
+	if minProviders < 2 {
+		minProviders = 2
+	}
+	if len(successful) < minProviders {
+		return false
+	}
+	if _, disagreed := computeAgreement(successful, agreementThreshold); len(disagreed) > 0 {
+		return false
+	}
+
+	combined := combineScores(successful, cfg.Strategy, weights)
+	for cat, value := range combined.ToMap() {
+		for _, t := range thresholds[cat] {
+			if math.Abs(value-t) < cfg.EarlyExitMargin {
+				return false
+			}
+		}
+	}
+	return true
+}
+func combineScores(results []ClassificationResult, strategy string, weights EnsembleWeights) *models.CategoryScores {
 	if len(results) == 0 {
 		return &models.CategoryScores{}
 	}
 
-	categories := []string{"toxicity", "hate", "harassment", "sexual_content", "violence", "profanity", "self_harm", "spam", "pii"}
-	categoryValues := make(map[string][]float64)
-
-	for _, cat := range categories {
+	categoryValues := make(map[string][]float64, len(models.Categories))
+	for _, cat := range models.Categories {
 		categoryValues[cat] = make([]float64, 0, len(results))
 	}
 
 	for _, r := range results {
-		categoryValues["toxicity"] = append(categoryValues["toxicity"], r.Scores.Toxicity)
-		categoryValues["hate"] = append(categoryValues["hate"], r.Scores.Hate)
-		categoryValues["harassment"] = append(categoryValues["harassment"], r.Scores.Harassment)
-		categoryValues["sexual_content"] = append(categoryValues["sexual_content"], r.Scores.SexualContent)
-		categoryValues["violence"] = append(categoryValues["violence"], r.Scores.Violence)
-		categoryValues["profanity"] = append(categoryValues["profanity"], r.Scores.Profanity)
-		categoryValues["self_harm"] = append(categoryValues["self_harm"], r.Scores.SelfHarm)
-		categoryValues["spam"] = append(categoryValues["spam"], r.Scores.Spam)
-		categoryValues["pii"] = append(categoryValues["pii"], r.Scores.PII)
-	}
-
-	combine := averageValues
+		for cat, value := range r.Scores.ToMap() {
+			categoryValues[cat] = append(categoryValues[cat], value)
+		}
+	}
+
+	combine := func(cat string) float64 { return averageValues(categoryValues[cat]) }
 	switch strategy {
 	case "median":
-		combine = medianValues
+		combine = func(cat string) float64 { return medianValues(categoryValues[cat]) }
 	case "max":
-		combine = maxValues
+		combine = func(cat string) float64 { return maxValues(categoryValues[cat]) }
+	case "weighted":
+		combine = func(cat string) float64 {
+			w := make([]float64, len(results))
+			for i, r := range results {
+				w[i] = weights.weight(r.ProviderName, cat)
+			}
+			return weightedValues(categoryValues[cat], w)
+		}
 	}
 
-	return &models.CategoryScores{
-		Toxicity:      combine(categoryValues["toxicity"]),
-		Hate:          combine(categoryValues["hate"]),
-		Harassment:    combine(categoryValues["harassment"]),
-		SexualContent: combine(categoryValues["sexual_content"]),
-		Violence:      combine(categoryValues["violence"]),
-		Profanity:     combine(categoryValues["profanity"]),
-		SelfHarm:      combine(categoryValues["self_harm"]),
-		Spam:          combine(categoryValues["spam"]),
-		PII:           combine(categoryValues["pii"]),
+	combined := make(map[string]float64, len(models.Categories))
+	for _, cat := range models.Categories {
+		combined[cat] = combine(cat)
 	}
+	scores := &models.CategoryScores{}
+	scores.FromMap(combined)
+	return scores
 }
 
 func averageValues(vals []float64) float64 {
@@ -175,6 +255,20 @@ func averageValues(vals []float64) float64 {
 	return sum / float64(len(vals))
 }
 
+// weightedValues returns the weighted mean of vals, falling back to the plain
+// mean when no value carries weight.
+func weightedValues(vals, weights []float64) float64 {
+	sum, total := 0.0, 0.0
+	for i, v := range vals {
+		sum += v * weights[i]
+		total += weights[i]
+	}
+	if total <= 0 {
+		return averageValues(vals)
+	}
+	return sum / total
+}
+
 func medianValues(vals []float64) float64 {
 	if len(vals) == 0 {
 		return 0
@@ -205,27 +299,18 @@ func maxValues(vals []float64) float64 {
 // computeAgreement calculates per-category agreement scores and identifies disagreements.
 // Agreement is 1.0 - (maxSpread / threshold). Categories where spread exceeds threshold are disagreements.
 func computeAgreement(results []ClassificationResult, threshold float64) (map[string]float64, []string) {
-	type catExtractor func(*models.CategoryScores) float64
-
-	extractors := map[string]catExtractor{
-		"toxicity":       func(s *models.CategoryScores) float64 { return s.Toxicity },
-		"hate":           func(s *models.CategoryScores) float64 { return s.Hate },
-		"harassment":     func(s *models.CategoryScores) float64 { return s.Harassment },
-		"sexual_content": func(s *models.CategoryScores) float64 { return s.SexualContent },
-		"violence":       func(s *models.CategoryScores) float64 { return s.Violence },
-		"profanity":      func(s *models.CategoryScores) float64 { return s.Profanity },
-		"self_harm":      func(s *models.CategoryScores) float64 { return s.SelfHarm },
-		"spam":           func(s *models.CategoryScores) float64 { return s.Spam },
-		"pii":            func(s *models.CategoryScores) float64 { return s.PII },
+	values := make([]map[string]float64, len(results))
+	for i, r := range results {
+		values[i] = r.Scores.ToMap()
 	}
 
 	agreement := make(map[string]float64)
 	var disagreed []string
 
-	for cat, extract := range extractors {
+	for _, cat := range models.Categories {
 		min, max := math.MaxFloat64, -math.MaxFloat64
-		for _, r := range results {
-			val := extract(r.Scores)
+		for _, v := range values {
+			val := v[cat]
 			if val < min {
 				min = val
 			}
@@ -1,10 +1,13 @@
 package classifier
 
 import (
+	"context"
 	"math"
 	"testing"
+	"time"
 
 	"github.com/proth1/text-moderator/internal/models"
+	"go.uber.org/zap"
 )
 
 func TestAverageValues(t *testing.T) {
@@ -77,7 +80,7 @@ func TestCombineScores_Average(t *testing.T) {
 		{Scores: &models.CategoryScores{Toxicity: 0.8, Hate: 0.6}},
 	}
 
-	combined := combineScores(results, "average")
+	combined := combineScores(results, "average", nil)
 	if math.Abs(combined.Toxicity-0.5) > 0.001 {
 		t.Errorf("average toxicity = %f, want 0.5", combined.Toxicity)
 	}
@@ -92,12 +95,107 @@ func TestCombineScores_Max(t *testing.T) {
 		{Scores: &models.CategoryScores{Toxicity: 0.8}},
 	}
 
-	combined := combineScores(results, "max")
+	combined := combineScores(results, "max", nil)
 	if math.Abs(combined.Toxicity-0.8) > 0.001 {
 		t.Errorf("max toxicity = %f, want 0.8", combined.Toxicity)
 	}
 }
 
+func TestCombineScores_Weighted(t *testing.T) {
+	results := []ClassificationResult{
+		{ProviderName: "trusted", Scores: &models.CategoryScores{Toxicity: 0.8, Hate: 0.2}},
+		{ProviderName: "noisy", Scores: &models.CategoryScores{Toxicity: 0.2, Hate: 0.6}},
+	}
+	weights := EnsembleWeights{
+		"trusted": {"toxicity": 0.9},
+		"noisy":   {"toxicity": 0.1, "hate": 3.0},
+	}
+
+	combined := combineScores(results, "weighted", weights)
+	if math.Abs(combined.Toxicity-0.74) > 0.001 {
+		t.Errorf("weighted toxicity = %f, want 0.74", combined.Toxicity)
+	}
+	// trusted has no hate weight and counts as 1.0.
+	if math.Abs(combined.Hate-0.5) > 0.001 {
+		t.Errorf("weighted hate = %f, want 0.5", combined.Hate)
+	}
+
+	// Without weights the strategy degrades to a plain average.
+	combined = combineScores(results, "weighted", nil)
+	if math.Abs(combined.Toxicity-0.5) > 0.001 {
+		t.Errorf("unweighted toxicity = %f, want 0.5", combined.Toxicity)
+	}
+}
+
+func TestWeightedValues_ZeroWeights(t *testing.T) {
+	got := weightedValues([]float64{0.2, 0.6}, []float64{0, 0})
+	if math.Abs(got-0.4) > 0.001 {
+		t.Errorf("weightedValues with zero weights = %f, want 0.4", got)
+	}
+}
+
+func newEarlyExitOrchestrator(margin float64, slowScores models.CategoryScores, fast ...models.CategoryScores) (*Orchestrator, *fakeProvider) {
+	cfg := OrchestratorConfig{
+		Ensemble: &EnsembleConfig{Enabled: true, MinProviders: 2, AgreementThreshold: 0.2, Strategy: "average", EarlyExitMargin: margin},
+	}
+	o := NewOrchestrator(cfg, zap.NewNop())
+	slow := &fakeProvider{name: "slow", scores: slowScores, delay: 2 * time.Second}
+	providers := []*fakeProvider{slow}
+	for i, s := range fast {
+		providers = append(providers, &fakeProvider{name: string(rune('a' + i)), scores: s})
+	}
+	for i, p := range providers {
+		o.config.Providers = append(o.config.Providers, ProviderConfig{Name: p.name, Priority: i + 1, Enabled: true})
+		o.RegisterProvider(p)
+	}
+	o.SetDecisionThresholds(map[string][]float64{"toxicity": {0.5, 0.8}})
+	return o, slow
+}
+
+func TestClassifyEnsemble_EarlyExit(t *testing.T) {
+	o, _ := newEarlyExitOrchestrator(0.15, models.CategoryScores{Toxicity: 0.9},
+		models.CategoryScores{Toxicity: 0.05}, models.CategoryScores{Toxicity: 0.1})
+
+	start := time.Now()
+	result, err := o.ClassifyEnsemble(context.Background(), "hello")
+	if err != nil {
+		t.Fatalf("ensemble failed: %v", err)
+	}
+	if !result.EarlyExit || len(result.ProviderResults) != 2 {
+		t.Errorf("got early exit %v with %d results, want true with 2", result.EarlyExit, len(result.ProviderResults))
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("elapsed %v, want the slow provider skipped", elapsed)
+	}
+}
+
+func TestClassifyEnsemble_NoEarlyExit(t *testing.T) {
+	tests := []struct {
+		name   string
+		margin float64
+		fast   []models.CategoryScores
+	}{
+		{"disabled", 0, []models.CategoryScores{{Toxicity: 0.05}, {Toxicity: 0.1}}},
+		{"near threshold", 0.15, []models.CategoryScores{{Toxicity: 0.45}, {Toxicity: 0.4}}},
+		{"disagreement", 0.15, []models.CategoryScores{{Toxicity: 0.0}, {Toxicity: 0.3}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o, slow := newEarlyExitOrchestrator(tt.margin, models.CategoryScores{Toxicity: 0.2}, tt.fast...)
+			slow.delay = 50 * time.Millisecond
+
+			result, err := o.ClassifyEnsemble(context.Background(), "hello")
+			if err != nil {
+				t.Fatalf("ensemble failed: %v", err)
+			}
+			if result.EarlyExit || len(result.ProviderResults) != 3 {
+				t.Errorf("got early exit %v with %d results, want false with 3", result.EarlyExit, len(result.ProviderResults))
+			}
+		})
+	}
+}
+
 func TestComputeAgreement_FullAgreement(t *testing.T) {
 	results := []ClassificationResult{
 		{Scores: &models.CategoryScores{Toxicity: 0.5, Hate: 0.3}},
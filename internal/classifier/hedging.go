@@ -0,0 +1,213 @@
+package classifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/proth1/text-moderator/internal/models"
+	"go.uber.org/zap"
+)
+
+// latencyWindow is the number of recent successful calls kept per provider for
+// percentile estimation.
+const latencyWindow = 200
+
+// minLatencySamples is the number of samples required before observed latency
+// percentiles replace HedgingConfig.InitialDelay.
+const minLatencySamples = 20
+
+// latencyTracker keeps a rolling window of successful call latencies.
+type latencyTracker struct {
+	mu      sync.Mutex
+	samples []time.Duration
+	next    int
+}
+
+func newLatencyTracker() *latencyTracker {
+	return &latencyTracker{samples: make([]time.Duration, 0, latencyWindow)}
+}
+
+// Observe records a successful call latency.
+func (t *latencyTracker) Observe(d time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.samples) < latencyWindow {
+		t.samples = append(t.samples, d)
+		return
+	}
+	t.samples[t.next] = d
+	t.next = (t.next + 1) % latencyWindow
+}
+
+// Percentile returns the p-th percentile (0-1) of observed latencies, or false
+// if too few samples have been recorded.
+func (t *latencyTracker) Percentile(p float64) (time.Duration, bool) {
+	t.mu.Lock()
+	sorted := make([]time.Duration, len(t.samples))
+	copy(sorted, t.samples)
+	t.mu.Unlock()
+
+	if len(sorted) < minLatencySamples {
+		return 0, false
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	idx := int(p*float64(len(sorted)-1) + 0.5)
+	if idx < 0 {
+		idx = 0
+	}
+	if idx >= len(sorted) {
+		idx = len(sorted) - 1
+	}
+	return sorted[idx], true
+}
+
+// hedgeDelay returns how long to wait on provider before firing a hedge request.
+func hedgeDelay(cfg *HedgingConfig, tracker *latencyTracker) time.Duration {
+	delay := cfg.InitialDelay
+	if tracker != nil {
+		if p, ok := tracker.Percentile(cfg.Percentile); ok {
+			delay = p
+		}
+	}
+	if delay < cfg.MinDelay {
+		delay = cfg.MinDelay
+	}
+	return delay
+}
+
+// chainCandidate is a provider participating in a classification chain.
+type chainCandidate struct {
+	name     string
+	provider Provider
+	execute  func(ctx context.Context) (*models.CategoryScores, []TextSpan, error)
+	latency  *latencyTracker
+}
+
+// attemptResult is the outcome of one provider attempt within a chain.
+type attemptResult struct {
+	candidate int
+	scores    *models.CategoryScores
+	spans     []TextSpan
+	err       error
+}
+
+// runChain walks the fallback chain, respecting the latency budget carried by
+// ctx and, when hedging is configured, racing the next candidate against a slow
+// one. It returns the index of the winning candidate, its scores and any spans.
+func (o *Orchestrator) runChain(ctx context.Context, candidates []chainCandidate, fallback bool, hedging *HedgingConfig) (int, *models.CategoryScores, []TextSpan, error) {
+	results := make(chan attemptResult, len(candidates))
+	var cancels []context.CancelFunc
+	defer func() {
+		for _, cancel := range cancels {
+			cancel()
+		}
+	}()
+
+	next, inflight := 0, 0
+	hedged := false
+	var hedgeTimer *time.Timer
+	var hedgeC <-chan time.Time
+	stopHedge := func() {
+		if hedgeTimer != nil {
+			hedgeTimer.Stop()
+		}
+		hedgeC = nil
+	}
+	defer stopHedge()
+
+	launch := func() {
+		idx := next
+		next++
+		inflight++
+		c := candidates[idx]
+
+		attemptCtx := ctx
+		if deadline, ok := ctx.Deadline(); ok {
+			// Split what is left of the budget evenly across the remaining candidates.
+			share := time.Until(deadline) / time.Duration(len(candidates)-idx)
+			var cancel context.CancelFunc
+			attemptCtx, cancel = context.WithTimeout(ctx, share)
+			cancels = append(cancels, cancel)
+		} else {
+			var cancel context.CancelFunc
+			attemptCtx, cancel = context.WithCancel(ctx)
+			cancels = append(cancels, cancel)
+		}
+
+		go func() {
+			start := time.Now()
+			scores, spans, err := c.execute(attemptCtx)
+			if err == nil && c.latency != nil {
+				c.latency.Observe(time.Since(start))
+			}
+			results <- attemptResult{candidate: idx, scores: scores, spans: spans, err: err}
+		}()
+
+		// Arm the hedge against the attempt just launched, once per chain.
+		if hedging != nil && hedging.Enabled && !hedged && next < len(candidates) {
+			stopHedge()
+			hedgeTimer = time.NewTimer(hedgeDelay(hedging, c.latency))
+			hedgeC = hedgeTimer.C
+		}
+	}
+
+	launch()
+
+	var lastErr error
+	for inflight > 0 {
+		select {
+		case r := <-results:
+			inflight--
+			if r.err == nil {
+				return r.candidate, r.scores, r.spans, nil
+			}
+			lastErr = r.err
+			o.logger.Warn("provider classification failed",
+				zap.String("provider", candidates[r.candidate].name),
+				zap.Error(r.err),
+			)
+			if !fallback {
+				return r.candidate, nil, nil, fmt.Errorf("provider %s failed: %w", candidates[r.candidate].name, r.err)
+			}
+			if inflight == 0 && next < len(candidates) {
+				if ctx.Err() != nil {
+					return -1, nil, nil, fmt.Errorf("latency budget exhausted: %w", lastErr)
+				}
+				// The pending hedge was armed against the attempt that just
+				// failed; the fallback re-arms it if candidates remain.
+				stopHedge()
+				launch()
+			}
+
+		case <-hedgeC:
+			hedgeC = nil
+			if next >= len(candidates) {
+				continue
+			}
+			hedged = true
+			o.logger.Debug("hedging slow provider",
+				zap.String("provider", candidates[next-1].name),
+				zap.String("hedge", candidates[next].name),
+			)
+			launch()
+
+		case <-ctx.Done():
+			if lastErr == nil {
+				lastErr = ctx.Err()
+			}
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return -1, nil, nil, fmt.Errorf("latency budget exhausted: %w", lastErr)
+			}
+			return -1, nil, nil, lastErr
+		}
+	}
+
+	if lastErr == nil {
+		return -1, nil, nil, fmt.Errorf("no classification providers available")
+	}
+	return -1, nil, nil, fmt.Errorf("all providers failed, last error: %w", lastErr)
+}
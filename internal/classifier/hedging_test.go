@@ -0,0 +1,228 @@
+package classifier
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/sony/gobreaker"
+	"go.uber.org/zap"
+)
+
+func newChainTestOrchestrator(cfg OrchestratorConfig, providers ...*fakeProvider) *Orchestrator {
+	for i, p := range providers {
+		cfg.Providers = append(cfg.Providers, ProviderConfig{Name: p.name, Priority: i + 1, Enabled: true})
+	}
+	cfg.FallbackEnabled = true
+	o := NewOrchestrator(cfg, zap.NewNop())
+	for _, p := range providers {
+		o.RegisterProvider(p)
+	}
+	return o
+}
+
+func TestLatencyBudget_SplitAcrossChain(t *testing.T) {
+	slow := &fakeProvider{name: "slow", delay: time.Second}
+	fast := &fakeProvider{name: "fast"}
+	spare := &fakeProvider{name: "spare"}
+	o := newChainTestOrchestrator(OrchestratorConfig{LatencyBudget: 300 * time.Millisecond}, slow, fast, spare)
+
+	start := time.Now()
+	result, err := o.Classify(context.Background(), "hello")
+	elapsed := time.Since(start)
+	if err != nil {
+		t.Fatalf("classify failed: %v", err)
+	}
+	if result.ProviderName != "fast" {
+		t.Errorf("got provider %s, want fast", result.ProviderName)
+	}
+	// The slow provider gets a third of the budget, not the whole of it.
+	if elapsed > 200*time.Millisecond {
+		t.Errorf("elapsed %v, want the slow provider cut off at its share (~100ms)", elapsed)
+	}
+}
+
+func TestLatencyBudget_Exhausted(t *testing.T) {
+	a := &fakeProvider{name: "a", delay: time.Second}
+	b := &fakeProvider{name: "b", delay: time.Second}
+	o := newChainTestOrchestrator(OrchestratorConfig{LatencyBudget: 100 * time.Millisecond}, a, b)
+
+	start := time.Now()
+	_, err := o.Classify(context.Background(), "hello")
+	elapsed := time.Since(start)
+	if err == nil {
+		t.Fatal("expected error when every provider exceeds the budget")
+	}
+	if elapsed > 300*time.Millisecond {
+		t.Errorf("elapsed %v, want roughly the 100ms budget", elapsed)
+	}
+}
+
+func TestLatencyBudget_Disabled(t *testing.T) {
+	slow := &fakeProvider{name: "slow", delay: 50 * time.Millisecond}
+	fast := &fakeProvider{name: "fast"}
+	o := newChainTestOrchestrator(OrchestratorConfig{}, slow, fast)
+
+	result, err := o.Classify(context.Background(), "hello")
+	if err != nil {
+		t.Fatalf("classify failed: %v", err)
+	}
+	if result.ProviderName != "slow" {
+		t.Errorf("got provider %s, want slow (no budget means no cut-off)", result.ProviderName)
+	}
+	if fast.calls.Load() != 0 {
+		t.Errorf("fallback provider called %d times, want 0", fast.calls.Load())
+	}
+}
+
+func TestHedging_FiresOnSlowProvider(t *testing.T) {
+	slow := &fakeProvider{name: "slow", delay: 500 * time.Millisecond}
+	hedge := &fakeProvider{name: "hedge"}
+	o := newChainTestOrchestrator(OrchestratorConfig{
+		Hedging: &HedgingConfig{Enabled: true, Percentile: 0.95, InitialDelay: 20 * time.Millisecond},
+	}, slow, hedge)
+
+	start := time.Now()
+	result, err := o.Classify(context.Background(), "hello")
+	elapsed := time.Since(start)
+	if err != nil {
+		t.Fatalf("classify failed: %v", err)
+	}
+	if result.ProviderName != "hedge" {
+		t.Errorf("got provider %s, want hedge", result.ProviderName)
+	}
+	if elapsed > 200*time.Millisecond {
+		t.Errorf("elapsed %v, want hedge to answer well before the slow provider", elapsed)
+	}
+}
+
+func TestHedging_NotFiredForFastProvider(t *testing.T) {
+	primary := &fakeProvider{name: "primary"}
+	hedge := &fakeProvider{name: "hedge"}
+	o := newChainTestOrchestrator(OrchestratorConfig{
+		Hedging: &HedgingConfig{Enabled: true, Percentile: 0.95, InitialDelay: 100 * time.Millisecond},
+	}, primary, hedge)
+
+	for i := 0; i < 5; i++ {
+		result, err := o.Classify(context.Background(), "hello")
+		if err != nil {
+			t.Fatalf("classify failed: %v", err)
+		}
+		if result.ProviderName != "primary" {
+			t.Errorf("got provider %s, want primary", result.ProviderName)
+		}
+	}
+	if hedge.calls.Load() != 0 {
+		t.Errorf("hedge provider called %d times, want 0", hedge.calls.Load())
+	}
+}
+
+func TestHedging_LoserDoesNotTripBreaker(t *testing.T) {
+	slow := &fakeProvider{name: "slow", delay: 200 * time.Millisecond}
+	hedge := &fakeProvider{name: "hedge"}
+	o := newChainTestOrchestrator(OrchestratorConfig{
+		Hedging: &HedgingConfig{Enabled: true, Percentile: 0.95, InitialDelay: 5 * time.Millisecond},
+	}, slow, hedge)
+
+	for i := 0; i < 8; i++ {
+		if _, err := o.Classify(context.Background(), "hello"); err != nil {
+			t.Fatalf("classify failed: %v", err)
+		}
+	}
+	if state := o.breakers["slow"].State(); state != gobreaker.StateClosed {
+		t.Errorf("slow provider breaker is %s, want closed", state)
+	}
+}
+
+func TestHedging_FallsBackWhenBothFail(t *testing.T) {
+	a := &fakeProvider{name: "a", delay: 30 * time.Millisecond, err: fmt.Errorf("a down")}
+	b := &fakeProvider{name: "b", err: fmt.Errorf("b down")}
+	c := &fakeProvider{name: "c"}
+	o := newChainTestOrchestrator(OrchestratorConfig{
+		Hedging: &HedgingConfig{Enabled: true, Percentile: 0.95, InitialDelay: 5 * time.Millisecond},
+	}, a, b, c)
+
+	result, err := o.Classify(context.Background(), "hello")
+	if err != nil {
+		t.Fatalf("classify failed: %v", err)
+	}
+	if result.ProviderName != "c" {
+		t.Errorf("got provider %s, want c", result.ProviderName)
+	}
+}
+
+func TestHedging_PrimaryFailsBeforeHedgeFires(t *testing.T) {
+	a := &fakeProvider{name: "a", delay: 5 * time.Millisecond, err: fmt.Errorf("a down")}
+	b := &fakeProvider{name: "b", delay: 200 * time.Millisecond}
+	o := newChainTestOrchestrator(OrchestratorConfig{
+		Hedging: &HedgingConfig{Enabled: true, Percentile: 0.95, InitialDelay: 50 * time.Millisecond},
+	}, a, b)
+
+	result, err := o.Classify(context.Background(), "hello")
+	if err != nil {
+		t.Fatalf("classify failed: %v", err)
+	}
+	if result.ProviderName != "b" {
+		t.Errorf("got provider %s, want b", result.ProviderName)
+	}
+	if b.calls.Load() != 1 {
+		t.Errorf("b called %d times, want 1", b.calls.Load())
+	}
+}
+
+func TestLatencyTracker_Percentile(t *testing.T) {
+	tracker := newLatencyTracker()
+	if _, ok := tracker.Percentile(0.95); ok {
+		t.Error("expected no percentile without samples")
+	}
+
+	for i := 1; i <= 100; i++ {
+		tracker.Observe(time.Duration(i) * time.Millisecond)
+	}
+
+	tests := []struct {
+		p    float64
+		want time.Duration
+	}{
+		{0.0, 1 * time.Millisecond},
+		{0.5, 51 * time.Millisecond},
+		{0.95, 95 * time.Millisecond},
+		{1.0, 100 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		got, ok := tracker.Percentile(tt.p)
+		if !ok || got != tt.want {
+			t.Errorf("Percentile(%v) = %v, want %v", tt.p, got, tt.want)
+		}
+	}
+}
+
+func TestLatencyTracker_RollingWindow(t *testing.T) {
+	tracker := newLatencyTracker()
+	for i := 0; i < latencyWindow; i++ {
+		tracker.Observe(time.Second)
+	}
+	for i := 0; i < latencyWindow; i++ {
+		tracker.Observe(time.Millisecond)
+	}
+	if got, _ := tracker.Percentile(1.0); got != time.Millisecond {
+		t.Errorf("old samples not evicted: max = %v", got)
+	}
+}
+
+func TestHedgeDelay(t *testing.T) {
+	cfg := &HedgingConfig{Percentile: 0.5, InitialDelay: 200 * time.Millisecond, MinDelay: 10 * time.Millisecond}
+
+	tracker := newLatencyTracker()
+	if got := hedgeDelay(cfg, tracker); got != 200*time.Millisecond {
+		t.Errorf("without samples got %v, want initial delay", got)
+	}
+
+	for i := 0; i < minLatencySamples; i++ {
+		tracker.Observe(time.Millisecond)
+	}
+	if got := hedgeDelay(cfg, tracker); got != 10*time.Millisecond {
+		t.Errorf("got %v, want MinDelay floor", got)
+	}
+}
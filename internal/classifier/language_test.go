@@ -0,0 +1,187 @@
+package classifier
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/proth1/text-moderator/internal/models"
+	"go.uber.org/zap"
+)
+
+// fakeLanguageProvider is a fakeProvider that supports a fixed set of languages.
+type fakeLanguageProvider struct {
+	fakeProvider
+	languages []string
+	hint      string
+}
+
+func (p *fakeLanguageProvider) ClassifyWithLanguage(ctx context.Context, text string, lang string) (*models.CategoryScores, error) {
+	p.hint = lang
+	return p.Classify(ctx, text)
+}
+
+func (p *fakeLanguageProvider) SupportedLanguages() []string { return p.languages }
+
+func newLanguageTestOrchestrator(providers ...Provider) *Orchestrator {
+	cfg := OrchestratorConfig{FallbackEnabled: true}
+	for i, p := range providers {
+		cfg.Providers = append(cfg.Providers, ProviderConfig{Name: p.Name(), Priority: i + 1, Enabled: true})
+	}
+	o := NewOrchestrator(cfg, zap.NewNop())
+	for _, p := range providers {
+		o.RegisterProvider(p)
+	}
+	return o
+}
+
+func TestClassifyWithLanguage_Routing(t *testing.T) {
+	tests := []struct {
+		name     string
+		langs    []string
+		wantName string
+		wantHint string
+		wantLang string
+	}{
+		{"english only", []string{"en"}, "english", "", "en"},
+		{"undetermined", []string{"und"}, "english", "", ""},
+		{"no languages", nil, "english", "", ""},
+		{"spanish", []string{"es"}, "spanish", "es", "es"},
+		{"code-switched prefers widest coverage", []string{"en", "hi"}, "multi", "en", "en"},
+		{"unsupported language", []string{"ja"}, "english", "", "ja"},
+		{"undetermined span ignored", []string{"und", "es"}, "spanish", "es", "es"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			english := &fakeProvider{name: "english"}
+			spanish := &fakeLanguageProvider{fakeProvider: fakeProvider{name: "spanish"}, languages: []string{"en", "es"}}
+			multi := &fakeLanguageProvider{fakeProvider: fakeProvider{name: "multi"}, languages: []string{"en", "es", "hi"}}
+			o := newLanguageTestOrchestrator(english, spanish, multi)
+
+			result, err := o.ClassifyWithLanguage(context.Background(), "some text", tt.langs)
+			if err != nil {
+				t.Fatalf("classify failed: %v", err)
+			}
+			if result.ProviderName != tt.wantName {
+				t.Errorf("got provider %s, want %s", result.ProviderName, tt.wantName)
+			}
+			if result.DetectedLanguage != tt.wantLang {
+				t.Errorf("got language %q, want %q", result.DetectedLanguage, tt.wantLang)
+			}
+			hint := spanish.hint + multi.hint
+			if hint != tt.wantHint {
+				t.Errorf("got hint %q, want %q", hint, tt.wantHint)
+			}
+		})
+	}
+}
+
+func TestClassifyWithLanguage_FallsBackToNextCandidate(t *testing.T) {
+	english := &fakeProvider{name: "english"}
+	multi := &fakeLanguageProvider{fakeProvider: fakeProvider{name: "multi", err: errors.New("unavailable")}, languages: []string{"en", "es", "hi"}}
+	spanish := &fakeLanguageProvider{fakeProvider: fakeProvider{name: "spanish"}, languages: []string{"es"}}
+	o := newLanguageTestOrchestrator(english, multi, spanish)
+
+	result, err := o.ClassifyWithLanguage(context.Background(), "some text", []string{"es", "hi"})
+	if err != nil {
+		t.Fatalf("classify failed: %v", err)
+	}
+	if result.ProviderName != "spanish" {
+		t.Errorf("got provider %s, want spanish after multi failed", result.ProviderName)
+	}
+}
+
+// fakeTranslator records what it was asked to translate.
+type fakeTranslator struct {
+	translation string
+	err         error
+	source      string
+	target      string
+}
+
+func (f *fakeTranslator) Translate(ctx context.Context, text, sourceLang, targetLang string) (string, error) {
+	f.source, f.target = sourceLang, targetLang
+	return f.translation, f.err
+}
+
+func (f *fakeTranslator) Name() string { return "fake" }
+
+// textProvider scores text by whether it contains a word.
+type textProvider struct {
+	fakeProvider
+	word string
+	seen string
+}
+
+func (p *textProvider) Classify(ctx context.Context, text string) (*models.CategoryScores, error) {
+	p.seen = text
+	if strings.Contains(text, p.word) {
+		return &models.CategoryScores{Toxicity: 0.9}, nil
+	}
+	return &models.CategoryScores{}, nil
+}
+
+func TestClassifyWithLanguage_TranslatesUnsupportedLanguage(t *testing.T) {
+	english := &textProvider{fakeProvider: fakeProvider{name: "english"}, word: "idiot"}
+	spanish := &fakeLanguageProvider{fakeProvider: fakeProvider{name: "spanish"}, languages: []string{"es"}}
+	o := newLanguageTestOrchestrator(english, spanish)
+	translator := &fakeTranslator{translation: "you idiot"}
+	o.SetTranslator(translator)
+
+	result, err := o.ClassifyWithLanguage(context.Background(), "お前はばかだ", []string{"ja", "en"})
+	if err != nil {
+		t.Fatalf("classify failed: %v", err)
+	}
+	if !result.Translated || result.SourceLanguage != "ja" {
+		t.Errorf("got translated=%v source=%q, want translated from ja", result.Translated, result.SourceLanguage)
+	}
+	if translator.source != "ja" || translator.target != PivotLanguage {
+		t.Errorf("translated %s -> %s, want ja -> %s", translator.source, translator.target, PivotLanguage)
+	}
+	if english.seen != "you idiot" || result.Scores.Toxicity != 0.9 {
+		t.Errorf("classified %q with toxicity %v, want the translation scored", english.seen, result.Scores.Toxicity)
+	}
+	if got := o.TranslationSource([]string{"ja", "en"}); got != "ja" {
+		t.Errorf("TranslationSource = %q, want ja", got)
+	}
+
+	// Supported and English-only text is not translated
+	for _, langs := range [][]string{{"es"}, {"en"}, {"und"}} {
+		result, err := o.ClassifyWithLanguage(context.Background(), "hola", langs)
+		if err != nil {
+			t.Fatalf("classify %v failed: %v", langs, err)
+		}
+		if result.Translated {
+			t.Errorf("%v was translated", langs)
+		}
+		if got := o.TranslationSource(langs); got != "" {
+			t.Errorf("TranslationSource(%v) = %q, want none", langs, got)
+		}
+	}
+}
+
+func TestClassifyWithLanguage_TranslationFailure(t *testing.T) {
+	english := &textProvider{fakeProvider: fakeProvider{name: "english"}}
+	o := newLanguageTestOrchestrator(english)
+	o.SetTranslator(&fakeTranslator{err: errors.New("unavailable")})
+
+	result, err := o.ClassifyWithLanguage(context.Background(), "お前はばかだ", []string{"ja"})
+	if err != nil {
+		t.Fatalf("classify failed: %v", err)
+	}
+	if result.Translated {
+		t.Error("result marked translated after translation failed")
+	}
+	if english.seen != "お前はばかだ" {
+		t.Errorf("classified %q, want the untranslated text", english.seen)
+	}
+}
+
+func TestPassthroughTranslator(t *testing.T) {
+	got, err := PassthroughTranslator{}.Translate(context.Background(), "hola", "es", "en")
+	if err != nil || got != "hola" {
+		t.Errorf("got %q, %v, want the text unchanged", got, err)
+	}
+}
@@ -0,0 +1,123 @@
+package classifier
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/proth1/text-moderator/internal/lexicon"
+	"github.com/proth1/text-moderator/internal/models"
+	"go.uber.org/zap"
+)
+
+// LexiconProvider classifies text in-process against the published lexicon lists.
+// It makes no network calls, so it remains available when every external
+// provider is down.
+// Control: MOD-005 (Multi-Provider Classification Orchestration)
+type LexiconProvider struct {
+	store   *lexicon.Store
+	matcher *lexicon.Matcher
+	mu      sync.RWMutex
+	logger  *zap.Logger
+}
+
+// NewLexiconProvider creates a new lexicon provider. Reload must load at least
+// one published entry before the provider reports healthy.
+func NewLexiconProvider(store *lexicon.Store, logger *zap.Logger) *LexiconProvider {
+	return &LexiconProvider{store: store, logger: logger}
+}
+
+// Reload loads the currently published lists from the store and swaps in a
+// freshly compiled matcher. On failure the previous matcher is kept.
+func (p *LexiconProvider) Reload(ctx context.Context) error {
+	lists, err := p.store.PublishedLists(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load lexicon lists: %w", err)
+	}
+
+	matcher, err := lexicon.Compile(lists)
+	if err != nil {
+		return fmt.Errorf("failed to compile lexicon lists: %w", err)
+	}
+
+	p.mu.Lock()
+	changed := p.matcher == nil || p.matcher.Version() != matcher.Version()
+	p.matcher = matcher
+	p.mu.Unlock()
+
+	if changed {
+		p.logger.Info("lexicon reloaded",
+			zap.Int("lists", len(lists)),
+			zap.Int("entries", matcher.Size()),
+			zap.String("version", matcher.Version()),
+		)
+	}
+	return nil
+}
+
+func (p *LexiconProvider) current() *lexicon.Matcher {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.matcher
+}
+
+// usable returns the current matcher, or an error if none is loaded or it has
+// no entries. An empty lexicon scores everything zero, which as the last
+// fallback would allow all content while the other providers are down.
+func (p *LexiconProvider) usable() (*lexicon.Matcher, error) {
+	m := p.current()
+	if m == nil {
+		return nil, fmt.Errorf("lexicon not loaded")
+	}
+	if m.Size() == 0 {
+		return nil, fmt.Errorf("lexicon has no published entries")
+	}
+	return m, nil
+}
+
+func (p *LexiconProvider) Classify(ctx context.Context, text string) (*models.CategoryScores, error) {
+	m, err := p.usable()
+	if err != nil {
+		return nil, err
+	}
+	scores, _ := m.Score(text)
+	return scores, nil
+}
+
+// ClassifyWithSpans implements SpanProvider, reporting each matched entry as a
+// span scored with the entry's weight. The lexicon is language-independent,
+// so lang is ignored.
+func (p *LexiconProvider) ClassifyWithSpans(ctx context.Context, text string, lang string) (*models.CategoryScores, []TextSpan, error) {
+	m, err := p.usable()
+	if err != nil {
+		return nil, nil, err
+	}
+	scores, matches := m.Score(text)
+	spans := make([]TextSpan, 0, len(matches))
+	for _, match := range matches {
+		spans = append(spans, TextSpan{
+			Category: match.Category,
+			Start:    match.Start,
+			End:      match.End,
+			Score:    match.Weight,
+			Source:   "lexicon:" + match.List,
+		})
+	}
+	return scores, spans, nil
+}
+
+func (p *LexiconProvider) Name() string {
+	return "lexicon"
+}
+
+func (p *LexiconProvider) ModelInfo() (string, string) {
+	if m := p.current(); m != nil {
+		return "lexicon", m.Version()
+	}
+	return "lexicon", "unloaded"
+}
+
+func (p *LexiconProvider) Health(ctx context.Context) error {
+	_, err := p.usable()
+	return err
+}
@@ -0,0 +1,49 @@
+package classifier
+
+import (
+	"context"
+	"testing"
+
+	"github.com/proth1/text-moderator/internal/lexicon"
+	"github.com/proth1/text-moderator/internal/models"
+	"go.uber.org/zap"
+)
+
+func TestLexiconProvider_EmptyLexiconIsUnavailable(t *testing.T) {
+	ctx := context.Background()
+	p := NewLexiconProvider(nil, zap.NewNop())
+	if p.Health(ctx) == nil {
+		t.Error("got healthy before loading, want an error")
+	}
+
+	empty, err := lexicon.Compile(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.matcher = empty
+	if p.Health(ctx) == nil {
+		t.Error("got healthy with no entries, want an error")
+	}
+	if _, err := p.Classify(ctx, "anything"); err == nil {
+		t.Error("got scores with no entries, want an error")
+	}
+	if _, _, err := p.ClassifyWithSpans(ctx, "anything", "en"); err == nil {
+		t.Error("got spans with no entries, want an error")
+	}
+
+	loaded, err := lexicon.Compile([]models.LexiconList{{
+		Name: "slurs", Version: 1, Category: "hate",
+		Entries: []models.LexiconEntry{{Pattern: "badword", MatchType: models.LexiconMatchTerm, Weight: 0.8}},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.matcher = loaded
+	if err := p.Health(ctx); err != nil {
+		t.Errorf("got %v with entries, want healthy", err)
+	}
+	scores, err := p.Classify(ctx, "a badword here")
+	if err != nil || scores.Hate != 0.8 {
+		t.Errorf("got %v, %v, want hate 0.8", scores, err)
+	}
+}
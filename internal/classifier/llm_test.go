@@ -1,6 +1,7 @@
 package classifier
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/proth1/text-moderator/internal/models"
@@ -86,3 +87,267 @@ func TestMergeAmbiguousScores(t *testing.T) {
 		t.Errorf("clear violence should keep primary score 0.9, got %f", merged.Violence)
 	}
 }
+
+func TestParseLLMResponse(t *testing.T) {
+	content := `{"toxicity":0.8,"harassment":0.6,"spam":0.1,` +
+		`"rationale":"  Direct insult aimed at the reader.  ",` +
+		`"phrases":{"toxicity":["you idiot",""],"harassment":["you idiot"],"spam":["buy now"],"unknown":["x"]}}`
+
+	scores, explanation, err := parseLLMResponse(content)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if scores.Toxicity != 0.8 || scores.Harassment != 0.6 {
+		t.Errorf("got scores %+v", scores)
+	}
+	if explanation == nil {
+		t.Fatal("expected explanation")
+	}
+	if explanation.Rationale != "Direct insult aimed at the reader." {
+		t.Errorf("rationale = %q", explanation.Rationale)
+	}
+	if got := explanation.Phrases["toxicity"]; len(got) != 1 || got[0] != "you idiot" {
+		t.Errorf("toxicity phrases = %v, want [you idiot]", got)
+	}
+	// spam is below the threshold and unknown is not a category
+	if _, ok := explanation.Phrases["spam"]; ok {
+		t.Error("phrases kept for untriggered category spam")
+	}
+	if _, ok := explanation.Phrases["unknown"]; ok {
+		t.Error("phrases kept for unknown category")
+	}
+}
+
+func TestParseLLMResponse_ScoresOnly(t *testing.T) {
+	scores, explanation, err := parseLLMResponse(`{"toxicity":0.4}`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if scores.Toxicity != 0.4 {
+		t.Errorf("toxicity = %f, want 0.4", scores.Toxicity)
+	}
+	if explanation != nil {
+		t.Errorf("got explanation %+v, want nil", explanation)
+	}
+
+	if _, _, err := parseLLMResponse("not json"); err == nil {
+		t.Error("expected error for invalid JSON")
+	}
+}
+
+func TestParseErrors_OmitCompletion(t *testing.T) {
+	// A reply that fails to parse can echo the user's content
+	echoed := "I refuse to score: my address is 12 Elm Street"
+	for _, content := range []string{echoed, `{"toxicity":"` + echoed + `"}`} {
+		_, _, scoresErr := parseLLMResponse(content)
+		_, injectionErr := parseInjectionResponse(content)
+		_, translationErr := parseTranslationResponse(content)
+		for _, err := range []error{scoresErr, injectionErr, translationErr} {
+			if err == nil {
+				t.Errorf("expected an error parsing %q", content)
+				continue
+			}
+			if strings.Contains(err.Error(), "Elm Street") {
+				t.Errorf("error includes the completion: %v", err)
+			}
+		}
+	}
+}
+
+func TestParseLLMResponse_Limits(t *testing.T) {
+	long := strings.Repeat("é", maxPhraseRunes+10)
+	content := `{"hate":0.9,"rationale":"` + strings.Repeat("r", maxRationaleRunes+10) + `",` +
+		`"phrases":{"hate":["` + long + `","b","c","d","e","f","g"]}}`
+
+	_, explanation, err := parseLLMResponse(content)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n := len([]rune(explanation.Rationale)); n != maxRationaleRunes {
+		t.Errorf("rationale length = %d, want %d", n, maxRationaleRunes)
+	}
+	phrases := explanation.Phrases["hate"]
+	if len(phrases) != maxPhrasesPerCategory {
+		t.Errorf("got %d phrases, want %d", len(phrases), maxPhrasesPerCategory)
+	}
+	if n := len([]rune(phrases[0])); n != maxPhraseRunes {
+		t.Errorf("phrase length = %d, want %d", n, maxPhraseRunes)
+	}
+}
+
+func TestParseLLMResponse_UnescapesPhrases(t *testing.T) {
+	content := `{"toxicity":0.9,"rationale":"Says &lt;3 then insults","phrases":{"toxicity":["you &amp; your &lt;friends&gt;"]}}`
+
+	_, explanation, err := parseLLMResponse(content)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if explanation.Rationale != "Says <3 then insults" {
+		t.Errorf("rationale = %q", explanation.Rationale)
+	}
+	if got := explanation.Phrases["toxicity"]; len(got) != 1 || got[0] != "you & your <friends>" {
+		t.Errorf("toxicity phrases = %v, want [you & your <friends>]", got)
+	}
+}
+
+func TestParseInjectionResponse(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    float64
+		wantErr bool
+	}{
+		{"plain", `{"prompt_injection":0.85}`, 0.85, false},
+		{"wrapped in prose", "Result:\n```json\n{\"prompt_injection\": 0.1}\n```", 0.1, false},
+		{"clamped", `{"prompt_injection":1.7}`, 1, false},
+		{"missing score", `{"toxicity":0.2}`, 0, true},
+		{"not json", "no injection here", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseInjectionResponse(tt.content)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTranslationResponse(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+		wantErr bool
+	}{
+		{"plain", `{"translation":"you are an idiot"}`, "you are an idiot", false},
+		{"wrapped in prose", "Here it is:\n{\"translation\": \"get lost\"}", "get lost", false},
+		{"unescapes delimiter entities", `{"translation":"a &lt;b&gt; c"}`, "a <b> c", false},
+		{"empty", `{"translation":"  "}`, "", true},
+		{"missing", `{"text":"hola"}`, "", true},
+		{"not json", "cannot translate", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseTranslationResponse(tt.content)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatExplanation(t *testing.T) {
+	tests := []struct {
+		name        string
+		explanation *models.DecisionExplanation
+		want        string
+	}{
+		{"nil", nil, ""},
+		{"rationale only", &models.DecisionExplanation{Rationale: "Mild spam."}, "Mild spam."},
+		{
+			"phrases sorted by category",
+			&models.DecisionExplanation{
+				Rationale: "Direct insult.",
+				Phrases:   map[string][]string{"toxicity": {"you idiot"}, "harassment": {"you idiot", "get lost"}},
+			},
+			`Direct insult. harassment: "you idiot", "get lost"; toxicity: "you idiot"`,
+		},
+		{
+			"phrases only",
+			&models.DecisionExplanation{Phrases: map[string][]string{"pii": {"555-0100"}}},
+			`pii: "555-0100"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FormatExplanation(tt.explanation); got != tt.want {
+				t.Errorf("FormatExplanation() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractJSONObject(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+		wantOK  bool
+	}{
+		{"bare object", `{"toxicity":0.1}`, `{"toxicity":0.1}`, true},
+		{"prose around", `Here you go: {"hate":0.5} Hope that helps!`, `{"hate":0.5}`, true},
+		{"code fence", "```json\n{\"spam\": 0.9}\n```", `{"spam": 0.9}`, true},
+		{"braces in strings", `{"rationale":"uses } and { chars","pii":0.2}`, `{"rationale":"uses } and { chars","pii":0.2}`, true},
+		{"escaped quote", `{"rationale":"said \"hi}\"","pii":0.2}`, `{"rationale":"said \"hi}\"","pii":0.2}`, true},
+		{"nested", `Result: {"phrases":{"hate":["x"]},"hate":0.8}.`, `{"phrases":{"hate":["x"]},"hate":0.8}`, true},
+		{"skips invalid candidate", `{not json} then {"spam":0.3}`, `{"spam":0.3}`, true},
+		{"no object", "I cannot classify this.", "", false},
+		{"unterminated", `{"toxicity":0.1`, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := extractJSONObject(tt.content)
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("extractJSONObject() = %q, %v, want %q, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestRejectsResponseFormat(t *testing.T) {
+	tests := []struct {
+		body string
+		want bool
+	}{
+		{`{"error":{"message":"unsupported response_format"}}`, true},
+		{`{"detail":"Unknown type JSON_SCHEMA"}`, true},
+		{`{"error":"json_object is not supported by this model"}`, true},
+		{`{"error":{"message":"maximum context length exceeded"}}`, false},
+		{`{"error":{"message":"model 'llama' not found"}}`, false},
+		{``, false},
+	}
+
+	for _, tt := range tests {
+		if got := rejectsResponseFormat([]byte(tt.body)); got != tt.want {
+			t.Errorf("rejectsResponseFormat(%q) = %v, want %v", tt.body, got, tt.want)
+		}
+	}
+}
+
+func TestLLMConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     LLMConfig
+		wantErr bool
+	}{
+		{"openai", LLMConfig{Provider: "openai", APIKey: "k"}, false},
+		{"openai without key", LLMConfig{Provider: "openai"}, true},
+		{"anthropic without key", LLMConfig{Provider: "anthropic"}, true},
+		{"self-hosted without key", LLMConfig{Provider: LLMProviderOpenAICompatible, BaseURL: "http://vllm:8000/v1", Model: "m"}, false},
+		{"self-hosted without base URL", LLMConfig{Provider: LLMProviderOpenAICompatible, Model: "m"}, true},
+		{"self-hosted without model", LLMConfig{Provider: LLMProviderOpenAICompatible, BaseURL: "http://vllm:8000/v1"}, true},
+		{"bad base URL", LLMConfig{Provider: "openai", APIKey: "k", BaseURL: "vllm:8000"}, true},
+		{"bad response format", LLMConfig{Provider: "openai", APIKey: "k", ResponseFormat: "xml"}, true},
+		{"unknown provider", LLMConfig{Provider: "bard", APIKey: "k"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"sort"
 	"sync"
-	"time"
 
 	"github.com/proth1/text-moderator/internal/models"
 	"github.com/sony/gobreaker"
@@ -16,134 +15,209 @@ import (
 // with fallback chains for resilience.
 // Control: MOD-001 (Multi-provider classification orchestration)
 type Orchestrator struct {
-	providers  map[string]Provider
-	breakers   map[string]*gobreaker.CircuitBreaker
-	config     OrchestratorConfig
-	calibrator *Calibrator
-	mu         sync.RWMutex
-	logger     *zap.Logger
+	providers    map[string]Provider
+	breakers     map[string]*gobreaker.CircuitBreaker
+	breakerModes map[string]BreakerMode // operator overrides; absent = BreakerAuto
+	latencies    map[string]*latencyTracker
+	config       OrchestratorConfig
+	calibrator   *Calibrator
+	translator   Translator // pivots unsupported languages to PivotLanguage; nil disables
+	mu           sync.RWMutex
+	logger       *zap.Logger
+
+	ensembleWeights    EnsembleWeights      // per-provider, per-category weights for the "weighted" strategy
+	decisionThresholds map[string][]float64 // policy thresholds per category, for ensemble early exit
+
+	// breakerListener has its own lock: gobreaker invokes it while holding the
+	// breaker's mutex, which may happen while o.mu is held.
+	breakerListener func(BreakerTransition)
+	listenerMu      sync.RWMutex
 }
 
 // NewOrchestrator creates a new provider orchestrator.
 func NewOrchestrator(cfg OrchestratorConfig, logger *zap.Logger) *Orchestrator {
 	return &Orchestrator{
-		providers: make(map[string]Provider),
-		breakers:  make(map[string]*gobreaker.CircuitBreaker),
-		config:    cfg,
-		logger:    logger,
+		providers:    make(map[string]Provider),
+		breakers:     make(map[string]*gobreaker.CircuitBreaker),
+		breakerModes: make(map[string]BreakerMode),
+		latencies:    make(map[string]*latencyTracker),
+		config:       cfg,
+		logger:       logger,
 	}
 }
 
 // RegisterProvider adds a classification provider to the orchestrator.
-// A circuit breaker is created per provider: opens after 5 consecutive failures,
-// half-opens after 60 seconds.
+// A circuit breaker is created per provider from its ProviderConfig.Breaker
+// settings; by default it opens after 5 consecutive failures and half-opens
+// after 60 seconds.
 func (o *Orchestrator) RegisterProvider(provider Provider) {
 	o.mu.Lock()
 	defer o.mu.Unlock()
 	name := provider.Name()
 	o.providers[name] = provider
-
-	o.breakers[name] = gobreaker.NewCircuitBreaker(gobreaker.Settings{
-		Name:        "classifier-" + name,
-		MaxRequests: 1,                // allow 1 request in half-open state
-		Interval:    0,                // don't clear counts in closed state
-		Timeout:     60 * time.Second, // half-open after 60s
-		ReadyToTrip: func(counts gobreaker.Counts) bool {
-			return counts.ConsecutiveFailures >= 5
-		},
-		OnStateChange: func(name string, from gobreaker.State, to gobreaker.State) {
-			o.logger.Warn("circuit breaker state change",
-				zap.String("breaker", name),
-				zap.String("from", from.String()),
-				zap.String("to", to.String()),
-			)
-		},
-	})
+	o.breakers[name] = o.newBreaker(name)
+	o.latencies[name] = newLatencyTracker()
 
 	o.logger.Info("registered classification provider", zap.String("provider", name))
 }
 
 // Classify routes the classification request to the highest-priority available provider,
-// falling back to alternatives on failure when fallback is enabled.
+// falling back to alternatives on failure when fallback is enabled. Providers sharing a
+// priority split traffic by Weight, keyed on a stable bucket derived from the text.
+// When configured, the whole chain is bounded by LatencyBudget and a slow provider
+// is hedged with the next one in line.
 func (o *Orchestrator) Classify(ctx context.Context, text string) (*ClassificationResult, error) {
-	o.mu.RLock()
-	defer o.mu.RUnlock()
-
-	ordered := o.orderedProviders()
-	if len(ordered) == 0 {
-		return nil, fmt.Errorf("no classification providers registered")
-	}
+	bucket := RoutingBucket(text)
 
-	var lastErr error
+	o.mu.RLock()
+	ordered := o.routedProviders(bucket)
+	candidates := make([]chainCandidate, 0, len(ordered))
 	for _, pcfg := range ordered {
 		provider, exists := o.providers[pcfg.Name]
 		if !exists {
 			o.logger.Warn("configured provider not registered", zap.String("provider", pcfg.Name))
 			continue
 		}
+		candidates = append(candidates, o.newCandidate(provider, text))
+	}
+	calibrator := o.calibrator
+	fallbackEnabled := o.config.FallbackEnabled
+	budget := o.config.LatencyBudget
+	hedging := o.config.Hedging
+	o.mu.RUnlock()
 
-		breaker := o.breakers[pcfg.Name]
-		var scores *models.CategoryScores
-
-		if breaker != nil {
-			result, err := breaker.Execute(func() (interface{}, error) {
-				return provider.Classify(ctx, text)
-			})
-			if err != nil {
-				lastErr = err
-				o.logger.Warn("provider classification failed",
-					zap.String("provider", pcfg.Name),
-					zap.Error(err),
-				)
-				if o.config.FallbackEnabled {
-					continue
-				}
-				return nil, fmt.Errorf("provider %s failed: %w", pcfg.Name, err)
-			}
-			scores = result.(*models.CategoryScores)
-		} else {
-			var err error
-			scores, err = provider.Classify(ctx, text)
-			if err != nil {
-				lastErr = err
-				o.logger.Warn("provider classification failed",
-					zap.String("provider", pcfg.Name),
-					zap.Error(err),
-				)
-				if o.config.FallbackEnabled {
-					continue
-				}
-				return nil, fmt.Errorf("provider %s failed: %w", pcfg.Name, err)
-			}
-		}
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("no classification providers registered")
+	}
 
-		if o.calibrator != nil {
-			scores = o.calibrator.Calibrate(pcfg.Name, scores)
-		}
+	// Hedging races the next provider in the chain, which only makes sense with fallback.
+	if !fallbackEnabled {
+		hedging = nil
+	}
 
-		modelName, modelVersion := provider.ModelInfo()
-		return &ClassificationResult{
-			Scores:       scores,
-			ProviderName: provider.Name(),
-			ModelName:    modelName,
-			ModelVersion: modelVersion,
-		}, nil
+	if budget > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, budget)
+		defer cancel()
+	}
+
+	idx, scores, spans, err := o.runChain(ctx, candidates, fallbackEnabled, hedging)
+	if err != nil {
+		return nil, err
+	}
+
+	provider := candidates[idx].provider
+	rawScores := scores
+	if calibrator != nil {
+		scores = calibrator.Calibrate(provider.Name(), scores)
+	}
+
+	modelName, modelVersion := provider.ModelInfo()
+	return &ClassificationResult{
+		Scores:             scores,
+		RawScores:          rawScores,
+		ProviderName:       provider.Name(),
+		ModelName:          modelName,
+		ModelVersion:       modelVersion,
+		RoutingBucket:      &bucket,
+		CalibrationVersion: calibrator.Version(),
+		Spans:              spans,
+	}, nil
+}
+
+// newCandidate builds a chain candidate that classifies text through the
+// provider's circuit breaker. Caller must hold o.mu.
+func (o *Orchestrator) newCandidate(provider Provider, text string) chainCandidate {
+	name := provider.Name()
+	guard := o.breakerFor(name)
+	return chainCandidate{
+		name:     name,
+		provider: provider,
+		execute: func(ctx context.Context) (*models.CategoryScores, []TextSpan, error) {
+			return classifyThroughBreaker(ctx, guard, provider, text, "")
+		},
+		latency: o.latencies[name],
 	}
+}
+
+// providerBreaker is a provider's circuit breaker with its operator override.
+type providerBreaker struct {
+	breaker *gobreaker.CircuitBreaker
+	mode    BreakerMode
+}
 
-	return nil, fmt.Errorf("all providers failed, last error: %w", lastErr)
+// breakerFor returns the breaker guarding the named provider. Caller must hold o.mu.
+func (o *Orchestrator) breakerFor(name string) providerBreaker {
+	return providerBreaker{breaker: o.breakers[name], mode: o.breakerModes[name]}
+}
+
+// execute runs a call to the named provider via the circuit breaker when one
+// is set, honouring any operator override. Every call to a provider goes
+// through here, so forcing a breaker open stops all traffic to it.
+func (b providerBreaker) execute(name string, call func() (*models.CategoryScores, []TextSpan, error)) (*models.CategoryScores, []TextSpan, error) {
+	switch b.mode {
+	case BreakerForcedOpen:
+		return nil, nil, fmt.Errorf("provider %s forced open: %w", name, gobreaker.ErrOpenState)
+	case BreakerForcedClosed:
+		return call()
+	}
+	if b.breaker == nil {
+		return call()
+	}
+	result, err := b.breaker.Execute(func() (interface{}, error) {
+		scores, spans, err := call()
+		return spannedScores{scores: scores, spans: spans}, err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	r := result.(spannedScores)
+	return r.scores, r.spans, nil
+}
+
+// classifyThroughBreaker classifies text with the language hint lang, if set,
+// through the provider's circuit breaker.
+func classifyThroughBreaker(ctx context.Context, guard providerBreaker, provider Provider, text, lang string) (*models.CategoryScores, []TextSpan, error) {
+	return guard.execute(provider.Name(), func() (*models.CategoryScores, []TextSpan, error) {
+		return classifyWithSpans(ctx, provider, text, lang)
+	})
+}
+
+// spannedScores carries a SpanProvider's spans through the circuit breaker.
+type spannedScores struct {
+	scores *models.CategoryScores
+	spans  []TextSpan
+}
+
+// classifyWithSpans classifies text with the language hint lang, if set, and
+// returns the provider's spans when it is a SpanProvider.
+func classifyWithSpans(ctx context.Context, provider Provider, text, lang string) (*models.CategoryScores, []TextSpan, error) {
+	if sp, ok := provider.(SpanProvider); ok {
+		return sp.ClassifyWithSpans(ctx, text, lang)
+	}
+	if lp, ok := provider.(LanguageAwareProvider); ok && lang != "" {
+		scores, err := lp.ClassifyWithLanguage(ctx, text, lang)
+		return scores, nil, err
+	}
+	scores, err := provider.Classify(ctx, text)
+	return scores, nil, err
 }
 
 // ClassifyWithProvider routes classification to a specific named provider.
 func (o *Orchestrator) ClassifyWithProvider(ctx context.Context, text string, providerName string) (*ClassificationResult, error) {
 	o.mu.RLock()
 	provider, exists := o.providers[providerName]
+	guard := o.breakerFor(providerName)
 	o.mu.RUnlock()
 
 	if !exists {
 		return nil, fmt.Errorf("provider %q not registered", providerName)
 	}
 
-	scores, err := provider.Classify(ctx, text)
+	scores, _, err := guard.execute(providerName, func() (*models.CategoryScores, []TextSpan, error) {
+		scores, err := provider.Classify(ctx, text)
+		return scores, nil, err
+	})
 	if err != nil {
 		return nil, fmt.Errorf("provider %s failed: %w", providerName, err)
 	}
@@ -164,6 +238,38 @@ func (o *Orchestrator) SetCalibrator(c *Calibrator) {
 	o.calibrator = c
 }
 
+// SetTranslator sets an optional translator used when no language-aware
+// provider supports the detected languages.
+func (o *Orchestrator) SetTranslator(t Translator) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.translator = t
+}
+
+// TranslationSource returns the language content in langs would be translated
+// from before classification, or "" when a language-aware provider supports
+// it, it is English or undetermined, or no translator is set.
+func (o *Orchestrator) TranslationSource(langs []string) string {
+	langs = routableLanguages(langs)
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	if o.translator == nil || !hasNonEnglish(langs) {
+		return ""
+	}
+	if len(rankLanguageProviders(o.orderedProviders(), o.providers, langs)) > 0 {
+		return ""
+	}
+	return translationSource(langs)
+}
+
+// CalibrationVersion returns the fitted calibration version currently applied,
+// or nil when scores are uncalibrated or use a hand-written config.
+func (o *Orchestrator) CalibrationVersion() *int {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return o.calibrator.Version()
+}
+
 // SetEnsembleConfig sets the ensemble configuration.
 func (o *Orchestrator) SetEnsembleConfig(cfg *EnsembleConfig) {
 	o.mu.Lock()
@@ -178,63 +284,265 @@ func (o *Orchestrator) IsEnsembleEnabled() bool {
 	return o.config.Ensemble != nil && o.config.Ensemble.Enabled
 }
 
-// ClassifyWithLanguage routes classification with a language hint.
-// Prefers LanguageAwareProvider implementations for non-English text.
-func (o *Orchestrator) ClassifyWithLanguage(ctx context.Context, text string, lang string) (*ClassificationResult, error) {
-	// For English or empty language, use standard classification
-	if lang == "" || lang == "en" {
+// ClassifyWithLanguage routes classification using the ranked languages
+// detected in the text. Code-switched text can carry several languages, so
+// LanguageAwareProviders are ranked by how many of them they support and tried
+// in that order, with routing order breaking ties; a provider must support at
+// least one non-English language to be preferred over standard
+// classification. The provider is given the highest-ranked language it
+// supports as its hint. When no language-aware provider supports the text and
+// a Translator is set, the text is translated to PivotLanguage and the
+// translation classified instead; the result is marked Translated. Otherwise,
+// and for English-only or undetermined text, standard classification is used.
+func (o *Orchestrator) ClassifyWithLanguage(ctx context.Context, text string, langs []string) (*ClassificationResult, error) {
+	langs = routableLanguages(langs)
+	primary := primaryLanguage(langs)
+
+	// For English-only or undetermined text, use standard classification
+	if !hasNonEnglish(langs) {
 		result, err := o.Classify(ctx, text)
 		if result != nil {
-			result.DetectedLanguage = lang
+			result.DetectedLanguage = primary
 		}
 		return result, err
 	}
 
+	bucket := RoutingBucket(text)
+
 	o.mu.RLock()
-	ordered := o.orderedProviders()
+	ordered := o.routedProviders(bucket)
 	providers := make(map[string]Provider, len(o.providers))
+	guards := make(map[string]providerBreaker, len(o.providers))
 	for k, v := range o.providers {
 		providers[k] = v
+		guards[k] = o.breakerFor(k)
 	}
 	calibrator := o.calibrator
+	translator := o.translator
 	fallbackEnabled := o.config.FallbackEnabled
+	budget := o.config.LatencyBudget
 	o.mu.RUnlock()
 
 	if len(ordered) == 0 {
 		return nil, fmt.Errorf("no classification providers registered")
 	}
 
-	// Try language-aware providers first
+	// The budget covers both the language-aware attempts and the standard fallback below.
+	if budget > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, budget)
+		defer cancel()
+	}
+
+	// Try language-aware providers first, best coverage of the language set first
+	candidates := rankLanguageProviders(ordered, providers, langs)
+	if len(candidates) == 0 && translator != nil {
+		return o.classifyTranslated(ctx, translator, text, langs)
+	}
+
 	var lastErr error
-	for _, pcfg := range ordered {
-		provider, exists := providers[pcfg.Name]
-		if !exists {
-			continue
+	for _, candidate := range candidates {
+		scores, spans, err := classifyThroughBreaker(ctx, guards[candidate.name], candidate.provider, text, candidate.hint)
+		if err != nil {
+			lastErr = err
+			o.logger.Warn("language-aware provider failed",
+				zap.String("provider", candidate.name),
+				zap.String("language", candidate.hint),
+				zap.Error(err),
+			)
+			if fallbackEnabled {
+				continue
+			}
+			return nil, fmt.Errorf("provider %s failed: %w", candidate.name, err)
+		}
+
+		rawScores := scores
+		if calibrator != nil {
+			scores = calibrator.Calibrate(candidate.name, scores)
 		}
 
-		langProvider, isLangAware := provider.(LanguageAwareProvider)
-		if !isLangAware {
+		modelName, modelVersion := candidate.provider.ModelInfo()
+		return &ClassificationResult{
+			Scores:             scores,
+			RawScores:          rawScores,
+			ProviderName:       candidate.provider.Name(),
+			ModelName:          modelName,
+			ModelVersion:       modelVersion,
+			DetectedLanguage:   primary,
+			RoutingBucket:      &bucket,
+			CalibrationVersion: calibrator.Version(),
+			Spans:              spans,
+		}, nil
+	}
+
+	// Fall back to standard classification if no language-aware provider succeeded
+	if lastErr != nil {
+		o.logger.Warn("no language-aware provider succeeded, falling back to standard classification",
+			zap.Strings("languages", langs),
+			zap.Error(lastErr),
+		)
+	}
+
+	result, err := o.Classify(ctx, text)
+	if result != nil {
+		result.DetectedLanguage = primary
+	}
+	return result, err
+}
+
+// classifyTranslated translates text from its highest-ranked non-English
+// language to PivotLanguage and classifies the translation. If translation
+// fails, the untranslated text is classified.
+func (o *Orchestrator) classifyTranslated(ctx context.Context, translator Translator, text string, langs []string) (*ClassificationResult, error) {
+	source := translationSource(langs)
+	translated, err := translator.Translate(ctx, text, source, PivotLanguage)
+	if err != nil {
+		o.logger.Warn("translation failed, classifying untranslated text",
+			zap.String("translator", translator.Name()),
+			zap.String("language", source),
+			zap.Error(err),
+		)
+		result, err := o.Classify(ctx, text)
+		if result != nil {
+			result.DetectedLanguage = primaryLanguage(langs)
+		}
+		return result, err
+	}
+
+	result, err := o.Classify(ctx, translated)
+	if result != nil {
+		result.DetectedLanguage = primaryLanguage(langs)
+		result.Translated = true
+		result.SourceLanguage = source
+		result.Spans = nil // offsets into the translation, not the text
+	}
+	return result, err
+}
+
+// translationSource returns the highest-ranked non-English language in langs.
+func translationSource(langs []string) string {
+	for _, lang := range langs {
+		if lang != PivotLanguage {
+			return lang
+		}
+	}
+	return ""
+}
+
+// languageCandidate is a language-aware provider chosen for a language set.
+type languageCandidate struct {
+	name     string
+	provider LanguageAwareProvider
+	hint     string
+	coverage int
+}
+
+// rankLanguageProviders returns the language-aware providers that support at
+// least one non-English language in langs, most languages covered first.
+// ordered is the routing order, which breaks ties.
+func rankLanguageProviders(ordered []ProviderConfig, providers map[string]Provider, langs []string) []languageCandidate {
+	var candidates []languageCandidate
+	for _, pcfg := range ordered {
+		langProvider, ok := providers[pcfg.Name].(LanguageAwareProvider)
+		if !ok {
 			continue
 		}
 
-		// Check if this provider supports the detected language
-		supported := false
+		supported := make(map[string]bool)
 		for _, sl := range langProvider.SupportedLanguages() {
-			if sl == lang {
-				supported = true
-				break
+			supported[sl] = true
+		}
+
+		candidate := languageCandidate{name: pcfg.Name, provider: langProvider}
+		nonEnglish := false
+		for _, lang := range langs {
+			if !supported[lang] {
+				continue
+			}
+			if candidate.hint == "" {
+				candidate.hint = lang
 			}
+			candidate.coverage++
+			nonEnglish = nonEnglish || lang != "en"
+		}
+		if nonEnglish {
+			candidates = append(candidates, candidate)
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].coverage > candidates[j].coverage
+	})
+	return candidates
+}
+
+// routableLanguages drops empty and undetermined ("und", ISO 639-2) codes and
+// duplicates, keeping rank order.
+func routableLanguages(langs []string) []string {
+	var out []string
+	seen := make(map[string]bool)
+	for _, lang := range langs {
+		if lang == "" || lang == "und" || seen[lang] {
+			continue
+		}
+		seen[lang] = true
+		out = append(out, lang)
+	}
+	return out
+}
+
+// primaryLanguage returns the highest-ranked routable language, or "" if none.
+func primaryLanguage(langs []string) string {
+	langs = routableLanguages(langs)
+	if len(langs) == 0 {
+		return ""
+	}
+	return langs[0]
+}
+
+func hasNonEnglish(langs []string) bool {
+	for _, lang := range langs {
+		if lang != "en" {
+			return true
 		}
-		if !supported {
+	}
+	return false
+}
+
+// ClassifyWithContext classifies a message in a conversation. Context-aware
+// providers are tried first in routing order and given the prior messages;
+// when none is registered or all fail, the message alone is classified with
+// ClassifyWithLanguage.
+func (o *Orchestrator) ClassifyWithContext(ctx context.Context, message models.ConversationMessage, history []models.ConversationMessage, langs []string) (*ClassificationResult, error) {
+	bucket := RoutingBucket(message.Content)
+
+	o.mu.RLock()
+	ordered := o.routedProviders(bucket)
+	providers := make(map[string]Provider, len(o.providers))
+	guards := make(map[string]providerBreaker, len(o.providers))
+	for k, v := range o.providers {
+		providers[k] = v
+		guards[k] = o.breakerFor(k)
+	}
+	calibrator := o.calibrator
+	fallbackEnabled := o.config.FallbackEnabled
+	o.mu.RUnlock()
+
+	var lastErr error
+	for _, pcfg := range ordered {
+		contextProvider, ok := providers[pcfg.Name].(ContextAwareProvider)
+		if !ok {
 			continue
 		}
 
-		scores, err := langProvider.ClassifyWithLanguage(ctx, text, lang)
+		scores, _, err := guards[pcfg.Name].execute(pcfg.Name, func() (*models.CategoryScores, []TextSpan, error) {
+			scores, err := contextProvider.ClassifyWithContext(ctx, message, history)
+			return scores, nil, err
+		})
 		if err != nil {
 			lastErr = err
-			o.logger.Warn("language-aware provider failed",
+			o.logger.Warn("context-aware provider failed",
 				zap.String("provider", pcfg.Name),
-				zap.String("language", lang),
 				zap.Error(err),
 			)
 			if fallbackEnabled {
@@ -243,33 +551,29 @@ func (o *Orchestrator) ClassifyWithLanguage(ctx context.Context, text string, la
 			return nil, fmt.Errorf("provider %s failed: %w", pcfg.Name, err)
 		}
 
+		rawScores := scores
 		if calibrator != nil {
 			scores = calibrator.Calibrate(pcfg.Name, scores)
 		}
 
-		modelName, modelVersion := provider.ModelInfo()
+		modelName, modelVersion := contextProvider.ModelInfo()
 		return &ClassificationResult{
-			Scores:           scores,
-			ProviderName:     provider.Name(),
-			ModelName:        modelName,
-			ModelVersion:     modelVersion,
-			DetectedLanguage: lang,
+			Scores:             scores,
+			RawScores:          rawScores,
+			ProviderName:       contextProvider.Name(),
+			ModelName:          modelName,
+			ModelVersion:       modelVersion,
+			DetectedLanguage:   primaryLanguage(langs),
+			RoutingBucket:      &bucket,
+			CalibrationVersion: calibrator.Version(),
+			UsedContext:        true,
 		}, nil
 	}
 
-	// Fall back to standard classification if no language-aware provider succeeded
 	if lastErr != nil {
-		o.logger.Warn("no language-aware provider succeeded, falling back to standard classification",
-			zap.String("language", lang),
-			zap.Error(lastErr),
-		)
+		o.logger.Warn("no context-aware provider succeeded, classifying message without context", zap.Error(lastErr))
 	}
-
-	result, err := o.Classify(ctx, text)
-	if result != nil {
-		result.DetectedLanguage = lang
-	}
-	return result, err
+	return o.ClassifyWithLanguage(ctx, message.Content, langs)
 }
 
 // HealthCheck returns the health status of all registered providers.
@@ -284,25 +588,38 @@ func (o *Orchestrator) HealthCheck(ctx context.Context) map[string]error {
 	return results
 }
 
-// orderedProviders returns enabled providers sorted by priority (ascending).
+// orderedProviders returns enabled serving (non-shadow) providers sorted by priority (ascending).
 func (o *Orchestrator) orderedProviders() []ProviderConfig {
 	var enabled []ProviderConfig
 	for _, p := range o.config.Providers {
-		if p.Enabled {
+		if p.Enabled && !p.Shadow {
 			enabled = append(enabled, p)
 		}
 	}
-	sort.Slice(enabled, func(i, j int) bool {
+	sort.SliceStable(enabled, func(i, j int) bool {
 		return enabled[i].Priority < enabled[j].Priority
 	})
 	return enabled
 }
 
+// routedProviders returns enabled providers in fallback order for the given
+// routing bucket, applying weighted selection within each priority group.
+func (o *Orchestrator) routedProviders(bucket int) []ProviderConfig {
+	return routeProviders(o.orderedProviders(), bucket)
+}
+
 // ClassificationResult holds the result from a provider classification.
 type ClassificationResult struct {
-	Scores           *models.CategoryScores
-	ProviderName     string
-	ModelName        string
-	ModelVersion     string
-	DetectedLanguage string
+	Scores             *models.CategoryScores
+	RawScores          *models.CategoryScores // provider scores before calibration
+	ProviderName       string
+	ModelName          string
+	ModelVersion       string
+	DetectedLanguage   string
+	RoutingBucket      *int       // traffic-splitting bucket; nil when the result was not routed
+	CalibrationVersion *int       // fitted calibration version applied; nil when none
+	UsedContext        bool       // classified by a ContextAwareProvider with conversation context
+	Translated         bool       // the text was translated to PivotLanguage before classification
+	SourceLanguage     string     // language the text was translated from; empty unless Translated
+	Spans              []TextSpan // flagged spans of the classified text; nil when the provider reports none
 }
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"sort"
 	"time"
 
 	"github.com/proth1/text-moderator/internal/models"
@@ -47,6 +48,7 @@ type perspectiveRequest struct {
 	Comment             perspectiveComment          `json:"comment"`
 	RequestedAttributes map[string]json.RawMessage  `json:"requestedAttributes"`
 	Languages           []string                    `json:"languages"`
+	SpanAnnotations     bool                        `json:"spanAnnotations,omitempty"`
 }
 
 type perspectiveComment struct {
@@ -58,7 +60,26 @@ type perspectiveResponse struct {
 }
 
 type perspectiveAttribute struct {
-	SummaryScore perspectiveScore `json:"summaryScore"`
+	SummaryScore perspectiveScore       `json:"summaryScore"`
+	SpanScores   []perspectiveSpanScore `json:"spanScores"`
+}
+
+// perspectiveSpanScore scores part of the comment. Begin and End are
+// character offsets.
+type perspectiveSpanScore struct {
+	Begin int              `json:"begin"`
+	End   int              `json:"end"`
+	Score perspectiveScore `json:"score"`
+}
+
+// perspectiveCategories maps Perspective attributes to moderation categories.
+var perspectiveCategories = map[string]string{
+	"TOXICITY":          "toxicity",
+	"IDENTITY_ATTACK":   "hate",
+	"INSULT":            "harassment",
+	"SEXUALLY_EXPLICIT": "sexual_content",
+	"THREAT":            "violence",
+	"PROFANITY":         "profanity",
 }
 
 type perspectiveScore struct {
@@ -71,6 +92,25 @@ func (p *PerspectiveProvider) Classify(ctx context.Context, text string) (*model
 
 // ClassifyWithLanguage implements LanguageAwareProvider for Perspective API.
 func (p *PerspectiveProvider) ClassifyWithLanguage(ctx context.Context, text string, lang string) (*models.CategoryScores, error) {
+	resp, err := p.analyze(ctx, text, lang, false)
+	if err != nil {
+		return nil, err
+	}
+	return p.convertScores(resp), nil
+}
+
+// ClassifyWithSpans implements SpanProvider using Perspective span annotations.
+// Spans scoring below minSpanScore are dropped.
+func (p *PerspectiveProvider) ClassifyWithSpans(ctx context.Context, text string, lang string) (*models.CategoryScores, []TextSpan, error) {
+	resp, err := p.analyze(ctx, text, lang, true)
+	if err != nil {
+		return nil, nil, err
+	}
+	return p.convertScores(resp), convertPerspectiveSpans(text, resp), nil
+}
+
+// analyze requests the attribute scores for text, with span annotations when spans is set.
+func (p *PerspectiveProvider) analyze(ctx context.Context, text string, lang string, spans bool) (*perspectiveResponse, error) {
 	if lang == "" {
 		lang = "en"
 	}
@@ -85,7 +125,8 @@ func (p *PerspectiveProvider) ClassifyWithLanguage(ctx context.Context, text str
 			"THREAT":              json.RawMessage(`{}`),
 			"SEXUALLY_EXPLICIT":   json.RawMessage(`{}`),
 		},
-		Languages: []string{lang},
+		Languages:       []string{lang},
+		SpanAnnotations: spans,
 	}
 
 	jsonData, err := json.Marshal(reqBody)
@@ -120,34 +161,48 @@ func (p *PerspectiveProvider) ClassifyWithLanguage(ctx context.Context, text str
 		return nil, fmt.Errorf("failed to parse response: %w", err)
 	}
 
-	return p.convertScores(&pResp), nil
+	return &pResp, nil
 }
 
 func (p *PerspectiveProvider) convertScores(resp *perspectiveResponse) *models.CategoryScores {
-	scores := &models.CategoryScores{}
-
-	if attr, ok := resp.AttributeScores["TOXICITY"]; ok {
-		scores.Toxicity = attr.SummaryScore.Value
-	}
-	if attr, ok := resp.AttributeScores["IDENTITY_ATTACK"]; ok {
-		scores.Hate = attr.SummaryScore.Value
-	}
-	if attr, ok := resp.AttributeScores["INSULT"]; ok {
-		scores.Harassment = attr.SummaryScore.Value
-	}
-	if attr, ok := resp.AttributeScores["SEXUALLY_EXPLICIT"]; ok {
-		scores.SexualContent = attr.SummaryScore.Value
-	}
-	if attr, ok := resp.AttributeScores["THREAT"]; ok {
-		scores.Violence = attr.SummaryScore.Value
+	values := make(map[string]float64, len(perspectiveCategories))
+	for attribute, category := range perspectiveCategories {
+		if attr, ok := resp.AttributeScores[attribute]; ok {
+			values[category] = attr.SummaryScore.Value
+		}
 	}
-	if attr, ok := resp.AttributeScores["PROFANITY"]; ok {
-		scores.Profanity = attr.SummaryScore.Value
-	}
-
+	scores := &models.CategoryScores{}
+	scores.FromMap(values)
 	return scores
 }
 
+// convertPerspectiveSpans converts span annotations to byte offsets into text,
+// ordered by offset then category.
+func convertPerspectiveSpans(text string, resp *perspectiveResponse) []TextSpan {
+	var spans []TextSpan
+	for attribute, category := range perspectiveCategories {
+		for _, s := range resp.AttributeScores[attribute].SpanScores {
+			if s.Score.Value < minSpanScore || s.End <= s.Begin {
+				continue
+			}
+			spans = append(spans, TextSpan{
+				Category: category,
+				Start:    runeOffsetToByte(text, s.Begin),
+				End:      runeOffsetToByte(text, s.End),
+				Score:    s.Score.Value,
+				Source:   "perspective",
+			})
+		}
+	}
+	sort.Slice(spans, func(i, j int) bool {
+		if spans[i].Start != spans[j].Start {
+			return spans[i].Start < spans[j].Start
+		}
+		return spans[i].Category < spans[j].Category
+	})
+	return spans
+}
+
 // SupportedLanguages returns the languages supported by Perspective API.
 func (p *PerspectiveProvider) SupportedLanguages() []string {
 	return []string{"en", "es", "fr", "de", "pt", "it", "ru", "tr", "ar", "zh", "ja", "ko", "hi", "id"}
@@ -2,6 +2,7 @@ package classifier
 
 import (
 	"context"
+	"time"
 
 	"github.com/proth1/text-moderator/internal/models"
 )
@@ -34,6 +35,26 @@ type LanguageAwareProvider interface {
 	SupportedLanguages() []string
 }
 
+// ContextAwareProvider extends Provider with classification of a message in
+// the context of the conversation it belongs to.
+type ContextAwareProvider interface {
+	Provider
+
+	// ClassifyWithContext scores message, reading the prior messages in
+	// history (oldest first) as context only.
+	ClassifyWithContext(ctx context.Context, message models.ConversationMessage, history []models.ConversationMessage) (*models.CategoryScores, error)
+}
+
+// SpanProvider extends Provider with the locations in the text behind its
+// scores, for highlighting and redaction.
+type SpanProvider interface {
+	Provider
+
+	// ClassifyWithSpans scores text like Classify, or like ClassifyWithLanguage
+	// when lang is set, and also returns the spans of text that drove the scores.
+	ClassifyWithSpans(ctx context.Context, text string, lang string) (*models.CategoryScores, []TextSpan, error)
+}
+
 // ProviderConfig defines routing configuration for a classification provider.
 type ProviderConfig struct {
 	// Name identifies which provider to use.
@@ -42,11 +63,34 @@ type ProviderConfig struct {
 	// Priority determines selection order (lower = higher priority).
 	Priority int `json:"priority" yaml:"priority"`
 
-	// Weight for weighted random selection among same-priority providers (0-100).
+	// Weight for traffic splitting among same-priority providers (0-100).
+	// Selection is sticky per content hash; see RoutingBucket.
 	Weight int `json:"weight,omitempty" yaml:"weight,omitempty"`
 
 	// Enabled controls whether this provider is active.
 	Enabled bool `json:"enabled" yaml:"enabled"`
+
+	// Shadow providers are invoked alongside the serving provider for evaluation
+	// only. They are never part of the fallback chain or ensemble.
+	Shadow bool `json:"shadow,omitempty" yaml:"shadow,omitempty"`
+
+	// Breaker overrides the default circuit breaker settings. Nil uses the defaults.
+	Breaker *BreakerConfig `json:"breaker,omitempty" yaml:"breaker,omitempty"`
+}
+
+// BreakerConfig controls the circuit breaker wrapping a provider.
+type BreakerConfig struct {
+	// MaxRequests allowed through while half-open (default 1).
+	MaxRequests uint32 `json:"max_requests" yaml:"max_requests"`
+
+	// Interval after which counts are cleared while closed (0 = never).
+	Interval time.Duration `json:"interval" yaml:"interval"`
+
+	// Timeout after which an open breaker half-opens (default 60s).
+	Timeout time.Duration `json:"timeout" yaml:"timeout"`
+
+	// ConsecutiveFailures that trip the breaker (default 5).
+	ConsecutiveFailures uint32 `json:"consecutive_failures" yaml:"consecutive_failures"`
 }
 
 // EnsembleConfig controls ensemble mode where multiple providers run in parallel.
@@ -54,7 +98,29 @@ type EnsembleConfig struct {
 	Enabled            bool    `json:"enabled" yaml:"enabled"`
 	MinProviders       int     `json:"min_providers" yaml:"min_providers"`
 	AgreementThreshold float64 `json:"agreement_threshold" yaml:"agreement_threshold"`
-	Strategy           string  `json:"strategy" yaml:"strategy"` // "average", "median", "max"
+	Strategy           string  `json:"strategy" yaml:"strategy"` // "average", "median", "max", "weighted"
+
+	// EarlyExitMargin lets the ensemble return before slower providers respond once
+	// the first responders agree and every combined score is at least this far from
+	// the decision thresholds (0 = always wait for every provider).
+	EarlyExitMargin float64 `json:"early_exit_margin,omitempty" yaml:"early_exit_margin,omitempty"`
+}
+
+// HedgingConfig controls hedged requests: when the serving provider is slower than
+// its usual latency, the next provider in the fallback chain is started in
+// parallel and whichever answers first wins.
+type HedgingConfig struct {
+	Enabled bool `json:"enabled" yaml:"enabled"`
+
+	// Percentile of the serving provider's observed latency (e.g. 0.95) after
+	// which the hedge request is fired.
+	Percentile float64 `json:"percentile" yaml:"percentile"`
+
+	// InitialDelay is used until enough latency samples have been observed.
+	InitialDelay time.Duration `json:"initial_delay" yaml:"initial_delay"`
+
+	// MinDelay is a floor on the hedge delay to avoid doubling load on fast providers.
+	MinDelay time.Duration `json:"min_delay,omitempty" yaml:"min_delay,omitempty"`
 }
 
 // OrchestratorConfig defines configuration for the provider orchestrator.
@@ -67,4 +133,13 @@ type OrchestratorConfig struct {
 
 	// Ensemble controls parallel multi-provider classification.
 	Ensemble *EnsembleConfig `json:"ensemble,omitempty" yaml:"ensemble,omitempty"`
+
+	// LatencyBudget caps the total time a single classification may spend across
+	// the fallback chain. Each attempt gets an equal share of the remaining budget,
+	// so a provider that fails fast leaves more time for the ones after it.
+	// Zero disables the budget.
+	LatencyBudget time.Duration `json:"latency_budget,omitempty" yaml:"latency_budget,omitempty"`
+
+	// Hedging optionally races the next provider against a slow serving provider.
+	Hedging *HedgingConfig `json:"hedging,omitempty" yaml:"hedging,omitempty"`
 }
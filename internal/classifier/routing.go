@@ -0,0 +1,123 @@
+package classifier
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// RoutingBuckets is the number of buckets content is spread over for weighted
+// traffic splitting. With 100 buckets a provider weight maps directly onto a
+// percentage of traffic when the weights in a priority group sum to 100.
+const RoutingBuckets = 100
+
+// RoutingBucket maps text to a stable bucket in [0, RoutingBuckets). The same
+// (normalized) content always lands in the same bucket, so retries and
+// duplicate submissions are routed to the same provider.
+func RoutingBucket(text string) int {
+	h := sha256.Sum256([]byte(text))
+	return int(binary.BigEndian.Uint64(h[:8]) % RoutingBuckets)
+}
+
+// routeProviders orders providers by priority and, within each group of
+// providers sharing a priority, moves the provider selected by bucket to the
+// front. Selection is proportional to Weight; the remaining providers in the
+// group keep their configured order and serve as fallbacks. Groups where no
+// provider has a weight are left in configured order.
+func routeProviders(providers []ProviderConfig, bucket int) []ProviderConfig {
+	ordered := make([]ProviderConfig, len(providers))
+	copy(ordered, providers)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].Priority < ordered[j].Priority
+	})
+
+	for start := 0; start < len(ordered); {
+		end := start + 1
+		for end < len(ordered) && ordered[end].Priority == ordered[start].Priority {
+			end++
+		}
+		selectWeighted(ordered[start:end], bucket)
+		start = end
+	}
+	return ordered
+}
+
+// selectWeighted moves the provider owning bucket to the front of group.
+func selectWeighted(group []ProviderConfig, bucket int) {
+	total := 0
+	for _, p := range group {
+		if p.Weight > 0 {
+			total += p.Weight
+		}
+	}
+	if len(group) < 2 || total == 0 {
+		return
+	}
+
+	// Scale the bucket onto the group's total weight so weights need not sum to 100.
+	point := bucket * total / RoutingBuckets
+	cumulative := 0
+	for i, p := range group {
+		if p.Weight <= 0 {
+			continue
+		}
+		cumulative += p.Weight
+		if point < cumulative {
+			chosen := group[i]
+			copy(group[1:i+1], group[:i])
+			group[0] = chosen
+			return
+		}
+	}
+}
+
+// RoutingOverride adjusts the priority, weight or shadow flag of a configured provider.
+type RoutingOverride struct {
+	Priority *int  `json:"priority,omitempty"`
+	Weight   *int  `json:"weight,omitempty"`
+	Shadow   *bool `json:"shadow,omitempty"`
+}
+
+// ApplyRoutingJSON applies per-provider routing overrides from a JSON object
+// keyed by provider name, e.g. to canary OpenAI at 5% next to HuggingFace:
+//
+//	{"huggingface":{"priority":1,"weight":95},"openai":{"priority":1,"weight":5}}
+//
+// or to evaluate OpenAI in shadow mode without serving its scores:
+//
+//	{"openai":{"shadow":true}}
+//
+// Configs are returned unchanged if the JSON is empty.
+func ApplyRoutingJSON(configs []ProviderConfig, routingJSON string) ([]ProviderConfig, error) {
+	if routingJSON == "" {
+		return configs, nil
+	}
+	var overrides map[string]RoutingOverride
+	if err := json.Unmarshal([]byte(routingJSON), &overrides); err != nil {
+		return configs, fmt.Errorf("invalid provider routing config: %w", err)
+	}
+
+	result := make([]ProviderConfig, len(configs))
+	copy(result, configs)
+	for i := range result {
+		o, ok := overrides[result[i].Name]
+		if !ok {
+			continue
+		}
+		if o.Priority != nil {
+			result[i].Priority = *o.Priority
+		}
+		if o.Weight != nil {
+			if *o.Weight < 0 || *o.Weight > 100 {
+				return configs, fmt.Errorf("provider %s: weight must be between 0 and 100", result[i].Name)
+			}
+			result[i].Weight = *o.Weight
+		}
+		if o.Shadow != nil {
+			result[i].Shadow = *o.Shadow
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,219 @@
+package classifier
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/proth1/text-moderator/internal/models"
+	"go.uber.org/zap"
+)
+
+// fakeProvider is a minimal Provider used to exercise orchestrator routing.
+// A non-zero delay simulates a slow provider that honours context cancellation.
+type fakeProvider struct {
+	name   string
+	scores models.CategoryScores
+	err    error
+	delay  time.Duration
+	calls  atomic.Int32
+}
+
+func (p *fakeProvider) Classify(ctx context.Context, text string) (*models.CategoryScores, error) {
+	p.calls.Add(1)
+	if p.delay > 0 {
+		select {
+		case <-time.After(p.delay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	if p.err != nil {
+		return nil, p.err
+	}
+	s := p.scores
+	return &s, nil
+}
+
+func (p *fakeProvider) Name() string                     { return p.name }
+func (p *fakeProvider) ModelInfo() (string, string)      { return p.name + "-model", "v1" }
+func (p *fakeProvider) Health(ctx context.Context) error { return nil }
+
+func TestRoutingBucket_Stable(t *testing.T) {
+	for _, text := range []string{"", "hello", "some longer piece of content"} {
+		a, b := RoutingBucket(text), RoutingBucket(text)
+		if a != b {
+			t.Errorf("RoutingBucket(%q) not stable: %d vs %d", text, a, b)
+		}
+		if a < 0 || a >= RoutingBuckets {
+			t.Errorf("RoutingBucket(%q) = %d, out of range", text, a)
+		}
+	}
+}
+
+func TestRouteProviders(t *testing.T) {
+	canary := []ProviderConfig{
+		{Name: "primary", Priority: 1, Weight: 95, Enabled: true},
+		{Name: "canary", Priority: 1, Weight: 5, Enabled: true},
+		{Name: "fallback", Priority: 2, Enabled: true},
+	}
+
+	tests := []struct {
+		name      string
+		providers []ProviderConfig
+		bucket    int
+		want      []string
+	}{
+		{"low bucket picks primary", canary, 0, []string{"primary", "canary", "fallback"}},
+		{"last primary bucket", canary, 94, []string{"primary", "canary", "fallback"}},
+		{"first canary bucket", canary, 95, []string{"canary", "primary", "fallback"}},
+		{"top bucket picks canary", canary, 99, []string{"canary", "primary", "fallback"}},
+		{
+			"unweighted group keeps config order",
+			[]ProviderConfig{{Name: "a", Priority: 1}, {Name: "b", Priority: 1}},
+			99,
+			[]string{"a", "b"},
+		},
+		{
+			"zero weight never selected first",
+			[]ProviderConfig{{Name: "a", Priority: 1}, {Name: "b", Priority: 1, Weight: 10}},
+			0,
+			[]string{"b", "a"},
+		},
+		{
+			"weights not summing to 100 are scaled",
+			[]ProviderConfig{{Name: "a", Priority: 1, Weight: 1}, {Name: "b", Priority: 1, Weight: 1}},
+			50,
+			[]string{"b", "a"},
+		},
+		{
+			"priority still wins over weight",
+			[]ProviderConfig{{Name: "late", Priority: 2, Weight: 100}, {Name: "early", Priority: 1}},
+			0,
+			[]string{"early", "late"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := routeProviders(tt.providers, tt.bucket)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d providers, want %d", len(got), len(tt.want))
+			}
+			for i, p := range got {
+				if p.Name != tt.want[i] {
+					t.Errorf("position %d: got %s, want %s", i, p.Name, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestRouteProviders_Distribution(t *testing.T) {
+	providers := []ProviderConfig{
+		{Name: "primary", Priority: 1, Weight: 95, Enabled: true},
+		{Name: "canary", Priority: 1, Weight: 5, Enabled: true},
+	}
+
+	const n = 20000
+	canaryCount := 0
+	for i := 0; i < n; i++ {
+		if routeProviders(providers, RoutingBucket(fmt.Sprintf("content-%d", i)))[0].Name == "canary" {
+			canaryCount++
+		}
+	}
+
+	share := float64(canaryCount) / n
+	if share < 0.04 || share > 0.06 {
+		t.Errorf("canary share = %f, want ~0.05", share)
+	}
+}
+
+func TestOrchestrator_WeightedRoutingIsSticky(t *testing.T) {
+	o := NewOrchestrator(OrchestratorConfig{
+		Providers: []ProviderConfig{
+			{Name: "primary", Priority: 1, Weight: 50, Enabled: true},
+			{Name: "canary", Priority: 1, Weight: 50, Enabled: true},
+		},
+		FallbackEnabled: true,
+	}, zap.NewNop())
+	o.RegisterProvider(&fakeProvider{name: "primary"})
+	o.RegisterProvider(&fakeProvider{name: "canary"})
+
+	seen := map[string]bool{}
+	for i := 0; i < 50; i++ {
+		text := fmt.Sprintf("message %d", i)
+		first, err := o.Classify(context.Background(), text)
+		if err != nil {
+			t.Fatalf("classify failed: %v", err)
+		}
+		again, err := o.Classify(context.Background(), text)
+		if err != nil {
+			t.Fatalf("classify failed: %v", err)
+		}
+		if first.ProviderName != again.ProviderName {
+			t.Errorf("%q routed to %s then %s", text, first.ProviderName, again.ProviderName)
+		}
+		if first.RoutingBucket == nil || *first.RoutingBucket != RoutingBucket(text) {
+			t.Errorf("%q: routing bucket not recorded", text)
+		}
+		seen[first.ProviderName] = true
+	}
+
+	if !seen["primary"] || !seen["canary"] {
+		t.Errorf("expected traffic on both providers, got %v", seen)
+	}
+}
+
+func TestOrchestrator_WeightedRoutingFallsBack(t *testing.T) {
+	o := NewOrchestrator(OrchestratorConfig{
+		Providers: []ProviderConfig{
+			{Name: "primary", Priority: 1, Weight: 0, Enabled: true},
+			{Name: "canary", Priority: 1, Weight: 100, Enabled: true},
+		},
+		FallbackEnabled: true,
+	}, zap.NewNop())
+	o.RegisterProvider(&fakeProvider{name: "primary"})
+	o.RegisterProvider(&fakeProvider{name: "canary", err: fmt.Errorf("unavailable")})
+
+	result, err := o.Classify(context.Background(), "hello")
+	if err != nil {
+		t.Fatalf("classify failed: %v", err)
+	}
+	if result.ProviderName != "primary" {
+		t.Errorf("got provider %s, want primary", result.ProviderName)
+	}
+}
+
+func TestApplyRoutingJSON(t *testing.T) {
+	base := []ProviderConfig{
+		{Name: "huggingface", Priority: 1, Enabled: true},
+		{Name: "openai", Priority: 3, Enabled: true},
+	}
+
+	got, err := ApplyRoutingJSON(base, `{"huggingface":{"weight":95},"openai":{"priority":1,"weight":5}}`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got[0].Weight != 95 || got[0].Priority != 1 {
+		t.Errorf("huggingface: got %+v", got[0])
+	}
+	if got[1].Weight != 5 || got[1].Priority != 1 {
+		t.Errorf("openai: got %+v", got[1])
+	}
+	if base[1].Priority != 3 {
+		t.Errorf("input configs were modified")
+	}
+
+	if _, err := ApplyRoutingJSON(base, `{"openai":{"weight":150}}`); err == nil {
+		t.Errorf("expected error for out-of-range weight")
+	}
+	if _, err := ApplyRoutingJSON(base, `not json`); err == nil {
+		t.Errorf("expected error for invalid JSON")
+	}
+	if got, _ := ApplyRoutingJSON(base, ""); len(got) != 2 || got[1].Priority != 3 {
+		t.Errorf("empty JSON should leave configs unchanged")
+	}
+}
@@ -0,0 +1,94 @@
+package classifier
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/proth1/text-moderator/internal/models"
+	"go.uber.org/zap"
+)
+
+// ShadowResult holds the outcome of a single shadow provider invocation.
+type ShadowResult struct {
+	ClassificationResult
+	Latency time.Duration
+	Err     error
+}
+
+// HasShadowProviders reports whether any enabled shadow provider is configured and registered.
+func (o *Orchestrator) HasShadowProviders() bool {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return len(o.shadowProviders()) > 0
+}
+
+// ClassifyShadow runs every enabled shadow provider in parallel and returns one
+// result per provider, including failures. Shadow results are for evaluation
+// only and must never influence the automated action.
+func (o *Orchestrator) ClassifyShadow(ctx context.Context, text string) []ShadowResult {
+	o.mu.RLock()
+	shadows := o.shadowProviders()
+	calibrator := o.calibrator
+	o.mu.RUnlock()
+
+	results := make([]ShadowResult, len(shadows))
+	var wg sync.WaitGroup
+	for i, p := range shadows {
+		wg.Add(1)
+		go func(idx int, provider Provider) {
+			defer wg.Done()
+			start := time.Now()
+			scores, err := o.executeWithBreaker(ctx, provider, text)
+			modelName, modelVersion := provider.ModelInfo()
+			res := ShadowResult{
+				ClassificationResult: ClassificationResult{
+					ProviderName: provider.Name(),
+					ModelName:    modelName,
+					ModelVersion: modelVersion,
+				},
+				Latency: time.Since(start),
+				Err:     err,
+			}
+			if err != nil {
+				o.logger.Debug("shadow provider failed",
+					zap.String("provider", provider.Name()),
+					zap.Error(err),
+				)
+			} else {
+				if calibrator != nil {
+					scores = calibrator.Calibrate(provider.Name(), scores)
+				}
+				res.Scores = scores
+			}
+			results[idx] = res
+		}(i, p)
+	}
+	wg.Wait()
+
+	return results
+}
+
+// shadowProviders returns the registered providers flagged as shadow. Caller must hold o.mu.
+func (o *Orchestrator) shadowProviders() []Provider {
+	var shadows []Provider
+	for _, pcfg := range o.config.Providers {
+		if !pcfg.Enabled || !pcfg.Shadow {
+			continue
+		}
+		if provider, exists := o.providers[pcfg.Name]; exists {
+			shadows = append(shadows, provider)
+		}
+	}
+	return shadows
+}
+
+// executeWithBreaker classifies text through the provider's circuit breaker, if any.
+func (o *Orchestrator) executeWithBreaker(ctx context.Context, provider Provider, text string) (*models.CategoryScores, error) {
+	o.mu.RLock()
+	guard := o.breakerFor(provider.Name())
+	o.mu.RUnlock()
+
+	scores, _, err := classifyThroughBreaker(ctx, guard, provider, text, "")
+	return scores, err
+}
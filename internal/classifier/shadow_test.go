@@ -0,0 +1,73 @@
+package classifier
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/proth1/text-moderator/internal/models"
+	"go.uber.org/zap"
+)
+
+func newShadowTestOrchestrator() *Orchestrator {
+	o := NewOrchestrator(OrchestratorConfig{
+		Providers: []ProviderConfig{
+			{Name: "live", Priority: 1, Enabled: true},
+			{Name: "candidate", Priority: 0, Enabled: true, Shadow: true},
+			{Name: "broken", Priority: 0, Enabled: true, Shadow: true},
+			{Name: "disabled", Priority: 0, Enabled: false, Shadow: true},
+		},
+		FallbackEnabled: true,
+		Ensemble:        &EnsembleConfig{Enabled: true, MinProviders: 1, AgreementThreshold: 0.3, Strategy: "average"},
+	}, zap.NewNop())
+	o.RegisterProvider(&fakeProvider{name: "live", scores: models.CategoryScores{Toxicity: 0.1}})
+	o.RegisterProvider(&fakeProvider{name: "candidate", scores: models.CategoryScores{Toxicity: 0.9}})
+	o.RegisterProvider(&fakeProvider{name: "broken", err: fmt.Errorf("boom")})
+	o.RegisterProvider(&fakeProvider{name: "disabled"})
+	return o
+}
+
+func TestShadowProvidersDoNotServe(t *testing.T) {
+	o := newShadowTestOrchestrator()
+
+	result, err := o.Classify(context.Background(), "hello")
+	if err != nil {
+		t.Fatalf("classify failed: %v", err)
+	}
+	if result.ProviderName != "live" {
+		t.Errorf("got provider %s, want live (shadow providers must not serve)", result.ProviderName)
+	}
+
+	ensemble, err := o.ClassifyEnsemble(context.Background(), "hello")
+	if err != nil {
+		t.Fatalf("ensemble failed: %v", err)
+	}
+	if len(ensemble.ProviderResults) != 1 || ensemble.CombinedScores.Toxicity != 0.1 {
+		t.Errorf("ensemble included shadow providers: %d results, toxicity %f",
+			len(ensemble.ProviderResults), ensemble.CombinedScores.Toxicity)
+	}
+}
+
+func TestClassifyShadow(t *testing.T) {
+	o := newShadowTestOrchestrator()
+
+	if !o.HasShadowProviders() {
+		t.Fatal("expected shadow providers")
+	}
+
+	results := o.ClassifyShadow(context.Background(), "hello")
+	if len(results) != 2 {
+		t.Fatalf("got %d shadow results, want 2", len(results))
+	}
+
+	byName := map[string]ShadowResult{}
+	for _, r := range results {
+		byName[r.ProviderName] = r
+	}
+	if r := byName["candidate"]; r.Err != nil || r.Scores == nil || r.Scores.Toxicity != 0.9 {
+		t.Errorf("candidate: got %+v", r)
+	}
+	if r := byName["broken"]; r.Err == nil || r.Scores != nil {
+		t.Errorf("broken: expected error without scores, got %+v", r)
+	}
+}
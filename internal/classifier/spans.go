@@ -0,0 +1,104 @@
+package classifier
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/proth1/text-moderator/internal/models"
+)
+
+// TextSpan is a part of the classified text that a provider flagged for a
+// category. Start and End are byte offsets into the classified text.
+type TextSpan struct {
+	Category string
+	Start    int
+	End      int
+	Score    float64
+	Source   string // provider that flagged the span, e.g. "perspective" or "lexicon:slurs"
+}
+
+// minSpanScore is the lowest score a provider's span annotation needs to be
+// reported. Perspective scores every sentence, most of them harmless.
+const minSpanScore = 0.5
+
+// PhraseSpans locates the phrases an LLM quoted in its explanation in text.
+// Every case-insensitive occurrence of a phrase is reported, scored with its
+// category's score.
+func PhraseSpans(text string, scores *models.CategoryScores, explanation *models.DecisionExplanation, source string) []TextSpan {
+	if explanation == nil || scores == nil {
+		return nil
+	}
+	values := scores.ToMap()
+
+	categories := make([]string, 0, len(explanation.Phrases))
+	for category := range explanation.Phrases {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+
+	var spans []TextSpan
+	for _, category := range categories {
+		for _, phrase := range explanation.Phrases[category] {
+			for _, start := range indexFold(text, phrase) {
+				spans = append(spans, TextSpan{
+					Category: category,
+					Start:    start,
+					End:      start + len(phrase),
+					Score:    values[category],
+					Source:   source,
+				})
+			}
+		}
+	}
+	return spans
+}
+
+// indexFold returns the byte offsets of every non-overlapping occurrence of
+// substr in s, ignoring case.
+func indexFold(s, substr string) []int {
+	if substr == "" {
+		return nil
+	}
+	var offsets []int
+	for i := 0; i+len(substr) <= len(s); {
+		if strings.EqualFold(s[i:i+len(substr)], substr) {
+			offsets = append(offsets, i)
+			i += len(substr)
+			continue
+		}
+		_, size := utf8.DecodeRuneInString(s[i:])
+		i += size
+	}
+	return offsets
+}
+
+// ChunkSpans collects the spans found in each window of chunked text,
+// converting them to byte offsets into the whole text. results[i] is the
+// classification of chunks[i] and may be nil.
+func ChunkSpans(text string, chunks []Chunk, results []*ClassificationResult) []TextSpan {
+	var spans []TextSpan
+	for i, result := range results {
+		if result == nil || len(result.Spans) == 0 {
+			continue
+		}
+		offset := runeOffsetToByte(text, chunks[i].Start)
+		for _, span := range result.Spans {
+			span.Start += offset
+			span.End += offset
+			spans = append(spans, span)
+		}
+	}
+	return spans
+}
+
+// runeOffsetToByte converts a rune offset into s to a byte offset.
+func runeOffsetToByte(s string, runes int) int {
+	for i := range s {
+		if runes == 0 {
+			return i
+		}
+		runes--
+	}
+	return len(s)
+}
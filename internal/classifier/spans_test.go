@@ -0,0 +1,139 @@
+package classifier
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/proth1/text-moderator/internal/models"
+)
+
+// fakeSpanProvider is a fakeProvider that flags every occurrence of a word.
+type fakeSpanProvider struct {
+	fakeProvider
+	word string
+}
+
+func (p *fakeSpanProvider) ClassifyWithSpans(ctx context.Context, text string, lang string) (*models.CategoryScores, []TextSpan, error) {
+	scores, err := p.Classify(ctx, text)
+	if err != nil {
+		return nil, nil, err
+	}
+	var spans []TextSpan
+	for _, start := range indexFold(text, p.word) {
+		spans = append(spans, TextSpan{Category: "toxicity", Start: start, End: start + len(p.word), Score: 0.9, Source: p.name})
+	}
+	return scores, spans, nil
+}
+
+func TestPhraseSpans(t *testing.T) {
+	scores := &models.CategoryScores{Harassment: 0.8, Toxicity: 0.6}
+	tests := []struct {
+		name    string
+		text    string
+		phrases map[string][]string
+		want    []TextSpan
+	}{
+		{
+			name:    "every occurrence, ignoring case",
+			text:    "Idiot. You idiot",
+			phrases: map[string][]string{"harassment": {"idiot"}},
+			want: []TextSpan{
+				{Category: "harassment", Start: 0, End: 5, Score: 0.8, Source: "llm"},
+				{Category: "harassment", Start: 11, End: 16, Score: 0.8, Source: "llm"},
+			},
+		},
+		{
+			name:    "categories in order",
+			text:    "you idiot",
+			phrases: map[string][]string{"toxicity": {"you idiot"}, "harassment": {"idiot"}},
+			want: []TextSpan{
+				{Category: "harassment", Start: 4, End: 9, Score: 0.8, Source: "llm"},
+				{Category: "toxicity", Start: 0, End: 9, Score: 0.6, Source: "llm"},
+			},
+		},
+		{
+			name:    "phrase not in text",
+			text:    "hello there",
+			phrases: map[string][]string{"harassment": {"idiot"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := PhraseSpans(tt.text, scores, &models.DecisionExplanation{Phrases: tt.phrases}, "llm")
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	if got := PhraseSpans("text", scores, nil, "llm"); got != nil {
+		t.Errorf("got %+v for no explanation, want none", got)
+	}
+}
+
+func TestChunkSpans(t *testing.T) {
+	text := "héllo wörld bad"
+	chunks := []Chunk{
+		{Index: 0, Start: 0, End: 11, Text: "héllo wörld"},
+		{Index: 1, Start: 6, End: 15, Text: "wörld bad"},
+	}
+	results := []*ClassificationResult{
+		nil,
+		{Spans: []TextSpan{{Category: "toxicity", Start: 7, End: 10, Score: 0.9}}},
+	}
+
+	spans := ChunkSpans(text, chunks, results)
+	if len(spans) != 1 {
+		t.Fatalf("got %d spans, want 1", len(spans))
+	}
+	if got := text[spans[0].Start:spans[0].End]; got != "bad" {
+		t.Errorf("span covers %q, want %q", got, "bad")
+	}
+}
+
+func TestConvertPerspectiveSpans(t *testing.T) {
+	text := "ça va, you idiot"
+	resp := &perspectiveResponse{AttributeScores: map[string]perspectiveAttribute{
+		"INSULT": {SpanScores: []perspectiveSpanScore{
+			{Begin: 0, End: 6, Score: perspectiveScore{Value: 0.1}},
+			{Begin: 7, End: 16, Score: perspectiveScore{Value: 0.9}},
+		}},
+		"SEVERE_TOXICITY": {SpanScores: []perspectiveSpanScore{
+			{Begin: 7, End: 16, Score: perspectiveScore{Value: 0.9}},
+		}},
+	}}
+
+	spans := convertPerspectiveSpans(text, resp)
+	if len(spans) != 1 {
+		t.Fatalf("got %d spans, want 1 (low scores and unmapped attributes dropped)", len(spans))
+	}
+	span := spans[0]
+	if span.Category != "harassment" || text[span.Start:span.End] != "you idiot" {
+		t.Errorf("got %s span %q, want harassment span %q", span.Category, text[span.Start:span.End], "you idiot")
+	}
+}
+
+func TestClassify_ReturnsProviderSpans(t *testing.T) {
+	o := newLanguageTestOrchestrator(&fakeSpanProvider{fakeProvider: fakeProvider{name: "spans"}, word: "idiot"})
+
+	result, err := o.Classify(context.Background(), "you idiot")
+	if err != nil {
+		t.Fatalf("classify failed: %v", err)
+	}
+	if len(result.Spans) != 1 || result.Spans[0].Start != 4 {
+		t.Errorf("got spans %+v, want one at offset 4", result.Spans)
+	}
+
+	// Spans of a translation do not point into the original text
+	o.SetTranslator(&fakeTranslator{translation: "you idiot"})
+	result, err = o.ClassifyWithLanguage(context.Background(), strings.Repeat("ばか", 3), []string{"ja"})
+	if err != nil {
+		t.Fatalf("classify failed: %v", err)
+	}
+	if !result.Translated || result.Spans != nil {
+		t.Errorf("got translated=%v spans=%+v, want translated without spans", result.Translated, result.Spans)
+	}
+}
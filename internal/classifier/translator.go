@@ -0,0 +1,32 @@
+package classifier
+
+import (
+	"context"
+)
+
+// PivotLanguage is the language content is translated into when no
+// LanguageAwareProvider supports its source language.
+const PivotLanguage = "en"
+
+// Translator translates content so it can be classified by providers that do
+// not support its source language.
+type Translator interface {
+	// Translate translates text from sourceLang into targetLang (ISO 639-1 codes).
+	Translate(ctx context.Context, text, sourceLang, targetLang string) (string, error)
+
+	// Name returns the translator's identifier, recorded with translated decisions.
+	Name() string
+}
+
+// PassthroughTranslator is a local stand-in Translator that returns text
+// unchanged. It lets the translation path, and the policy handling of
+// translated decisions, be exercised without a translation model.
+type PassthroughTranslator struct{}
+
+// Translate returns text unchanged.
+func (PassthroughTranslator) Translate(ctx context.Context, text, sourceLang, targetLang string) (string, error) {
+	return text, ctx.Err()
+}
+
+// Name returns "passthrough".
+func (PassthroughTranslator) Name() string { return "passthrough" }
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"go.uber.org/zap"
@@ -52,13 +53,45 @@ type Config struct {
 	PerspectiveAPIKey    string
 	OpenAIAPIKey         string
 	CalibrationConfigJSON string // JSON string for per-provider score calibration
+	CalibrationReloadInterval time.Duration // How often the active fitted calibration version is reloaded
 	EnsembleEnabled      bool   // Enable ensemble mode (parallel multi-provider)
-	EnsembleStrategy     string // Ensemble strategy: "average", "median", "max"
+	EnsembleStrategy     string // Ensemble strategy: "average", "median", "max", "weighted"
+	EnsembleWeightsRefreshInterval time.Duration // How often weighted-ensemble weights and thresholds are reloaded
+	EnsembleEarlyExitMargin float64 // Distance from every policy threshold required for ensemble early exit (0 = disabled)
+	ProviderRoutingJSON  string // JSON per-provider priority/weight overrides for traffic splitting
+	ProviderBreakerJSON  string // JSON per-provider circuit breaker settings
+	ClassifyLatencyBudget time.Duration // Total time budget across the provider fallback chain (0 = unlimited)
+	HedgingEnabled       bool          // Race the next provider when the serving one is slow
+	HedgingPercentile    int           // Latency percentile (1-99) after which a hedge request fires
+	HedgingInitialDelay  time.Duration // Hedge delay used until enough latency samples exist
 
 	// LLM Classification Provider (second-pass for ambiguous scores)
-	LLMProvider string // "anthropic" or "openai"
-	LLMAPIKey   string
-	LLMModel    string
+	LLMProvider       string // "anthropic", "openai" or "openai-compatible"
+	LLMAPIKey         string // Optional for openai-compatible servers
+	LLMModel          string
+	LLMBaseURL        string // API root override, e.g. a self-hosted vLLM or Ollama /v1 endpoint
+	LLMResponseFormat string // "auto", "json_schema", "json_object" or "none"
+
+	// Prompt-injection detection for content sent to the LLM
+	PromptInjectionModelCheck bool // Ask the LLM to check for injection alongside the heuristic detector
+
+	// Translation of languages no language-aware provider supports
+	Translator string // "llm", "passthrough" (local stand-in) or "" to classify untranslated
+
+	// Long-content chunking (windows classified separately, then aggregated)
+	ChunkMaxTokens     int    // Estimated tokens per window (0 = classify content whole)
+	ChunkOverlapTokens int    // Tokens shared between consecutive windows
+	ChunkAggregation   string // "max" or "topk_mean"
+	ChunkTopK          int    // Windows averaged per category by "topk_mean"
+	ChunkConcurrency   int    // Windows classified concurrently per request
+
+	// Conversation moderation
+	ConversationMaxContext int // Prior messages accepted as context by /moderate/conversation
+
+	// Lexicon Classification Provider (offline, in-process)
+	LexiconEnabled        bool
+	LexiconPriority       int           // Fallback priority (lower = tried first)
+	LexiconReloadInterval time.Duration // How often published lists are reloaded
 
 	// Data Retention
 	RetentionSubmissionDays int
@@ -113,13 +146,45 @@ func Load() (*Config, error) {
 		PerspectiveAPIKey:    getEnv("PERSPECTIVE_API_KEY", ""),
 		OpenAIAPIKey:         getEnv("OPENAI_API_KEY", ""),
 		CalibrationConfigJSON: getEnv("CALIBRATION_CONFIG_JSON", ""),
+		CalibrationReloadInterval: getEnvAsDuration("CALIBRATION_RELOAD_INTERVAL", time.Minute),
 		EnsembleEnabled:      getEnvAsBool("ENSEMBLE_ENABLED", false),
 		EnsembleStrategy:     getEnv("ENSEMBLE_STRATEGY", "average"),
+		EnsembleWeightsRefreshInterval: getEnvAsDuration("ENSEMBLE_WEIGHTS_REFRESH_INTERVAL", 5*time.Minute),
+		EnsembleEarlyExitMargin: getEnvAsFloat("ENSEMBLE_EARLY_EXIT_MARGIN", 0),
+		ProviderRoutingJSON:  getEnv("PROVIDER_ROUTING_JSON", ""),
+		ProviderBreakerJSON:  getEnv("PROVIDER_BREAKER_JSON", ""),
+		ClassifyLatencyBudget: getEnvAsDuration("CLASSIFY_LATENCY_BUDGET", 0),
+		HedgingEnabled:       getEnvAsBool("HEDGING_ENABLED", false),
+		HedgingPercentile:    getEnvAsInt("HEDGING_PERCENTILE", 95),
+		HedgingInitialDelay:  getEnvAsDuration("HEDGING_INITIAL_DELAY", 2*time.Second),
 
 		// LLM Classification Provider
-		LLMProvider: getEnv("LLM_PROVIDER", ""),
-		LLMAPIKey:   getEnv("LLM_API_KEY", ""),
-		LLMModel:    getEnv("LLM_MODEL", ""),
+		LLMProvider:       getEnv("LLM_PROVIDER", ""),
+		LLMAPIKey:         getEnv("LLM_API_KEY", ""),
+		LLMModel:          getEnv("LLM_MODEL", ""),
+		LLMBaseURL:        getEnv("LLM_BASE_URL", ""),
+		LLMResponseFormat: getEnv("LLM_RESPONSE_FORMAT", ""),
+
+		// Prompt-injection detection
+		PromptInjectionModelCheck: getEnvAsBool("PROMPT_INJECTION_MODEL_CHECK", true),
+
+		// Translation pivot
+		Translator: getEnv("TRANSLATOR", ""),
+
+		// Long-content chunking
+		ChunkMaxTokens:     getEnvAsInt("CHUNK_MAX_TOKENS", 400),
+		ChunkOverlapTokens: getEnvAsInt("CHUNK_OVERLAP_TOKENS", 64),
+		ChunkAggregation:   getEnv("CHUNK_AGGREGATION", "max"),
+		ChunkTopK:          getEnvAsInt("CHUNK_TOP_K", 2),
+		ChunkConcurrency:   getEnvAsInt("CHUNK_CONCURRENCY", 4),
+
+		// Conversation moderation
+		ConversationMaxContext: getEnvAsInt("CONVERSATION_MAX_CONTEXT", 10),
+
+		// Lexicon Classification Provider
+		LexiconEnabled:        getEnvAsBool("LEXICON_ENABLED", true),
+		LexiconPriority:       getEnvAsInt("LEXICON_PRIORITY", 99),
+		LexiconReloadInterval: getEnvAsDuration("LEXICON_RELOAD_INTERVAL", time.Minute),
 
 		// Security
 		AllowedOrigins:       getEnv("ALLOWED_ORIGINS", ""),
@@ -146,6 +211,15 @@ func Load() (*Config, error) {
 	return cfg, nil
 }
 
+// ModerationBaseURL returns the base URL of the moderation service. A full
+// URL (for Cloud Run) takes precedence over host:port.
+func (c *Config) ModerationBaseURL() string {
+	if c.ModerationURL != "" {
+		return strings.TrimRight(c.ModerationURL, "/")
+	}
+	return fmt.Sprintf("http://%s:%s", c.ModerationHost, c.ModerationPort)
+}
+
 // NewLogger creates a new zap logger based on configuration
 func (c *Config) NewLogger() (*zap.Logger, error) {
 	level := zapcore.InfoLevel
@@ -228,6 +302,20 @@ func getEnvAsInt32(key string, defaultValue int32) int32 {
 }
 
 // getEnvAsBool retrieves an environment variable as bool or returns a default value
+func getEnvAsFloat(key string, defaultValue float64) float64 {
+	valueStr := os.Getenv(key)
+	if valueStr == "" {
+		return defaultValue
+	}
+
+	value, err := strconv.ParseFloat(valueStr, 64)
+	if err != nil {
+		return defaultValue
+	}
+
+	return value
+}
+
 func getEnvAsBool(key string, defaultValue bool) bool {
 	valueStr := os.Getenv(key)
 	if valueStr == "" {
@@ -0,0 +1,3 @@
+-- Rollback Migration 018
+DROP TABLE IF EXISTS lexicon_entries;
+DROP TABLE IF EXISTS lexicon_lists;
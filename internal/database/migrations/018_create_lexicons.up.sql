@@ -0,0 +1,31 @@
+-- Migration 018: Versioned term and regex lists for the offline lexicon provider
+-- Control: MOD-005 (Multi-Provider Classification Orchestration)
+
+CREATE TABLE IF NOT EXISTS lexicon_lists (
+    id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name        VARCHAR(255) NOT NULL,
+    version     INTEGER NOT NULL DEFAULT 1,
+    category    VARCHAR(50) NOT NULL,
+    description TEXT,
+    status      policy_status NOT NULL DEFAULT 'draft',
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    created_by  UUID REFERENCES users(id),
+    UNIQUE(name, version)
+);
+
+CREATE TABLE IF NOT EXISTS lexicon_entries (
+    id         UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    list_id    UUID NOT NULL REFERENCES lexicon_lists(id) ON DELETE CASCADE,
+    pattern    TEXT NOT NULL,
+    match_type VARCHAR(10) NOT NULL DEFAULT 'term', -- 'term' or 'regex'
+    weight     DOUBLE PRECISION NOT NULL DEFAULT 0.5 CHECK (weight > 0 AND weight <= 1),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_lexicon_lists_status ON lexicon_lists (status);
+CREATE INDEX IF NOT EXISTS idx_lexicon_lists_name_version ON lexicon_lists (name, version);
+CREATE INDEX IF NOT EXISTS idx_lexicon_entries_list ON lexicon_entries (list_id);
+
+COMMENT ON TABLE lexicon_lists IS 'Versioned weighted term lists scored in-process by the lexicon classification provider';
+COMMENT ON COLUMN lexicon_lists.category IS 'CategoryScores field the list contributes to (e.g., profanity, hate)';
+COMMENT ON COLUMN lexicon_entries.weight IS 'Contribution of a single match to the category score (0-1, combined by noisy-OR)';
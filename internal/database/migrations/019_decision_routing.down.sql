@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_decisions_provider;
+
+ALTER TABLE moderation_decisions
+    DROP COLUMN IF EXISTS provider_name,
+    DROP COLUMN IF EXISTS routing_bucket;
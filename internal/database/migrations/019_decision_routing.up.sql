@@ -0,0 +1,11 @@
+-- Migration 019: Record provider routing on moderation decisions
+-- Control: MOD-005 (Multi-Provider Classification Orchestration)
+
+ALTER TABLE moderation_decisions
+    ADD COLUMN IF NOT EXISTS provider_name VARCHAR(50),
+    ADD COLUMN IF NOT EXISTS routing_bucket SMALLINT CHECK (routing_bucket >= 0 AND routing_bucket < 100);
+
+CREATE INDEX IF NOT EXISTS idx_decisions_provider ON moderation_decisions(provider_name, created_at DESC);
+
+COMMENT ON COLUMN moderation_decisions.provider_name IS 'Classification provider that produced the scores (NULL for cache hits)';
+COMMENT ON COLUMN moderation_decisions.routing_bucket IS 'Content-hash bucket (0-99) used for weighted traffic splitting';
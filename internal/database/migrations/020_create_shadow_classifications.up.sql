@@ -0,0 +1,24 @@
+-- Migration 020: Shadow provider classifications for side-by-side model evaluation
+-- Control: MOD-005 (Multi-Provider Classification Orchestration)
+--
+-- Shadow providers run alongside the serving provider but never influence the
+-- automated action. decision_id has no foreign key because moderation_decisions
+-- is partitioned (see migration 015).
+
+CREATE TABLE IF NOT EXISTS shadow_classifications (
+    id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    decision_id     UUID NOT NULL,
+    provider_name   VARCHAR(50) NOT NULL,
+    model_name      VARCHAR(255),
+    model_version   VARCHAR(50),
+    category_scores JSONB,
+    latency_ms      INTEGER NOT NULL DEFAULT 0,
+    error           TEXT,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_shadow_decision ON shadow_classifications(decision_id);
+CREATE INDEX IF NOT EXISTS idx_shadow_provider_created ON shadow_classifications(provider_name, created_at DESC);
+
+COMMENT ON TABLE shadow_classifications IS 'Scores from shadow providers, recorded for offline comparison against live decisions';
+COMMENT ON COLUMN shadow_classifications.error IS 'Provider error message when the shadow call failed (category_scores is NULL)';
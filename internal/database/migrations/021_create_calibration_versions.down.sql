@@ -0,0 +1,5 @@
+ALTER TABLE moderation_decisions
+    DROP COLUMN IF EXISTS calibration_version,
+    DROP COLUMN IF EXISTS raw_category_scores;
+
+DROP TABLE IF EXISTS calibration_versions;
@@ -0,0 +1,29 @@
+-- Migration 021: Versioned score calibrations fitted from human review outcomes
+-- Control: MOD-005 (Multi-Provider Classification Orchestration)
+--
+-- Each row holds per-provider, per-category Platt or isotonic parameters fitted
+-- from calibration_data. At most one version is active; the moderation service
+-- loads it at startup and on reload.
+
+CREATE TABLE IF NOT EXISTS calibration_versions (
+    id           UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    version      INTEGER NOT NULL UNIQUE,
+    method       VARCHAR(20) NOT NULL CHECK (method IN ('platt', 'isotonic')),
+    params       JSONB NOT NULL,
+    sample_count INTEGER NOT NULL DEFAULT 0,
+    window_days  INTEGER NOT NULL,
+    active       BOOLEAN NOT NULL DEFAULT FALSE,
+    created_by   UUID REFERENCES users(id),
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    activated_at TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_calibration_versions_active ON calibration_versions (active) WHERE active;
+
+ALTER TABLE moderation_decisions
+    ADD COLUMN IF NOT EXISTS calibration_version INTEGER,
+    ADD COLUMN IF NOT EXISTS raw_category_scores JSONB;
+
+COMMENT ON TABLE calibration_versions IS 'Score calibrations fitted from reviewed decisions; params map provider -> category -> parameters';
+COMMENT ON COLUMN moderation_decisions.calibration_version IS 'Fitted calibration version applied to category_scores (NULL when none)';
+COMMENT ON COLUMN moderation_decisions.raw_category_scores IS 'Provider scores before calibration, used to refit calibrations';
@@ -0,0 +1,2 @@
+ALTER TABLE moderation_decisions
+    DROP COLUMN IF EXISTS explanation_details;
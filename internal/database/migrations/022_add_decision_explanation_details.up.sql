@@ -0,0 +1,11 @@
+-- Migration 022: Structured LLM explanations on moderation decisions
+-- Control: MOD-001 (Decision tracking and traceability)
+--
+-- explanation keeps the human-readable summary; explanation_details holds the
+-- rationale and the offending phrases per triggered category so reviewers can
+-- see why content was escalated.
+
+ALTER TABLE moderation_decisions
+    ADD COLUMN IF NOT EXISTS explanation_details JSONB;
+
+COMMENT ON COLUMN moderation_decisions.explanation_details IS 'LLM rationale and offending phrases per triggered category';
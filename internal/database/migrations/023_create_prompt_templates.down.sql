@@ -0,0 +1,5 @@
+ALTER TABLE evidence_records
+    DROP COLUMN IF EXISTS prompt_template_id,
+    DROP COLUMN IF EXISTS prompt_template_version;
+
+DROP TABLE IF EXISTS prompt_templates;
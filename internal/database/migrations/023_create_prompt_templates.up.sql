@@ -0,0 +1,31 @@
+-- Migration 023: Versioned LLM prompt templates per policy
+-- Control: POL-001 (Policy management)
+--
+-- The LLM second pass renders the published template of the policy being
+-- applied. Evidence records which template version the model received.
+
+CREATE TABLE IF NOT EXISTS prompt_templates (
+    id                   UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    policy_id            UUID NOT NULL REFERENCES policies(id) ON DELETE CASCADE,
+    version              INTEGER NOT NULL,
+    template             TEXT NOT NULL,
+    guidelines           TEXT NOT NULL DEFAULT '',
+    category_definitions JSONB NOT NULL DEFAULT '{}',
+    examples             JSONB NOT NULL DEFAULT '[]',
+    status               policy_status NOT NULL DEFAULT 'draft',
+    created_at           TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    created_by           UUID REFERENCES users(id),
+    UNIQUE(policy_id, version)
+);
+
+CREATE INDEX IF NOT EXISTS idx_prompt_templates_policy_status ON prompt_templates (policy_id, status);
+
+-- At most one published template per policy, even under concurrent publishes
+CREATE UNIQUE INDEX IF NOT EXISTS idx_prompt_templates_one_published ON prompt_templates (policy_id) WHERE status = 'published';
+
+ALTER TABLE evidence_records
+    ADD COLUMN IF NOT EXISTS prompt_template_id UUID,
+    ADD COLUMN IF NOT EXISTS prompt_template_version INTEGER;
+
+COMMENT ON TABLE prompt_templates IS 'LLM classification prompts per policy with {{guidelines}}, {{categories}}, {{examples}} and {{content}} placeholders';
+COMMENT ON COLUMN evidence_records.prompt_template_version IS 'Prompt template version sent to the LLM second pass (NULL when it did not run)';
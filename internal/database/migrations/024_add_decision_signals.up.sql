@@ -0,0 +1,11 @@
+-- Migration 024: Detector signals on moderation decisions
+-- Control: MOD-001 (Decision tracking and traceability)
+--
+-- signals holds scores from detectors that sit outside the category scores,
+-- such as prompt_injection for content that tries to manipulate the LLM
+-- classifier, so reviewers can see why a decision was escalated.
+
+ALTER TABLE moderation_decisions
+    ADD COLUMN IF NOT EXISTS signals JSONB;
+
+COMMENT ON COLUMN moderation_decisions.signals IS 'Detector signal scores (e.g. prompt_injection), 0.0-1.0';
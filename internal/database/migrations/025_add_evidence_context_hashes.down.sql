@@ -0,0 +1,2 @@
+ALTER TABLE evidence_records
+    DROP COLUMN IF EXISTS context_hashes;
@@ -0,0 +1,12 @@
+-- Migration 025: Conversation context on evidence records
+-- Control: AUD-001 (Immutable evidence generation and audit trail)
+--
+-- Messages moderated through /moderate/conversation are classified with the
+-- prior messages in their thread. The hashes of those messages are kept so
+-- auditors can tie a decision to the exact context it was made in without
+-- storing the context itself.
+
+ALTER TABLE evidence_records
+    ADD COLUMN IF NOT EXISTS context_hashes TEXT[];
+
+COMMENT ON COLUMN evidence_records.context_hashes IS 'SHA-256 hashes of prior conversation messages used as classification context, oldest first';
@@ -0,0 +1,3 @@
+ALTER TABLE moderation_decisions
+    DROP COLUMN IF EXISTS source_language,
+    DROP COLUMN IF EXISTS translated;
@@ -0,0 +1,14 @@
+-- Migration 026: Translation flag on moderation decisions
+-- Control: MOD-001 (Decision tracking and traceability)
+--
+-- Content in a language no language-aware provider supports is translated to
+-- English before classification. translated marks those decisions, so policy
+-- and reviewers can treat their less reliable scores differently, and
+-- source_language records the language the content was translated from.
+
+ALTER TABLE moderation_decisions
+    ADD COLUMN IF NOT EXISTS translated BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS source_language VARCHAR(10);
+
+COMMENT ON COLUMN moderation_decisions.translated IS 'Scores are for a machine translation of the content';
+COMMENT ON COLUMN moderation_decisions.source_language IS 'ISO 639-1 language the content was translated from';
@@ -0,0 +1,6 @@
+ALTER TABLE evidence_records
+    DROP COLUMN IF EXISTS normalization_profile;
+
+ALTER TABLE policies
+    DROP COLUMN IF EXISTS normalization_profiles,
+    DROP COLUMN IF EXISTS normalization_profile;
@@ -0,0 +1,19 @@
+-- Migration 027: Normalization profiles
+-- Control: POL-001 (Policy management and versioning)
+--
+-- Text is normalized before hashing and classification, and communities need
+-- different aggressiveness: leetspeak decoding mangles code, while children's
+-- platforms want every lookalike folded. A policy names the profile its
+-- traffic is normalized with and may define its own. Evidence records the
+-- profile used, since the same text normalizes differently under each.
+
+ALTER TABLE policies
+    ADD COLUMN IF NOT EXISTS normalization_profile VARCHAR(64),
+    ADD COLUMN IF NOT EXISTS normalization_profiles JSONB;
+
+ALTER TABLE evidence_records
+    ADD COLUMN IF NOT EXISTS normalization_profile VARCHAR(100);
+
+COMMENT ON COLUMN policies.normalization_profile IS 'Default normalization profile for content moderated under this policy; NULL selects standard';
+COMMENT ON COLUMN policies.normalization_profiles IS 'Custom normalization profiles defined by this policy';
+COMMENT ON COLUMN evidence_records.normalization_profile IS 'Key of the normalization profile the content was normalized with';
@@ -0,0 +1,13 @@
+-- Migration 028: Expression rules on policies
+-- Control: POL-001 (Deterministic policy evaluation)
+--
+-- Thresholds compare one category score at a time. Rules are boolean
+-- expressions over all scores, detector signals, request context, trust score
+-- and detected language, e.g. hate > 0.6 && context.audience == "kids",
+-- evaluated in order after the thresholds. Expressions are validated when the
+-- policy is created.
+
+ALTER TABLE policies
+    ADD COLUMN IF NOT EXISTS rules JSONB;
+
+COMMENT ON COLUMN policies.rules IS 'Ordered expression rules: id, expression, action and optional final flag';
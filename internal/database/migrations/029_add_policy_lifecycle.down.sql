@@ -0,0 +1,10 @@
+ALTER TABLE evidence_records
+    DROP COLUMN IF EXISTS actor_id,
+    DROP COLUMN IF EXISTS policy_status,
+    DROP COLUMN IF EXISTS policy_transition;
+
+DROP INDEX IF EXISTS idx_policies_name_status;
+
+ALTER TABLE policies
+    DROP COLUMN IF EXISTS published_by,
+    DROP COLUMN IF EXISTS published_at;
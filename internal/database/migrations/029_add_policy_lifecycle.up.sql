@@ -0,0 +1,32 @@
+-- Migration 029: Policy lifecycle transitions
+-- Control: POL-001 (Policy definition, versioning, and lifecycle management)
+--
+-- Policies are published, archived and rolled back through the policy-engine.
+-- published_at and published_by record a policy's last publish, so a rollback
+-- only restores versions that were once in force. Each transition writes a
+-- POL-001 evidence record naming the operation, the resulting status and the
+-- actor.
+
+ALTER TABLE policies
+    ADD COLUMN IF NOT EXISTS published_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS published_by UUID REFERENCES users(id);
+
+-- Published versions in force before this migration count as published
+UPDATE policies
+SET published_at = COALESCE(effective_date, created_at)
+WHERE status = 'published' AND published_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_policies_name_status ON policies(name, status);
+
+COMMENT ON COLUMN policies.effective_date IS 'When a published policy takes effect; a future date schedules the publish';
+COMMENT ON COLUMN policies.published_at IS 'When the policy was last published';
+COMMENT ON COLUMN policies.published_by IS 'User who last published the policy';
+
+ALTER TABLE evidence_records
+    ADD COLUMN IF NOT EXISTS policy_transition VARCHAR(20),
+    ADD COLUMN IF NOT EXISTS policy_status VARCHAR(20),
+    ADD COLUMN IF NOT EXISTS actor_id UUID;
+
+COMMENT ON COLUMN evidence_records.policy_transition IS 'Policy lifecycle operation: publish, archive or rollback';
+COMMENT ON COLUMN evidence_records.policy_status IS 'Status the policy was left in by the transition';
+COMMENT ON COLUMN evidence_records.actor_id IS 'User who performed the policy transition';
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS policy_simulation_flips;
+DROP TABLE IF EXISTS policy_simulations;
@@ -0,0 +1,53 @@
+-- Migration 030: Policy simulations
+-- Control: POL-001 (Policy definition and versioning)
+--
+-- A simulation replays the category scores and context of historical
+-- moderation decisions through a policy, usually a draft, before it is
+-- published. The summary holds the action distributions and agreement with
+-- human reviews; the decisions whose action would flip are kept for paging
+-- and export. decision_id has no foreign key because moderation_decisions is
+-- partitioned (see migration 015).
+--
+-- A simulation runs in the background of the policy engine that started it,
+-- which records a heartbeat while it runs. A running simulation past its
+-- deadline or without a recent heartbeat is failed as abandoned.
+
+CREATE TABLE IF NOT EXISTS policy_simulations (
+    id             UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    policy_id      UUID NOT NULL REFERENCES policies(id),
+    policy_version INTEGER NOT NULL,
+    range_start    TIMESTAMPTZ NOT NULL,
+    range_end      TIMESTAMPTZ NOT NULL,
+    decision_limit INTEGER NOT NULL,
+    status         VARCHAR(20) NOT NULL DEFAULT 'running',
+    summary        JSONB,
+    error          TEXT,
+    created_by     UUID REFERENCES users(id),
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    completed_at   TIMESTAMPTZ,
+    deadline_at    TIMESTAMPTZ NOT NULL,
+    heartbeat_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_policy_simulations_policy ON policy_simulations(policy_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_policy_simulations_running ON policy_simulations(heartbeat_at) WHERE status = 'running';
+
+CREATE TABLE IF NOT EXISTS policy_simulation_flips (
+    simulation_id    UUID NOT NULL REFERENCES policy_simulations(id) ON DELETE CASCADE,
+    decision_id      UUID NOT NULL,
+    decided_at       TIMESTAMPTZ NOT NULL,
+    original_action  VARCHAR(50) NOT NULL,
+    simulated_action VARCHAR(50) NOT NULL,
+    triggered_rules  TEXT[],
+    review_action    VARCHAR(20),
+    PRIMARY KEY (simulation_id, decision_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_policy_simulation_flips_order ON policy_simulation_flips(simulation_id, decided_at);
+
+COMMENT ON TABLE policy_simulations IS 'Backtests of policies against historical moderation decisions';
+COMMENT ON COLUMN policy_simulations.deadline_at IS 'Time after which a running simulation is failed';
+COMMENT ON COLUMN policy_simulations.heartbeat_at IS 'Last time the running simulation recorded it was alive';
+COMMENT ON COLUMN policy_simulations.summary IS 'Original and simulated action distributions, flip counts and agreement with human reviews';
+COMMENT ON TABLE policy_simulation_flips IS 'Historical decisions whose action the simulated policy changes';
+COMMENT ON COLUMN policy_simulation_flips.review_action IS 'Latest human review action on the decision, if reviewed';
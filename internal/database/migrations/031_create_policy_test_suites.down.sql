@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS policy_test_runs;
+DROP TABLE IF EXISTS policy_test_cases;
@@ -0,0 +1,43 @@
+-- Migration 031: Policy test suites
+-- Control: POL-001 (Policy definition and versioning)
+--
+-- Golden test cases are attached to a policy by name, so every new version is
+-- tested against the known examples of the versions before it. A case is
+-- either raw content, which is classified, or fixed category scores, with the
+-- action the policy must take. The suite runs on demand and before a publish,
+-- which it blocks if any case fails; every run is kept.
+
+CREATE TABLE IF NOT EXISTS policy_test_cases (
+    id               UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    policy_name      VARCHAR(255) NOT NULL,
+    name             VARCHAR(255) NOT NULL,
+    content          TEXT,
+    scores           JSONB,
+    context_metadata JSONB,
+    expected_action  VARCHAR(50) NOT NULL,
+    created_by       UUID REFERENCES users(id),
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (policy_name, name),
+    CONSTRAINT chk_policy_test_case_input CHECK ((content IS NULL) <> (scores IS NULL))
+);
+
+CREATE TABLE IF NOT EXISTS policy_test_runs (
+    id             UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    policy_id      UUID NOT NULL REFERENCES policies(id),
+    policy_version INTEGER NOT NULL,
+    trigger        VARCHAR(20) NOT NULL,
+    passed         BOOLEAN NOT NULL,
+    total          INTEGER NOT NULL,
+    failed         INTEGER NOT NULL,
+    results        JSONB NOT NULL,
+    created_by     UUID REFERENCES users(id),
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_policy_test_runs_policy ON policy_test_runs(policy_id, created_at DESC);
+
+COMMENT ON TABLE policy_test_cases IS 'Golden examples every version of the named policy must handle with the expected action';
+COMMENT ON COLUMN policy_test_cases.content IS 'Raw text to classify; NULL when the case has fixed scores';
+COMMENT ON COLUMN policy_test_cases.scores IS 'Fixed category scores; NULL when the case has raw content';
+COMMENT ON TABLE policy_test_runs IS 'Runs of policy test suites, on demand or before a publish';
+COMMENT ON COLUMN policy_test_runs.results IS 'Per-case expected and actual actions';
@@ -0,0 +1 @@
+UPDATE policies SET scope = scope - 'default' WHERE scope ? 'default';
@@ -0,0 +1,19 @@
+-- Migration 032: Explicit default policy
+-- Control: POL-003 (Regional Policy Resolution)
+--
+-- Requests are routed to a policy by the routes in its scope, and those no
+-- route matches get the policy whose scope has "default": true. The default
+-- used to be whichever policy was published last; that version alone is
+-- marked as the default so resolution is unchanged until routes are added.
+-- Its drafts and archived versions are left unmarked, so publishing one later
+-- does not carry a default flag nobody chose.
+
+UPDATE policies
+SET scope = COALESCE(scope, '{}'::jsonb) || '{"default": true}'::jsonb
+WHERE id = (
+    SELECT id
+    FROM policies
+    WHERE status = 'published' AND (effective_date IS NULL OR effective_date <= NOW())
+    ORDER BY COALESCE(effective_date, created_at) DESC, created_at DESC
+    LIMIT 1
+);
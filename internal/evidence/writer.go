@@ -6,6 +6,7 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
@@ -40,9 +41,12 @@ func (w *Writer) WriteEvidenceInTx(ctx context.Context, tx pgx.Tx, evidence *mod
 		INSERT INTO evidence_records (
 			id, control_id, policy_id, policy_version, decision_id, review_id,
 			model_name, model_version, category_scores, automated_action,
-			human_override, submission_hash, immutable, chain_hash, previous_hash
+			human_override, submission_hash, immutable, chain_hash, previous_hash,
+			prompt_template_id, prompt_template_version, context_hashes,
+			normalization_profile, policy_transition, policy_status, actor_id
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19,
+			$20, $21, $22
 		)
 	`
 
@@ -62,6 +66,13 @@ func (w *Writer) WriteEvidenceInTx(ctx context.Context, tx pgx.Tx, evidence *mod
 		evidence.Immutable,
 		evidence.ChainHash,
 		evidence.PreviousHash,
+		evidence.PromptTemplateID,
+		evidence.PromptTemplateVersion,
+		evidence.ContextHashes,
+		evidence.NormalizationProfile,
+		evidence.PolicyTransition,
+		evidence.PolicyStatus,
+		evidence.ActorID,
 	)
 
 	if err != nil {
@@ -137,6 +148,24 @@ func (w *Writer) RecordPolicyApplication(ctx context.Context, policy *models.Pol
 	return w.writeEvidence(ctx, evidence)
 }
 
+// RecordPolicyTransition creates an evidence record for a policy lifecycle
+// transition within the transaction making it, with the status it left the
+// policy in and the acting user
+func (w *Writer) RecordPolicyTransition(ctx context.Context, tx pgx.Tx, policy *models.Policy, transition models.PolicyTransition, actorID uuid.UUID) error {
+	evidence := &models.EvidenceRecord{
+		ID:               uuid.New(),
+		ControlID:        "POL-001",
+		PolicyID:         &policy.ID,
+		PolicyVersion:    &policy.Version,
+		PolicyTransition: &transition,
+		PolicyStatus:     &policy.Status,
+		ActorID:          &actorID,
+		Immutable:        true,
+	}
+
+	return w.WriteEvidenceInTx(ctx, tx, evidence)
+}
+
 // computeChainHash builds the hash chain by fetching the latest chain_hash
 // and computing SHA-256(previous_hash + record fields).
 func (w *Writer) computeChainHash(ctx context.Context, evidence *models.EvidenceRecord) {
@@ -168,6 +197,24 @@ func (w *Writer) computeChainHash(ctx context.Context, evidence *models.Evidence
 	if evidence.SubmissionHash != nil {
 		data += "|" + *evidence.SubmissionHash
 	}
+	if evidence.PromptTemplateID != nil && evidence.PromptTemplateVersion != nil {
+		data += fmt.Sprintf("|prompt:%s:%d", evidence.PromptTemplateID.String(), *evidence.PromptTemplateVersion)
+	}
+	if len(evidence.ContextHashes) > 0 {
+		data += "|context:" + strings.Join(evidence.ContextHashes, ",")
+	}
+	if evidence.NormalizationProfile != nil {
+		data += "|normalization:" + *evidence.NormalizationProfile
+	}
+	if evidence.PolicyTransition != nil && evidence.PolicyStatus != nil {
+		data += fmt.Sprintf("|policy:%s:%s", *evidence.PolicyTransition, *evidence.PolicyStatus)
+		if evidence.PolicyID != nil && evidence.PolicyVersion != nil {
+			data += fmt.Sprintf(":%s:%d", evidence.PolicyID.String(), *evidence.PolicyVersion)
+		}
+	}
+	if evidence.ActorID != nil {
+		data += "|actor:" + evidence.ActorID.String()
+	}
 
 	h := sha256.Sum256([]byte(data))
 	chainHash := hex.EncodeToString(h[:])
@@ -183,9 +230,12 @@ func (w *Writer) writeEvidence(ctx context.Context, evidence *models.EvidenceRec
 		INSERT INTO evidence_records (
 			id, control_id, policy_id, policy_version, decision_id, review_id,
 			model_name, model_version, category_scores, automated_action,
-			human_override, submission_hash, immutable, chain_hash, previous_hash
+			human_override, submission_hash, immutable, chain_hash, previous_hash,
+			prompt_template_id, prompt_template_version, context_hashes,
+			normalization_profile, policy_transition, policy_status, actor_id
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19,
+			$20, $21, $22
 		)
 	`
 
@@ -205,6 +255,13 @@ func (w *Writer) writeEvidence(ctx context.Context, evidence *models.EvidenceRec
 		evidence.Immutable,
 		evidence.ChainHash,
 		evidence.PreviousHash,
+		evidence.PromptTemplateID,
+		evidence.PromptTemplateVersion,
+		evidence.ContextHashes,
+		evidence.NormalizationProfile,
+		evidence.PolicyTransition,
+		evidence.PolicyStatus,
+		evidence.ActorID,
 	)
 
 	if err != nil {
@@ -229,7 +286,9 @@ func (w *Writer) ListEvidence(ctx context.Context, controlID *string, limit int,
 	query := `
 		SELECT id, control_id, policy_id, policy_version, decision_id, review_id,
 		       model_name, model_version, category_scores, automated_action,
-		       human_override, submission_hash, immutable, chain_hash, previous_hash, created_at
+		       human_override, submission_hash, immutable, chain_hash, previous_hash, created_at,
+		       prompt_template_id, prompt_template_version, context_hashes,
+		       normalization_profile, policy_transition, policy_status, actor_id
 		FROM evidence_records
 		WHERE ($1::text IS NULL OR control_id = $1)
 		ORDER BY created_at DESC
@@ -262,6 +321,13 @@ func (w *Writer) ListEvidence(ctx context.Context, controlID *string, limit int,
 			&record.ChainHash,
 			&record.PreviousHash,
 			&record.CreatedAt,
+			&record.PromptTemplateID,
+			&record.PromptTemplateVersion,
+			&record.ContextHashes,
+			&record.NormalizationProfile,
+			&record.PolicyTransition,
+			&record.PolicyStatus,
+			&record.ActorID,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan evidence record: %w", err)
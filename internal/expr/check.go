@@ -0,0 +1,221 @@
+package expr
+
+// functions are the global functions and their result types.
+var functions = map[string]Type{
+	"has":  Bool,
+	"size": Number,
+}
+
+// methods are the string methods, their argument counts and result types.
+var methods = map[string]struct {
+	args   int
+	result Type
+}{
+	"contains":   {1, Bool},
+	"startsWith": {1, Bool},
+	"endsWith":   {1, Bool},
+	"lower":      {0, String},
+}
+
+// check returns the static type of n, or an error if n cannot be evaluated
+// in env.
+func check(n node, env Env) (Type, error) {
+	switch n := n.(type) {
+	case *literalNode:
+		return typeOfLiteral(n.value), nil
+
+	case *identNode:
+		t, ok := env[n.name]
+		if !ok {
+			return 0, errorf(n.pos, "undeclared reference to %q", n.name)
+		}
+		return t, nil
+
+	case *memberNode:
+		t, err := check(n.operand, env)
+		if err != nil {
+			return 0, err
+		}
+		if t != Map && t != Dyn {
+			return 0, errorf(n.pos, "type %s has no field %q", t, n.field)
+		}
+		return Dyn, nil
+
+	case *indexNode:
+		t, err := check(n.operand, env)
+		if err != nil {
+			return 0, err
+		}
+		key, err := check(n.key, env)
+		if err != nil {
+			return 0, err
+		}
+		switch t {
+		case Map:
+			if !is(key, String) {
+				return 0, errorf(n.key.position(), "map key must be a string, got %s", key)
+			}
+		case List:
+			if !is(key, Number) {
+				return 0, errorf(n.key.position(), "list index must be a number, got %s", key)
+			}
+		case Dyn:
+		default:
+			return 0, errorf(n.pos, "type %s cannot be indexed", t)
+		}
+		return Dyn, nil
+
+	case *unaryNode:
+		t, err := check(n.operand, env)
+		if err != nil {
+			return 0, err
+		}
+		want := Bool
+		if n.op == "-" {
+			want = Number
+		}
+		if !is(t, want) {
+			return 0, errorf(n.pos, "operator %s needs a %s, got %s", n.op, want, t)
+		}
+		return want, nil
+
+	case *binaryNode:
+		return checkBinary(n, env)
+
+	case *listNode:
+		for _, item := range n.items {
+			if _, err := check(item, env); err != nil {
+				return 0, err
+			}
+		}
+		return List, nil
+
+	case *callNode:
+		return checkCall(n, env)
+	}
+	return 0, errorf(n.position(), "unsupported expression")
+}
+
+func checkBinary(n *binaryNode, env Env) (Type, error) {
+	left, err := check(n.left, env)
+	if err != nil {
+		return 0, err
+	}
+	right, err := check(n.right, env)
+	if err != nil {
+		return 0, err
+	}
+
+	mismatch := func() (Type, error) {
+		return 0, errorf(n.pos, "operator %s cannot be applied to %s and %s", n.op, left, right)
+	}
+	switch n.op {
+	case "&&", "||":
+		if !is(left, Bool) || !is(right, Bool) {
+			return mismatch()
+		}
+		return Bool, nil
+	case "==", "!=":
+		if left != right && left != Dyn && right != Dyn && left != Null && right != Null {
+			return mismatch()
+		}
+		return Bool, nil
+	case "<", "<=", ">", ">=":
+		if !(is(left, Number) && is(right, Number)) && !(is(left, String) && is(right, String)) {
+			return mismatch()
+		}
+		return Bool, nil
+	case "in":
+		if !is(right, List) && !is(right, Map) {
+			return mismatch()
+		}
+		return Bool, nil
+	case "+":
+		for _, t := range []Type{Number, String, List} {
+			if is(left, t) && is(right, t) {
+				if left == Dyn && right == Dyn {
+					return Dyn, nil
+				}
+				return t, nil
+			}
+		}
+		return mismatch()
+	default: // - * / %
+		if !is(left, Number) || !is(right, Number) {
+			return mismatch()
+		}
+		return Number, nil
+	}
+}
+
+func checkCall(n *callNode, env Env) (Type, error) {
+	if n.target == nil {
+		result, ok := functions[n.fn]
+		if !ok {
+			return 0, errorf(n.pos, "undeclared function %q", n.fn)
+		}
+		if len(n.args) != 1 {
+			return 0, errorf(n.pos, "%s takes 1 argument, got %d", n.fn, len(n.args))
+		}
+		t, err := check(n.args[0], env)
+		if err != nil {
+			return 0, err
+		}
+		switch n.fn {
+		case "has":
+			switch n.args[0].(type) {
+			case *memberNode, *indexNode:
+			default:
+				return 0, errorf(n.pos, "has needs a field, e.g. has(context.audience)")
+			}
+		case "size":
+			if !is(t, String) && !is(t, List) && !is(t, Map) {
+				return 0, errorf(n.pos, "size cannot be applied to %s", t)
+			}
+		}
+		return result, nil
+	}
+
+	m, ok := methods[n.fn]
+	if !ok {
+		return 0, errorf(n.pos, "undeclared method %q", n.fn)
+	}
+	if len(n.args) != m.args {
+		return 0, errorf(n.pos, "%s takes %d arguments, got %d", n.fn, m.args, len(n.args))
+	}
+	t, err := check(n.target, env)
+	if err != nil {
+		return 0, err
+	}
+	if !is(t, String) {
+		return 0, errorf(n.pos, "%s cannot be applied to %s", n.fn, t)
+	}
+	for _, arg := range n.args {
+		t, err := check(arg, env)
+		if err != nil {
+			return 0, err
+		}
+		if !is(t, String) {
+			return 0, errorf(arg.position(), "%s needs a string argument, got %s", n.fn, t)
+		}
+	}
+	return m.result, nil
+}
+
+// is reports whether a value of static type t may be a want at evaluation.
+func is(t, want Type) bool {
+	return t == want || t == Dyn
+}
+
+func typeOfLiteral(v interface{}) Type {
+	switch v.(type) {
+	case bool:
+		return Bool
+	case float64:
+		return Number
+	case string:
+		return String
+	default:
+		return Null
+	}
+}
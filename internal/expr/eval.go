@@ -0,0 +1,431 @@
+package expr
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"unicode/utf8"
+)
+
+// eval evaluates n. Values are nil, bool, float64, string, []interface{} and
+// map[string]interface{}.
+func eval(n node, vars map[string]interface{}) (interface{}, error) {
+	switch n := n.(type) {
+	case *literalNode:
+		return n.value, nil
+
+	case *identNode:
+		return normalize(vars[n.name]), nil
+
+	case *memberNode:
+		v, err := eval(n.operand, vars)
+		if err != nil {
+			return nil, err
+		}
+		return field(v, n.field, n.pos)
+
+	case *indexNode:
+		v, err := eval(n.operand, vars)
+		if err != nil {
+			return nil, err
+		}
+		key, err := eval(n.key, vars)
+		if err != nil {
+			return nil, err
+		}
+		return index(v, key, n.pos)
+
+	case *unaryNode:
+		v, err := eval(n.operand, vars)
+		if err != nil {
+			return nil, err
+		}
+		switch v := v.(type) {
+		case bool:
+			if n.op == "!" {
+				return !v, nil
+			}
+		case float64:
+			if n.op == "-" {
+				return -v, nil
+			}
+		}
+		return nil, errorf(n.pos, "operator %s cannot be applied to %s", n.op, typeOf(v))
+
+	case *binaryNode:
+		if n.op == "&&" || n.op == "||" {
+			return evalLogical(n, vars)
+		}
+		left, err := eval(n.left, vars)
+		if err != nil {
+			return nil, err
+		}
+		right, err := eval(n.right, vars)
+		if err != nil {
+			return nil, err
+		}
+		return evalBinary(n, left, right)
+
+	case *listNode:
+		items := make([]interface{}, len(n.items))
+		for i, item := range n.items {
+			v, err := eval(item, vars)
+			if err != nil {
+				return nil, err
+			}
+			items[i] = v
+		}
+		return items, nil
+
+	case *callNode:
+		return evalCall(n, vars)
+	}
+	return nil, errorf(n.position(), "unsupported expression")
+}
+
+// evalLogical evaluates && and ||. Like CEL, an error or non-bool on one side
+// is absorbed when the other side decides the result: false && error is false.
+func evalLogical(n *binaryNode, vars map[string]interface{}) (interface{}, error) {
+	decisive := n.op == "||" // the value that decides the result on its own
+	var firstErr error
+	for _, side := range []node{n.left, n.right} {
+		v, err := eval(side, vars)
+		if err == nil {
+			b, ok := v.(bool)
+			if ok && b == decisive {
+				return decisive, nil
+			}
+			if !ok {
+				err = errorf(side.position(), "operator %s needs a bool, got %s", n.op, typeOf(v))
+			}
+		}
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return !decisive, nil
+}
+
+func evalBinary(n *binaryNode, left, right interface{}) (interface{}, error) {
+	mismatch := func() (interface{}, error) {
+		return nil, errorf(n.pos, "operator %s cannot be applied to %s and %s", n.op, typeOf(left), typeOf(right))
+	}
+	switch n.op {
+	case "==":
+		return equal(left, right), nil
+	case "!=":
+		return !equal(left, right), nil
+
+	case "<", "<=", ">", ">=":
+		// Missing values never satisfy an ordering
+		if left == nil || right == nil {
+			return false, nil
+		}
+		var cmp int
+		switch l := left.(type) {
+		case float64:
+			r, ok := right.(float64)
+			if !ok {
+				return mismatch()
+			}
+			cmp = compare(l, r)
+		case string:
+			r, ok := right.(string)
+			if !ok {
+				return mismatch()
+			}
+			cmp = strings.Compare(l, r)
+		default:
+			return mismatch()
+		}
+		switch n.op {
+		case "<":
+			return cmp < 0, nil
+		case "<=":
+			return cmp <= 0, nil
+		case ">":
+			return cmp > 0, nil
+		default:
+			return cmp >= 0, nil
+		}
+
+	case "in":
+		switch r := right.(type) {
+		case nil:
+			return false, nil
+		case []interface{}:
+			for _, item := range r {
+				if equal(left, normalize(item)) {
+					return true, nil
+				}
+			}
+			return false, nil
+		case map[string]interface{}:
+			key, ok := left.(string)
+			if !ok {
+				return mismatch()
+			}
+			_, found := r[key]
+			return found, nil
+		}
+		return mismatch()
+
+	case "+":
+		switch l := left.(type) {
+		case float64:
+			if r, ok := right.(float64); ok {
+				return l + r, nil
+			}
+		case string:
+			if r, ok := right.(string); ok {
+				return l + r, nil
+			}
+		case []interface{}:
+			if r, ok := right.([]interface{}); ok {
+				return append(append([]interface{}{}, l...), r...), nil
+			}
+		}
+		return mismatch()
+	}
+
+	// - * / %
+	l, lok := left.(float64)
+	r, rok := right.(float64)
+	if !lok || !rok {
+		return mismatch()
+	}
+	switch n.op {
+	case "-":
+		return l - r, nil
+	case "*":
+		return l * r, nil
+	case "/":
+		if r == 0 {
+			return nil, errorf(n.pos, "division by zero")
+		}
+		return l / r, nil
+	default:
+		if r == 0 {
+			return nil, errorf(n.pos, "modulus by zero")
+		}
+		return math.Mod(l, r), nil
+	}
+}
+
+func evalCall(n *callNode, vars map[string]interface{}) (interface{}, error) {
+	if n.fn == "has" {
+		// has tests presence without evaluating the missing field
+		var operand, key interface{}
+		var err error
+		switch arg := n.args[0].(type) {
+		case *memberNode:
+			operand, err = eval(arg.operand, vars)
+			key = arg.field
+		case *indexNode:
+			operand, err = eval(arg.operand, vars)
+			if err == nil {
+				key, err = eval(arg.key, vars)
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+		v, err := index(operand, key, n.pos)
+		if err != nil {
+			return false, nil
+		}
+		return v != nil, nil
+	}
+
+	var target interface{}
+	if n.target != nil {
+		var err error
+		if target, err = eval(n.target, vars); err != nil {
+			return nil, err
+		}
+	}
+	args := make([]interface{}, len(n.args))
+	for i, arg := range n.args {
+		v, err := eval(arg, vars)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = v
+	}
+
+	if n.fn == "size" {
+		switch v := args[0].(type) {
+		case string:
+			return float64(utf8.RuneCountInString(v)), nil
+		case []interface{}:
+			return float64(len(v)), nil
+		case map[string]interface{}:
+			return float64(len(v)), nil
+		}
+		return nil, errorf(n.pos, "size cannot be applied to %s", typeOf(args[0]))
+	}
+
+	// String methods; a method on a missing value is false (or null for lower)
+	if target == nil {
+		if n.fn == "lower" {
+			return nil, nil
+		}
+		return false, nil
+	}
+	s, ok := target.(string)
+	if !ok {
+		return nil, errorf(n.pos, "%s cannot be applied to %s", n.fn, typeOf(target))
+	}
+	if n.fn == "lower" {
+		return strings.ToLower(s), nil
+	}
+	arg, ok := args[0].(string)
+	if !ok {
+		return nil, errorf(n.pos, "%s needs a string argument, got %s", n.fn, typeOf(args[0]))
+	}
+	switch n.fn {
+	case "contains":
+		return strings.Contains(s, arg), nil
+	case "startsWith":
+		return strings.HasPrefix(s, arg), nil
+	default:
+		return strings.HasSuffix(s, arg), nil
+	}
+}
+
+// field returns v.name. Fields of a missing value are missing too, so
+// context.a.b is null rather than an error when context.a is not set.
+func field(v interface{}, name string, pos int) (interface{}, error) {
+	switch v := v.(type) {
+	case nil:
+		return nil, nil
+	case map[string]interface{}:
+		return normalize(v[name]), nil
+	}
+	return nil, errorf(pos, "type %s has no field %q", typeOf(v), name)
+}
+
+func index(v, key interface{}, pos int) (interface{}, error) {
+	switch v := v.(type) {
+	case nil:
+		return nil, nil
+	case map[string]interface{}:
+		k, ok := key.(string)
+		if !ok {
+			return nil, errorf(pos, "map key must be a string, got %s", typeOf(key))
+		}
+		return normalize(v[k]), nil
+	case []interface{}:
+		i, ok := key.(float64)
+		if !ok || i != math.Trunc(i) {
+			return nil, errorf(pos, "list index must be an integer, got %v", key)
+		}
+		// Compared as floats, since a large index overflows int
+		if i < 0 || i >= float64(len(v)) {
+			return nil, errorf(pos, "list index %v out of range", i)
+		}
+		return normalize(v[int(i)]), nil
+	}
+	return nil, errorf(pos, "type %s cannot be indexed", typeOf(v))
+}
+
+// equal compares values by value. Values of different types are unequal.
+func equal(a, b interface{}) bool {
+	switch a := a.(type) {
+	case nil:
+		return b == nil
+	case []interface{}:
+		bl, ok := b.([]interface{})
+		if !ok || len(a) != len(bl) {
+			return false
+		}
+		for i := range a {
+			if !equal(normalize(a[i]), normalize(bl[i])) {
+				return false
+			}
+		}
+		return true
+	case map[string]interface{}:
+		bm, ok := b.(map[string]interface{})
+		if !ok || len(a) != len(bm) {
+			return false
+		}
+		for k, v := range a {
+			if !equal(normalize(v), normalize(bm[k])) {
+				return false
+			}
+		}
+		return true
+	}
+	return a == b
+}
+
+func compare(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// normalize converts a Go value to an expression value: integers to float64
+// and typed maps and slices to their generic forms.
+func normalize(v interface{}) interface{} {
+	switch v := v.(type) {
+	case int:
+		return float64(v)
+	case int32:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case float32:
+		return float64(v)
+	case *float64:
+		if v == nil {
+			return nil
+		}
+		return *v
+	case []string:
+		items := make([]interface{}, len(v))
+		for i, s := range v {
+			items[i] = s
+		}
+		return items
+	case map[string]float64:
+		m := make(map[string]interface{}, len(v))
+		for k, f := range v {
+			m[k] = f
+		}
+		return m
+	case map[string]string:
+		m := make(map[string]interface{}, len(v))
+		for k, s := range v {
+			m[k] = s
+		}
+		return m
+	}
+	return v
+}
+
+func typeOf(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return Bool.String()
+	case float64:
+		return Number.String()
+	case string:
+		return String.String()
+	case []interface{}:
+		return List.String()
+	case map[string]interface{}:
+		return Map.String()
+	}
+	return fmt.Sprintf("%T", v)
+}
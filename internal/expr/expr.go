@@ -0,0 +1,119 @@
+// Package expr implements the expression language of policy rules: a small,
+// CEL-like language of boolean conditions over scores and request context,
+// such as `hate > 0.6 && context.audience == "kids"`.
+//
+// Expressions are type-checked when compiled against an Env declaring the
+// variables they may reference, so a typo in a rule is rejected when the
+// policy is created instead of silently never matching.
+//
+// The language supports:
+//
+//   - literals: numbers, "strings" or 'strings', true, false, null and [lists]
+//   - operators, loosest first: ||, &&, comparisons (== != < <= > >= in),
+//     addition and subtraction, * / %, and unary ! and -
+//   - field access on maps (context.audience) and indexing (context["age-band"], list[0])
+//   - has(context.audience), size(x), and the string methods contains,
+//     startsWith, endsWith and lower
+//
+// Missing map keys evaluate to null. Ordering comparisons involving null are
+// false, so `trust_score < 0.3` does not match when no trust score is known.
+// As in CEL, && and || absorb errors when the other side decides the result.
+package expr
+
+import (
+	"fmt"
+)
+
+// Type is the static type of an expression.
+type Type int
+
+const (
+	Dyn    Type = iota // known only at evaluation, e.g. a context value
+	Bool               // true or false
+	Number             // float64
+	String             // string
+	List               // list of Dyn
+	Map                // map from string to Dyn
+	Null               // the null literal
+)
+
+func (t Type) String() string {
+	switch t {
+	case Bool:
+		return "bool"
+	case Number:
+		return "number"
+	case String:
+		return "string"
+	case List:
+		return "list"
+	case Map:
+		return "map"
+	case Null:
+		return "null"
+	default:
+		return "dyn"
+	}
+}
+
+// Env declares the variables an expression may reference and their types.
+type Env map[string]Type
+
+// Error is a syntax or type error at a byte offset in the expression.
+type Error struct {
+	Pos int
+	Msg string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("position %d: %s", e.Pos+1, e.Msg)
+}
+
+func errorf(pos int, format string, args ...interface{}) *Error {
+	return &Error{Pos: pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+// Program is a compiled expression.
+type Program struct {
+	source string
+	root   node
+}
+
+// Compile parses and type-checks an expression, which must be boolean.
+func Compile(source string, env Env) (*Program, error) {
+	root, err := parse(source)
+	if err != nil {
+		return nil, err
+	}
+	t, err := check(root, env)
+	if err != nil {
+		return nil, err
+	}
+	if t != Bool && t != Dyn {
+		return nil, errorf(0, "expression must be a bool, got %s", t)
+	}
+	return &Program{source: source, root: root}, nil
+}
+
+// Eval evaluates the program with the given variable values. Numbers may be
+// any Go integer or float type; maps and lists are converted recursively.
+// An expression evaluating to null is false.
+func (p *Program) Eval(vars map[string]interface{}) (bool, error) {
+	v, err := eval(p.root, vars)
+	if err != nil {
+		return false, err
+	}
+	switch v := v.(type) {
+	case bool:
+		return v, nil
+	case nil:
+		return false, nil
+	default:
+		return false, fmt.Errorf("expression evaluated to %s, not a bool", typeOf(v))
+	}
+}
+
+// String returns the source of the program.
+func (p *Program) String() string {
+	return p.source
+}
@@ -0,0 +1,167 @@
+package expr
+
+import (
+	"strings"
+	"testing"
+)
+
+var testEnv = Env{
+	"hate":        Number,
+	"toxicity":    Number,
+	"spam":        Number,
+	"trust_score": Number,
+	"language":    String,
+	"languages":   List,
+	"translated":  Bool,
+	"context":     Map,
+}
+
+func TestEval(t *testing.T) {
+	vars := map[string]interface{}{
+		"hate":        0.7,
+		"toxicity":    0.5,
+		"spam":        0.45,
+		"trust_score": nil,
+		"language":    "es",
+		"languages":   []string{"es", "en"},
+		"translated":  false,
+		"context": map[string]interface{}{
+			"audience": "kids",
+			"age":      12,
+			"platform": "Mobile-iOS",
+			"tags":     []interface{}{"new", "chat"},
+			"age-band": "under-13",
+		},
+	}
+
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{`hate > 0.6 && context.audience == "kids"`, true},
+		{`toxicity > 0.4 && spam > 0.4`, true},
+		{`toxicity > 0.4 && spam > 0.5`, false},
+		{`hate > 0.9 || language == "es"`, true},
+		{`!(hate > 0.6)`, false},
+		{`hate + toxicity >= 1.2`, true},
+		{`hate * 2 > 1 && -hate < 0`, true},
+		{`context.age < 13`, true},
+		{`context.age == 12`, true},
+		{`context["age-band"] == "under-13"`, true},
+		{`language in ["es", "pt"]`, true},
+		{`"en" in languages`, true},
+		{`"chat" in context.tags`, true},
+		{`"audience" in context`, true},
+		{`has(context.audience)`, true},
+		{`has(context.region)`, false},
+		{`has(context.region.country)`, false},
+		{`context.region == null`, true},
+		{`context.region.country == "US"`, false},
+		{`context.platform.lower().startsWith("mobile")`, true},
+		{`context.platform.contains("iOS") && context.platform.endsWith("iOS")`, true},
+		{`size(context.tags) == 2 && size(language) == 2`, true},
+		{`trust_score < 0.3`, false},
+		{`trust_score >= 0.3`, false},
+		{`context.missing.startsWith("x")`, false},
+		{`translated`, false},
+		{`context.unset`, false},
+		{`'single' + "double" == "singledouble"`, true},
+		{`10 % 3 == 1 && 1.5e1 == 15`, true},
+		// Errors on one side are absorbed when the other decides
+		{`hate > 0.9 && context.audience > 5`, false},
+		{`hate > 0.6 || context.audience > 5`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			p, err := Compile(tt.expr, testEnv)
+			if err != nil {
+				t.Fatalf("compile failed: %v", err)
+			}
+			got, err := p.Eval(vars)
+			if err != nil {
+				t.Fatalf("eval failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEval_RuntimeErrors(t *testing.T) {
+	vars := map[string]interface{}{
+		"hate":      0.7,
+		"languages": []interface{}{"en", "fr"},
+		"context":   map[string]interface{}{"audience": "kids", "age": 12.0},
+	}
+
+	tests := []string{
+		`languages[2] == "en"`,
+		`languages[1e20] == "en"`,
+		`context.audience > 5`,
+		`context.age.startsWith("1")`,
+		`hate / 0 > 1`,
+		`context.audience.x == 1`,
+		`hate > 0.6 && context.audience > 5`,
+	}
+
+	for _, src := range tests {
+		t.Run(src, func(t *testing.T) {
+			p, err := Compile(src, testEnv)
+			if err != nil {
+				t.Fatalf("compile failed: %v", err)
+			}
+			if _, err := p.Eval(vars); err == nil {
+				t.Error("got no error, want a runtime error")
+			}
+		})
+	}
+}
+
+func TestCompile_Errors(t *testing.T) {
+	tests := []struct {
+		expr      string
+		wantError string
+	}{
+		{``, "empty expression"},
+		{`hate >`, "unexpected end of expression"},
+		{`hate > 0.6 &&`, "unexpected end of expression"},
+		{`(hate > 0.6`, `expected ")"`},
+		{`hate > 0.6)`, `unexpected ")"`},
+		{`hat > 0.6`, `undeclared reference to "hat"`},
+		{`hate > "high"`, "cannot be applied to number and string"},
+		{`hate == "high"`, "cannot be applied to number and string"},
+		{`hate && spam`, "cannot be applied to number and number"},
+		{`hate + 1`, "must be a bool, got number"},
+		{`language.code == "es"`, `type string has no field "code"`},
+		{`matches(language)`, `undeclared function "matches"`},
+		{`language.matches("e")`, `undeclared method "matches"`},
+		{`has(language)`, "has needs a field"},
+		{`hate.startsWith("x")`, "startsWith cannot be applied to number"},
+		{`context.a == "unterminated`, "unterminated string"},
+		{`hate > 0.6 $ spam`, "unexpected character"},
+		{`!hate`, "operator ! needs a bool"},
+		{strings.Repeat("(", 100) + "true" + strings.Repeat(")", 100), "nested too deeply"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			_, err := Compile(tt.expr, testEnv)
+			if err == nil || !strings.Contains(err.Error(), tt.wantError) {
+				t.Errorf("got error %v, want %q", err, tt.wantError)
+			}
+		})
+	}
+}
+
+func TestCompile_ErrorPosition(t *testing.T) {
+	_, err := Compile(`hate > 0.6 && hat > 0.2`, testEnv)
+	e, ok := err.(*Error)
+	if !ok {
+		t.Fatalf("got %T, want *Error", err)
+	}
+	if e.Pos != 14 {
+		t.Errorf("got position %d, want 14", e.Pos)
+	}
+}
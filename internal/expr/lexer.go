@@ -0,0 +1,143 @@
+package expr
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokNumber
+	tokString
+	tokIdent
+	tokOp
+)
+
+type token struct {
+	kind  tokenKind
+	text  string      // identifier or operator
+	value interface{} // number or string literal
+	pos   int
+}
+
+// operators lists multi-character operators before their prefixes.
+var operators = []string{"||", "&&", "==", "!=", "<=", ">=", "<", ">", "!", "+", "-", "*", "/", "%", "(", ")", "[", "]", ".", ","}
+
+// lex splits source into tokens, ending with tokEOF.
+func lex(source string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(source); {
+		r, size := utf8.DecodeRuneInString(source[i:])
+		switch {
+		case unicode.IsSpace(r):
+			i += size
+		case r == '"' || r == '\'':
+			s, n, err := lexString(source, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: tokString, value: s, pos: i})
+			i += n
+		case isDigit(r) || r == '.' && i+1 < len(source) && isDigit(rune(source[i+1])):
+			n := lexNumber(source[i:])
+			f, err := strconv.ParseFloat(source[i:i+n], 64)
+			if err != nil {
+				return nil, errorf(i, "invalid number %q", source[i:i+n])
+			}
+			tokens = append(tokens, token{kind: tokNumber, value: f, pos: i})
+			i += n
+		case r == '_' || unicode.IsLetter(r):
+			j := i
+			for j < len(source) {
+				r, size := utf8.DecodeRuneInString(source[j:])
+				if r != '_' && !unicode.IsLetter(r) && !isDigit(r) {
+					break
+				}
+				j += size
+			}
+			tokens = append(tokens, token{kind: tokIdent, text: source[i:j], pos: i})
+			i = j
+		default:
+			op := ""
+			for _, candidate := range operators {
+				if strings.HasPrefix(source[i:], candidate) {
+					op = candidate
+					break
+				}
+			}
+			if op == "" {
+				return nil, errorf(i, "unexpected character %q", r)
+			}
+			tokens = append(tokens, token{kind: tokOp, text: op, pos: i})
+			i += len(op)
+		}
+	}
+	return append(tokens, token{kind: tokEOF, pos: len(source)}), nil
+}
+
+// lexString reads the quoted string at source[start:], returning its value
+// and length including the quotes.
+func lexString(source string, start int) (string, int, error) {
+	quote := source[start]
+	var b strings.Builder
+	for i := start + 1; i < len(source); i++ {
+		c := source[i]
+		switch {
+		case c == quote:
+			return b.String(), i + 1 - start, nil
+		case c == '\\':
+			i++
+			if i == len(source) {
+				break
+			}
+			switch source[i] {
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			case '\\', '"', '\'':
+				b.WriteByte(source[i])
+			default:
+				return "", 0, errorf(i-1, "invalid escape \\%c", source[i])
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", 0, errorf(start, "unterminated string")
+}
+
+// lexNumber returns the length of the number at the start of s.
+func lexNumber(s string) int {
+	i := 0
+	for i < len(s) && isDigit(rune(s[i])) {
+		i++
+	}
+	if i < len(s) && s[i] == '.' {
+		i++
+		for i < len(s) && isDigit(rune(s[i])) {
+			i++
+		}
+	}
+	if i < len(s) && (s[i] == 'e' || s[i] == 'E') {
+		j := i + 1
+		if j < len(s) && (s[j] == '+' || s[j] == '-') {
+			j++
+		}
+		if j < len(s) && isDigit(rune(s[j])) {
+			i = j
+			for i < len(s) && isDigit(rune(s[i])) {
+				i++
+			}
+		}
+	}
+	return i
+}
+
+func isDigit(r rune) bool {
+	return '0' <= r && r <= '9'
+}
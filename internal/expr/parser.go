@@ -0,0 +1,306 @@
+package expr
+
+// node is a node of the expression syntax tree.
+type node interface {
+	position() int
+}
+
+type literalNode struct {
+	pos   int
+	value interface{} // nil, bool, float64 or string
+}
+
+type identNode struct {
+	pos  int
+	name string
+}
+
+// memberNode is field access, operand.field.
+type memberNode struct {
+	pos     int
+	operand node
+	field   string
+}
+
+// indexNode is operand[key].
+type indexNode struct {
+	pos     int
+	operand node
+	key     node
+}
+
+type unaryNode struct {
+	pos     int
+	op      string
+	operand node
+}
+
+type binaryNode struct {
+	pos         int
+	op          string
+	left, right node
+}
+
+// callNode is a function call, fn(args), or a method call, target.fn(args).
+type callNode struct {
+	pos    int
+	target node // nil for functions
+	fn     string
+	args   []node
+}
+
+type listNode struct {
+	pos   int
+	items []node
+}
+
+func (n *literalNode) position() int { return n.pos }
+func (n *identNode) position() int   { return n.pos }
+func (n *memberNode) position() int  { return n.pos }
+func (n *indexNode) position() int   { return n.pos }
+func (n *unaryNode) position() int   { return n.pos }
+func (n *binaryNode) position() int  { return n.pos }
+func (n *callNode) position() int    { return n.pos }
+func (n *listNode) position() int    { return n.pos }
+
+// maxDepth bounds nesting, so a hostile rule cannot exhaust the stack.
+const maxDepth = 64
+
+// parser is a recursive descent parser over the tokens of one expression.
+type parser struct {
+	tokens []token
+	next   int
+	depth  int
+}
+
+func parse(source string) (node, error) {
+	tokens, err := lex(source)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	if p.peek().kind == tokEOF {
+		return nil, errorf(0, "empty expression")
+	}
+	n, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, errorf(t.pos, "unexpected %s", describe(t))
+	}
+	return n, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.next]
+}
+
+// accept consumes the next token if it is the operator or keyword s.
+func (p *parser) accept(s string) (token, bool) {
+	t := p.peek()
+	if (t.kind == tokOp || t.kind == tokIdent) && t.text == s {
+		p.next++
+		return t, true
+	}
+	return t, false
+}
+
+func (p *parser) expect(s string) error {
+	if t, ok := p.accept(s); !ok {
+		return errorf(t.pos, "expected %q, got %s", s, describe(t))
+	}
+	return nil
+}
+
+// parseBinary parses a left-associative chain of the operators ops.
+func (p *parser) parseBinary(operand func() (node, error), ops ...string) (node, error) {
+	left, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		var t token
+		matched := false
+		for _, op := range ops {
+			if t, matched = p.accept(op); matched {
+				break
+			}
+		}
+		if !matched {
+			return left, nil
+		}
+		right, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{pos: t.pos, op: t.text, left: left, right: right}
+	}
+}
+
+func (p *parser) parseOr() (node, error) {
+	p.depth++
+	defer func() { p.depth-- }()
+	if p.depth > maxDepth {
+		return nil, errorf(p.peek().pos, "expression nested too deeply")
+	}
+	return p.parseBinary(p.parseAnd, "||")
+}
+
+func (p *parser) parseAnd() (node, error) {
+	return p.parseBinary(p.parseRelation, "&&")
+}
+
+func (p *parser) parseRelation() (node, error) {
+	return p.parseBinary(p.parseAddition, "==", "!=", "<=", ">=", "<", ">", "in")
+}
+
+func (p *parser) parseAddition() (node, error) {
+	return p.parseBinary(p.parseMultiplication, "+", "-")
+}
+
+func (p *parser) parseMultiplication() (node, error) {
+	return p.parseBinary(p.parseUnary, "*", "/", "%")
+}
+
+func (p *parser) parseUnary() (node, error) {
+	for _, op := range []string{"!", "-"} {
+		if t, ok := p.accept(op); ok {
+			p.depth++
+			defer func() { p.depth-- }()
+			if p.depth > maxDepth {
+				return nil, errorf(t.pos, "expression nested too deeply")
+			}
+			operand, err := p.parseUnary()
+			if err != nil {
+				return nil, err
+			}
+			return &unaryNode{pos: t.pos, op: op, operand: operand}, nil
+		}
+	}
+	return p.parsePostfix()
+}
+
+func (p *parser) parsePostfix() (node, error) {
+	n, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if t, ok := p.accept("."); ok {
+			name := p.peek()
+			if name.kind != tokIdent {
+				return nil, errorf(name.pos, "expected field name, got %s", describe(name))
+			}
+			p.next++
+			if _, ok := p.accept("("); ok {
+				args, err := p.parseArgs(")")
+				if err != nil {
+					return nil, err
+				}
+				n = &callNode{pos: name.pos, target: n, fn: name.text, args: args}
+				continue
+			}
+			n = &memberNode{pos: t.pos, operand: n, field: name.text}
+			continue
+		}
+		if t, ok := p.accept("["); ok {
+			key, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect("]"); err != nil {
+				return nil, err
+			}
+			n = &indexNode{pos: t.pos, operand: n, key: key}
+			continue
+		}
+		return n, nil
+	}
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	t := p.peek()
+	switch t.kind {
+	case tokNumber, tokString:
+		p.next++
+		return &literalNode{pos: t.pos, value: t.value}, nil
+	case tokIdent:
+		p.next++
+		switch t.text {
+		case "true":
+			return &literalNode{pos: t.pos, value: true}, nil
+		case "false":
+			return &literalNode{pos: t.pos, value: false}, nil
+		case "null":
+			return &literalNode{pos: t.pos, value: nil}, nil
+		case "in":
+			return nil, errorf(t.pos, "unexpected %s", describe(t))
+		}
+		if _, ok := p.accept("("); ok {
+			args, err := p.parseArgs(")")
+			if err != nil {
+				return nil, err
+			}
+			return &callNode{pos: t.pos, fn: t.text, args: args}, nil
+		}
+		return &identNode{pos: t.pos, name: t.text}, nil
+	case tokOp:
+		switch t.text {
+		case "(":
+			p.next++
+			n, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(")"); err != nil {
+				return nil, err
+			}
+			return n, nil
+		case "[":
+			p.next++
+			items, err := p.parseArgs("]")
+			if err != nil {
+				return nil, err
+			}
+			return &listNode{pos: t.pos, items: items}, nil
+		}
+	}
+	return nil, errorf(t.pos, "unexpected %s", describe(t))
+}
+
+// parseArgs parses a comma-separated list of expressions up to the closing
+// token, which it consumes.
+func (p *parser) parseArgs(closing string) ([]node, error) {
+	var args []node
+	if _, ok := p.accept(closing); ok {
+		return args, nil
+	}
+	for {
+		arg, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+		if _, ok := p.accept(","); ok {
+			continue
+		}
+		if err := p.expect(closing); err != nil {
+			return nil, err
+		}
+		return args, nil
+	}
+}
+
+func describe(t token) string {
+	switch t.kind {
+	case tokEOF:
+		return "end of expression"
+	case tokNumber:
+		return "number"
+	case tokString:
+		return "string"
+	default:
+		return "\"" + t.text + "\""
+	}
+}
@@ -29,7 +29,13 @@ func NewTracker(db *pgxpool.Pool, logger *zap.Logger) *Tracker {
 // reject → disagree (model was wrong)
 // escalate → uncertain (needs more review)
 func (t *Tracker) RecordFeedback(ctx context.Context, decision *models.ModerationDecision, reviewAction models.ReviewActionType) {
-	outcome := mapReviewToOutcome(reviewAction)
+	outcome := ReviewOutcome(reviewAction)
+
+	// Attribute feedback to the provider that served the decision; older decisions only carry the model name.
+	providerName := decision.ModelName
+	if decision.ProviderName != nil && *decision.ProviderName != "" {
+		providerName = *decision.ProviderName
+	}
 
 	query := `
 		INSERT INTO calibration_data (id, provider_name, decision_id, category_scores, automated_action, review_outcome, created_at)
@@ -38,7 +44,7 @@ func (t *Tracker) RecordFeedback(ctx context.Context, decision *models.Moderatio
 
 	_, err := t.db.Exec(ctx, query,
 		uuid.New(),
-		decision.ModelName,
+		providerName,
 		decision.ID,
 		decision.CategoryScores,
 		decision.AutomatedAction,
@@ -100,7 +106,9 @@ func (t *Tracker) GetProviderAccuracy(ctx context.Context) ([]ProviderAccuracy,
 	return results, nil
 }
 
-func mapReviewToOutcome(action models.ReviewActionType) string {
+// ReviewOutcome maps a review action to whether the reviewer agreed with the
+// automated action: "agree", "disagree" or "uncertain".
+func ReviewOutcome(action models.ReviewActionType) string {
 	switch action {
 	case models.ReviewActionApprove, models.ReviewActionEdit:
 		return "agree"
@@ -0,0 +1,177 @@
+package feedback
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/proth1/text-moderator/internal/models"
+)
+
+// flagThreshold is the score at which a provider is considered to have flagged a category.
+const flagThreshold = 0.5
+
+// weightPrior is the number of pseudo-observations pulling a weight towards
+// neutralWeight, so providers with little review history are not over- or under-trusted.
+const weightPrior = 20
+
+// neutralWeight is the weight assumed before any review feedback exists.
+const neutralWeight = 0.5
+
+// minWeight keeps a poorly performing provider from being silenced entirely.
+const minWeight = 0.05
+
+// CategoryAccuracy holds how often a provider's flags for a category were
+// confirmed as harmful by human reviewers.
+type CategoryAccuracy struct {
+	ProviderName string  `json:"provider_name"`
+	Category     string  `json:"category"`
+	Flagged      int     `json:"flagged"`
+	Confirmed    int     `json:"confirmed"`
+	Precision    float64 `json:"precision"`
+}
+
+// feedbackSample is a single reviewed decision from calibration_data.
+type feedbackSample struct {
+	provider string
+	scores   models.CategoryScores
+	action   models.PolicyAction
+	outcome  string
+}
+
+// GetCategoryAccuracy computes per-provider, per-category precision from the
+// last 30 days of calibration data.
+func (t *Tracker) GetCategoryAccuracy(ctx context.Context) ([]CategoryAccuracy, error) {
+	query := `
+		SELECT provider_name, category_scores, automated_action, review_outcome
+		FROM calibration_data
+		WHERE created_at >= NOW() - INTERVAL '30 days'
+		  AND review_outcome IN ('agree', 'disagree')
+	`
+
+	rows, err := t.db.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query calibration data: %w", err)
+	}
+	defer rows.Close()
+
+	var samples []feedbackSample
+	for rows.Next() {
+		var s feedbackSample
+		if err := rows.Scan(&s.provider, &s.scores, &s.action, &s.outcome); err != nil {
+			return nil, fmt.Errorf("failed to scan calibration data: %w", err)
+		}
+		samples = append(samples, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read calibration data: %w", err)
+	}
+
+	return aggregateCategoryAccuracy(samples), nil
+}
+
+// EnsembleWeights returns per-provider, per-category weights for the weighted
+// ensemble strategy, derived from review feedback.
+func (t *Tracker) EnsembleWeights(ctx context.Context) (map[string]map[string]float64, error) {
+	accuracy, err := t.GetProviderAccuracy(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get provider accuracy: %w", err)
+	}
+	categories, err := t.GetCategoryAccuracy(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return buildWeights(accuracy, categories), nil
+}
+
+// aggregateCategoryAccuracy counts, per provider and category, how many flagged
+// decisions reviewers judged harmful.
+func aggregateCategoryAccuracy(samples []feedbackSample) []CategoryAccuracy {
+	type key struct{ provider, category string }
+	counts := make(map[key]*CategoryAccuracy)
+
+	for _, s := range samples {
+		harmful := IsHarmful(s.action, s.outcome)
+		for cat, score := range s.scores.ToMap() {
+			if score < flagThreshold {
+				continue
+			}
+			k := key{s.provider, cat}
+			ca, ok := counts[k]
+			if !ok {
+				ca = &CategoryAccuracy{ProviderName: s.provider, Category: cat}
+				counts[k] = ca
+			}
+			ca.Flagged++
+			if harmful {
+				ca.Confirmed++
+			}
+		}
+	}
+
+	results := make([]CategoryAccuracy, 0, len(counts))
+	for _, ca := range counts {
+		ca.Precision = float64(ca.Confirmed) / float64(ca.Flagged)
+		results = append(results, *ca)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].ProviderName != results[j].ProviderName {
+			return results[i].ProviderName < results[j].ProviderName
+		}
+		return results[i].Category < results[j].Category
+	})
+	return results
+}
+
+// buildWeights blends category precision with overall provider accuracy. Each
+// rate is shrunk towards neutralWeight by weightPrior pseudo-observations; a
+// category without flagged samples falls back to the provider's overall weight.
+func buildWeights(accuracy []ProviderAccuracy, categories []CategoryAccuracy) map[string]map[string]float64 {
+	weights := make(map[string]map[string]float64)
+
+	for _, pa := range accuracy {
+		conclusive := pa.AgreeCount + pa.DisagreeCount
+		rate := 0.0
+		if conclusive > 0 {
+			rate = float64(pa.AgreeCount) / float64(conclusive)
+		}
+		base := shrink(rate, conclusive)
+		cats := make(map[string]float64, len(models.Categories))
+		for _, cat := range models.Categories {
+			cats[cat] = base
+		}
+		weights[pa.ProviderName] = cats
+	}
+
+	for _, ca := range categories {
+		cats, ok := weights[ca.ProviderName]
+		if !ok {
+			cats = make(map[string]float64, len(models.Categories))
+			for _, cat := range models.Categories {
+				cats[cat] = neutralWeight
+			}
+			weights[ca.ProviderName] = cats
+		}
+		cats[ca.Category] = shrink(ca.Precision, ca.Flagged)
+	}
+
+	return weights
+}
+
+// shrink pulls rate towards neutralWeight in proportion to how few samples back it.
+func shrink(rate float64, n int) float64 {
+	w := (rate*float64(n) + neutralWeight*weightPrior) / float64(n+weightPrior)
+	if w < minWeight {
+		w = minWeight
+	}
+	return w
+}
+
+// IsHarmful derives the reviewer's verdict on the content from the automated
+// action and review outcome ("agree" or "disagree").
+func IsHarmful(action models.PolicyAction, outcome string) bool {
+	if outcome == "agree" {
+		return action != models.ActionAllow
+	}
+	return action == models.ActionAllow
+}
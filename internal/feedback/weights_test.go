@@ -0,0 +1,91 @@
+package feedback
+
+import (
+	"math"
+	"testing"
+
+	"github.com/proth1/text-moderator/internal/models"
+)
+
+func TestIsHarmful(t *testing.T) {
+	tests := []struct {
+		action  models.PolicyAction
+		outcome string
+		want    bool
+	}{
+		{models.ActionBlock, "agree", true},
+		{models.ActionBlock, "disagree", false},
+		{models.ActionAllow, "agree", false},
+		{models.ActionAllow, "disagree", true},
+		{models.ActionWarn, "agree", true},
+	}
+
+	for _, tt := range tests {
+		if got := IsHarmful(tt.action, tt.outcome); got != tt.want {
+			t.Errorf("IsHarmful(%s, %s) = %v, want %v", tt.action, tt.outcome, got, tt.want)
+		}
+	}
+}
+
+func TestAggregateCategoryAccuracy(t *testing.T) {
+	samples := []feedbackSample{
+		{provider: "openai", scores: models.CategoryScores{Toxicity: 0.9}, action: models.ActionBlock, outcome: "agree"},
+		{provider: "openai", scores: models.CategoryScores{Toxicity: 0.7}, action: models.ActionBlock, outcome: "disagree"},
+		{provider: "openai", scores: models.CategoryScores{Toxicity: 0.6, Spam: 0.8}, action: models.ActionBlock, outcome: "agree"},
+		{provider: "openai", scores: models.CategoryScores{Toxicity: 0.1}, action: models.ActionAllow, outcome: "agree"},
+	}
+
+	got := aggregateCategoryAccuracy(samples)
+	if len(got) != 2 {
+		t.Fatalf("got %d entries, want 2: %+v", len(got), got)
+	}
+	spam, tox := got[0], got[1]
+	if spam.Category != "spam" || spam.Flagged != 1 || spam.Confirmed != 1 {
+		t.Errorf("spam: got %+v", spam)
+	}
+	if tox.Category != "toxicity" || tox.Flagged != 3 || tox.Confirmed != 2 {
+		t.Errorf("toxicity: got %+v", tox)
+	}
+	if math.Abs(tox.Precision-2.0/3.0) > 0.001 {
+		t.Errorf("toxicity precision = %f, want 0.667", tox.Precision)
+	}
+}
+
+func TestBuildWeights(t *testing.T) {
+	accuracy := []ProviderAccuracy{
+		{ProviderName: "good", AgreeCount: 180, DisagreeCount: 20, UncertainCount: 50},
+		{ProviderName: "new"},
+	}
+	categories := []CategoryAccuracy{
+		{ProviderName: "good", Category: "spam", Flagged: 80, Confirmed: 20, Precision: 0.25},
+		{ProviderName: "lexicon", Category: "profanity", Flagged: 20, Confirmed: 20, Precision: 1.0},
+	}
+
+	weights := buildWeights(accuracy, categories)
+
+	tests := []struct {
+		provider, category string
+		want               float64
+	}{
+		// (0.9*200 + 0.5*20) / 220
+		{"good", "toxicity", 190.0 / 220.0},
+		// (0.25*80 + 0.5*20) / 100
+		{"good", "spam", 0.3},
+		// no feedback at all stays neutral
+		{"new", "hate", 0.5},
+		// category-only provider: (1.0*20 + 0.5*20) / 40
+		{"lexicon", "profanity", 0.75},
+		{"lexicon", "toxicity", 0.5},
+	}
+	for _, tt := range tests {
+		if got := weights[tt.provider][tt.category]; math.Abs(got-tt.want) > 0.001 {
+			t.Errorf("weight[%s][%s] = %f, want %f", tt.provider, tt.category, got, tt.want)
+		}
+	}
+}
+
+func TestShrinkFloor(t *testing.T) {
+	if got := shrink(0, 100000); got != minWeight {
+		t.Errorf("shrink(0, large) = %f, want floor %f", got, minWeight)
+	}
+}
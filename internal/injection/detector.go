@@ -0,0 +1,74 @@
+package injection
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Control: MOD-005 (Multi-Provider Classification Orchestration)
+
+// Signal is the decision signal name recorded for prompt-injection attempts.
+const Signal = "prompt_injection"
+
+// DefaultThreshold is the score at or above which content is treated as an
+// injection attempt and the LLM's scores are not trusted.
+const DefaultThreshold = 0.5
+
+// rule is a single heuristic with the weight a match contributes to the score.
+type rule struct {
+	name   string
+	re     *regexp.Regexp
+	weight float64
+}
+
+// rules target text that addresses the classifier rather than a human reader.
+// Weights are combined by noisy-OR, so several weak hints add up.
+var rules = []rule{
+	{"override_instructions", regexp.MustCompile(`\b(ignore|disregard|forget|override|bypass)\b[^.!?\n]{0,40}\b(previous|prior|above|earlier|preceding|all|any|your|the|these|system)\b[^.!?\n]{0,20}\b(instructions?|prompts?|rules|directions|guidelines|context)\b`), 0.9},
+	{"new_instructions", regexp.MustCompile(`\b(new|updated|real|actual)\s+(instructions?|system prompt|task)\s*(:|are\b|is\b)`), 0.5},
+	{"role_markers", regexp.MustCompile(`(?m)(^\s*(system|assistant|developer)\s*:|<\|im_(start|end)\|>|\[/?inst\]|<</?sys>>|###\s*(instruction|system|response))`), 0.8},
+	{"delimiter_escape", regexp.MustCompile(`</?\s*user_content\s*>`), 0.9},
+	{"prompt_probe", regexp.MustCompile(`\b(system prompt|developer mode|jailbreak|dan mode|prompt injection)\b`), 0.5},
+	{"persona_switch", regexp.MustCompile(`\b(you are now|from now on,? you|pretend (to be|you are)|roleplay as)\b`), 0.4},
+	{"addresses_model", regexp.MustCompile(`\b(as an ai|language model|dear (ai|model|classifier|moderator bot)|(ai|llm|model|classifier|moderation system),? (please|you must|you should))\b`), 0.4},
+	{"force_scores", regexp.MustCompile(`\b(return|output|respond with|reply with|set|give|assign)\b[^.!?\n]{0,40}\b(zeros?|0(\.0+)?|lowest|minimum)\b[^.!?\n]{0,20}\b(scores?|for (every|all|each)|across)\b`), 0.8},
+	{"force_verdict", regexp.MustCompile(`\b(classify|mark|label|rate|treat|flag)\b[^.!?\n]{0,30}\b(this|it|the)(\s+(text|message|content|comment|post))?\s+as (safe|harmless|clean|benign|acceptable|not (toxic|harmful))\b`), 0.7},
+	{"suppress_moderation", regexp.MustCompile(`\b(do not|don't|never)\s+(flag|moderate|classify|block|report|censor)\b`), 0.4},
+	{"json_scores", regexp.MustCompile(`"?(toxicity|hate|harassment|sexual_content|violence|profanity|self_harm|spam|pii)"?\s*:\s*0(\.0+)?\b`), 0.7},
+}
+
+// Result is the outcome of heuristic injection detection.
+type Result struct {
+	Score float64  `json:"score"`
+	Rules []string `json:"rules,omitempty"`
+}
+
+// Detected reports whether the score reaches DefaultThreshold.
+func (r Result) Detected() bool {
+	return r.Score >= DefaultThreshold
+}
+
+// Detect scores text for prompt-injection attempts using pattern heuristics.
+// It is cheap enough to run on every submission sent to an LLM.
+func Detect(text string) Result {
+	lower := strings.ToLower(text)
+
+	var result Result
+	remaining := 1.0
+	for _, r := range rules {
+		if r.re.MatchString(lower) {
+			result.Rules = append(result.Rules, r.name)
+			remaining *= 1 - r.weight
+		}
+	}
+	result.Score = 1 - remaining
+	return result
+}
+
+// Combine merges heuristic and model-based scores, trusting whichever is higher.
+func Combine(heuristic, model float64) float64 {
+	if model > heuristic {
+		return model
+	}
+	return heuristic
+}
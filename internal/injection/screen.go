@@ -0,0 +1,68 @@
+package injection
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"time"
+)
+
+// ruleModelCheck is reported in Result.Rules when a remembered score from the
+// model-based check contributed to the result.
+const ruleModelCheck = "model_check"
+
+// Store keeps model-based check scores between requests; *cache.RedisCache
+// satisfies it.
+type Store interface {
+	Get(ctx context.Context, key string) (string, error)
+	Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error
+}
+
+// Screen checks every submission for prompt injection, whether or not it is
+// sent to the LLM. The heuristic runs on each call. The model-based check only
+// runs with the LLM second pass, which a classification cache hit skips, so
+// its scores are remembered by content: resubmitting the same text keeps the
+// signal it earned the first time.
+type Screen struct {
+	store Store // nil remembers nothing
+	ttl   time.Duration
+}
+
+// NewScreen creates a screen remembering model-based scores in store for ttl,
+// which should be at least as long as classification results are cached.
+func NewScreen(store Store, ttl time.Duration) *Screen {
+	return &Screen{store: store, ttl: ttl}
+}
+
+// Check scores text with the heuristic, combined with any score the
+// model-based check gave the same text before.
+func (s *Screen) Check(ctx context.Context, text string) Result {
+	result := Detect(text)
+	if s.store == nil {
+		return result
+	}
+	cached, err := s.store.Get(ctx, screenKey(text))
+	if err != nil {
+		return result
+	}
+	if model, err := strconv.ParseFloat(cached, 64); err == nil && model > result.Score {
+		result.Score = Combine(result.Score, model)
+		result.Rules = append(result.Rules, ruleModelCheck)
+	}
+	return result
+}
+
+// Remember records the model-based check's score for text. Scores of zero are
+// not stored.
+func (s *Screen) Remember(ctx context.Context, text string, model float64) error {
+	if s.store == nil || model <= 0 {
+		return nil
+	}
+	return s.store.Set(ctx, screenKey(text), strconv.FormatFloat(model, 'f', -1, 64), s.ttl)
+}
+
+func screenKey(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return "injection:" + hex.EncodeToString(sum[:])
+}
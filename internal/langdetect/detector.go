@@ -1,15 +1,60 @@
 package langdetect
 
 import (
+	"sort"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/pemistahl/lingua-go"
 )
 
+// Undetermined is the ISO 639-2 code reported when no language can be
+// identified with confidence.
+const Undetermined = "und"
+
+// minTextLength is the shortest text, in bytes, detection is attempted on.
+const minTextLength = 10
+
+// minSecondaryConfidence is the whole-text confidence a language needs to be
+// ranked without a span of its own.
+const minSecondaryConfidence = 0.15
+
 // DetectionResult holds the result of language detection.
 type DetectionResult struct {
+	Language   string          `json:"language"`
+	Confidence float64         `json:"confidence"`
+	Languages  []LanguageScore `json:"languages,omitempty"`
+	Spans      []Span          `json:"spans,omitempty"`
+}
+
+// LanguageScore is one ranked language. Confidence is lingua's confidence for
+// the whole text and Share is the fraction of the text in spans of the language.
+type LanguageScore struct {
 	Language   string  `json:"language"`
 	Confidence float64 `json:"confidence"`
+	Share      float64 `json:"share"`
+}
+
+// Span is a contiguous single-language section of the text. Start and End
+// are rune offsets.
+type Span struct {
+	Language string `json:"language"`
+	Start    int    `json:"start"`
+	End      int    `json:"end"`
+}
+
+// Determined reports whether any language was identified.
+func (r DetectionResult) Determined() bool {
+	return r.Language != Undetermined
+}
+
+// Codes returns the ranked language codes, most likely first.
+func (r DetectionResult) Codes() []string {
+	codes := make([]string, len(r.Languages))
+	for i, l := range r.Languages {
+		codes[i] = l.Language
+	}
+	return codes
 }
 
 // Detector wraps lingua-go for language detection.
@@ -37,29 +82,93 @@ func NewWithLanguages(languages []lingua.Language) *Detector {
 	return &Detector{detector: detector}
 }
 
-// Detect identifies the language of the input text.
-// Returns "en" as default if detection fails or confidence is too low.
+// Detect identifies the languages of the input text. The text is segmented
+// into single-language spans so code-switched content (e.g. Spanglish) reports
+// every language it mixes, ranked with the whole-text language first and the
+// rest by their share of the text. Language is the top-ranked language, or
+// Undetermined when the text is too short or no language stands out.
 func (d *Detector) Detect(text string) DetectionResult {
-	if len(text) < 10 {
-		return DetectionResult{Language: "en", Confidence: 0.0}
+	undetermined := DetectionResult{Language: Undetermined, Confidence: 0.0}
+	if len(strings.TrimSpace(text)) < minTextLength {
+		return undetermined
 	}
 
-	language, exists := d.detector.DetectLanguageOf(text)
-	if !exists {
-		return DetectionResult{Language: "en", Confidence: 0.0}
+	primary, detected := d.detector.DetectLanguageOf(text)
+
+	confidences := make(map[lingua.Language]float64)
+	for _, cv := range d.detector.ComputeLanguageConfidenceValues(text) {
+		confidences[cv.Language()] = cv.Value()
 	}
 
-	confidences := d.detector.ComputeLanguageConfidenceValues(text)
-	var confidence float64
-	for _, cv := range confidences {
-		if cv.Language() == language {
-			confidence = cv.Value()
-			break
+	var spans []Span
+	shares := make(map[lingua.Language]float64)
+	for _, section := range d.detector.DetectMultipleLanguagesOf(text) {
+		start := utf8.RuneCountInString(text[:section.StartIndex()])
+		end := start + utf8.RuneCountInString(text[section.StartIndex():section.EndIndex()])
+		spans = append(spans, Span{Language: isoCode(section.Language()), Start: start, End: end})
+		if section.Language() != lingua.Unknown {
+			shares[section.Language()] += float64(section.EndIndex()-section.StartIndex()) / float64(len(text))
 		}
 	}
 
+	if !detected && len(shares) == 0 {
+		return undetermined
+	}
+
+	candidates := make([]lingua.Language, 0, len(shares)+1)
+	seen := make(map[lingua.Language]bool)
+	add := func(l lingua.Language) {
+		if !seen[l] {
+			seen[l] = true
+			candidates = append(candidates, l)
+		}
+	}
+	if detected {
+		add(primary)
+	}
+	for l := range shares {
+		add(l)
+	}
+	for l, c := range confidences {
+		if c >= minSecondaryConfidence {
+			add(l)
+		}
+	}
+
+	// The whole-text language stays first; the rest rank by share, then confidence
+	rest := candidates
+	if detected {
+		rest = candidates[1:]
+	}
+	sort.Slice(rest, func(i, j int) bool {
+		a, b := rest[i], rest[j]
+		if shares[a] != shares[b] {
+			return shares[a] > shares[b]
+		}
+		if confidences[a] != confidences[b] {
+			return confidences[a] > confidences[b]
+		}
+		return a < b
+	})
+
+	languages := make([]LanguageScore, len(candidates))
+	for i, l := range candidates {
+		languages[i] = LanguageScore{Language: isoCode(l), Confidence: confidences[l], Share: shares[l]}
+	}
+
 	return DetectionResult{
-		Language:   strings.ToLower(language.IsoCode639_1().String()),
-		Confidence: confidence,
+		Language:   languages[0].Language,
+		Confidence: languages[0].Confidence,
+		Languages:  languages,
+		Spans:      spans,
+	}
+}
+
+// isoCode returns the lowercase ISO 639-1 code for a language, or
+// Undetermined for lingua.Unknown.
+func isoCode(l lingua.Language) string {
+	if l == lingua.Unknown {
+		return Undetermined
 	}
+	return strings.ToLower(l.IsoCode639_1().String())
 }
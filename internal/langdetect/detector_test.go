@@ -1,14 +1,15 @@
 package langdetect
 
 import (
+	"strings"
 	"testing"
 )
 
 func TestDetect_ShortText(t *testing.T) {
 	d := New()
 	result := d.Detect("hi")
-	if result.Language != "en" {
-		t.Errorf("short text should default to en, got %q", result.Language)
+	if result.Language != Undetermined || result.Determined() {
+		t.Errorf("short text should be undetermined, got %q", result.Language)
 	}
 	if result.Confidence != 0.0 {
 		t.Errorf("short text confidence should be 0.0, got %f", result.Confidence)
@@ -18,8 +19,11 @@ func TestDetect_ShortText(t *testing.T) {
 func TestDetect_EmptyText(t *testing.T) {
 	d := New()
 	result := d.Detect("")
-	if result.Language != "en" {
-		t.Errorf("empty text should default to en, got %q", result.Language)
+	if result.Language != Undetermined {
+		t.Errorf("empty text should be undetermined, got %q", result.Language)
+	}
+	if len(result.Languages) != 0 || len(result.Spans) != 0 {
+		t.Errorf("empty text should have no languages or spans, got %+v", result)
 	}
 }
 
@@ -49,3 +53,52 @@ func TestDetect_FrenchText(t *testing.T) {
 		t.Errorf("expected fr, got %q", result.Language)
 	}
 }
+
+func TestDetect_AmbiguousText(t *testing.T) {
+	d := New()
+	result := d.Detect("hello there")
+	if result.Determined() {
+		t.Errorf("ambiguous text should be undetermined, got %q", result.Language)
+	}
+}
+
+func TestDetect_CodeSwitchedText(t *testing.T) {
+	d := New()
+	text := "I told him que no quiero ir a la fiesta because it is too late"
+	result := d.Detect(text)
+	if result.Language != "en" {
+		t.Errorf("expected en first, got %q", result.Language)
+	}
+
+	codes := strings.Join(result.Codes(), ",")
+	if !strings.Contains(codes, "en") || !strings.Contains(codes, "es") {
+		t.Errorf("expected en and es to be ranked, got %v", result.Codes())
+	}
+
+	if len(result.Spans) < 2 {
+		t.Fatalf("expected the text to be segmented, got %+v", result.Spans)
+	}
+	runes := []rune(text)
+	for i, span := range result.Spans {
+		if i > 0 && span.Start != result.Spans[i-1].End {
+			t.Errorf("span %d starts at %d, want %d", i, span.Start, result.Spans[i-1].End)
+		}
+		if span.End > len(runes) {
+			t.Errorf("span %d ends past the text", i)
+		}
+	}
+	if result.Spans[0].Start != 0 || result.Spans[len(result.Spans)-1].End != len(runes) {
+		t.Error("spans do not cover the text")
+	}
+}
+
+func TestDetect_SingleLanguageRanking(t *testing.T) {
+	d := New()
+	result := d.Detect("Esta es una oración larga en español que debería ser detectada correctamente")
+	if len(result.Languages) == 0 || result.Languages[0].Language != "es" {
+		t.Fatalf("expected es ranked first, got %+v", result.Languages)
+	}
+	if result.Languages[0].Share != 1 {
+		t.Errorf("expected es to cover the text, got share %f", result.Languages[0].Share)
+	}
+}
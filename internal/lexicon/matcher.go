@@ -0,0 +1,185 @@
+package lexicon
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/proth1/text-moderator/internal/models"
+)
+
+// Categories lists the CategoryScores fields a lexicon list may contribute to.
+var Categories = models.Categories
+
+// defaultWeight is the weight of an entry created without one.
+const defaultWeight = 0.5
+
+// compiledEntry is a single lexicon entry ready for matching.
+type compiledEntry struct {
+	re       *regexp.Regexp
+	category string
+	weight   float64
+	list     string
+	pattern  string
+	term     bool // span is capture group 1, excluding the boundary characters
+}
+
+// Match describes a single lexicon entry that matched the input text.
+type Match struct {
+	List     string  `json:"list"`
+	Category string  `json:"category"`
+	Pattern  string  `json:"pattern"`
+	Weight   float64 `json:"weight"`
+	Start    int     `json:"start"`
+	End      int     `json:"end"`
+}
+
+// Matcher scores text against a fixed set of compiled lexicon lists.
+// A Matcher is immutable once built and safe for concurrent use.
+type Matcher struct {
+	entries []compiledEntry
+	version string
+}
+
+// Compile builds a Matcher from the given lists. Entries are matched
+// case-insensitively; "term" entries only match on word boundaries.
+func Compile(lists []models.LexiconList) (*Matcher, error) {
+	m := &Matcher{}
+	ids := make([]string, 0, len(lists))
+
+	for _, list := range lists {
+		ids = append(ids, fmt.Sprintf("%s@%d", list.Name, list.Version))
+		for _, entry := range list.Entries {
+			re, err := compileEntry(entry)
+			if err != nil {
+				return nil, fmt.Errorf("list %s v%d: %w", list.Name, list.Version, err)
+			}
+			m.entries = append(m.entries, compiledEntry{
+				re:       re,
+				category: list.Category,
+				weight:   entry.Weight,
+				list:     list.Name,
+				pattern:  entry.Pattern,
+				term:     entry.MatchType != models.LexiconMatchRegex,
+			})
+		}
+	}
+
+	sort.Strings(ids)
+	h := sha256.Sum256([]byte(strings.Join(ids, ",")))
+	m.version = hex.EncodeToString(h[:6])
+
+	return m, nil
+}
+
+// compileEntry turns a lexicon entry into a case-insensitive regular expression.
+func compileEntry(entry models.LexiconEntry) (*regexp.Regexp, error) {
+	switch entry.MatchType {
+	case models.LexiconMatchRegex:
+		re, err := regexp.Compile("(?i)" + entry.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regex %q: %w", entry.Pattern, err)
+		}
+		return re, nil
+	case models.LexiconMatchTerm, "":
+		term := strings.TrimSpace(entry.Pattern)
+		if term == "" {
+			return nil, fmt.Errorf("empty term")
+		}
+		return regexp.MustCompile(`(?i)(?:^|[^\pL\pN_])(` + regexp.QuoteMeta(term) + `)(?:$|[^\pL\pN_])`), nil
+	default:
+		return nil, fmt.Errorf("unknown match type %q", entry.MatchType)
+	}
+}
+
+// Score computes category scores for text. Each entry counts at most once, and
+// matched weights within a category are combined as 1 - Π(1 - weight), so a
+// single strong match dominates while many weak matches still accumulate.
+func (m *Matcher) Score(text string) (*models.CategoryScores, []Match) {
+	remaining := make(map[string]float64, len(Categories))
+	var matches []Match
+
+	for _, e := range m.entries {
+		loc := e.re.FindStringSubmatchIndex(text)
+		if loc == nil {
+			continue
+		}
+		start, end := loc[0], loc[1]
+		if e.term {
+			start, end = loc[2], loc[3]
+		}
+
+		if _, ok := remaining[e.category]; !ok {
+			remaining[e.category] = 1.0
+		}
+		remaining[e.category] *= 1.0 - e.weight
+
+		matches = append(matches, Match{
+			List:     e.list,
+			Category: e.category,
+			Pattern:  e.pattern,
+			Weight:   e.weight,
+			Start:    start,
+			End:      end,
+		})
+	}
+
+	values := make(map[string]float64, len(remaining))
+	for category, rest := range remaining {
+		values[category] = 1.0 - rest
+	}
+	scores := &models.CategoryScores{}
+	scores.FromMap(values)
+	return scores, matches
+}
+
+// Version returns a short fingerprint of the list names and versions compiled into this Matcher.
+func (m *Matcher) Version() string {
+	return m.version
+}
+
+// Size returns the number of compiled entries.
+func (m *Matcher) Size() int {
+	return len(m.entries)
+}
+
+// ValidateRequest checks that a lexicon creation request references a known
+// category, uses valid weights and match types, and that every regex compiles.
+// Missing match types default to "term" and missing weights to 0.5; a weight
+// given explicitly must be greater than 0 and at most 1.
+func ValidateRequest(req *models.CreateLexiconRequest) error {
+	validCategory := false
+	for _, c := range Categories {
+		if c == req.Category {
+			validCategory = true
+			break
+		}
+	}
+	if !validCategory {
+		return fmt.Errorf("invalid category %q", req.Category)
+	}
+	if len(req.Entries) == 0 {
+		return fmt.Errorf("entries must not be empty")
+	}
+
+	for i := range req.Entries {
+		entry := &req.Entries[i]
+		if entry.MatchType == "" {
+			entry.MatchType = models.LexiconMatchTerm
+		}
+		if entry.Weight == nil {
+			weight := defaultWeight
+			entry.Weight = &weight
+		}
+		if *entry.Weight <= 0 || *entry.Weight > 1 {
+			return fmt.Errorf("entry %d: weight must be greater than 0 and at most 1", i)
+		}
+		if _, err := compileEntry(models.LexiconEntry{Pattern: entry.Pattern, MatchType: entry.MatchType}); err != nil {
+			return fmt.Errorf("entry %d: %w", i, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package lexicon
+
+import (
+	"math"
+	"testing"
+
+	"github.com/proth1/text-moderator/internal/models"
+)
+
+func testLists() []models.LexiconList {
+	return []models.LexiconList{
+		{
+			Name: "slurs", Version: 2, Category: "hate",
+			Entries: []models.LexiconEntry{
+				{Pattern: "badword", MatchType: models.LexiconMatchTerm, Weight: 0.8},
+				{Pattern: "grr", MatchType: models.LexiconMatchTerm, Weight: 0.5},
+			},
+		},
+		{
+			Name: "spam-links", Version: 1, Category: "spam",
+			Entries: []models.LexiconEntry{
+				{Pattern: `buy\s+now`, MatchType: models.LexiconMatchRegex, Weight: 0.6},
+			},
+		},
+	}
+}
+
+func TestMatcher_Score(t *testing.T) {
+	m, err := Compile(testLists())
+	if err != nil {
+		t.Fatalf("compile failed: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		text     string
+		wantHate float64
+		wantSpam float64
+		matches  int
+	}{
+		{"no match", "hello world", 0, 0, 0},
+		{"term match", "you badword", 0.8, 0, 1},
+		{"case insensitive", "BadWord!", 0.8, 0, 1},
+		{"term requires word boundary", "badwords", 0, 0, 0},
+		{"term inside word ignored", "grrrr", 0, 0, 0},
+		{"noisy-or combination", "badword grr", 0.9, 0, 2},
+		{"regex match", "Buy   now please", 0, 0.6, 1},
+		{"multiple categories", "buy now badword", 0.8, 0.6, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scores, matches := m.Score(tt.text)
+			if math.Abs(scores.Hate-tt.wantHate) > 1e-9 {
+				t.Errorf("hate: got %f, want %f", scores.Hate, tt.wantHate)
+			}
+			if math.Abs(scores.Spam-tt.wantSpam) > 1e-9 {
+				t.Errorf("spam: got %f, want %f", scores.Spam, tt.wantSpam)
+			}
+			if len(matches) != tt.matches {
+				t.Errorf("matches: got %d, want %d", len(matches), tt.matches)
+			}
+		})
+	}
+}
+
+func TestMatcher_TermSpan(t *testing.T) {
+	m, err := Compile(testLists())
+	if err != nil {
+		t.Fatalf("compile failed: %v", err)
+	}
+
+	text := "oh, badword."
+	_, matches := m.Score(text)
+	if len(matches) != 1 {
+		t.Fatalf("expected 1 match, got %d", len(matches))
+	}
+	if got := text[matches[0].Start:matches[0].End]; got != "badword" {
+		t.Errorf("span: got %q, want %q", got, "badword")
+	}
+}
+
+func TestMatcher_Version(t *testing.T) {
+	lists := testLists()
+	a, _ := Compile(lists)
+	b, _ := Compile([]models.LexiconList{lists[1], lists[0]})
+	if a.Version() != b.Version() {
+		t.Errorf("version should not depend on list order: %s vs %s", a.Version(), b.Version())
+	}
+
+	lists[0].Version = 3
+	c, _ := Compile(lists)
+	if a.Version() == c.Version() {
+		t.Errorf("version should change when a list version changes")
+	}
+}
+
+func TestValidateRequest(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     models.CreateLexiconRequest
+		wantErr bool
+	}{
+		{"valid", models.CreateLexiconRequest{Name: "x", Category: "hate", Entries: []models.CreateLexiconEntryRequest{{Pattern: "foo"}}}, false},
+		{"unknown category", models.CreateLexiconRequest{Name: "x", Category: "nope", Entries: []models.CreateLexiconEntryRequest{{Pattern: "foo"}}}, true},
+		{"no entries", models.CreateLexiconRequest{Name: "x", Category: "hate"}, true},
+		{"bad regex", models.CreateLexiconRequest{Name: "x", Category: "spam", Entries: []models.CreateLexiconEntryRequest{{Pattern: "(", MatchType: models.LexiconMatchRegex}}}, true},
+		{"weight of 1", models.CreateLexiconRequest{Name: "x", Category: "spam", Entries: []models.CreateLexiconEntryRequest{{Pattern: "foo", Weight: weight(1)}}}, false},
+		{"weight out of range", models.CreateLexiconRequest{Name: "x", Category: "spam", Entries: []models.CreateLexiconEntryRequest{{Pattern: "foo", Weight: weight(1.5)}}}, true},
+		{"zero weight", models.CreateLexiconRequest{Name: "x", Category: "spam", Entries: []models.CreateLexiconEntryRequest{{Pattern: "foo", Weight: weight(0)}}}, true},
+		{"negative weight", models.CreateLexiconRequest{Name: "x", Category: "spam", Entries: []models.CreateLexiconEntryRequest{{Pattern: "foo", Weight: weight(-0.1)}}}, true},
+		{"unknown match type", models.CreateLexiconRequest{Name: "x", Category: "spam", Entries: []models.CreateLexiconEntryRequest{{Pattern: "foo", MatchType: "glob"}}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateRequest(&tt.req)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("got err %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateRequest_Defaults(t *testing.T) {
+	req := models.CreateLexiconRequest{Name: "x", Category: "hate", Entries: []models.CreateLexiconEntryRequest{{Pattern: "foo"}}}
+	if err := ValidateRequest(&req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if req.Entries[0].MatchType != models.LexiconMatchTerm {
+		t.Errorf("match type: got %q, want %q", req.Entries[0].MatchType, models.LexiconMatchTerm)
+	}
+	if req.Entries[0].Weight == nil || *req.Entries[0].Weight != 0.5 {
+		t.Errorf("weight: got %v, want %f", req.Entries[0].Weight, 0.5)
+	}
+}
+
+func weight(w float64) *float64 {
+	return &w
+}
@@ -0,0 +1,234 @@
+package lexicon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/proth1/text-moderator/internal/models"
+	"go.uber.org/zap"
+)
+
+// ErrNotDraft is returned when publishing a list version that is not a draft.
+var ErrNotDraft = errors.New("lexicon list is not a draft")
+
+// Store persists versioned lexicon lists.
+// Control: MOD-005 (Multi-Provider Classification Orchestration)
+type Store struct {
+	db     *pgxpool.Pool
+	logger *zap.Logger
+}
+
+// NewStore creates a new lexicon store.
+func NewStore(db *pgxpool.Pool, logger *zap.Logger) *Store {
+	return &Store{db: db, logger: logger}
+}
+
+// CreateList creates a new draft version of the named list together with its entries.
+// The version is one greater than the highest existing version with the same name.
+func (s *Store) CreateList(ctx context.Context, req *models.CreateLexiconRequest, createdBy uuid.UUID) (*models.LexiconList, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var maxVersion int
+	err = tx.QueryRow(ctx, `SELECT COALESCE(MAX(version), 0) FROM lexicon_lists WHERE name = $1`, req.Name).Scan(&maxVersion)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check existing lexicon versions: %w", err)
+	}
+
+	list := &models.LexiconList{
+		ID:        uuid.New(),
+		Name:      req.Name,
+		Version:   maxVersion + 1,
+		Category:  req.Category,
+		Status:    models.PolicyStatusDraft,
+		CreatedBy: &createdBy,
+	}
+	if req.Description != "" {
+		list.Description = &req.Description
+	}
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO lexicon_lists (id, name, version, category, description, status, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING created_at
+	`, list.ID, list.Name, list.Version, list.Category, list.Description, list.Status, list.CreatedBy).Scan(&list.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create lexicon list: %w", err)
+	}
+
+	for _, e := range req.Entries {
+		entry := models.LexiconEntry{
+			ID:        uuid.New(),
+			Pattern:   e.Pattern,
+			MatchType: e.MatchType,
+			Weight:    *e.Weight,
+		}
+		_, err := tx.Exec(ctx, `
+			INSERT INTO lexicon_entries (id, list_id, pattern, match_type, weight)
+			VALUES ($1, $2, $3, $4, $5)
+		`, entry.ID, list.ID, entry.Pattern, entry.MatchType, entry.Weight)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create lexicon entry: %w", err)
+		}
+		list.Entries = append(list.Entries, entry)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit lexicon list: %w", err)
+	}
+
+	s.logger.Info("lexicon list created",
+		zap.String("list_id", list.ID.String()),
+		zap.String("name", list.Name),
+		zap.Int("version", list.Version),
+		zap.Int("entries", len(list.Entries)),
+	)
+
+	return list, nil
+}
+
+// ListLists returns lexicon list metadata (without entries) with optional status filtering.
+func (s *Store) ListLists(ctx context.Context, status *models.PolicyStatus) ([]models.LexiconList, error) {
+	query := `
+		SELECT id, name, version, category, description, status, created_at, created_by
+		FROM lexicon_lists
+		WHERE ($1::policy_status IS NULL OR status = $1)
+		ORDER BY name, version DESC
+	`
+
+	rows, err := s.db.Query(ctx, query, status)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query lexicon lists: %w", err)
+	}
+	defer rows.Close()
+
+	var lists []models.LexiconList
+	for rows.Next() {
+		var l models.LexiconList
+		if err := rows.Scan(&l.ID, &l.Name, &l.Version, &l.Category, &l.Description, &l.Status, &l.CreatedAt, &l.CreatedBy); err != nil {
+			return nil, fmt.Errorf("failed to scan lexicon list: %w", err)
+		}
+		lists = append(lists, l)
+	}
+
+	return lists, rows.Err()
+}
+
+// GetList returns a single lexicon list including its entries.
+func (s *Store) GetList(ctx context.Context, id uuid.UUID) (*models.LexiconList, error) {
+	var l models.LexiconList
+	err := s.db.QueryRow(ctx, `
+		SELECT id, name, version, category, description, status, created_at, created_by
+		FROM lexicon_lists
+		WHERE id = $1
+	`, id).Scan(&l.ID, &l.Name, &l.Version, &l.Category, &l.Description, &l.Status, &l.CreatedAt, &l.CreatedBy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query lexicon list: %w", err)
+	}
+
+	entries, err := s.loadEntries(ctx, []uuid.UUID{l.ID})
+	if err != nil {
+		return nil, err
+	}
+	l.Entries = entries[l.ID]
+
+	return &l, nil
+}
+
+// PublishList publishes a draft list version and archives any previously
+// published version with the same name, so exactly one version of each list
+// is active. Published and archived versions cannot be published again.
+func (s *Store) PublishList(ctx context.Context, id uuid.UUID) (*models.LexiconList, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var name string
+	var status models.PolicyStatus
+	if err := tx.QueryRow(ctx, `SELECT name, status FROM lexicon_lists WHERE id = $1 FOR UPDATE`, id).Scan(&name, &status); err != nil {
+		return nil, fmt.Errorf("failed to query lexicon list: %w", err)
+	}
+	if status != models.PolicyStatusDraft {
+		return nil, fmt.Errorf("%w: status is %s", ErrNotDraft, status)
+	}
+
+	if _, err := tx.Exec(ctx,
+		`UPDATE lexicon_lists SET status = 'archived' WHERE name = $1 AND status = 'published' AND id <> $2`,
+		name, id,
+	); err != nil {
+		return nil, fmt.Errorf("failed to archive previous lexicon version: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE lexicon_lists SET status = 'published' WHERE id = $1`, id); err != nil {
+		return nil, fmt.Errorf("failed to publish lexicon list: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit lexicon publish: %w", err)
+	}
+
+	s.logger.Info("lexicon list published", zap.String("list_id", id.String()), zap.String("name", name))
+
+	return s.GetList(ctx, id)
+}
+
+// PublishedLists returns all published lists with their entries.
+func (s *Store) PublishedLists(ctx context.Context) ([]models.LexiconList, error) {
+	status := models.PolicyStatusPublished
+	lists, err := s.ListLists(ctx, &status)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uuid.UUID, len(lists))
+	for i, l := range lists {
+		ids[i] = l.ID
+	}
+	entries, err := s.loadEntries(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for i := range lists {
+		lists[i].Entries = entries[lists[i].ID]
+	}
+
+	return lists, nil
+}
+
+// loadEntries fetches entries for the given list IDs, grouped by list.
+func (s *Store) loadEntries(ctx context.Context, listIDs []uuid.UUID) (map[uuid.UUID][]models.LexiconEntry, error) {
+	result := make(map[uuid.UUID][]models.LexiconEntry, len(listIDs))
+	if len(listIDs) == 0 {
+		return result, nil
+	}
+
+	rows, err := s.db.Query(ctx, `
+		SELECT id, list_id, pattern, match_type, weight
+		FROM lexicon_entries
+		WHERE list_id = ANY($1)
+		ORDER BY created_at
+	`, listIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query lexicon entries: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var e models.LexiconEntry
+		var listID uuid.UUID
+		if err := rows.Scan(&e.ID, &listID, &e.Pattern, &e.MatchType, &e.Weight); err != nil {
+			return nil, fmt.Errorf("failed to scan lexicon entry: %w", err)
+		}
+		result[listID] = append(result[listID], e)
+	}
+
+	return result, rows.Err()
+}
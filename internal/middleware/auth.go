@@ -107,6 +107,31 @@ func RequireRole(allowedRoles ...string) gin.HandlerFunc {
 	}
 }
 
+// APIKey returns the request's API key, or "" if it has none. Services behind
+// the gateway use it to identify the key's owner.
+func APIKey(c *gin.Context) string {
+	return extractAPIKey(c)
+}
+
+// APIKeyHashHeader carries the hash of the caller's API key from the gateway
+// to internal services, whether the caller sent the key as X-API-Key or as a
+// Bearer token. The gateway sets it after authentication and never forwards
+// a client's own value.
+const APIKeyHashHeader = "X-API-Key-Hash"
+
+// APIKeyHash returns the hash of the request's API key, which identifies its
+// owner: the hash forwarded by the gateway, or else the hash of the key the
+// request carries, or "" if it has neither.
+func APIKeyHash(c *gin.Context) string {
+	if hash := c.GetHeader(APIKeyHashHeader); hash != "" {
+		return hash
+	}
+	if key := extractAPIKey(c); key != "" {
+		return HashAPIKey(key)
+	}
+	return ""
+}
+
 // extractAPIKey extracts the API key from request headers
 func extractAPIKey(c *gin.Context) string {
 	// Try X-API-Key header first
@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestAPIKeyHash(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name    string
+		headers map[string]string
+		want    string
+	}{
+		{"x-api-key", map[string]string{APIKeyHeader: "key-1"}, HashAPIKey("key-1")},
+		{"bearer", map[string]string{AuthorizationHeader: "Bearer key-1"}, HashAPIKey("key-1")},
+		{"forwarded by gateway", map[string]string{APIKeyHashHeader: "abc", APIKeyHeader: "internal-token"}, "abc"},
+		{"no key", nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodPost, "/moderate", nil)
+			for k, v := range tt.headers {
+				c.Request.Header.Set(k, v)
+			}
+			if got := APIKeyHash(c); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
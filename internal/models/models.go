@@ -1,6 +1,7 @@
 package models
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
@@ -36,6 +37,16 @@ const (
 	PolicyStatusArchived  PolicyStatus = "archived"
 )
 
+// PolicyTransition is a policy lifecycle operation
+// Control: POL-001 (Policy lifecycle management)
+type PolicyTransition string
+
+const (
+	TransitionPublish  PolicyTransition = "publish"
+	TransitionArchive  PolicyTransition = "archive"
+	TransitionRollback PolicyTransition = "rollback"
+)
+
 // PolicyAction represents the action to be taken for moderation
 // Control: POL-001 (Policy-driven decision making)
 type PolicyAction string
@@ -60,6 +71,111 @@ type Policy struct {
 	EffectiveDate *time.Time              `json:"effective_date,omitempty" db:"effective_date"`
 	CreatedAt     time.Time               `json:"created_at" db:"created_at"`
 	CreatedBy     *uuid.UUID              `json:"created_by,omitempty" db:"created_by"`
+
+	// PublishedAt and PublishedBy record the policy's last publish; a version
+	// that was never published cannot be the target of a rollback.
+	PublishedAt *time.Time `json:"published_at,omitempty" db:"published_at"`
+	PublishedBy *uuid.UUID `json:"published_by,omitempty" db:"published_by"`
+
+	// NormalizationProfile names the profile content is normalized with under
+	// this policy unless a request selects another; empty means "standard".
+	// NormalizationProfiles defines the policy's own profiles.
+	NormalizationProfile  *string                `json:"normalization_profile,omitempty" db:"normalization_profile"`
+	NormalizationProfiles []NormalizationProfile `json:"normalization_profiles,omitempty" db:"normalization_profiles"`
+
+	// Rules are expression rules evaluated in order after the thresholds
+	Rules []PolicyRule `json:"rules,omitempty" db:"rules"`
+}
+
+// PolicyAuthor identifies a user who created or published a policy version
+type PolicyAuthor struct {
+	ID    uuid.UUID `json:"id"`
+	Email string    `json:"email"`
+}
+
+// PolicyVersion is a version of a policy with who created it and who last
+// published it
+type PolicyVersion struct {
+	Policy
+	Author    *PolicyAuthor `json:"author,omitempty"`
+	Publisher *PolicyAuthor `json:"publisher,omitempty"`
+}
+
+// PolicyChangeType is how a policy setting differs between two versions
+type PolicyChangeType string
+
+const (
+	PolicyChangeAdded   PolicyChangeType = "added"
+	PolicyChangeRemoved PolicyChangeType = "removed"
+	PolicyChangeChanged PolicyChangeType = "changed"
+	PolicyChangeMoved   PolicyChangeType = "moved"
+)
+
+// PolicyDiff lists the changes that turn policy version From into To
+// Control: POL-001 (Policy definition and versioning)
+type PolicyDiff struct {
+	From       PolicyVersion     `json:"from"`
+	To         PolicyVersion     `json:"to"`
+	Thresholds []ThresholdChange `json:"thresholds"`
+	Actions    []ActionChange    `json:"actions"`
+	Scope      []ScopeChange     `json:"scope"`
+	Rules      []RuleChange      `json:"rules"`
+}
+
+// ThresholdChange is a category threshold added, removed or changed
+type ThresholdChange struct {
+	Category string           `json:"category"`
+	Change   PolicyChangeType `json:"change"`
+	From     *float64         `json:"from,omitempty"`
+	To       *float64         `json:"to,omitempty"`
+}
+
+// ActionChange is a category action added, removed or changed
+type ActionChange struct {
+	Category string           `json:"category"`
+	Change   PolicyChangeType `json:"change"`
+	From     *PolicyAction    `json:"from,omitempty"`
+	To       *PolicyAction    `json:"to,omitempty"`
+}
+
+// ScopeChange is a scope value added, removed or changed at a dotted path,
+// e.g. "region" or "translated.action"
+type ScopeChange struct {
+	Path   string           `json:"path"`
+	Change PolicyChangeType `json:"change"`
+	From   interface{}      `json:"from,omitempty"`
+	To     interface{}      `json:"to,omitempty"`
+}
+
+// RuleChange is a rule added, removed, changed or moved in the evaluation order
+type RuleChange struct {
+	ID     string           `json:"id"`
+	Change PolicyChangeType `json:"change"`
+	From   *PolicyRule      `json:"from,omitempty"`
+	To     *PolicyRule      `json:"to,omitempty"`
+}
+
+// PolicyRule applies Action when Expression matches, e.g.
+// `hate > 0.6 && context.audience == "kids"`. Rules are evaluated in order;
+// a matching Final rule decides the action outright and stops evaluation
+type PolicyRule struct {
+	ID          string       `json:"id"`
+	Expression  string       `json:"expression"`
+	Action      PolicyAction `json:"action"`
+	Final       bool         `json:"final,omitempty"`
+	Description string       `json:"description,omitempty"`
+}
+
+// NormalizationProfile selects the normalization steps and tables applied to
+// content. A profile extends a built-in profile (Base, "standard" by default),
+// optionally replacing its steps and adding lookalike and leetspeak mappings
+type NormalizationProfile struct {
+	Name           string            `json:"name"`
+	Base           string            `json:"base,omitempty"`
+	Steps          []string          `json:"steps,omitempty"`
+	FoldAllScripts *bool             `json:"fold_all_scripts,omitempty"`
+	Confusables    map[string]string `json:"confusables,omitempty"`
+	Leetspeak      map[string]string `json:"leetspeak,omitempty"`
 }
 
 // CategoryScores represents the confidence scores for each moderation category
@@ -76,6 +192,51 @@ type CategoryScores struct {
 	PII            float64 `json:"pii"`
 }
 
+// Categories lists the moderation category names, in CategoryScores field order.
+var Categories = []string{"toxicity", "hate", "harassment", "sexual_content", "violence", "profanity", "self_harm", "spam", "pii"}
+
+// ToMap returns the scores keyed by category name.
+func (s *CategoryScores) ToMap() map[string]float64 {
+	return map[string]float64{
+		"toxicity":       s.Toxicity,
+		"hate":           s.Hate,
+		"harassment":     s.Harassment,
+		"sexual_content": s.SexualContent,
+		"violence":       s.Violence,
+		"profanity":      s.Profanity,
+		"self_harm":      s.SelfHarm,
+		"spam":           s.Spam,
+		"pii":            s.PII,
+	}
+}
+
+// FromMap sets the scores of the categories named in m. Categories m does not
+// name keep their scores, and unknown names are ignored.
+func (s *CategoryScores) FromMap(m map[string]float64) {
+	for category, value := range m {
+		switch category {
+		case "toxicity":
+			s.Toxicity = value
+		case "hate":
+			s.Hate = value
+		case "harassment":
+			s.Harassment = value
+		case "sexual_content":
+			s.SexualContent = value
+		case "violence":
+			s.Violence = value
+		case "profanity":
+			s.Profanity = value
+		case "self_harm":
+			s.SelfHarm = value
+		case "spam":
+			s.Spam = value
+		case "pii":
+			s.PII = value
+		}
+	}
+}
+
 // TextSubmission represents a text submission for moderation
 // Control: MOD-001 (Input tracking and hashing)
 type TextSubmission struct {
@@ -90,18 +251,33 @@ type TextSubmission struct {
 // ModerationDecision represents the result of content moderation
 // Control: MOD-001 (Decision tracking and traceability)
 type ModerationDecision struct {
-	ID              uuid.UUID       `json:"id" db:"id"`
-	SubmissionID    uuid.UUID       `json:"submission_id" db:"submission_id"`
-	ModelName       string          `json:"model_name" db:"model_name"`
-	ModelVersion    string          `json:"model_version" db:"model_version"`
-	CategoryScores  CategoryScores  `json:"category_scores" db:"category_scores"`
-	PolicyID        *uuid.UUID      `json:"policy_id,omitempty" db:"policy_id"`
-	PolicyVersion   *int            `json:"policy_version,omitempty" db:"policy_version"`
-	AutomatedAction PolicyAction    `json:"automated_action" db:"automated_action"`
-	Confidence      *float64        `json:"confidence,omitempty" db:"confidence"`
-	Explanation     *string         `json:"explanation,omitempty" db:"explanation"`
-	CorrelationID   *uuid.UUID      `json:"correlation_id,omitempty" db:"correlation_id"`
-	CreatedAt       time.Time       `json:"created_at" db:"created_at"`
+	ID                 uuid.UUID            `json:"id" db:"id"`
+	SubmissionID       uuid.UUID            `json:"submission_id" db:"submission_id"`
+	ModelName          string               `json:"model_name" db:"model_name"`
+	ModelVersion       string               `json:"model_version" db:"model_version"`
+	CategoryScores     CategoryScores       `json:"category_scores" db:"category_scores"`
+	PolicyID           *uuid.UUID           `json:"policy_id,omitempty" db:"policy_id"`
+	PolicyVersion      *int                 `json:"policy_version,omitempty" db:"policy_version"`
+	AutomatedAction    PolicyAction         `json:"automated_action" db:"automated_action"`
+	Confidence         *float64             `json:"confidence,omitempty" db:"confidence"`
+	Explanation        *string              `json:"explanation,omitempty" db:"explanation"`
+	ExplanationDetails *DecisionExplanation `json:"explanation_details,omitempty" db:"explanation_details"`
+	CorrelationID      *uuid.UUID           `json:"correlation_id,omitempty" db:"correlation_id"`
+	ProviderName       *string              `json:"provider_name,omitempty" db:"provider_name"`
+	RoutingBucket      *int                 `json:"routing_bucket,omitempty" db:"routing_bucket"`
+	CalibrationVersion *int                 `json:"calibration_version,omitempty" db:"calibration_version"`
+	RawCategoryScores  *CategoryScores      `json:"raw_category_scores,omitempty" db:"raw_category_scores"`
+	Signals            map[string]float64   `json:"signals,omitempty" db:"signals"`
+	Translated         bool                 `json:"translated" db:"translated"`
+	SourceLanguage     *string              `json:"source_language,omitempty" db:"source_language"`
+	CreatedAt          time.Time            `json:"created_at" db:"created_at"`
+}
+
+// DecisionExplanation is an LLM's rationale for a decision and the phrases that
+// triggered each flagged category
+type DecisionExplanation struct {
+	Rationale string              `json:"rationale"`
+	Phrases   map[string][]string `json:"phrases,omitempty"`
 }
 
 // ReviewActionType represents the type of action taken during human review
@@ -146,34 +322,149 @@ type EvidenceRecord struct {
 	ChainHash       *string           `json:"chain_hash,omitempty" db:"chain_hash"`
 	PreviousHash    *string           `json:"previous_hash,omitempty" db:"previous_hash"`
 	CreatedAt       time.Time         `json:"created_at" db:"created_at"`
+
+	// PromptTemplateID and PromptTemplateVersion identify the LLM prompt
+	// template used for the decision, when the LLM second pass ran.
+	PromptTemplateID      *uuid.UUID `json:"prompt_template_id,omitempty" db:"prompt_template_id"`
+	PromptTemplateVersion *int       `json:"prompt_template_version,omitempty" db:"prompt_template_version"`
+
+	// ContextHashes are SHA-256 hashes of the prior conversation messages the
+	// target message was classified with, oldest first.
+	ContextHashes []string `json:"context_hashes,omitempty" db:"context_hashes"`
+
+	// NormalizationProfile identifies the normalization profile the content
+	// was normalized with, as returned by normalizer.ProfileKey.
+	NormalizationProfile *string `json:"normalization_profile,omitempty" db:"normalization_profile"`
+
+	// PolicyTransition, PolicyStatus and ActorID describe a policy lifecycle
+	// transition: the operation, the status it left the policy in and who
+	// performed it.
+	PolicyTransition *PolicyTransition `json:"policy_transition,omitempty" db:"policy_transition"`
+	PolicyStatus     *PolicyStatus     `json:"policy_status,omitempty" db:"policy_status"`
+	ActorID          *uuid.UUID        `json:"actor_id,omitempty" db:"actor_id"`
 }
 
 // ModerationRequest represents an incoming moderation request
 type ModerationRequest struct {
-	Content         string                 `json:"content" binding:"required"`
-	ContextMetadata map[string]interface{} `json:"context_metadata,omitempty"`
-	Source          string                 `json:"source,omitempty"`
-	PolicyID        *uuid.UUID             `json:"policy_id,omitempty"`
+	Content              string                 `json:"content" binding:"required"`
+	ContextMetadata      map[string]interface{} `json:"context_metadata,omitempty"`
+	Source               string                 `json:"source,omitempty"`
+	PolicyID             *uuid.UUID             `json:"policy_id,omitempty"`
+	NormalizationProfile string                 `json:"normalization_profile,omitempty"`
+}
+
+// ConversationMessage is one message in a conversation thread
+type ConversationMessage struct {
+	AuthorID string `json:"author_id" binding:"required"`
+	Content  string `json:"content" binding:"required"`
+}
+
+// ConversationModerationRequest asks for a message to be moderated in the
+// context of the prior messages in its thread. Only Message is evaluated
+// against policy; Context is ordered oldest first
+type ConversationModerationRequest struct {
+	Message              ConversationMessage    `json:"message" binding:"required"`
+	Context              []ConversationMessage  `json:"context,omitempty" binding:"omitempty,dive"`
+	ContextMetadata      map[string]interface{} `json:"context_metadata,omitempty"`
+	Source               string                 `json:"source,omitempty"`
+	PolicyID             *uuid.UUID             `json:"policy_id,omitempty"`
+	NormalizationProfile string                 `json:"normalization_profile,omitempty"`
 }
 
 // ModerationResponse represents the response from moderation
 type ModerationResponse struct {
-	DecisionID       uuid.UUID       `json:"decision_id"`
-	SubmissionID     uuid.UUID       `json:"submission_id"`
-	Action           PolicyAction    `json:"action"`
-	CategoryScores   CategoryScores  `json:"category_scores"`
-	Confidence       *float64        `json:"confidence,omitempty"`
-	Explanation      *string         `json:"explanation,omitempty"`
-	PolicyApplied    *string         `json:"policy_applied,omitempty"`
-	PolicyVersion    *int            `json:"policy_version,omitempty"`
-	RequiresReview   bool            `json:"requires_review"`
-	DetectedLanguage string          `json:"detected_language,omitempty"`
+	DecisionID           uuid.UUID                   `json:"decision_id"`
+	SubmissionID         uuid.UUID                   `json:"submission_id"`
+	Action               PolicyAction                `json:"action"`
+	CategoryScores       CategoryScores              `json:"category_scores"`
+	Confidence           *float64                    `json:"confidence,omitempty"`
+	Explanation          *string                     `json:"explanation,omitempty"`
+	ExplanationDetails   *DecisionExplanation        `json:"explanation_details,omitempty"`
+	Signals              map[string]float64          `json:"signals,omitempty"`
+	PolicyApplied        *string                     `json:"policy_applied,omitempty"`
+	PolicyVersion        *int                        `json:"policy_version,omitempty"`
+	RequiresReview       bool                        `json:"requires_review"`
+	DetectedLanguage     string                      `json:"detected_language,omitempty"`
+	DetectedLanguages    []string                    `json:"detected_languages,omitempty"`
+	Translated           bool                        `json:"translated,omitempty"`
+	SourceLanguage       string                      `json:"source_language,omitempty"`
+	ChunkCount           int                         `json:"chunk_count,omitempty"`
+	ChunkAttributions    map[string]ChunkAttribution `json:"chunk_attributions,omitempty"`
+	ContextHashes        []string                    `json:"context_hashes,omitempty"`
+	ContextUsed          *bool                       `json:"context_used,omitempty"`
+	FlaggedSpans         []FlaggedSpan               `json:"flagged_spans,omitempty"`
+	NormalizationProfile string                      `json:"normalization_profile,omitempty"`
+	PolicyResolution     *PolicyResolution           `json:"policy_resolution,omitempty"`
+}
+
+// ClassifyRequest asks for content to be classified for a policy as a
+// moderation request would be, without recording anything
+type ClassifyRequest struct {
+	PolicyID uuid.UUID `json:"policy_id" binding:"required"`
+	Content  string    `json:"content" binding:"required"`
+}
+
+// ClassifyResponse is the classification of content for a policy, with the
+// inputs its policy evaluation depends on
+type ClassifyResponse struct {
+	CategoryScores       CategoryScores     `json:"category_scores"`
+	Signals              map[string]float64 `json:"signals,omitempty"`
+	DetectedLanguage     string             `json:"detected_language,omitempty"`
+	DetectedLanguages    []string           `json:"detected_languages,omitempty"`
+	Translated           bool               `json:"translated,omitempty"`
+	SourceLanguage       string             `json:"source_language,omitempty"`
+	EnsembleDisagreement bool               `json:"ensemble_disagreement,omitempty"`
+}
+
+// PolicyResolutionMethod is how the policy applied to a request was chosen
+// Control: POL-003 (Regional Policy Resolution)
+type PolicyResolutionMethod string
+
+const (
+	ResolvedByRequest PolicyResolutionMethod = "request"
+	ResolvedByRoute   PolicyResolutionMethod = "route"
+	ResolvedByDefault PolicyResolutionMethod = "default"
+)
+
+// PolicyResolution reports the policy applied to a request and why: the
+// request's policy_id, the scope route that matched it, or the explicit default
+type PolicyResolution struct {
+	PolicyID uuid.UUID              `json:"policy_id"`
+	Method   PolicyResolutionMethod `json:"method"`
+	RouteID  string                 `json:"route_id,omitempty"`
+	Match    map[string]interface{} `json:"match,omitempty"`
+	Priority int                    `json:"priority,omitempty"`
+}
+
+// FlaggedSpan is a part of the submitted content that drove a category score,
+// for highlighting and redaction. Start and End are character offsets into the
+// original, unnormalized content
+type FlaggedSpan struct {
+	Category string  `json:"category"`
+	Start    int     `json:"start"`
+	End      int     `json:"end"`
+	Text     string  `json:"text"`
+	Score    float64 `json:"score"`
+	Source   string  `json:"source"`
+}
+
+// ChunkAttribution identifies the window of long content that drove a
+// category's aggregated score. Start and End are character offsets into the
+// normalized content
+type ChunkAttribution struct {
+	Index int     `json:"index"`
+	Start int     `json:"start"`
+	End   int     `json:"end"`
+	Score float64 `json:"score"`
 }
 
 // PolicyEvaluationRequest represents a request to evaluate scores against a policy
 type PolicyEvaluationRequest struct {
-	CategoryScores CategoryScores `json:"category_scores" binding:"required"`
-	PolicyID       uuid.UUID      `json:"policy_id" binding:"required"`
+	CategoryScores  CategoryScores         `json:"category_scores" binding:"required"`
+	PolicyID        uuid.UUID              `json:"policy_id" binding:"required"`
+	ContextMetadata map[string]interface{} `json:"context_metadata,omitempty"`
+	TrustScore      *float64               `json:"trust_score,omitempty"`
+	Language        string                 `json:"language,omitempty"`
 }
 
 // PolicyEvaluationResponse represents the result of policy evaluation
@@ -186,10 +477,19 @@ type PolicyEvaluationResponse struct {
 
 // CreatePolicyRequest represents a request to create a new policy
 type CreatePolicyRequest struct {
-	Name       string                  `json:"name" binding:"required"`
-	Thresholds map[string]float64      `json:"thresholds" binding:"required"`
-	Actions    map[string]PolicyAction `json:"actions" binding:"required"`
-	Scope      map[string]interface{}  `json:"scope,omitempty"`
+	Name                  string                  `json:"name" binding:"required"`
+	Thresholds            map[string]float64      `json:"thresholds" binding:"required"`
+	Actions               map[string]PolicyAction `json:"actions" binding:"required"`
+	Scope                 map[string]interface{}  `json:"scope,omitempty"`
+	NormalizationProfile  *string                 `json:"normalization_profile,omitempty"`
+	NormalizationProfiles []NormalizationProfile  `json:"normalization_profiles,omitempty"`
+	Rules                 []PolicyRule            `json:"rules,omitempty"`
+}
+
+// PublishPolicyRequest optionally schedules a publish. Without an effective
+// date, or with one in the past, the policy takes effect immediately
+type PublishPolicyRequest struct {
+	EffectiveDate *time.Time `json:"effective_date,omitempty"`
 }
 
 // ReviewQueueItem represents an item in the review queue
@@ -217,11 +517,12 @@ type SubmitReviewRequest struct {
 
 // AsyncModerationRequest represents a request for asynchronous moderation.
 type AsyncModerationRequest struct {
-	Content         string                 `json:"content" binding:"required"`
-	ContextMetadata map[string]interface{} `json:"context_metadata,omitempty"`
-	Source          string                 `json:"source,omitempty"`
-	PolicyID        *uuid.UUID             `json:"policy_id,omitempty"`
-	CallbackURL     string                 `json:"callback_url" binding:"required"`
+	Content              string                 `json:"content" binding:"required"`
+	ContextMetadata      map[string]interface{} `json:"context_metadata,omitempty"`
+	Source               string                 `json:"source,omitempty"`
+	PolicyID             *uuid.UUID             `json:"policy_id,omitempty"`
+	NormalizationProfile string                 `json:"normalization_profile,omitempty"`
+	CallbackURL          string                 `json:"callback_url" binding:"required"`
 }
 
 // AsyncModerationResponse is returned immediately for async requests.
@@ -245,10 +546,11 @@ type HealthResponse struct {
 type WebhookEventType string
 
 const (
-	EventModerationCompleted WebhookEventType = "moderation.completed"
-	EventReviewRequired      WebhookEventType = "review.required"
-	EventReviewCompleted     WebhookEventType = "review.completed"
-	EventPolicyUpdated       WebhookEventType = "policy.updated"
+	EventModerationCompleted    WebhookEventType = "moderation.completed"
+	EventReviewRequired         WebhookEventType = "review.required"
+	EventReviewCompleted        WebhookEventType = "review.completed"
+	EventPolicyUpdated          WebhookEventType = "policy.updated"
+	EventProviderBreakerChanged WebhookEventType = "provider.breaker_changed"
 )
 
 // WebhookSubscription represents a registered webhook endpoint
@@ -288,6 +590,36 @@ type WebhookPayload struct {
 	Data      interface{}      `json:"data"`
 }
 
+// ProviderBreakerEvent is the webhook payload for a provider circuit breaker state change
+type ProviderBreakerEvent struct {
+	Provider      string    `json:"provider"`
+	PreviousState string    `json:"previous_state"`
+	State         string    `json:"state"`
+	Forced        bool      `json:"forced"`
+	ChangedAt     time.Time `json:"changed_at"`
+}
+
+// PolicyUpdatedEvent is the webhook payload for a policy lifecycle transition,
+// also returned by the transition endpoints. Archived lists the other versions
+// of the policy the transition archived; Restored is the version a rollback
+// put back in force; TestRun is the test suite run that cleared a publish
+type PolicyUpdatedEvent struct {
+	Transition     PolicyTransition `json:"transition"`
+	Policy         Policy           `json:"policy"`
+	PreviousStatus PolicyStatus     `json:"previous_status"`
+	Scheduled      bool             `json:"scheduled"`
+	Archived       []uuid.UUID      `json:"archived,omitempty"`
+	Restored       *Policy          `json:"restored,omitempty"`
+	TestRun        *PolicyTestRun   `json:"test_run,omitempty"`
+	ChangedBy      uuid.UUID        `json:"changed_by"`
+	ChangedAt      time.Time        `json:"changed_at"`
+}
+
+// SetBreakerModeRequest forces a provider circuit breaker open or closed, or back to automatic
+type SetBreakerModeRequest struct {
+	Mode string `json:"mode" binding:"required,oneof=open closed auto"`
+}
+
 // CreateWebhookRequest represents a request to create a webhook subscription
 type CreateWebhookRequest struct {
 	URL         string   `json:"url" binding:"required"`
@@ -304,11 +636,12 @@ type BatchModerationRequest struct {
 
 // BatchModerationItem represents a single item in a batch moderation request
 type BatchModerationItem struct {
-	ID              string                 `json:"id"`
-	Content         string                 `json:"content" binding:"required"`
-	ContextMetadata map[string]interface{} `json:"context_metadata,omitempty"`
-	Source          string                 `json:"source,omitempty"`
-	PolicyID        *uuid.UUID             `json:"policy_id,omitempty"`
+	ID                   string                 `json:"id"`
+	Content              string                 `json:"content" binding:"required"`
+	ContextMetadata      map[string]interface{} `json:"context_metadata,omitempty"`
+	Source               string                 `json:"source,omitempty"`
+	PolicyID             *uuid.UUID             `json:"policy_id,omitempty"`
+	NormalizationProfile string                 `json:"normalization_profile,omitempty"`
 }
 
 // BatchModerationResponse represents the response from batch moderation
@@ -319,12 +652,14 @@ type BatchModerationResponse struct {
 
 // BatchModerationResult represents a single result in a batch moderation response
 type BatchModerationResult struct {
-	ItemID         string              `json:"item_id"`
-	DecisionID     uuid.UUID           `json:"decision_id,omitempty"`
-	Action         PolicyAction        `json:"action,omitempty"`
-	CategoryScores *CategoryScores     `json:"category_scores,omitempty"`
-	RequiresReview bool                `json:"requires_review"`
-	Error          string              `json:"error,omitempty"`
+	ItemID           string             `json:"item_id"`
+	DecisionID       uuid.UUID          `json:"decision_id,omitempty"`
+	Action           PolicyAction       `json:"action,omitempty"`
+	CategoryScores   *CategoryScores    `json:"category_scores,omitempty"`
+	Signals          map[string]float64 `json:"signals,omitempty"`
+	RequiresReview   bool               `json:"requires_review"`
+	PolicyResolution *PolicyResolution  `json:"policy_resolution,omitempty"`
+	Error            string             `json:"error,omitempty"`
 }
 
 // BatchSummary provides aggregate stats for the batch
@@ -336,3 +671,267 @@ type BatchSummary struct {
 	Escalated int `json:"escalated"`
 	Failed    int `json:"failed"`
 }
+
+// --- Policy Simulation Models ---
+// Control: POL-001 (Policy definition and versioning)
+
+// SimulationStatus is the state of a policy simulation
+type SimulationStatus string
+
+const (
+	SimulationRunning   SimulationStatus = "running"
+	SimulationCompleted SimulationStatus = "completed"
+	SimulationFailed    SimulationStatus = "failed"
+)
+
+// SimulatePolicyRequest selects the historical decisions a policy simulation
+// replays: those made in [From, To), oldest first, up to Limit
+type SimulatePolicyRequest struct {
+	From  time.Time `json:"from" binding:"required"`
+	To    time.Time `json:"to" binding:"required"`
+	Limit int       `json:"limit,omitempty"`
+}
+
+// PolicySimulation is a backtest of a policy against historical moderation
+// decisions. Summary is set once the simulation completes
+type PolicySimulation struct {
+	ID            uuid.UUID          `json:"id" db:"id"`
+	PolicyID      uuid.UUID          `json:"policy_id" db:"policy_id"`
+	PolicyVersion int                `json:"policy_version" db:"policy_version"`
+	From          time.Time          `json:"from" db:"range_start"`
+	To            time.Time          `json:"to" db:"range_end"`
+	Limit         int                `json:"limit" db:"decision_limit"`
+	Status        SimulationStatus   `json:"status" db:"status"`
+	Summary       *SimulationSummary `json:"summary,omitempty" db:"summary"`
+	Error         *string            `json:"error,omitempty" db:"error"`
+	CreatedBy     *uuid.UUID         `json:"created_by,omitempty" db:"created_by"`
+	CreatedAt     time.Time          `json:"created_at" db:"created_at"`
+	CompletedAt   *time.Time         `json:"completed_at,omitempty" db:"completed_at"`
+}
+
+// SimulationSummary compares a simulated policy's actions with the actions
+// originally taken. ActionShift is the change in each action's count and
+// Flips counts the flipped decisions by "original->simulated" action
+type SimulationSummary struct {
+	Decisions        int                  `json:"decisions"`
+	Flipped          int                  `json:"flipped"`
+	Errors           int                  `json:"errors"`
+	OriginalActions  map[PolicyAction]int `json:"original_actions"`
+	SimulatedActions map[PolicyAction]int `json:"simulated_actions"`
+	ActionShift      map[PolicyAction]int `json:"action_shift"`
+	Flips            map[string]int       `json:"flips"`
+	Review           ReviewAgreement      `json:"review"`
+}
+
+// ReviewAgreement measures how often the original and simulated actions agree
+// with human reviewers on whether content is harmful, over the reviewed
+// decisions with a conclusive review (escalations are excluded)
+type ReviewAgreement struct {
+	Reviewed           int     `json:"reviewed"`
+	OriginalAgreed     int     `json:"original_agreed"`
+	SimulatedAgreed    int     `json:"simulated_agreed"`
+	OriginalAgreement  float64 `json:"original_agreement"`
+	SimulatedAgreement float64 `json:"simulated_agreement"`
+}
+
+// SimulationFlip is a historical decision whose action the simulated policy changes
+type SimulationFlip struct {
+	DecisionID      uuid.UUID         `json:"decision_id" db:"decision_id"`
+	DecidedAt       time.Time         `json:"decided_at" db:"decided_at"`
+	OriginalAction  PolicyAction      `json:"original_action" db:"original_action"`
+	SimulatedAction PolicyAction      `json:"simulated_action" db:"simulated_action"`
+	TriggeredRules  []string          `json:"triggered_rules" db:"triggered_rules"`
+	ReviewAction    *ReviewActionType `json:"review_action,omitempty" db:"review_action"`
+}
+
+// SimulationFlipPage is a page of a simulation's flipped decisions, oldest first
+type SimulationFlipPage struct {
+	Flips  []SimulationFlip `json:"flips"`
+	Total  int              `json:"total"`
+	Limit  int              `json:"limit"`
+	Offset int              `json:"offset"`
+}
+
+// --- Policy Test Suite Models ---
+// Control: POL-001 (Policy definition and versioning)
+
+// PolicyTestCase is a golden example attached to a policy by name, so every
+// version of the policy is tested against it. A case is either raw Content,
+// which is classified, or fixed Scores, evaluated with its ContextMetadata
+type PolicyTestCase struct {
+	ID              uuid.UUID              `json:"id" db:"id"`
+	PolicyName      string                 `json:"policy_name" db:"policy_name"`
+	Name            string                 `json:"name" db:"name"`
+	Content         *string                `json:"content,omitempty" db:"content"`
+	Scores          *CategoryScores        `json:"scores,omitempty" db:"scores"`
+	ContextMetadata map[string]interface{} `json:"context_metadata,omitempty" db:"context_metadata"`
+	ExpectedAction  PolicyAction           `json:"expected_action" db:"expected_action"`
+	CreatedBy       *uuid.UUID             `json:"created_by,omitempty" db:"created_by"`
+	CreatedAt       time.Time              `json:"created_at" db:"created_at"`
+}
+
+// CreatePolicyTestCaseRequest attaches a test case to a policy; exactly one
+// of Content and Scores is required
+type CreatePolicyTestCaseRequest struct {
+	Name            string                 `json:"name" binding:"required"`
+	Content         *string                `json:"content,omitempty"`
+	Scores          *CategoryScores        `json:"scores,omitempty"`
+	ContextMetadata map[string]interface{} `json:"context_metadata,omitempty"`
+	ExpectedAction  PolicyAction           `json:"expected_action" binding:"required"`
+}
+
+// PolicyTestTrigger is what started a policy test suite run
+type PolicyTestTrigger string
+
+const (
+	TestTriggerManual  PolicyTestTrigger = "manual"
+	TestTriggerPublish PolicyTestTrigger = "publish"
+)
+
+// PolicyTestRun is the result of running a policy's test suite against one
+// version of it. The run passed if every case did
+type PolicyTestRun struct {
+	ID            uuid.UUID          `json:"id" db:"id"`
+	PolicyID      uuid.UUID          `json:"policy_id" db:"policy_id"`
+	PolicyVersion int                `json:"policy_version" db:"policy_version"`
+	Trigger       PolicyTestTrigger  `json:"trigger" db:"trigger"`
+	Passed        bool               `json:"passed" db:"passed"`
+	Total         int                `json:"total" db:"total"`
+	Failed        int                `json:"failed" db:"failed"`
+	Results       []PolicyTestResult `json:"results" db:"results"`
+	CreatedBy     *uuid.UUID         `json:"created_by,omitempty" db:"created_by"`
+	CreatedAt     time.Time          `json:"created_at" db:"created_at"`
+}
+
+// PolicyTestResult is the outcome of one test case. Error is set, and the
+// case failed, if it could not be classified or evaluated
+type PolicyTestResult struct {
+	CaseID         uuid.UUID     `json:"case_id"`
+	Name           string        `json:"name"`
+	ExpectedAction PolicyAction  `json:"expected_action"`
+	Action         *PolicyAction `json:"action,omitempty"`
+	TriggeredRules []string      `json:"triggered_rules,omitempty"`
+	Passed         bool          `json:"passed"`
+	Error          string        `json:"error,omitempty"`
+}
+
+// --- Lexicon Models ---
+// Control: MOD-005 (Multi-Provider Classification Orchestration)
+
+// LexiconMatchType determines how a lexicon entry pattern is matched against text.
+type LexiconMatchType string
+
+const (
+	LexiconMatchTerm  LexiconMatchType = "term"
+	LexiconMatchRegex LexiconMatchType = "regex"
+)
+
+// LexiconList is a versioned, weighted list of patterns contributing to one category
+type LexiconList struct {
+	ID          uuid.UUID      `json:"id" db:"id"`
+	Name        string         `json:"name" db:"name"`
+	Version     int            `json:"version" db:"version"`
+	Category    string         `json:"category" db:"category"`
+	Description *string        `json:"description,omitempty" db:"description"`
+	Status      PolicyStatus   `json:"status" db:"status"`
+	Entries     []LexiconEntry `json:"entries,omitempty"`
+	CreatedAt   time.Time      `json:"created_at" db:"created_at"`
+	CreatedBy   *uuid.UUID     `json:"created_by,omitempty" db:"created_by"`
+}
+
+// LexiconEntry is a single term or regular expression in a lexicon list
+type LexiconEntry struct {
+	ID        uuid.UUID        `json:"id" db:"id"`
+	Pattern   string           `json:"pattern" db:"pattern"`
+	MatchType LexiconMatchType `json:"match_type" db:"match_type"`
+	Weight    float64          `json:"weight" db:"weight"`
+}
+
+// CreateLexiconRequest represents a request to create a new lexicon list version
+type CreateLexiconRequest struct {
+	Name        string                      `json:"name" binding:"required"`
+	Category    string                      `json:"category" binding:"required"`
+	Description string                      `json:"description,omitempty"`
+	Entries     []CreateLexiconEntryRequest `json:"entries" binding:"required"`
+}
+
+// CreateLexiconEntryRequest represents a single entry in a CreateLexiconRequest
+type CreateLexiconEntryRequest struct {
+	Pattern   string           `json:"pattern" binding:"required"`
+	MatchType LexiconMatchType `json:"match_type,omitempty"`
+	Weight    *float64         `json:"weight,omitempty"`
+}
+
+// --- Shadow Classification Models ---
+// Control: MOD-005 (Multi-Provider Classification Orchestration)
+
+// ShadowClassification records a shadow provider's scores for a live decision
+type ShadowClassification struct {
+	ID             uuid.UUID       `json:"id" db:"id"`
+	DecisionID     uuid.UUID       `json:"decision_id" db:"decision_id"`
+	ProviderName   string          `json:"provider_name" db:"provider_name"`
+	ModelName      string          `json:"model_name,omitempty" db:"model_name"`
+	ModelVersion   string          `json:"model_version,omitempty" db:"model_version"`
+	CategoryScores *CategoryScores `json:"category_scores,omitempty" db:"category_scores"`
+	LatencyMs      int             `json:"latency_ms" db:"latency_ms"`
+	Error          *string         `json:"error,omitempty" db:"error"`
+	CreatedAt      time.Time       `json:"created_at" db:"created_at"`
+}
+
+// --- Calibration Models ---
+// Control: MOD-005 (Multi-Provider Classification Orchestration)
+
+// CalibrationVersion is a versioned set of score calibrations fitted from review outcomes
+type CalibrationVersion struct {
+	ID          uuid.UUID       `json:"id" db:"id"`
+	Version     int             `json:"version" db:"version"`
+	Method      string          `json:"method" db:"method"`
+	Params      json.RawMessage `json:"params" db:"params"`
+	SampleCount int             `json:"sample_count" db:"sample_count"`
+	WindowDays  int             `json:"window_days" db:"window_days"`
+	Active      bool            `json:"active" db:"active"`
+	CreatedBy   *uuid.UUID      `json:"created_by,omitempty" db:"created_by"`
+	CreatedAt   time.Time       `json:"created_at" db:"created_at"`
+	ActivatedAt *time.Time      `json:"activated_at,omitempty" db:"activated_at"`
+}
+
+// FitCalibrationRequest represents a request to fit a new calibration version
+type FitCalibrationRequest struct {
+	Method     string `json:"method" binding:"required,oneof=platt isotonic"`
+	WindowDays int    `json:"window_days,omitempty"`
+	MinSamples int    `json:"min_samples,omitempty"`
+	Activate   bool   `json:"activate,omitempty"`
+}
+
+// --- Prompt Template Models ---
+// Control: POL-001 (Policy management)
+
+// PromptExample is a few-shot example shown to the LLM with its expected scores
+type PromptExample struct {
+	Text      string         `json:"text" binding:"required"`
+	Scores    CategoryScores `json:"scores"`
+	Rationale string         `json:"rationale,omitempty"`
+}
+
+// PromptTemplate is a versioned LLM classification prompt attached to a policy
+type PromptTemplate struct {
+	ID                  uuid.UUID         `json:"id" db:"id"`
+	PolicyID            uuid.UUID         `json:"policy_id" db:"policy_id"`
+	Version             int               `json:"version" db:"version"`
+	Template            string            `json:"template" db:"template"`
+	Guidelines          string            `json:"guidelines,omitempty" db:"guidelines"`
+	CategoryDefinitions map[string]string `json:"category_definitions,omitempty" db:"category_definitions"`
+	Examples            []PromptExample   `json:"examples,omitempty" db:"examples"`
+	Status              PolicyStatus      `json:"status" db:"status"`
+	CreatedAt           time.Time         `json:"created_at" db:"created_at"`
+	CreatedBy           *uuid.UUID        `json:"created_by,omitempty" db:"created_by"`
+}
+
+// CreatePromptTemplateRequest represents a request to create a prompt template version.
+// An empty template uses the built-in classification prompt.
+type CreatePromptTemplateRequest struct {
+	Template            string            `json:"template,omitempty"`
+	Guidelines          string            `json:"guidelines,omitempty"`
+	CategoryDefinitions map[string]string `json:"category_definitions,omitempty"`
+	Examples            []PromptExample   `json:"examples,omitempty"`
+}
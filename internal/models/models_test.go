@@ -0,0 +1,44 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestCategoryScores_MapRoundTrip(t *testing.T) {
+	scores := CategoryScores{
+		Toxicity: 0.1, Hate: 0.2, Harassment: 0.3, SexualContent: 0.4, Violence: 0.5,
+		Profanity: 0.6, SelfHarm: 0.7, Spam: 0.8, PII: 0.9,
+	}
+
+	m := scores.ToMap()
+	if len(m) != len(Categories) {
+		t.Fatalf("ToMap has %d categories, Categories lists %d", len(m), len(Categories))
+	}
+
+	// Category names match the JSON field names
+	var byJSON map[string]float64
+	data, _ := json.Marshal(scores)
+	if err := json.Unmarshal(data, &byJSON); err != nil {
+		t.Fatalf("unmarshal failed: %v", err)
+	}
+	for _, cat := range Categories {
+		if m[cat] != byJSON[cat] || m[cat] == 0 {
+			t.Errorf("category %s: ToMap %v, JSON %v", cat, m[cat], byJSON[cat])
+		}
+	}
+
+	var back CategoryScores
+	back.FromMap(m)
+	if back != scores {
+		t.Errorf("FromMap(ToMap()) = %+v, want %+v", back, scores)
+	}
+}
+
+func TestCategoryScores_FromMapKeepsUnnamed(t *testing.T) {
+	scores := CategoryScores{Hate: 0.5, Spam: 0.2}
+	scores.FromMap(map[string]float64{"spam": 0.9, "unknown": 1})
+	if scores.Hate != 0.5 || scores.Spam != 0.9 {
+		t.Errorf("got %+v, want hate 0.5 and spam 0.9", scores)
+	}
+}
@@ -0,0 +1,125 @@
+package normalizer
+
+import (
+	"strings"
+)
+
+// Alignment maps byte offsets in normalized text back to the original text,
+// so that spans found in the normalized text can be highlighted or redacted
+// in what the user actually wrote.
+type Alignment struct {
+	starts []int // original offset where the source of each normalized byte begins
+	ends   []int // original offset where the source of each normalized byte ends
+	length int   // length of the original text
+}
+
+// Original returns the byte range of the original text that normalized[start:end]
+// was produced from. Offsets are clamped to the normalized text. A span that
+// covers part of a rewritten word ("h4t3" → "hate") maps to the whole word.
+// A nil Alignment maps offsets to themselves.
+func (a *Alignment) Original(start, end int) (int, int) {
+	if a == nil {
+		return start, end
+	}
+	n := len(a.starts)
+	start = min(max(start, 0), n)
+	end = min(max(end, start), n)
+	if start == n {
+		return a.length, a.length
+	}
+	if start == end {
+		return a.starts[start], a.starts[start]
+	}
+	return a.starts[start], max(a.ends[end-1], a.starts[start])
+}
+
+// output collects the text a normalization stage writes. When tracking, it
+// also records the input byte range each output byte was produced from.
+type output struct {
+	b      strings.Builder
+	track  bool
+	starts []int
+	ends   []int
+}
+
+func newOutput(size int, track bool) *output {
+	o := &output{track: track}
+	o.b.Grow(size)
+	if track {
+		o.starts = make([]int, 0, size)
+		o.ends = make([]int, 0, size)
+	}
+	return o
+}
+
+// write appends s, produced from input[start:end].
+func (o *output) write(s string, start, end int) {
+	o.b.WriteString(s)
+	if !o.track {
+		return
+	}
+	for range len(s) {
+		o.starts = append(o.starts, start)
+		o.ends = append(o.ends, end)
+	}
+}
+
+// writeRune appends r, produced from input[start:end].
+func (o *output) writeRune(r rune, start, end int) {
+	if !o.track {
+		o.b.WriteRune(r)
+		return
+	}
+	o.write(string(r), start, end)
+}
+
+// copy appends s, which appears unchanged at input[start:].
+func (o *output) copy(s string, start int) {
+	o.b.WriteString(s)
+	if !o.track {
+		return
+	}
+	for i := range len(s) {
+		o.starts = append(o.starts, start+i)
+		o.ends = append(o.ends, start+i+1)
+	}
+}
+
+func (o *output) String() string {
+	return o.b.String()
+}
+
+// stage is one normalization step, writing its result for text to out.
+type stage func(text string, out *output)
+
+// aligner runs normalization stages, composing their alignments when tracking.
+type aligner struct {
+	track     bool
+	alignment *Alignment
+}
+
+func newAligner(text string, track bool) *aligner {
+	a := &aligner{track: track}
+	if track {
+		identity := newOutput(len(text), true)
+		identity.copy(text, 0)
+		a.alignment = &Alignment{starts: identity.starts, ends: identity.ends, length: len(text)}
+	}
+	return a
+}
+
+// apply runs a stage on text and returns its output.
+func (a *aligner) apply(text string, s stage) string {
+	out := newOutput(len(text), a.track)
+	s(text, out)
+	if a.track {
+		prev := a.alignment
+		for i := range out.starts {
+			// Every output byte has a non-empty source range in the stage input
+			out.starts[i] = prev.starts[out.starts[i]]
+			out.ends[i] = prev.ends[out.ends[i]-1]
+		}
+		a.alignment = &Alignment{starts: out.starts, ends: out.ends, length: prev.length}
+	}
+	return out.String()
+}
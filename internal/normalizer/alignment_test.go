@@ -0,0 +1,77 @@
+package normalizer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNormalizeWithAlignment_Original(t *testing.T) {
+	n := New()
+	tests := []struct {
+		name  string
+		input string
+		find  string // in the normalized text
+		want  string // in the original text
+	}{
+		{"unchanged", "you are an idiot", "idiot", "idiot"},
+		{"collapsed whitespace", "  you   are  an idiot ", "are an", "are  an"},
+		{"zero-width", "you i\u200Bd\u200Biot", "idiot", "i\u200Bd\u200Biot"},
+		{"fullwidth", "you \uFF49\uFF44\uFF49\uFF4F\uFF54", "idiot", "\uFF49\uFF44\uFF49\uFF4F\uFF54"},
+		{"homoglyph", "you \u0456d\u0456ot", "idiot", "\u0456d\u0456ot"},
+		{"leetspeak", "pure h4t3 here", "hate", "h4t3"},
+		{"part of a rewritten word", "pure h4t3 here", "at", "h4t3"},
+		{"separators", "well f.u.c.k off", "fuck", "f.u.c.k"},
+		{"spelled out", "well f u c k off", "fuck", "f u c k"},
+		{"spelled out with punctuation", "well f u c k!", "fuck!", "f u c k!"},
+		{"elongation", "so fuuuuuck", "fuck", "fuuuuuck"},
+		{"zalgo", "z\u0310\u0311\u0312ap it", "zap", "z\u0310\u0311\u0312ap"},
+		{"after code", "run `x1` now, h4t3", "hate", "h4t3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			normalized, alignment := n.NormalizeWithAlignment(tt.input)
+			if normalized != n.Normalize(tt.input) {
+				t.Fatalf("NormalizeWithAlignment(%q) = %q, want %q", tt.input, normalized, n.Normalize(tt.input))
+			}
+			start := strings.Index(normalized, tt.find)
+			if start < 0 {
+				t.Fatalf("%q not found in %q", tt.find, normalized)
+			}
+			s, e := alignment.Original(start, start+len(tt.find))
+			if got := tt.input[s:e]; got != tt.want {
+				t.Errorf("Original(%q) = %q, want %q", tt.find, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAlignment_Clamp(t *testing.T) {
+	n := New()
+	normalized, alignment := n.NormalizeWithAlignment(" hello ")
+	tests := []struct {
+		name       string
+		start, end int
+		wantStart  int
+		wantEnd    int
+	}{
+		{"whole", 0, len(normalized), 1, 6},
+		{"empty", 2, 2, 3, 3},
+		{"past end", 10, 20, 7, 7},
+		{"negative", -5, 2, 1, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, e := alignment.Original(tt.start, tt.end)
+			if s != tt.wantStart || e != tt.wantEnd {
+				t.Errorf("Original(%d, %d) = (%d, %d), want (%d, %d)", tt.start, tt.end, s, e, tt.wantStart, tt.wantEnd)
+			}
+		})
+	}
+
+	var none *Alignment
+	if s, e := none.Original(2, 4); s != 2 || e != 4 {
+		t.Errorf("nil Original(2, 4) = (%d, %d), want (2, 4)", s, e)
+	}
+}
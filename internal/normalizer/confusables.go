@@ -0,0 +1,231 @@
+package normalizer
+
+import (
+	"bufio"
+	_ "embed"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// confusablesData is the Unicode UTS #39 confusables.txt, whose version is
+// noted in its header. scripts/update-confusables.sh replaces it with the
+// latest Unicode release.
+//
+//go:embed data/confusables.txt
+var confusablesData string
+
+// parseConfusables reads a confusables.txt table and keeps the mappings from
+// a single non-ASCII rune to ASCII letters, which are the ones that can
+// disguise Latin text. UTS #39 maps I-like letters to the prototype "l", which
+// suits comparing skeletons but not classifying text, so capital sources that
+// map to "l" are mapped to "I" instead.
+func parseConfusables(data string) map[rune]string {
+	mapping := make(map[rune]string)
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimPrefix(scanner.Text(), "\uFEFF")
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Split(line, ";")
+		if len(fields) < 2 {
+			continue
+		}
+
+		source, ok := decodeCodePoints(fields[0])
+		if !ok || utf8.RuneCountInString(source) != 1 {
+			continue
+		}
+		target, ok := decodeCodePoints(fields[1])
+		if !ok || !isASCIILetters(target) {
+			continue
+		}
+
+		r, _ := utf8.DecodeRuneInString(source)
+		if r < utf8.RuneSelf {
+			continue
+		}
+		if target == "l" && unicode.IsUpper(r) {
+			target = "I"
+		}
+		mapping[r] = target
+	}
+	return mapping
+}
+
+// decodeCodePoints decodes space-separated hex code points, e.g. "0072 006E".
+func decodeCodePoints(field string) (string, bool) {
+	var b strings.Builder
+	for _, hex := range strings.Fields(field) {
+		cp, err := strconv.ParseUint(hex, 16, 32)
+		if err != nil || !utf8.ValidRune(rune(cp)) {
+			return "", false
+		}
+		b.WriteRune(rune(cp))
+	}
+	return b.String(), b.Len() > 0
+}
+
+func isASCIILetters(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z') {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// commonScripts are checked before the full script table, which is a map.
+var commonScripts = []string{"Cyrillic", "Greek", "Arabic", "Han", "Hiragana", "Katakana", "Hangul", "Devanagari", "Hebrew", "Thai", "Armenian"}
+
+// scriptOf returns the Unicode script of r, or "" for runes shared between
+// scripts (Common and Inherited, e.g. digits, punctuation and combining marks).
+func scriptOf(r rune) string {
+	if r < utf8.RuneSelf {
+		if unicode.IsLetter(r) {
+			return "Latin"
+		}
+		return ""
+	}
+	if unicode.Is(unicode.Common, r) || unicode.Is(unicode.Inherited, r) {
+		return ""
+	}
+	if unicode.Is(unicode.Latin, r) {
+		return "Latin"
+	}
+	for _, name := range commonScripts {
+		if unicode.Is(unicode.Scripts[name], r) {
+			return name
+		}
+	}
+	for name, table := range unicode.Scripts {
+		if unicode.Is(table, r) {
+			return name
+		}
+	}
+	return ""
+}
+
+// word is a run of non-space text with the scripts of its letters.
+type word struct {
+	start, end int // byte offsets
+	scripts    map[string]bool
+	foldable   bool // every non-Latin letter has a Latin lookalike
+}
+
+// singleScript returns the word's script if it has exactly one.
+func (w word) singleScript() string {
+	if len(w.scripts) != 1 {
+		return ""
+	}
+	for script := range w.scripts {
+		return script
+	}
+	return ""
+}
+
+// foldConfusables maps lookalike letters to Latin where they disguise Latin
+// text, while leaving genuine non-Latin text alone:
+//
+//   - Non-ASCII Latin lookalikes (e.g. dotless "ı" or long "ſ") are always mapped.
+//   - In a word mixing scripts (e.g. Cyrillic "і" in "іdiot"), the non-Latin
+//     letters are mapped if all of them have Latin lookalikes.
+//   - A word written in a single non-Latin script is mapped only if every
+//     letter has a Latin lookalike and the script is not genuinely used
+//     elsewhere in the text, i.e. no word in it has a letter without one.
+//     This keeps Russian "хор" intact in Russian text while "ѕһіt" in English
+//     text is folded.
+//
+// With foldAllScripts set, every lookalike in every word is mapped.
+func (n *Normalizer) foldConfusables(text string, out *output) {
+	var words []word
+	genuine := make(map[string]bool)
+	start := -1
+	for i, r := range text + " " {
+		if !unicode.IsSpace(r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start < 0 {
+			continue
+		}
+		w := n.scanWord(text, start, i)
+		if script := w.singleScript(); script != "" && script != "Latin" && !w.foldable {
+			genuine[script] = true
+		}
+		words = append(words, w)
+		start = -1
+	}
+
+	last := 0
+	for _, w := range words {
+		foldOther := w.foldable && len(w.scripts) > 1
+		if script := w.singleScript(); script != "" && script != "Latin" {
+			foldOther = w.foldable && !genuine[script]
+		}
+		if n.foldAllScripts {
+			foldOther = true
+		}
+		out.copy(text[last:w.start], last)
+		n.writeFolded(out, text, w, foldOther)
+		last = w.end
+	}
+	out.copy(text[last:], last)
+}
+
+func (n *Normalizer) scanWord(text string, start, end int) word {
+	w := word{start: start, end: end, scripts: make(map[string]bool), foldable: true}
+	for _, r := range text[start:end] {
+		script := scriptOf(r)
+		if script == "" {
+			continue
+		}
+		w.scripts[script] = true
+		if script == "Latin" {
+			continue
+		}
+		if _, ok := n.confusables[r]; !ok {
+			w.foldable = false
+		}
+	}
+	return w
+}
+
+// writeFolded writes the word with Latin lookalikes mapped, and lookalikes
+// from other scripts mapped too when foldOther is set.
+func (n *Normalizer) writeFolded(out *output, text string, w word, foldOther bool) {
+	for i, r := range text[w.start:w.end] {
+		i += w.start
+		end := i + runeLen(text, i)
+		replacement, ok := n.confusables[r]
+		if ok && (foldOther || scriptOf(r) == "Latin") {
+			out.write(replacement, i, end)
+		} else {
+			out.writeRune(r, i, end)
+		}
+	}
+}
+
+// IsMixedScript reports whether a word mixes letters from more than one
+// script, the UTS #39 signal that it may be spoofed. Common and Inherited
+// runes such as digits, punctuation and combining marks are ignored.
+func IsMixedScript(s string) bool {
+	first := ""
+	for _, r := range s {
+		script := scriptOf(r)
+		if script == "" {
+			continue
+		}
+		if first == "" {
+			first = script
+		} else if script != first {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,156 @@
+package normalizer
+
+import (
+	"testing"
+)
+
+func TestParseConfusables(t *testing.T) {
+	data := "\uFEFF# confusables.txt\n" +
+		"0430 ;\t0061 ;\tMA\t# ( а → a ) CYRILLIC SMALL LETTER A → LATIN SMALL LETTER A\n" +
+		"0406 ;\t006C ;\tMA\t# ( І → l ) CYRILLIC CAPITAL LETTER BYELORUSSIAN-UKRAINIAN I → LATIN SMALL LETTER L\n" +
+		"04CF ;\t006C ;\tMA\t# ( ӏ → l ) CYRILLIC SMALL LETTER PALOCHKA → LATIN SMALL LETTER L\n" +
+		"217F ;\t006D ;\tMA\t# ( ⅿ → m ) SMALL ROMAN NUMERAL ONE THOUSAND → LATIN SMALL LETTER M\n" +
+		"0031 ;\t006C ;\tMA\t# ( 1 → l ) DIGIT ONE → LATIN SMALL LETTER L\n" +
+		"03BA ;\t0138 ;\tMA\t# ( κ → ĸ ) GREEK SMALL LETTER KAPPA → LATIN SMALL LETTER KRA\n" +
+		"01C3 ;\t0021 ;\tMA\t# ( ǃ → ! ) LATIN LETTER RETROFLEX CLICK → EXCLAMATION MARK\n" +
+		"33A1 ;\t006D 00B2 ;\tMA\t# ( ㎡ → m² )\n" +
+		"2474 ;\t0028 0031 0029 ;\tMA\t# ( ⑴ → (1) )\n" +
+		"not a mapping\n"
+
+	got := parseConfusables(data)
+	want := map[rune]string{'а': "a", 'І': "I", 'ӏ': "l", 'ⅿ': "m"}
+	if len(got) != len(want) {
+		t.Errorf("got %d mappings %v, want %v", len(got), got, want)
+	}
+	for r, target := range want {
+		if got[r] != target {
+			t.Errorf("%q maps to %q, want %q", r, got[r], target)
+		}
+	}
+}
+
+func TestEmbeddedConfusables(t *testing.T) {
+	n := New()
+	for _, r := range []rune{'Ꭺ', 'օ', 'ꓮ', 'ᗅ', 'ⲟ', 'ɑ'} {
+		if _, ok := n.confusables[r]; !ok {
+			t.Errorf("embedded table has no mapping for %q (U+%04X)", r, r)
+		}
+	}
+}
+
+func TestEmbeddedConfusables_ScriptCoverage(t *testing.T) {
+	n := New()
+	// Lookalikes missing from the hand-curated table the Unicode table replaced
+	tests := []struct {
+		script string
+		runes  []rune
+	}{
+		{"armenian", []rune{'Տ', 'ա', 'գ', 'ռ', 'ք'}},
+		{"cherokee", []rune{'Ꭹ', 'Ꮍ', 'Ꮒ', 'Ꮢ', 'Ꮥ', 'Ꮷ', 'Ᏻ', 'ꭵ'}},
+		{"lisu", []rune{'ꓒ'}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.script, func(t *testing.T) {
+			for _, r := range tt.runes {
+				if _, ok := n.confusables[r]; !ok {
+					t.Errorf("embedded table has no mapping for %q (U+%04X)", r, r)
+				}
+			}
+		})
+	}
+}
+
+func TestNormalize_Confusables(t *testing.T) {
+	n := New()
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"cyrillic letter in latin word", "you іdiot", "you idiot"},
+		{"cyrillic-only lookalike word in english text", "what ѕһіt", "what shit"},
+		{"armenian", "gօ away", "go away"},
+		{"cherokee", "ᎪᏞᎪᎷ", "ALAM"},
+		{"lisu", "ꓧꓰꓡꓡꓳ there", "HELLO there"},
+		{"latin lookalikes", "ıdıot", "idiot"},
+		{"mathematical alphanumerics", "\U0001d41d\U0001d42e\U0001d426\U0001d41b", "dumb"},
+		{"genuine russian untouched", "хор поет", "хор поет"},
+		{"mixed word with genuine cyrillic untouched", "Москваcity", "Москваcity"},
+		{"greek and cyrillic lookalikes mixed", "ρаy", "pay"},
+		{"japanese untouched", "こんにちは", "こんにちは"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := n.Normalize(tt.in); got != tt.want {
+				t.Errorf("Normalize(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalize_FormatCharacters(t *testing.T) {
+	n := New()
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"soft hyphen", "id\u00ADiot", "idiot"},
+		{"bidi override", "\u202Eidiot\u202C", "idiot"},
+		{"mongolian vowel separator", "id\u180Eiot", "idiot"},
+		{"tag characters", "idiot\U000e0069\U000e0064", "idiot"},
+		{"invisible times", "id\u2062iot", "idiot"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := n.Normalize(tt.in); got != tt.want {
+				t.Errorf("Normalize(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalize_Zalgo(t *testing.T) {
+	n := New()
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"stacked marks stripped", "h\u0336\u0321\u034E\u0353e\u0338\u0317\u0359\u032Al\u0335l\u0334\u0348\u0325\u0316o", "hello"},
+		{"strikethrough overlay stripped", "i\u0336d\u0336i\u0336o\u0336t\u0336", "idiot"},
+		{"single accent kept", "café", "café"},
+		{"two uncomposable marks kept", "a\u0330\u0331", "a\u0330\u0331"},
+		{"devanagari signs kept", "क्ष", "क्ष"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := n.Normalize(tt.in); got != tt.want {
+				t.Errorf("Normalize(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsMixedScript(t *testing.T) {
+	tests := []struct {
+		in   string
+		want bool
+	}{
+		{"idiot", false},
+		{"іdiot", true},
+		{"хор", false},
+		{"h3llo!", false},
+		{"café", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := IsMixedScript(tt.in); got != tt.want {
+			t.Errorf("IsMixedScript(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
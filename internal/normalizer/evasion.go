@@ -0,0 +1,316 @@
+package normalizer
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Tokens that are not words and must not be decoded: leetspeak would turn
+// "555-1234" into "sss-l2e4".
+var (
+	urlPattern     = regexp.MustCompile(`(?i)^((https?|ftp)://|www\.)\S+$|^[a-z0-9-]+(\.[a-z0-9-]+)*\.(com|net|org|edu|gov|io|co|uk|de|fr|ru|jp|cn|in|br|au|ca|us|me|ly|tv|app|dev|info|biz)(/\S*)?$`)
+	emailPattern   = regexp.MustCompile(`(?i)^[^@\s]+@[^@\s]+\.[a-z]{2,}$`)
+	numberPattern  = regexp.MustCompile(`(?i)^[+\-(#]?[$€£¥₹]?\d[\d.,:/\-()%x×]*(st|nd|rd|th|s|k|m|bn|am|pm|px|ms|kg|km|gb|mb|h|min)?$`)
+	mentionPattern = regexp.MustCompile(`^@\w+$`)
+	codePattern    = regexp.MustCompile(`^[A-Za-z_$][\w.$]*\(.*\)[;.,]?$|==|!=|=>|->|::|&&|</|/>|[{};]|^~?/[\w.\-/]+$|^\w+\.(go|py|js|ts|java|rb|rs|sh|c|h|cpp|json|yaml|yml|md|txt|html|css)$|^[A-Za-z]\w*(_\w+)+$`)
+)
+
+// trailingDigits matches a version or counter suffix such as "covid19" or
+// "win10", which is left alone; a single trailing digit ("h3ll0") is decoded.
+var trailingDigits = regexp.MustCompile(`[A-Za-z]\d{2,}$`)
+
+// Word separators collapsed when they split a word into single letters, as
+// in "f.u.c.k" or "f-u-c-k".
+const wordSeparators = ".-_*·•~+"
+
+// Letters that are commonly doubled in English. Elongated runs of these keep
+// two ("goooood" → "good"); other letters keep one ("fuuuuck" → "fuck").
+const doublingLetters = "bcdeflmnoprstzBCDEFLMNOPRSTZ"
+
+// minSpelledRun is the fewest single letters, one per word, that are joined
+// into a word: "f u c k" → "fuck".
+const minSpelledRun = 3
+
+// standaloneLetters are single letters that are words of their own ("a", "I")
+// or chat shorthand ("u r"). A run made only of them is not joined.
+const standaloneLetters = "aiuryoAIURYO"
+
+// decodeEvasion undoes spelling tricks used to slip words past classifiers:
+// separators inside words, letters spelled out one per word, elongation and
+// single- and multi-character leetspeak. Tokens that look like numbers, URLs,
+// emails, @mentions or code, and text inside backticks, are left unchanged.
+func (n *Normalizer) decodeEvasion(text string, out *output) {
+	offset := 0
+	for i, part := range splitCode(text) {
+		// Odd parts are code spans
+		if i%2 == 1 {
+			out.copy(part, offset)
+		} else {
+			n.decodeProse(part, offset, out)
+		}
+		offset += len(part)
+	}
+}
+
+// splitCode splits text around code spans delimited by backticks (inline or
+// fenced). Even-indexed parts are prose and odd-indexed parts are code,
+// including their backticks. An unterminated backtick starts no span.
+func splitCode(text string) []string {
+	var parts []string
+	for {
+		start := strings.IndexByte(text, '`')
+		if start < 0 {
+			break
+		}
+		fence := "`"
+		if strings.HasPrefix(text[start:], "```") {
+			fence = "```"
+		}
+		end := strings.Index(text[start+len(fence):], fence)
+		if end < 0 {
+			break
+		}
+		end += start + 2*len(fence)
+		parts = append(parts, text[:start], text[start:end])
+		text = text[end:]
+	}
+	return append(parts, text)
+}
+
+// token is a whitespace-delimited word split into surrounding punctuation and
+// the core that is decoded.
+type token struct {
+	pos    int    // offset of the whitespace before the token
+	space  string // whitespace before the token
+	lead   string
+	raw    string // core before decoding
+	core   string
+	trail  string
+	letter bool // the decoded core is a single letter
+}
+
+// coreStart returns the offset of the token's core.
+func (t token) coreStart() int {
+	return t.pos + len(t.space) + len(t.lead)
+}
+
+// write writes the token, mapping a rewritten core to the whole original core.
+func (t token) write(out *output) {
+	start := t.coreStart()
+	out.copy(t.space+t.lead, t.pos)
+	if t.core == t.raw {
+		out.copy(t.core, start)
+	} else {
+		out.write(t.core, start, start+len(t.raw))
+	}
+	out.copy(t.trail, start+len(t.raw))
+}
+
+// decodeProse decodes each token of text, which starts at offset in the stage
+// input, and joins letters spelled out one per word.
+func (n *Normalizer) decodeProse(text string, offset int, out *output) {
+	var tokens []token
+	rest := text
+	for rest != "" {
+		pos := offset + len(text) - len(rest)
+		i := strings.IndexFunc(rest, func(r rune) bool { return !unicode.IsSpace(r) })
+		if i < 0 {
+			tokens = append(tokens, token{pos: pos, space: rest})
+			break
+		}
+		j := strings.IndexFunc(rest[i:], unicode.IsSpace)
+		if j < 0 {
+			j = len(rest) - i
+		}
+		t := splitToken(rest[i : i+j])
+		t.pos = pos
+		t.space = rest[:i]
+		t.raw = t.core
+		t.core = n.decodeToken(t.core)
+		t.letter = t.lead == "" && utf8.RuneCountInString(t.core) == 1 && isLetter(t.core)
+		tokens = append(tokens, t)
+		rest = rest[i+j:]
+	}
+
+	for i := 0; i < len(tokens); i++ {
+		// Join a run of single letters, keeping the punctuation after the last
+		run := i
+		for run < len(tokens) && tokens[run].letter && (run == i || tokens[run-1].trail == "") {
+			run++
+		}
+		if run-i >= minSpelledRun && !allStandalone(tokens[i:run]) {
+			out.copy(tokens[i].space, tokens[i].pos)
+			for _, t := range tokens[i:run] {
+				start := t.coreStart()
+				out.write(t.core, start, start+len(t.raw))
+			}
+			last := tokens[run-1]
+			out.copy(last.trail, last.coreStart()+len(last.raw))
+			i = run - 1
+			continue
+		}
+		tokens[i].write(out)
+	}
+}
+
+// splitToken separates leading and trailing punctuation from a token's core.
+// Protected tokens are returned whole as the core.
+func splitToken(s string) token {
+	if isProtected(s) {
+		return token{core: s}
+	}
+	core := strings.TrimLeft(s, `"'¿¡«“‘`)
+	lead := s[:len(s)-len(core)]
+	trimmed := strings.TrimRight(core, `.,!?;:"'»”’`)
+	trail := core[len(trimmed):]
+	core = trimmed
+
+	// Brackets around a word, but not the leet "(_)" or "()"
+	if strings.HasPrefix(core, "(") && strings.HasSuffix(core, ")") && len(core) > 2 &&
+		!strings.ContainsAny(core[1:len(core)-1], "()") && !strings.HasPrefix(core, "(_") {
+		lead += "("
+		trail = ")" + trail
+		core = core[1 : len(core)-1]
+	}
+	return token{lead: lead, core: core, trail: trail}
+}
+
+// isProtected reports whether a token is a number, URL, email, @mention or code.
+func isProtected(s string) bool {
+	core := strings.TrimRight(s, `.,!?;:"'`)
+	if core == "" {
+		return false
+	}
+	return numberPattern.MatchString(core) || urlPattern.MatchString(core) ||
+		emailPattern.MatchString(core) || mentionPattern.MatchString(core) ||
+		codePattern.MatchString(core)
+}
+
+// decodeToken decodes one word: separators, multi- and single-character
+// leetspeak, then elongation.
+func (n *Normalizer) decodeToken(core string) string {
+	if core == "" || isProtected(core) {
+		return core
+	}
+
+	core = collapseSeparators(core)
+	core = n.decodeLeetSequences(core)
+
+	// Single-character leetspeak needs a letter to be in a word: "h4t3" is
+	// decoded, "4" and "<3" are not
+	if strings.IndexFunc(core, isASCIILetter) >= 0 {
+		protect := len(core)
+		if loc := trailingDigits.FindStringIndex(core); loc != nil {
+			protect = loc[0] + 1
+		}
+		core = n.mapRunes(core[:protect], n.leetspeak) + core[protect:]
+	}
+
+	return collapseElongation(core)
+}
+
+// collapseSeparators removes separators from a word spelled as single
+// characters between them, e.g. "f.u.c.k" or "s-h-!-t". Words with longer
+// segments ("e-mail", "re-do") are unchanged.
+func collapseSeparators(s string) string {
+	segments := strings.FieldsFunc(s, func(r rune) bool { return strings.ContainsRune(wordSeparators, r) })
+	if len(segments) < minSpelledRun {
+		return s
+	}
+	for _, seg := range segments {
+		if utf8.RuneCountInString(seg) != 1 {
+			return s
+		}
+	}
+	return strings.Join(segments, "")
+}
+
+// decodeLeetSequences replaces multi-character leetspeak such as "|-|" (h) and
+// "\/" (v). "ph" is read as "f" only before "u" ("phuck") or in a word with
+// other leetspeak, so "phone" and "graph" are unchanged.
+func (n *Normalizer) decodeLeetSequences(s string) string {
+	hasLeet := strings.ContainsAny(s, `|\/()[]{}<>013457@$!`)
+	var b strings.Builder
+	b.Grow(len(s))
+	for i := 0; i < len(s); {
+		if matched, latin := n.matchLeetSequence(s[i:]); matched > 0 {
+			b.WriteString(latin)
+			i += matched
+			continue
+		}
+		if (s[i] == 'p' || s[i] == 'P') && i+1 < len(s) && (s[i+1] == 'h' || s[i+1] == 'H') {
+			if hasLeet || (i+2 < len(s) && (s[i+2] == 'u' || s[i+2] == 'U')) {
+				b.WriteByte("fF"[boolIndex(s[i] == 'P')])
+				i += 2
+				continue
+			}
+		}
+		b.WriteByte(s[i])
+		i++
+	}
+	return b.String()
+}
+
+// matchLeetSequence returns the length of the leet sequence at the start of s
+// and its letter, or 0 if there is none.
+func (n *Normalizer) matchLeetSequence(s string) (int, string) {
+	for _, seq := range n.leetSequences {
+		if strings.HasPrefix(s, seq.leet) {
+			return len(seq.leet), seq.latin
+		}
+	}
+	return 0, ""
+}
+
+// collapseElongation shortens runs of three or more of the same letter: to
+// two for letters English often doubles, otherwise to one.
+func collapseElongation(s string) string {
+	runes := []rune(s)
+	var b strings.Builder
+	b.Grow(len(s))
+	for i := 0; i < len(runes); {
+		j := i
+		for j < len(runes) && unicode.ToLower(runes[j]) == unicode.ToLower(runes[i]) {
+			j++
+		}
+		keep := j - i
+		if keep >= 3 && unicode.IsLetter(runes[i]) {
+			keep = 1
+			if strings.ContainsRune(doublingLetters, runes[i]) {
+				keep = 2
+			}
+		}
+		for _, r := range runes[i : i+keep] {
+			b.WriteRune(r)
+		}
+		i = j
+	}
+	return b.String()
+}
+
+func allStandalone(tokens []token) bool {
+	for _, t := range tokens {
+		if !strings.Contains(standaloneLetters, t.core) {
+			return false
+		}
+	}
+	return true
+}
+
+func isLetter(s string) bool {
+	r, _ := utf8.DecodeRuneInString(s)
+	return unicode.IsLetter(r)
+}
+
+func isASCIILetter(r rune) bool {
+	return 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z'
+}
+
+func boolIndex(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
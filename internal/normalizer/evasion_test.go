@@ -0,0 +1,77 @@
+package normalizer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNormalize_Evasion(t *testing.T) {
+	n := New()
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"dotted", "f.u.c.k off", "fuck off"},
+		{"hyphenated", "you s-h-!-t", "you shit"},
+		{"spelled out", "f u c k you", "fuck you"},
+		{"spelled out with punctuation", "what the f u c k!", "what the fuck!"},
+		{"elongated", "fuuuuuck", "fuck"},
+		{"elongated doubling letter", "gooooood", "good"},
+		{"multi-rune leet", `|-|3ll()`, "hello"},
+		{"multi-rune leet v", `\/ile`, "vile"},
+		{"multi-rune leet m and n", `|\/|0|\|3y`, "money"},
+		{"ph before u", "phuck", "fuck"},
+		{"ph with leet", "ph4g", "fag"},
+		{"single-rune leet", "h4t3 sp33ch", "hate speech"},
+		{"punctuation kept", "hello!", "hello!"},
+		{"quoted word", `"h4t3r"`, `"hater"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := n.Normalize(tt.input)
+			if got != tt.want {
+				t.Errorf("Normalize(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalize_ProtectedTokens(t *testing.T) {
+	n := New()
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"phone number", "call 555-1234"},
+		{"price", "only $19.99 today"},
+		{"percentage", "up 45% this year"},
+		{"time", "meet at 10:30pm"},
+		{"ordinal", "the 3rd time"},
+		{"version suffix", "covid19 and win10"},
+		{"url", "see https://example.com/a1b2?x=3"},
+		{"bare domain", "visit t3st.io now"},
+		{"email", "mail j0hn.d03@example.com"},
+		{"mention", "thanks @l33t_h4x0r"},
+		{"inline code", "run `x = a1 + b3` first"},
+		{"fenced code", "```\nif x1 == 3 { return }\n```"},
+		{"function call", "call parse(s1) here"},
+		{"snake case", "set max_r3tries"},
+		{"filename", "edit m41n.go"},
+		{"hyphenated word", "send an e-mail"},
+		{"ph word", "my phone and graph"},
+		{"standalone letters", "u r a star"},
+		{"bare number", "4 of us"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := n.Normalize(tt.input)
+			want := strings.Join(strings.Fields(tt.input), " ")
+			if got != want {
+				t.Errorf("Normalize(%q) = %q, want unchanged", tt.input, got)
+			}
+		})
+	}
+}
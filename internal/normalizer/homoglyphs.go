@@ -1,6 +1,8 @@
 package normalizer
 
-// defaultHomoglyphs returns a mapping of common Cyrillic and Greek homoglyphs to Latin equivalents.
+// defaultHomoglyphs returns common Cyrillic and Greek homoglyphs with their
+// Latin equivalents. They override the confusables table, whose UTS #39
+// prototypes favour comparison over readability (e.g. Greek "κ" maps to "ĸ").
 func defaultHomoglyphs() map[rune]rune {
 	return map[rune]rune{
 		// Cyrillic → Latin
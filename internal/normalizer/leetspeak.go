@@ -14,3 +14,38 @@ func defaultLeetspeak() map[rune]rune {
 		'!': 'i',
 	}
 }
+
+// leetSequence is a multi-character leetspeak spelling of a letter.
+type leetSequence struct {
+	leet  string
+	latin string
+}
+
+// defaultLeetSequences returns common multi-character leetspeak, longest
+// first so that "|\/|" is read as "m" rather than "|" followed by "v".
+func defaultLeetSequences() []leetSequence {
+	return []leetSequence{
+		{`|\/|`, "m"},
+		{`/\/\`, "m"},
+		{`\/\/`, "w"},
+		{`|\|`, "n"},
+		{`/\/`, "n"},
+		{`|-|`, "h"},
+		{`]-[`, "h"},
+		{`|_|`, "u"},
+		{`(_)`, "u"},
+		{`}{`, "h"},
+		{`\/`, "v"},
+		{`/\`, "a"},
+		{`|<`, "k"},
+		{`|{`, "k"},
+		{`|)`, "d"},
+		{`[)`, "d"},
+		{`|3`, "b"},
+		{`|2`, "r"},
+		{`><`, "x"},
+		{`()`, "o"},
+		{`[]`, "o"},
+		{`|_`, "l"},
+	}
+}
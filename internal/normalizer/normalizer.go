@@ -3,68 +3,169 @@ package normalizer
 import (
 	"strings"
 	"unicode"
+	"unicode/utf8"
 
 	"golang.org/x/text/unicode/norm"
 )
 
+// maxStackedMarks is the most combining marks kept on one base character.
+// Real orthographies rarely stack more than two after NFKC composition;
+// "Zalgo" text stacks dozens.
+const maxStackedMarks = 2
+
 // Normalizer pre-processes text to defeat Unicode evasion before hashing and classification.
 type Normalizer struct {
-	homoglyphs map[rune]rune
-	leetspeak  map[rune]rune
+	confusables    map[rune]string
+	leetspeak      map[rune]rune
+	leetSequences  []leetSequence
+	steps          map[string]bool // enabled steps; nil enables all
+	foldAllScripts bool            // fold lookalikes even in genuinely used scripts
 }
 
-// New creates a Normalizer with default homoglyph and leetspeak mappings.
+// New creates a Normalizer with the embedded confusables table, default
+// homoglyph overrides and single- and multi-character leetspeak mappings.
 func New() *Normalizer {
+	confusables := parseConfusables(confusablesData)
+	for r, latin := range defaultHomoglyphs() {
+		confusables[r] = string(latin)
+	}
 	return &Normalizer{
-		homoglyphs: defaultHomoglyphs(),
-		leetspeak:  defaultLeetspeak(),
+		confusables:   confusables,
+		leetspeak:     defaultLeetspeak(),
+		leetSequences: defaultLeetSequences(),
 	}
 }
 
 // Normalize applies all normalization steps to the input text:
 // 1. NFKC Unicode normalization
-// 2. Strip zero-width characters
-// 3. Homoglyph → Latin mapping
-// 4. Leetspeak decoding
-// 5. Collapse whitespace
+// 2. Strip format (Cf) characters such as zero-width and bidi controls
+// 3. Strip stacked combining marks ("Zalgo")
+// 4. Confusable → Latin mapping, with mixed-script detection
+// 5. Evasion decoding: separators, spelled-out letters, elongation and
+// leetspeak, skipping numbers, URLs, emails and code
+// 6. Collapse whitespace
+//
+// A Normalizer created with NewWithProfile applies only the profile's steps;
+// whitespace is always collapsed.
 func (n *Normalizer) Normalize(text string) string {
+	normalized, _ := n.normalize(text, false)
+	return normalized
+}
+
+// NormalizeWithAlignment is Normalize, also returning the Alignment that maps
+// offsets in the normalized text back to text.
+func (n *Normalizer) NormalizeWithAlignment(text string) (string, *Alignment) {
+	return n.normalize(text, true)
+}
+
+func (n *Normalizer) normalize(text string, track bool) (string, *Alignment) {
+	a := newAligner(text, track)
+
 	// Step 1: NFKC normalization (decomposes and recomposes by compatibility)
-	text = norm.NFKC.String(text)
+	if n.enabled(StepNFKC) {
+		text = a.apply(text, nfkc)
+	}
 
-	// Step 2: Strip zero-width characters
-	text = stripZeroWidth(text)
+	// Step 2: Strip invisible format characters
+	if n.enabled(StepFormat) {
+		text = a.apply(text, stripFormat)
+	}
 
-	// Step 3: Homoglyph → Latin mapping
-	text = n.mapRunes(text, n.homoglyphs)
+	// Step 3: Strip stacked combining marks
+	if n.enabled(StepMarks) {
+		text = a.apply(text, stripStackedMarks)
+	}
 
-	// Step 4: Leetspeak decoding
-	text = n.mapRunes(text, n.leetspeak)
+	// Step 4: Confusable → Latin mapping
+	if n.enabled(StepConfusables) {
+		text = a.apply(text, n.foldConfusables)
+	}
 
-	// Step 5: Collapse whitespace
-	text = collapseWhitespace(text)
+	// Step 5: Evasion and leetspeak decoding
+	if n.enabled(StepEvasion) {
+		text = a.apply(text, n.decodeEvasion)
+	}
 
-	return text
+	// Step 6: Collapse whitespace
+	text = a.apply(text, collapseWhitespace)
+
+	return text, a.alignment
 }
 
-// stripZeroWidth removes zero-width Unicode characters used to evade filters.
-func stripZeroWidth(text string) string {
-	var b strings.Builder
-	b.Grow(len(text))
-	for _, r := range text {
-		switch r {
-		case '\u200B', // zero-width space
-			'\u200C', // zero-width non-joiner
-			'\u200D', // zero-width joiner
-			'\u200E', // left-to-right mark
-			'\u200F', // right-to-left mark
-			'\u2060', // word joiner
-			'\uFEFF': // byte order mark / zero-width no-break space
+// enabled reports whether a normalization step is enabled.
+func (n *Normalizer) enabled(step string) bool {
+	return n.steps == nil || n.steps[step]
+}
+
+// nfkc applies NFKC normalization. When tracking offsets, text is normalized
+// one segment at a time between normalization boundaries, which gives the
+// same result as normalizing it whole.
+func nfkc(text string, out *output) {
+	if !out.track {
+		out.write(norm.NFKC.String(text), 0, len(text))
+		return
+	}
+	for start := 0; start < len(text); {
+		end := start + norm.NFKC.NextBoundaryInString(text[start:], true)
+		if end <= start {
+			end = len(text)
+		}
+		segment := text[start:end]
+		if normalized := norm.NFKC.String(segment); normalized != segment {
+			out.write(normalized, start, end)
+		} else {
+			out.copy(segment, start)
+		}
+		start = end
+	}
+}
+
+// stripFormat removes format (Cf) characters, which are invisible and used to
+// split words and evade filters: zero-width spaces and joiners, bidi
+// controls, soft hyphens, tag characters and the byte order mark.
+func stripFormat(text string, out *output) {
+	for i, r := range text {
+		if unicode.Is(unicode.Cf, r) {
 			continue
-		default:
-			b.WriteRune(r)
 		}
+		out.writeRune(r, i, i+runeLen(text, i))
 	}
-	return b.String()
+}
+
+// stripStackedMarks removes the combining marks from any character carrying
+// more than maxStackedMarks of them. Characters with fewer keep their marks,
+// so scripts that rely on combining vowel signs and diacritics are unaffected,
+// except for the overlay marks U+0334-U+0338 (strikethrough and slashes),
+// which no orthography uses and are always removed.
+func stripStackedMarks(text string, out *output) {
+	var marks []int // byte offsets
+	flush := func() {
+		if len(marks) <= maxStackedMarks {
+			for _, i := range marks {
+				r, size := utf8.DecodeRuneInString(text[i:])
+				out.writeRune(r, i, i+size)
+			}
+		}
+		marks = marks[:0]
+	}
+	for i, r := range text {
+		if r >= '\u0334' && r <= '\u0338' {
+			continue
+		}
+		if unicode.In(r, unicode.Mn, unicode.Me) {
+			marks = append(marks, i)
+			continue
+		}
+		flush()
+		out.writeRune(r, i, i+runeLen(text, i))
+	}
+	flush()
+}
+
+// runeLen returns the length in bytes of the rune at text[i:].
+func runeLen(text string, i int) int {
+	_, size := utf8.DecodeRuneInString(text[i:])
+	return size
 }
 
 // mapRunes replaces runes according to the provided mapping.
@@ -82,20 +183,21 @@ func (n *Normalizer) mapRunes(text string, mapping map[rune]rune) string {
 }
 
 // collapseWhitespace replaces runs of whitespace with a single space and trims edges.
-func collapseWhitespace(text string) string {
-	var b strings.Builder
-	b.Grow(len(text))
-	inSpace := false
-	for _, r := range text {
+func collapseWhitespace(text string, out *output) {
+	space := -1 // start of the pending run of whitespace
+	wrote := false
+	for i, r := range text {
 		if unicode.IsSpace(r) {
-			if !inSpace {
-				b.WriteRune(' ')
-				inSpace = true
+			if space < 0 {
+				space = i
 			}
-		} else {
-			b.WriteRune(r)
-			inSpace = false
+			continue
+		}
+		if space >= 0 && wrote {
+			out.write(" ", space, i)
 		}
+		space = -1
+		out.writeRune(r, i, i+runeLen(text, i))
+		wrote = true
 	}
-	return strings.TrimSpace(b.String())
 }
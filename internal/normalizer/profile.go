@@ -0,0 +1,259 @@
+package normalizer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/proth1/text-moderator/internal/models"
+)
+
+// Normalization steps a profile can enable. Whitespace is always collapsed.
+const (
+	StepNFKC        = "nfkc"        // NFKC Unicode normalization
+	StepFormat      = "format"      // strip format (Cf) characters
+	StepMarks       = "marks"       // strip stacked combining marks
+	StepConfusables = "confusables" // map lookalikes to Latin
+	StepEvasion     = "evasion"     // separators, spelled-out letters, elongation and leetspeak
+)
+
+// Steps lists every normalization step in the order they are applied.
+var Steps = []string{StepNFKC, StepFormat, StepMarks, StepConfusables, StepEvasion}
+
+// Built-in profile names.
+const (
+	// ProfileStandard applies every step. It is used when neither the request
+	// nor the policy selects a profile.
+	ProfileStandard = "standard"
+
+	// ProfileStrict applies every step and folds lookalikes in every word,
+	// even in scripts genuinely used in the text. Suited to children's
+	// communities, at the cost of mangling non-Latin text.
+	ProfileStrict = "strict"
+
+	// ProfileCode cleans up Unicode but decodes no lookalikes or leetspeak,
+	// for code-sharing communities where identifiers look like evasion.
+	ProfileCode = "code"
+
+	// ProfileMinimal only applies NFKC and strips invisible characters.
+	ProfileMinimal = "minimal"
+)
+
+// builtinProfiles are the profiles available to every policy.
+var builtinProfiles = map[string]models.NormalizationProfile{
+	ProfileStandard: {Name: ProfileStandard, Steps: Steps},
+	ProfileStrict:   {Name: ProfileStrict, Steps: Steps, FoldAllScripts: boolPtr(true)},
+	ProfileCode:     {Name: ProfileCode, Steps: []string{StepNFKC, StepFormat, StepMarks}},
+	ProfileMinimal:  {Name: ProfileMinimal, Steps: []string{StepNFKC, StepFormat}},
+}
+
+// maxLeetSequence is the longest custom leetspeak sequence, in runes.
+const maxLeetSequence = 4
+
+func boolPtr(b bool) *bool { return &b }
+
+// BuiltinProfile returns the built-in profile with the given name.
+func BuiltinProfile(name string) (models.NormalizationProfile, bool) {
+	p, ok := builtinProfiles[name]
+	return p, ok
+}
+
+// ResolveProfile returns the profile named name, looking first in custom (a
+// policy's own profiles) and then in the built-in profiles. An empty name
+// selects ProfileStandard. Custom profiles are expanded onto their base, so
+// the result lists every setting explicitly.
+func ResolveProfile(name string, custom []models.NormalizationProfile) (models.NormalizationProfile, error) {
+	if name == "" {
+		name = ProfileStandard
+	}
+	for _, p := range custom {
+		if p.Name == name {
+			return expandProfile(p)
+		}
+	}
+	if p, ok := builtinProfiles[name]; ok {
+		return p, nil
+	}
+	return models.NormalizationProfile{}, fmt.Errorf("unknown normalization profile %q", name)
+}
+
+// expandProfile fills in the steps and settings a custom profile inherits
+// from its base.
+func expandProfile(p models.NormalizationProfile) (models.NormalizationProfile, error) {
+	baseName := p.Base
+	if baseName == "" {
+		baseName = ProfileStandard
+	}
+	base, ok := builtinProfiles[baseName]
+	if !ok {
+		return models.NormalizationProfile{}, fmt.Errorf("profile %q: unknown base profile %q", p.Name, baseName)
+	}
+	p.Base = baseName
+	if p.Steps == nil {
+		p.Steps = base.Steps
+	}
+	if p.FoldAllScripts == nil {
+		p.FoldAllScripts = base.FoldAllScripts
+	}
+	return p, nil
+}
+
+// ValidateProfiles checks a policy's custom profiles and its default profile
+// name, which may name a custom or built-in profile.
+func ValidateProfiles(defaultName string, profiles []models.NormalizationProfile) error {
+	seen := make(map[string]bool, len(profiles))
+	for _, p := range profiles {
+		if p.Name == "" {
+			return fmt.Errorf("normalization profile name must not be empty")
+		}
+		if _, ok := builtinProfiles[p.Name]; ok {
+			return fmt.Errorf("normalization profile %q shadows a built-in profile", p.Name)
+		}
+		if seen[p.Name] {
+			return fmt.Errorf("duplicate normalization profile %q", p.Name)
+		}
+		seen[p.Name] = true
+		if err := validateProfile(p); err != nil {
+			return err
+		}
+	}
+	if _, err := ResolveProfile(defaultName, profiles); err != nil {
+		return err
+	}
+	return nil
+}
+
+func validateProfile(p models.NormalizationProfile) error {
+	if _, err := expandProfile(p); err != nil {
+		return err
+	}
+	for _, step := range p.Steps {
+		if !isStep(step) {
+			return fmt.Errorf("profile %q: unknown step %q", p.Name, step)
+		}
+	}
+	for from, to := range p.Confusables {
+		if utf8.RuneCountInString(from) != 1 {
+			return fmt.Errorf("profile %q: confusable %q must be a single character", p.Name, from)
+		}
+		if !isASCIILetters(to) {
+			return fmt.Errorf("profile %q: confusable %q must map to ASCII letters", p.Name, from)
+		}
+	}
+	for from, to := range p.Leetspeak {
+		if n := utf8.RuneCountInString(from); n == 0 || n > maxLeetSequence {
+			return fmt.Errorf("profile %q: leetspeak %q must be 1 to %d characters", p.Name, from, maxLeetSequence)
+		}
+		if len(to) != 1 || !isASCIILetters(to) {
+			return fmt.Errorf("profile %q: leetspeak %q must map to a single ASCII letter", p.Name, from)
+		}
+	}
+	return nil
+}
+
+func isStep(name string) bool {
+	for _, s := range Steps {
+		if s == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ProfileKey identifies a resolved profile in content hashes, cache keys and
+// evidence. Built-in profiles are identified by name; custom profiles by name
+// and a fingerprint of their settings, so editing a profile, or two policies
+// defining a profile of the same name differently, never share results.
+func ProfileKey(p models.NormalizationProfile) string {
+	if builtin, ok := builtinProfiles[p.Name]; ok && p.Base == "" && len(p.Confusables) == 0 && len(p.Leetspeak) == 0 {
+		if fmt.Sprint(builtin.Steps) == fmt.Sprint(p.Steps) && boolValue(builtin.FoldAllScripts) == boolValue(p.FoldAllScripts) {
+			return p.Name
+		}
+	}
+	// encoding/json sorts map keys, so equal profiles marshal identically
+	data, _ := json.Marshal(p)
+	h := sha256.Sum256(data)
+	return p.Name + "@" + hex.EncodeToString(h[:4])
+}
+
+func boolValue(b *bool) bool {
+	return b != nil && *b
+}
+
+// NewWithProfile creates a Normalizer applying a resolved profile: only its
+// steps run, and its mappings are added to the default tables.
+func NewWithProfile(p models.NormalizationProfile) (*Normalizer, error) {
+	if err := validateProfile(p); err != nil {
+		return nil, err
+	}
+	n := New()
+	n.steps = make(map[string]bool, len(p.Steps))
+	for _, step := range p.Steps {
+		n.steps[step] = true
+	}
+	n.foldAllScripts = boolValue(p.FoldAllScripts)
+
+	for from, to := range p.Confusables {
+		r, _ := utf8.DecodeRuneInString(from)
+		n.confusables[r] = to
+	}
+
+	var sequences []leetSequence
+	for from, to := range p.Leetspeak {
+		if utf8.RuneCountInString(from) == 1 {
+			r, _ := utf8.DecodeRuneInString(from)
+			n.leetspeak[r] = rune(to[0])
+			continue
+		}
+		sequences = append(sequences, leetSequence{leet: from, latin: to})
+	}
+	if len(sequences) > 0 {
+		// Longest first, so a custom sequence wins over any it contains
+		n.leetSequences = append(sequences, n.leetSequences...)
+		sort.SliceStable(n.leetSequences, func(i, j int) bool {
+			return len(n.leetSequences[i].leet) > len(n.leetSequences[j].leet)
+		})
+	}
+	return n, nil
+}
+
+// Registry builds Normalizers for profiles on first use and reuses them.
+// It is safe for concurrent use.
+type Registry struct {
+	mu          sync.RWMutex
+	normalizers map[string]*Normalizer
+}
+
+// NewRegistry creates an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{normalizers: make(map[string]*Normalizer)}
+}
+
+// Get returns the Normalizer for a resolved profile and the profile's key.
+func (r *Registry) Get(p models.NormalizationProfile) (*Normalizer, string, error) {
+	key := ProfileKey(p)
+
+	r.mu.RLock()
+	n, ok := r.normalizers[key]
+	r.mu.RUnlock()
+	if ok {
+		return n, key, nil
+	}
+
+	n, err := NewWithProfile(p)
+	if err != nil {
+		return nil, "", err
+	}
+	r.mu.Lock()
+	if existing, ok := r.normalizers[key]; ok {
+		n = existing
+	} else {
+		r.normalizers[key] = n
+	}
+	r.mu.Unlock()
+	return n, key, nil
+}
@@ -0,0 +1,165 @@
+package normalizer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/proth1/text-moderator/internal/models"
+)
+
+func TestNewWithProfile_BuiltinProfiles(t *testing.T) {
+	tests := []struct {
+		profile string
+		input   string
+		want    string
+	}{
+		{ProfileStandard, "you \u0456d\u0456ot h4t3", "you idiot hate"},
+		{ProfileStandard, "\u0445\u043e\u0440 \u043f\u043e\u0435\u0442", "\u0445\u043e\u0440 \u043f\u043e\u0435\u0442"},
+		{ProfileStrict, "\u0445\u043e\u0440 \u043f\u043e\u0435\u0442", "xop \u043foe\u0442"},
+		{ProfileCode, "you \u0456d\u0456ot h4t3", "you \u0456d\u0456ot h4t3"},
+		{ProfileCode, "k\u200bill", "kill"},
+		{ProfileCode, "z\u0310\u0311\u0312ap", "zap"},
+		{ProfileMinimal, "k\u200bill h4t3", "kill h4t3"},
+		{ProfileMinimal, "z\u0310\u0311\u0312ap", "z\u0310\u0311\u0312ap"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.profile+" "+tt.input, func(t *testing.T) {
+			p, err := ResolveProfile(tt.profile, nil)
+			if err != nil {
+				t.Fatalf("resolve failed: %v", err)
+			}
+			n, err := NewWithProfile(p)
+			if err != nil {
+				t.Fatalf("NewWithProfile failed: %v", err)
+			}
+			if got := n.Normalize(tt.input); got != tt.want {
+				t.Errorf("Normalize(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewWithProfile_CustomMappings(t *testing.T) {
+	custom := []models.NormalizationProfile{{
+		Name:      "gaming",
+		Leetspeak: map[string]string{"€": "e", ")(": "h"},
+	}}
+	p, err := ResolveProfile("gaming", custom)
+	if err != nil {
+		t.Fatalf("resolve failed: %v", err)
+	}
+	n, err := NewWithProfile(p)
+	if err != nil {
+		t.Fatalf("NewWithProfile failed: %v", err)
+	}
+	if got := n.Normalize("€vil )(ello h4t3"); got != "evil hello hate" {
+		t.Errorf("got %q, want custom and default mappings applied", got)
+	}
+
+	// Custom mappings do not leak into other normalizers
+	if got := New().Normalize("€vil )(ello"); got != "€vil )(ello" {
+		t.Errorf("default normalizer got %q, want unchanged", got)
+	}
+}
+
+func TestResolveProfile(t *testing.T) {
+	custom := []models.NormalizationProfile{{Name: "kids", Base: ProfileStrict}}
+
+	p, err := ResolveProfile("", custom)
+	if err != nil || p.Name != ProfileStandard {
+		t.Errorf("empty name resolved to %q, %v, want standard", p.Name, err)
+	}
+
+	p, err = ResolveProfile("kids", custom)
+	if err != nil {
+		t.Fatalf("resolve failed: %v", err)
+	}
+	if len(p.Steps) != len(Steps) || !boolValue(p.FoldAllScripts) {
+		t.Errorf("got steps %v fold_all_scripts %v, want inherited from strict", p.Steps, boolValue(p.FoldAllScripts))
+	}
+
+	if _, err := ResolveProfile("missing", custom); err == nil {
+		t.Error("unknown profile resolved")
+	}
+}
+
+func TestValidateProfiles(t *testing.T) {
+	tests := []struct {
+		name      string
+		def       string
+		profiles  []models.NormalizationProfile
+		wantError string
+	}{
+		{"builtin default", ProfileCode, nil, ""},
+		{"custom default", "kids", []models.NormalizationProfile{{Name: "kids", Base: ProfileStrict}}, ""},
+		{"unknown default", "kids", nil, "unknown normalization profile"},
+		{"empty name", "", []models.NormalizationProfile{{}}, "must not be empty"},
+		{"shadows builtin", "", []models.NormalizationProfile{{Name: ProfileStrict}}, "shadows"},
+		{"duplicate", "", []models.NormalizationProfile{{Name: "a"}, {Name: "a"}}, "duplicate"},
+		{"unknown base", "", []models.NormalizationProfile{{Name: "a", Base: "loose"}}, "unknown base"},
+		{"unknown step", "", []models.NormalizationProfile{{Name: "a", Steps: []string{"stem"}}}, "unknown step"},
+		{"multi-rune confusable", "", []models.NormalizationProfile{{Name: "a", Confusables: map[string]string{"ab": "a"}}}, "single character"},
+		{"non-letter confusable", "", []models.NormalizationProfile{{Name: "a", Confusables: map[string]string{"ß": "1"}}}, "ASCII letters"},
+		{"long leetspeak", "", []models.NormalizationProfile{{Name: "a", Leetspeak: map[string]string{"|||||": "m"}}}, "1 to 4"},
+		{"leetspeak to word", "", []models.NormalizationProfile{{Name: "a", Leetspeak: map[string]string{"|<": "kk"}}}, "single ASCII letter"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateProfiles(tt.def, tt.profiles)
+			if tt.wantError == "" {
+				if err != nil {
+					t.Errorf("got error %v, want none", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantError) {
+				t.Errorf("got error %v, want %q", err, tt.wantError)
+			}
+		})
+	}
+}
+
+func TestProfileKey(t *testing.T) {
+	standard, _ := ResolveProfile(ProfileStandard, nil)
+	if got := ProfileKey(standard); got != ProfileStandard {
+		t.Errorf("ProfileKey(standard) = %q, want standard", got)
+	}
+
+	resolve := func(p models.NormalizationProfile) models.NormalizationProfile {
+		resolved, err := ResolveProfile(p.Name, []models.NormalizationProfile{p})
+		if err != nil {
+			t.Fatalf("resolve failed: %v", err)
+		}
+		return resolved
+	}
+	a := ProfileKey(resolve(models.NormalizationProfile{Name: "chat", Leetspeak: map[string]string{"€": "e"}}))
+	b := ProfileKey(resolve(models.NormalizationProfile{Name: "chat", Leetspeak: map[string]string{"€": "e"}}))
+	c := ProfileKey(resolve(models.NormalizationProfile{Name: "chat", Base: ProfileCode}))
+	if !strings.HasPrefix(a, "chat@") {
+		t.Errorf("got key %q, want it to start with the profile name", a)
+	}
+	if a != b {
+		t.Errorf("equal profiles got keys %q and %q", a, b)
+	}
+	if a == c {
+		t.Errorf("different profiles named chat share key %q", a)
+	}
+}
+
+func TestRegistry_ReusesNormalizers(t *testing.T) {
+	r := NewRegistry()
+	p, _ := ResolveProfile(ProfileCode, nil)
+	first, key, err := r.Get(p)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	second, _, _ := r.Get(p)
+	if first != second {
+		t.Error("registry built a second normalizer for the same profile")
+	}
+	if key != ProfileCode {
+		t.Errorf("got key %q, want %q", key, ProfileCode)
+	}
+}
@@ -29,6 +29,7 @@ type Metrics struct {
 	ProviderRequestTotal    *prometheus.CounterVec
 	ProviderRequestDuration *prometheus.HistogramVec
 	ProviderFailures        *prometheus.CounterVec
+	CircuitBreakerState     *prometheus.GaugeVec
 
 	// Policy evaluation metrics
 	PolicyEvaluationTotal    *prometheus.CounterVec
@@ -101,6 +102,11 @@ func NewMetrics(serviceName string) *Metrics {
 			Help: "Total provider failures triggering fallback",
 		}, []string{"provider"}),
 
+		CircuitBreakerState: promauto.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "provider_circuit_breaker_state",
+			Help: "Provider circuit breaker state (0 = closed, 1 = half-open, 2 = open)",
+		}, []string{"provider"}),
+
 		PolicyEvaluationTotal: promauto.NewCounterVec(prometheus.CounterOpts{
 			Name: "policy_evaluation_total",
 			Help: "Total policy evaluations",
@@ -0,0 +1,209 @@
+package prompt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/proth1/text-moderator/internal/classifier"
+	"github.com/proth1/text-moderator/internal/models"
+	"go.uber.org/zap"
+)
+
+// Store persists versioned LLM prompt templates per policy.
+// Control: POL-001 (Policy management)
+type Store struct {
+	db     *pgxpool.Pool
+	logger *zap.Logger
+}
+
+// NewStore creates a new prompt template store.
+func NewStore(db *pgxpool.Pool, logger *zap.Logger) *Store {
+	return &Store{db: db, logger: logger}
+}
+
+const templateColumns = `id, policy_id, version, template, guidelines, category_definitions, examples, status, created_at, created_by`
+
+// CreateTemplate creates a new draft template version for a policy. The version
+// is one greater than the policy's highest existing template version. An empty
+// template body stores the built-in prompt so the exact instructions are auditable.
+func (s *Store) CreateTemplate(ctx context.Context, policyID uuid.UUID, req *models.CreatePromptTemplateRequest, createdBy uuid.UUID) (*models.PromptTemplate, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Lock the policy row to serialise version allocation per policy.
+	if err := tx.QueryRow(ctx, `SELECT id FROM policies WHERE id = $1 FOR UPDATE`, policyID).Scan(&policyID); err != nil {
+		return nil, fmt.Errorf("failed to query policy: %w", err)
+	}
+
+	var maxVersion int
+	err = tx.QueryRow(ctx, `SELECT COALESCE(MAX(version), 0) FROM prompt_templates WHERE policy_id = $1`, policyID).Scan(&maxVersion)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check existing prompt template versions: %w", err)
+	}
+
+	t := &models.PromptTemplate{
+		ID:                  uuid.New(),
+		PolicyID:            policyID,
+		Version:             maxVersion + 1,
+		Template:            req.Template,
+		Guidelines:          req.Guidelines,
+		CategoryDefinitions: req.CategoryDefinitions,
+		Examples:            req.Examples,
+		Status:              models.PolicyStatusDraft,
+		CreatedBy:           &createdBy,
+	}
+	if t.Template == "" {
+		t.Template = classifier.DefaultPromptTemplate
+	}
+	if t.CategoryDefinitions == nil {
+		t.CategoryDefinitions = map[string]string{}
+	}
+	if t.Examples == nil {
+		t.Examples = []models.PromptExample{}
+	}
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO prompt_templates (id, policy_id, version, template, guidelines, category_definitions, examples, status, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING created_at
+	`, t.ID, t.PolicyID, t.Version, t.Template, t.Guidelines, t.CategoryDefinitions, t.Examples, t.Status, t.CreatedBy).Scan(&t.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create prompt template: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit prompt template: %w", err)
+	}
+
+	s.logger.Info("prompt template created",
+		zap.String("template_id", t.ID.String()),
+		zap.String("policy_id", policyID.String()),
+		zap.Int("version", t.Version),
+	)
+
+	return t, nil
+}
+
+// ListTemplates returns all template versions for a policy, newest first.
+func (s *Store) ListTemplates(ctx context.Context, policyID uuid.UUID) ([]models.PromptTemplate, error) {
+	rows, err := s.db.Query(ctx, `
+		SELECT `+templateColumns+`
+		FROM prompt_templates
+		WHERE policy_id = $1
+		ORDER BY version DESC
+	`, policyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query prompt templates: %w", err)
+	}
+	defer rows.Close()
+
+	var templates []models.PromptTemplate
+	for rows.Next() {
+		t, err := scanTemplate(rows)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, *t)
+	}
+
+	return templates, rows.Err()
+}
+
+// GetTemplate returns a single template version.
+func (s *Store) GetTemplate(ctx context.Context, id uuid.UUID) (*models.PromptTemplate, error) {
+	row := s.db.QueryRow(ctx, `SELECT `+templateColumns+` FROM prompt_templates WHERE id = $1`, id)
+	return scanTemplate(row)
+}
+
+// PublishTemplate publishes a template version and archives the policy's
+// previously published version, so each policy has at most one active template.
+func (s *Store) PublishTemplate(ctx context.Context, id uuid.UUID) (*models.PromptTemplate, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var policyID uuid.UUID
+	if err := tx.QueryRow(ctx, `SELECT policy_id FROM prompt_templates WHERE id = $1`, id).Scan(&policyID); err != nil {
+		return nil, fmt.Errorf("failed to query prompt template: %w", err)
+	}
+
+	// Lock the policy row to serialise publishes per policy, as CreateTemplate
+	// does for version allocation.
+	if err := tx.QueryRow(ctx, `SELECT id FROM policies WHERE id = $1 FOR UPDATE`, policyID).Scan(&policyID); err != nil {
+		return nil, fmt.Errorf("failed to query policy: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx,
+		`UPDATE prompt_templates SET status = 'archived' WHERE policy_id = $1 AND status = 'published' AND id <> $2`,
+		policyID, id,
+	); err != nil {
+		return nil, fmt.Errorf("failed to archive previous prompt template: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE prompt_templates SET status = 'published' WHERE id = $1`, id); err != nil {
+		return nil, fmt.Errorf("failed to publish prompt template: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit prompt template publish: %w", err)
+	}
+
+	s.logger.Info("prompt template published", zap.String("template_id", id.String()), zap.String("policy_id", policyID.String()))
+
+	return s.GetTemplate(ctx, id)
+}
+
+// PublishedTemplate returns the policy's published template, or nil if it has
+// none. A new policy version starts with the published template of the
+// version it was created from (see CarryForwardTemplate).
+func (s *Store) PublishedTemplate(ctx context.Context, policyID uuid.UUID) (*models.PromptTemplate, error) {
+	row := s.db.QueryRow(ctx, `
+		SELECT `+templateColumns+`
+		FROM prompt_templates
+		WHERE policy_id = $1 AND status = 'published'
+	`, policyID)
+	t, err := scanTemplate(row)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	return t, err
+}
+
+// CarryForwardTemplate copies the published template of the latest earlier
+// version of the named policy that has one to the new version policyID, as
+// its published template with the same version number, so a new policy
+// version keeps its prompt until a different template is published for it.
+// It runs in the caller's transaction and reports whether a template was copied.
+func CarryForwardTemplate(ctx context.Context, tx pgx.Tx, policyID uuid.UUID, name string) (bool, error) {
+	tag, err := tx.Exec(ctx, `
+		INSERT INTO prompt_templates (id, policy_id, version, template, guidelines, category_definitions, examples, status, created_by)
+		SELECT gen_random_uuid(), $1, t.version, t.template, t.guidelines, t.category_definitions, t.examples, 'published', t.created_by
+		FROM prompt_templates t
+		JOIN policies p ON p.id = t.policy_id
+		WHERE p.name = $2 AND p.id <> $1 AND t.status = 'published'
+		ORDER BY p.version DESC
+		LIMIT 1
+	`, policyID, name)
+	if err != nil {
+		return false, fmt.Errorf("failed to carry forward prompt template: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func scanTemplate(row pgx.Row) (*models.PromptTemplate, error) {
+	var t models.PromptTemplate
+	err := row.Scan(&t.ID, &t.PolicyID, &t.Version, &t.Template, &t.Guidelines, &t.CategoryDefinitions, &t.Examples, &t.Status, &t.CreatedAt, &t.CreatedBy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan prompt template: %w", err)
+	}
+	return &t, nil
+}
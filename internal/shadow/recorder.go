@@ -0,0 +1,96 @@
+package shadow
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/proth1/text-moderator/internal/models"
+	"go.uber.org/zap"
+)
+
+// Recorder persists shadow provider classifications and compares them against
+// live decisions and human review outcomes.
+// Control: MOD-005 (Multi-Provider Classification Orchestration)
+type Recorder struct {
+	db     *pgxpool.Pool
+	logger *zap.Logger
+}
+
+// NewRecorder creates a new shadow classification recorder.
+func NewRecorder(db *pgxpool.Pool, logger *zap.Logger) *Recorder {
+	return &Recorder{db: db, logger: logger}
+}
+
+// Record stores shadow classifications for a decision. Failures are logged and
+// otherwise ignored: shadow results must never affect the serving path.
+func (r *Recorder) Record(ctx context.Context, records []models.ShadowClassification) {
+	for _, rec := range records {
+		if rec.ID == uuid.Nil {
+			rec.ID = uuid.New()
+		}
+		_, err := r.db.Exec(ctx, `
+			INSERT INTO shadow_classifications (
+				id, decision_id, provider_name, model_name, model_version,
+				category_scores, latency_ms, error
+			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		`, rec.ID, rec.DecisionID, rec.ProviderName, rec.ModelName, rec.ModelVersion,
+			rec.CategoryScores, rec.LatencyMs, rec.Error,
+		)
+		if err != nil {
+			r.logger.Warn("failed to record shadow classification",
+				zap.String("decision_id", rec.DecisionID.String()),
+				zap.String("provider", rec.ProviderName),
+				zap.Error(err),
+			)
+		}
+	}
+}
+
+// GenerateReport compares shadow scores against live scores and human review
+// outcomes for the last `window` of shadow classifications. A decision counts
+// as flagged when any category score is at or above threshold.
+func (r *Recorder) GenerateReport(ctx context.Context, window time.Duration, threshold float64) (*Report, error) {
+	since := time.Now().Add(-window)
+
+	// The latest calibration_data row per decision carries the human review outcome.
+	rows, err := r.db.Query(ctx, `
+		SELECT s.provider_name, s.category_scores, s.latency_ms, s.error IS NOT NULL,
+		       d.category_scores, d.automated_action, c.review_outcome
+		FROM shadow_classifications s
+		JOIN moderation_decisions d ON d.id = s.decision_id AND d.created_at >= $1 - INTERVAL '1 hour'
+		LEFT JOIN LATERAL (
+			SELECT review_outcome FROM calibration_data
+			WHERE decision_id = s.decision_id
+			ORDER BY created_at DESC
+			LIMIT 1
+		) c ON true
+		WHERE s.created_at >= $1
+	`, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query shadow classifications: %w", err)
+	}
+	defer rows.Close()
+
+	var comparisons []comparison
+	for rows.Next() {
+		var cmp comparison
+		if err := rows.Scan(&cmp.provider, &cmp.shadow, &cmp.latencyMs, &cmp.failed,
+			&cmp.live, &cmp.liveAction, &cmp.reviewOutcome); err != nil {
+			return nil, fmt.Errorf("failed to scan shadow comparison: %w", err)
+		}
+		comparisons = append(comparisons, cmp)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read shadow comparisons: %w", err)
+	}
+
+	return &Report{
+		Window:      window,
+		GeneratedAt: time.Now(),
+		Threshold:   threshold,
+		Providers:   summarize(comparisons, threshold),
+	}, nil
+}
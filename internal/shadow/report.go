@@ -0,0 +1,163 @@
+package shadow
+
+import (
+	"sort"
+	"time"
+
+	"github.com/proth1/text-moderator/internal/models"
+)
+
+// Report compares each shadow provider with the live serving path.
+type Report struct {
+	Window      time.Duration        `json:"window"`
+	GeneratedAt time.Time            `json:"generated_at"`
+	Threshold   float64              `json:"threshold"`
+	Providers   []ProviderComparison `json:"providers"`
+}
+
+// ProviderComparison summarizes how a shadow provider would have performed
+// had it been serving.
+type ProviderComparison struct {
+	ProviderName    string  `json:"provider_name"`
+	Classifications int     `json:"classifications"`
+	Errors          int     `json:"errors"`
+	AvgLatencyMs    float64 `json:"avg_latency_ms"`
+
+	// MeanAbsDiff is the mean absolute difference between shadow and live scores per category.
+	MeanAbsDiff map[string]float64 `json:"mean_abs_diff"`
+
+	// FlagAgreementRate is the fraction of decisions where shadow and live agree
+	// on whether the content should be flagged.
+	FlagAgreementRate float64 `json:"flag_agreement_rate"`
+
+	// Reviewed counts decisions with a conclusive human review outcome; the
+	// accuracy rates are measured against those outcomes.
+	Reviewed       int     `json:"reviewed"`
+	LiveAccuracy   float64 `json:"live_accuracy"`
+	ShadowAccuracy float64 `json:"shadow_accuracy"`
+}
+
+// comparison is a single shadow classification joined with its live decision.
+type comparison struct {
+	provider      string
+	shadow        *models.CategoryScores
+	latencyMs     int
+	failed        bool
+	live          models.CategoryScores
+	liveAction    models.PolicyAction
+	reviewOutcome *string
+}
+
+// summarize aggregates comparisons per shadow provider, sorted by provider name.
+func summarize(comparisons []comparison, threshold float64) []ProviderComparison {
+	type accumulator struct {
+		ProviderComparison
+		latencyTotal  int
+		scored        int
+		flagAgree     int
+		liveCorrect   int
+		shadowCorrect int
+		diffTotals    map[string]float64
+	}
+
+	byProvider := make(map[string]*accumulator)
+	for _, cmp := range comparisons {
+		acc, ok := byProvider[cmp.provider]
+		if !ok {
+			acc = &accumulator{
+				ProviderComparison: ProviderComparison{ProviderName: cmp.provider},
+				diffTotals:         make(map[string]float64),
+			}
+			byProvider[cmp.provider] = acc
+		}
+
+		acc.Classifications++
+		acc.latencyTotal += cmp.latencyMs
+		if cmp.failed || cmp.shadow == nil {
+			acc.Errors++
+			continue
+		}
+		acc.scored++
+
+		live := cmp.live.ToMap()
+		shadow := cmp.shadow.ToMap()
+		for cat, lv := range live {
+			diff := shadow[cat] - lv
+			if diff < 0 {
+				diff = -diff
+			}
+			acc.diffTotals[cat] += diff
+		}
+
+		liveFlagged := flagged(live, threshold)
+		shadowFlagged := flagged(shadow, threshold)
+		if liveFlagged == shadowFlagged {
+			acc.flagAgree++
+		}
+
+		harmful, conclusive := humanLabel(cmp.liveAction, cmp.reviewOutcome)
+		if !conclusive {
+			continue
+		}
+		acc.Reviewed++
+		if liveFlagged == harmful {
+			acc.liveCorrect++
+		}
+		if shadowFlagged == harmful {
+			acc.shadowCorrect++
+		}
+	}
+
+	results := make([]ProviderComparison, 0, len(byProvider))
+	for _, acc := range byProvider {
+		pc := acc.ProviderComparison
+		pc.MeanAbsDiff = make(map[string]float64, len(acc.diffTotals))
+		if acc.Classifications > 0 {
+			pc.AvgLatencyMs = float64(acc.latencyTotal) / float64(acc.Classifications)
+		}
+		if acc.scored > 0 {
+			for cat, total := range acc.diffTotals {
+				pc.MeanAbsDiff[cat] = total / float64(acc.scored)
+			}
+			pc.FlagAgreementRate = float64(acc.flagAgree) / float64(acc.scored)
+		}
+		if acc.Reviewed > 0 {
+			pc.LiveAccuracy = float64(acc.liveCorrect) / float64(acc.Reviewed)
+			pc.ShadowAccuracy = float64(acc.shadowCorrect) / float64(acc.Reviewed)
+		}
+		results = append(results, pc)
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].ProviderName < results[j].ProviderName
+	})
+	return results
+}
+
+// humanLabel derives whether a reviewer judged the content harmful from the
+// automated action and the calibration review outcome. "agree" confirms the
+// automated action, "disagree" inverts it; anything else is inconclusive.
+func humanLabel(action models.PolicyAction, outcome *string) (harmful bool, conclusive bool) {
+	if outcome == nil {
+		return false, false
+	}
+	automatedHarmful := action != models.ActionAllow
+	switch *outcome {
+	case "agree":
+		return automatedHarmful, true
+	case "disagree":
+		return !automatedHarmful, true
+	default:
+		return false, false
+	}
+}
+
+// flagged reports whether any category score is at or above threshold.
+func flagged(values map[string]float64, threshold float64) bool {
+	for _, v := range values {
+		if v >= threshold {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,104 @@
+package shadow
+
+import (
+	"math"
+	"testing"
+
+	"github.com/proth1/text-moderator/internal/models"
+)
+
+func strPtr(s string) *string { return &s }
+
+func TestHumanLabel(t *testing.T) {
+	tests := []struct {
+		name           string
+		action         models.PolicyAction
+		outcome        *string
+		wantHarmful    bool
+		wantConclusive bool
+	}{
+		{"no review", models.ActionBlock, nil, false, false},
+		{"agree with block", models.ActionBlock, strPtr("agree"), true, true},
+		{"disagree with block", models.ActionBlock, strPtr("disagree"), false, true},
+		{"agree with allow", models.ActionAllow, strPtr("agree"), false, true},
+		{"disagree with allow", models.ActionAllow, strPtr("disagree"), true, true},
+		{"uncertain", models.ActionWarn, strPtr("uncertain"), false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			harmful, conclusive := humanLabel(tt.action, tt.outcome)
+			if harmful != tt.wantHarmful || conclusive != tt.wantConclusive {
+				t.Errorf("got (%v, %v), want (%v, %v)", harmful, conclusive, tt.wantHarmful, tt.wantConclusive)
+			}
+		})
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	comparisons := []comparison{
+		// Shadow agrees with live; reviewer confirms the block.
+		{
+			provider:      "candidate",
+			shadow:        &models.CategoryScores{Toxicity: 0.9},
+			latencyMs:     100,
+			live:          models.CategoryScores{Toxicity: 0.8},
+			liveAction:    models.ActionBlock,
+			reviewOutcome: strPtr("agree"),
+		},
+		// Live blocked wrongly; shadow would have allowed, matching the reviewer.
+		{
+			provider:      "candidate",
+			shadow:        &models.CategoryScores{Toxicity: 0.2},
+			latencyMs:     300,
+			live:          models.CategoryScores{Toxicity: 0.7},
+			liveAction:    models.ActionBlock,
+			reviewOutcome: strPtr("disagree"),
+		},
+		// Shadow call failed.
+		{provider: "candidate", latencyMs: 200, failed: true, liveAction: models.ActionAllow},
+		// A second shadow provider with no reviews.
+		{
+			provider:   "another",
+			shadow:     &models.CategoryScores{Spam: 0.6},
+			live:       models.CategoryScores{},
+			liveAction: models.ActionAllow,
+		},
+	}
+
+	got := summarize(comparisons, 0.5)
+	if len(got) != 2 {
+		t.Fatalf("got %d providers, want 2", len(got))
+	}
+	if got[0].ProviderName != "another" || got[1].ProviderName != "candidate" {
+		t.Fatalf("providers not sorted: %s, %s", got[0].ProviderName, got[1].ProviderName)
+	}
+
+	c := got[1]
+	if c.Classifications != 3 || c.Errors != 1 {
+		t.Errorf("counts: got %d classifications / %d errors, want 3 / 1", c.Classifications, c.Errors)
+	}
+	if math.Abs(c.AvgLatencyMs-200) > 0.001 {
+		t.Errorf("avg latency = %f, want 200", c.AvgLatencyMs)
+	}
+	if math.Abs(c.MeanAbsDiff["toxicity"]-0.3) > 0.001 {
+		t.Errorf("toxicity mean abs diff = %f, want 0.3", c.MeanAbsDiff["toxicity"])
+	}
+	if math.Abs(c.FlagAgreementRate-0.5) > 0.001 {
+		t.Errorf("flag agreement = %f, want 0.5", c.FlagAgreementRate)
+	}
+	if c.Reviewed != 2 {
+		t.Errorf("reviewed = %d, want 2", c.Reviewed)
+	}
+	if math.Abs(c.LiveAccuracy-0.5) > 0.001 {
+		t.Errorf("live accuracy = %f, want 0.5", c.LiveAccuracy)
+	}
+	if math.Abs(c.ShadowAccuracy-1.0) > 0.001 {
+		t.Errorf("shadow accuracy = %f, want 1.0", c.ShadowAccuracy)
+	}
+
+	a := got[0]
+	if a.FlagAgreementRate != 0 || a.Reviewed != 0 {
+		t.Errorf("another: got agreement %f reviewed %d, want 0 / 0", a.FlagAgreementRate, a.Reviewed)
+	}
+}
@@ -0,0 +1,28 @@
+#!/usr/bin/env bash
+# Replace the normalizer's embedded confusables table with the full Unicode
+# UTS #39 confusables.txt. Rebuild the moderation service afterwards.
+#
+# Usage: ./scripts/update-confusables.sh [unicode-version]   (default: latest)
+
+set -euo pipefail
+
+SCRIPT_DIR="$(cd "$(dirname "$0")" && pwd)"
+ROOT_DIR="$(dirname "$SCRIPT_DIR")"
+DEST="$ROOT_DIR/internal/normalizer/data/confusables.txt"
+VERSION="${1:-latest}"
+URL="https://www.unicode.org/Public/security/$VERSION/confusables.txt"
+
+TMP="$(mktemp)"
+trap 'rm -f "$TMP"' EXIT
+
+echo "Downloading $URL"
+curl -fsSL "$URL" -o "$TMP"
+
+if ! grep -q '; *MA' "$TMP"; then
+  echo "Error: $URL does not look like confusables.txt"
+  exit 1
+fi
+
+mv "$TMP" "$DEST"
+trap - EXIT
+echo "Updated $DEST"
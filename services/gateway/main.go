@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
 	"os/signal"
 	"strings"
@@ -200,7 +201,31 @@ func setupRouter(cfg *config.Config, logger *zap.Logger, metrics *observability.
 		v1.GET("/policies", proxyHandler(cfg, logger, "policy-engine", "/policies"))
 		v1.POST("/policies", proxyHandler(cfg, logger, "policy-engine", "/policies"))
 		v1.GET("/policies/:id", proxyHandler(cfg, logger, "policy-engine", "/policies/:id"))
+		v1.GET("/policies/by-name/:name/versions", proxyHandler(cfg, logger, "policy-engine", "/policies/by-name/:name/versions"))
+		v1.GET("/policies/:id/diff", proxyHandler(cfg, logger, "policy-engine", "/policies/:id/diff"))
 		v1.POST("/policies/:id/evaluate", proxyHandler(cfg, logger, "policy-engine", "/policies/:id/evaluate"))
+		v1.POST("/policies/:id/publish", proxyHandler(cfg, logger, "policy-engine", "/policies/:id/publish"))
+		v1.POST("/policies/:id/archive", proxyHandler(cfg, logger, "policy-engine", "/policies/:id/archive"))
+		v1.POST("/policies/:id/rollback", proxyHandler(cfg, logger, "policy-engine", "/policies/:id/rollback"))
+		v1.POST("/policies/:id/simulate", proxyHandler(cfg, logger, "policy-engine", "/policies/:id/simulate"))
+		v1.GET("/simulations/:id", proxyHandler(cfg, logger, "policy-engine", "/simulations/:id"))
+		v1.GET("/simulations/:id/flips", proxyHandler(cfg, logger, "policy-engine", "/simulations/:id/flips"))
+		v1.GET("/simulations/:id/export", proxyHandler(cfg, logger, "policy-engine", "/simulations/:id/export"))
+		v1.GET("/policies/by-name/:name/test-cases", proxyHandler(cfg, logger, "policy-engine", "/policies/by-name/:name/test-cases"))
+		v1.POST("/policies/by-name/:name/test-cases", proxyHandler(cfg, logger, "policy-engine", "/policies/by-name/:name/test-cases"))
+		v1.DELETE("/policies/by-name/:name/test-cases/:case_id", proxyHandler(cfg, logger, "policy-engine", "/policies/by-name/:name/test-cases/:case_id"))
+		v1.POST("/policies/:id/test-runs", proxyHandler(cfg, logger, "policy-engine", "/policies/:id/test-runs"))
+		v1.GET("/policies/:id/test-runs", proxyHandler(cfg, logger, "policy-engine", "/policies/:id/test-runs"))
+		v1.GET("/policies/:id/prompt-templates", proxyHandler(cfg, logger, "policy-engine", "/policies/:id/prompt-templates"))
+		v1.POST("/policies/:id/prompt-templates", proxyHandler(cfg, logger, "policy-engine", "/policies/:id/prompt-templates"))
+		v1.GET("/prompt-templates/:id", proxyHandler(cfg, logger, "policy-engine", "/prompt-templates/:id"))
+		v1.POST("/prompt-templates/:id/publish", proxyHandler(cfg, logger, "policy-engine", "/prompt-templates/:id/publish"))
+
+		// Lexicon management proxy
+		v1.GET("/lexicons", proxyHandler(cfg, logger, "policy-engine", "/lexicons"))
+		v1.POST("/lexicons", proxyHandler(cfg, logger, "policy-engine", "/lexicons"))
+		v1.GET("/lexicons/:id", proxyHandler(cfg, logger, "policy-engine", "/lexicons/:id"))
+		v1.POST("/lexicons/:id/publish", proxyHandler(cfg, logger, "policy-engine", "/lexicons/:id/publish"))
 
 		// Review service proxy
 		v1.GET("/reviews", proxyHandler(cfg, logger, "review", "/reviews"))
@@ -219,6 +244,12 @@ func setupRouter(cfg *config.Config, logger *zap.Logger, metrics *observability.
 		// Compliance report generation proxy
 		v1.POST("/reports/generate", proxyHandler(cfg, logger, "review", "/reports/generate"))
 		v1.GET("/reports/fairness", proxyHandler(cfg, logger, "review", "/reports/fairness"))
+		v1.GET("/reports/shadow", proxyHandler(cfg, logger, "review", "/reports/shadow"))
+
+		// Score calibration proxy
+		v1.GET("/calibrations", proxyHandler(cfg, logger, "review", "/calibrations"))
+		v1.POST("/calibrations/fit", proxyHandler(cfg, logger, "review", "/calibrations/fit"))
+		v1.POST("/calibrations/:id/activate", proxyHandler(cfg, logger, "review", "/calibrations/:id/activate"))
 
 		// Batch moderation proxy
 		v1.POST("/moderate/batch", proxyHandler(cfg, logger, "moderation", "/moderate/batch"))
@@ -226,6 +257,13 @@ func setupRouter(cfg *config.Config, logger *zap.Logger, metrics *observability.
 		// Async moderation proxy
 		v1.POST("/moderate/async", proxyHandler(cfg, logger, "moderation", "/moderate/async"))
 
+		// Conversation-context moderation proxy
+		v1.POST("/moderate/conversation", proxyHandler(cfg, logger, "moderation", "/moderate/conversation"))
+
+		// Provider status and circuit breaker overrides proxy
+		v1.GET("/providers", proxyHandler(cfg, logger, "moderation", "/providers"))
+		v1.POST("/providers/:name/breaker", proxyHandler(cfg, logger, "moderation", "/providers/:name/breaker"))
+
 		// GDPR erasure proxy
 		v1.DELETE("/submissions/:hash", proxyHandler(cfg, logger, "review", "/submissions/:hash"))
 
@@ -295,10 +333,7 @@ func healthHandler(cfg *config.Config, logger *zap.Logger) gin.HandlerFunc {
 func serviceBaseURL(cfg *config.Config, service string) string {
 	switch service {
 	case "moderation":
-		if cfg.ModerationURL != "" {
-			return strings.TrimRight(cfg.ModerationURL, "/")
-		}
-		return fmt.Sprintf("http://%s:%s", cfg.ModerationHost, cfg.ModerationPort)
+		return cfg.ModerationBaseURL()
 	case "policy-engine":
 		if cfg.PolicyEngineURL != "" {
 			return strings.TrimRight(cfg.PolicyEngineURL, "/")
@@ -383,6 +418,13 @@ func proxyHandler(cfg *config.Config, logger *zap.Logger, service string, path s
 			proxyReq.Header.Set(internalServiceTokenHeader, cfg.InternalServiceToken)
 		}
 
+		// Identify the caller's API key to internal services however it was
+		// sent, since only X-API-Key is forwarded. The hash is computed from
+		// the key itself; a client's own X-API-Key-Hash is never forwarded.
+		if key := middleware.APIKey(c); key != "" {
+			proxyReq.Header.Set(middleware.APIKeyHashHeader, middleware.HashAPIKey(key))
+		}
+
 		// Send request using shared client with connection pooling
 		resp, err := sharedHTTPClient.Do(proxyReq)
 		if err != nil {
@@ -426,6 +468,8 @@ func proxyHandler(cfg *config.Config, logger *zap.Logger, service string, path s
 	}
 }
 
-func replacePathParam(url, key, value string) string {
-	return strings.Replace(url, ":"+key, value, 1)
+// replacePathParam substitutes a path parameter, escaping it since gin
+// unescapes parameters such as policy names containing spaces.
+func replacePathParam(target, key, value string) string {
+	return strings.Replace(target, ":"+key, url.PathEscape(value), 1)
 }
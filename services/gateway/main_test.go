@@ -0,0 +1,50 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/proth1/text-moderator/internal/config"
+	"github.com/proth1/text-moderator/internal/middleware"
+	"go.uber.org/zap"
+)
+
+func TestProxyHandler_ForwardsAPIKeyHash(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name    string
+		headers map[string]string
+	}{
+		{"x-api-key", map[string]string{middleware.APIKeyHeader: "key-1"}},
+		{"bearer", map[string]string{middleware.AuthorizationHeader: "Bearer key-1"}},
+		{"client hash ignored", map[string]string{middleware.AuthorizationHeader: "Bearer key-1", middleware.APIKeyHashHeader: "forged"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r.Header.Get(middleware.APIKeyHashHeader)
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer backend.Close()
+
+			router := gin.New()
+			router.POST("/moderate", proxyHandler(&config.Config{ModerationURL: backend.URL}, zap.NewNop(), "moderation", "/moderate"))
+
+			req := httptest.NewRequest(http.MethodPost, "/moderate", strings.NewReader(`{}`))
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			router.ServeHTTP(httptest.NewRecorder(), req)
+
+			if want := middleware.HashAPIKey("key-1"); got != want {
+				t.Errorf("forwarded key hash %q, want %q", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/proth1/text-moderator/internal/middleware"
+	"github.com/proth1/text-moderator/internal/models"
+	"go.uber.org/zap"
+)
+
+// Control: POL-001 (Policy test content classified by the moderation pipeline)
+
+// ModerationClient calls the moderation service from other services
+type ModerationClient struct {
+	baseURL      string
+	serviceToken string
+	httpClient   *http.Client
+	logger       *zap.Logger
+}
+
+// ModerationConfig holds moderation service client configuration
+type ModerationConfig struct {
+	BaseURL      string
+	ServiceToken string // internal service token; empty in development
+	Timeout      time.Duration
+}
+
+// NewModerationClient creates a new moderation service client
+func NewModerationClient(cfg ModerationConfig, logger *zap.Logger) *ModerationClient {
+	return &ModerationClient{
+		baseURL:      cfg.BaseURL,
+		serviceToken: cfg.ServiceToken,
+		httpClient: &http.Client{
+			Timeout: cfg.Timeout,
+		},
+		logger: logger,
+	}
+}
+
+// Classify classifies content for a policy through the moderation pipeline,
+// as a moderation request applying the policy would be, without recording a
+// decision.
+func (c *ModerationClient) Classify(ctx context.Context, policyID uuid.UUID, content string) (*models.ClassifyResponse, error) {
+	jsonData, err := json.Marshal(models.ClassifyRequest{PolicyID: policyID, Content: content})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/classify", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.serviceToken != "" {
+		req.Header.Set(middleware.InternalServiceHeader, c.serviceToken)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		c.logger.Warn("moderation service classify error",
+			zap.Int("status_code", resp.StatusCode),
+			zap.String("policy_id", policyID.String()),
+		)
+		return nil, fmt.Errorf("moderation service returned status %d", resp.StatusCode)
+	}
+
+	var classified models.ClassifyResponse
+	if err := json.Unmarshal(body, &classified); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	return &classified, nil
+}
@@ -7,29 +7,39 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
 	"os/signal"
 	"regexp"
+	"sort"
 	"sync"
 	"syscall"
 	"time"
+	"unicode/utf8"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/proth1/text-moderator/internal/behavior"
 	"github.com/proth1/text-moderator/internal/cache"
+	"github.com/proth1/text-moderator/internal/calibration"
 	"github.com/proth1/text-moderator/internal/classifier"
 	"github.com/proth1/text-moderator/internal/config"
 	"github.com/proth1/text-moderator/internal/database"
 	"github.com/proth1/text-moderator/internal/evidence"
+	"github.com/proth1/text-moderator/internal/feedback"
+	"github.com/proth1/text-moderator/internal/injection"
 	"github.com/proth1/text-moderator/internal/langdetect"
+	"github.com/proth1/text-moderator/internal/lexicon"
 	"github.com/proth1/text-moderator/internal/middleware"
 	"github.com/proth1/text-moderator/internal/models"
 	"github.com/proth1/text-moderator/internal/normalizer"
 	"github.com/proth1/text-moderator/internal/observability"
+	"github.com/proth1/text-moderator/internal/prompt"
+	"github.com/proth1/text-moderator/internal/shadow"
 	"github.com/proth1/text-moderator/internal/webhook"
 	"github.com/proth1/text-moderator/services/moderation/client"
 	"github.com/proth1/text-moderator/services/policy-engine/engine"
@@ -111,11 +121,38 @@ func main() {
 			Name: "openai", Priority: 3, Enabled: true,
 		})
 	}
+	if cfg.LexiconEnabled {
+		providerConfigs = append(providerConfigs, classifier.ProviderConfig{
+			Name: "lexicon", Priority: cfg.LexiconPriority, Enabled: true,
+		})
+	}
+
+	// Apply optional priority/weight overrides (e.g. to canary a provider at a fraction of traffic)
+	providerConfigs, err = classifier.ApplyRoutingJSON(providerConfigs, cfg.ProviderRoutingJSON)
+	if err != nil {
+		logger.Warn("ignoring provider routing overrides", zap.Error(err))
+	}
+	providerConfigs, err = classifier.ApplyBreakerJSON(providerConfigs, cfg.ProviderBreakerJSON)
+	if err != nil {
+		logger.Warn("ignoring circuit breaker overrides", zap.Error(err))
+	}
 
-	orchestrator := classifier.NewOrchestrator(classifier.OrchestratorConfig{
+	orchestratorConfig := classifier.OrchestratorConfig{
 		Providers:       providerConfigs,
 		FallbackEnabled: true,
-	}, logger)
+		LatencyBudget:   cfg.ClassifyLatencyBudget,
+	}
+	if cfg.HedgingEnabled {
+		orchestratorConfig.Hedging = &classifier.HedgingConfig{
+			Enabled:      true,
+			Percentile:   float64(cfg.HedgingPercentile) / 100,
+			InitialDelay: cfg.HedgingInitialDelay,
+			MinDelay:     50 * time.Millisecond,
+		}
+		logger.Info("hedged provider requests enabled", zap.Int("percentile", cfg.HedgingPercentile))
+	}
+
+	orchestrator := classifier.NewOrchestrator(orchestratorConfig, logger)
 
 	// Register providers
 	orchestrator.RegisterProvider(classifier.NewHuggingFaceProvider(hfClient))
@@ -132,26 +169,97 @@ func main() {
 		}, logger))
 	}
 
-	// Initialize optional score calibrator
-	if cal := classifier.NewCalibratorFromJSON(cfg.CalibrationConfigJSON); cal != nil {
-		orchestrator.SetCalibrator(cal)
+	// Register the offline lexicon provider and keep it in sync with published lists
+	lexiconCtx, lexiconCancel := context.WithCancel(context.Background())
+	if cfg.LexiconEnabled {
+		lexiconProvider := classifier.NewLexiconProvider(lexicon.NewStore(db.Pool, logger), logger)
+		if err := lexiconProvider.Reload(ctx); err != nil {
+			logger.Warn("failed to load lexicon lists", zap.Error(err))
+		}
+		orchestrator.RegisterProvider(lexiconProvider)
+
+		go func() {
+			ticker := time.NewTicker(cfg.LexiconReloadInterval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-lexiconCtx.Done():
+					return
+				case <-ticker.C:
+					if err := lexiconProvider.Reload(lexiconCtx); err != nil {
+						logger.Warn("failed to reload lexicon lists", zap.Error(err))
+					}
+				}
+			}
+		}()
+	}
+
+	// Initialize optional score calibrator. An active fitted calibration version
+	// takes precedence over the hand-written CALIBRATION_CONFIG_JSON.
+	staticCalibrator := classifier.NewCalibratorFromJSON(cfg.CalibrationConfigJSON)
+	if staticCalibrator != nil {
+		orchestrator.SetCalibrator(staticCalibrator)
 		logger.Info("score calibration enabled")
 	}
+	calibrationStore := calibration.NewStore(db.Pool, logger)
+	reloadCalibration(ctx, orchestrator, calibrationStore, staticCalibrator, logger)
+
+	calibrationCtx, calibrationCancel := context.WithCancel(context.Background())
+	go func() {
+		ticker := time.NewTicker(cfg.CalibrationReloadInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-calibrationCtx.Done():
+				return
+			case <-ticker.C:
+				reloadCalibration(calibrationCtx, orchestrator, calibrationStore, staticCalibrator, logger)
+			}
+		}
+	}()
 
 	// Initialize optional LLM provider for second-pass classification
 	var llmProvider *classifier.LLMProvider
-	if cfg.LLMProvider != "" && cfg.LLMAPIKey != "" {
-		llmProvider = classifier.NewLLMProvider(classifier.LLMConfig{
-			Provider: cfg.LLMProvider,
-			APIKey:   cfg.LLMAPIKey,
-			Model:    cfg.LLMModel,
-		}, logger)
-		logger.Info("LLM second-pass classification enabled",
-			zap.String("provider", cfg.LLMProvider),
-			zap.String("model", cfg.LLMModel),
-		)
+	if cfg.LLMProvider != "" {
+		llmConfig := classifier.LLMConfig{
+			Provider:       cfg.LLMProvider,
+			APIKey:         cfg.LLMAPIKey,
+			Model:          cfg.LLMModel,
+			BaseURL:        cfg.LLMBaseURL,
+			ResponseFormat: cfg.LLMResponseFormat,
+		}
+		if err := llmConfig.Validate(); err != nil {
+			logger.Warn("LLM second-pass classification disabled", zap.Error(err))
+		} else {
+			llmProvider = classifier.NewLLMProvider(llmConfig, logger)
+			logger.Info("LLM second-pass classification enabled",
+				zap.String("provider", cfg.LLMProvider),
+				zap.String("model", cfg.LLMModel),
+				zap.String("base_url", cfg.LLMBaseURL),
+			)
+		}
 	}
 
+	// Optional translation of content in languages no provider supports
+	switch cfg.Translator {
+	case "":
+	case "llm":
+		if llmProvider != nil {
+			orchestrator.SetTranslator(llmProvider)
+			logger.Info("translation pivot enabled", zap.String("translator", llmProvider.Name()))
+		} else {
+			logger.Warn("translation pivot disabled: TRANSLATOR=llm requires an LLM provider")
+		}
+	case "passthrough":
+		orchestrator.SetTranslator(classifier.PassthroughTranslator{})
+		logger.Warn("translation pivot using the passthrough stand-in; content is classified untranslated")
+	default:
+		logger.Warn("translation pivot disabled: unknown translator", zap.String("translator", cfg.Translator))
+	}
+
+	// Per-policy prompt templates for the LLM second pass
+	promptStore := prompt.NewStore(db.Pool, logger)
+
 	// Configure ensemble mode if enabled
 	if cfg.EnsembleEnabled {
 		orchestrator.SetEnsembleConfig(&classifier.EnsembleConfig{
@@ -159,12 +267,14 @@ func main() {
 			MinProviders:       2,
 			AgreementThreshold: 0.3,
 			Strategy:           cfg.EnsembleStrategy,
+			EarlyExitMargin:    cfg.EnsembleEarlyExitMargin,
 		})
 		logger.Info("ensemble classification mode enabled", zap.String("strategy", cfg.EnsembleStrategy))
 	}
 
-	// Initialize text normalizer for Unicode evasion defense
-	textNormalizer := normalizer.New()
+	// Initialize text normalizers for Unicode evasion defense, built per
+	// normalization profile on first use
+	normalizers := normalizer.NewRegistry()
 
 	// Initialize language detector
 	langDetector := langdetect.New()
@@ -172,12 +282,36 @@ func main() {
 	// Initialize policy evaluator
 	evaluator := engine.NewEvaluator(db.Pool, logger)
 
+	// Keep weighted-ensemble weights and early-exit thresholds in sync with review
+	// feedback and published policies; refreshed periodically, never per request
+	ensembleCtx, ensembleCancel := context.WithCancel(context.Background())
+	if cfg.EnsembleEnabled {
+		feedbackTracker := feedback.NewTracker(db.Pool, logger)
+		refreshEnsembleInputs(ctx, orchestrator, feedbackTracker, evaluator, logger)
+
+		go func() {
+			ticker := time.NewTicker(cfg.EnsembleWeightsRefreshInterval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ensembleCtx.Done():
+					return
+				case <-ticker.C:
+					refreshEnsembleInputs(ensembleCtx, orchestrator, feedbackTracker, evaluator, logger)
+				}
+			}
+		}()
+	}
+
 	// Initialize behavioral scorer for user trust scores
 	behaviorScorer := behavior.NewScorer(db.Pool, logger)
 
 	// Initialize evidence writer
 	evidenceWriter := evidence.NewWriter(db.Pool, logger)
 
+	// Initialize shadow classification recorder (used only when shadow providers are configured)
+	shadowRecorder := shadow.NewRecorder(db.Pool, logger)
+
 	// Initialize webhook dispatcher
 	webhookDispatcher := webhook.NewDispatcher(db.Pool, logger)
 
@@ -192,11 +326,31 @@ func main() {
 	// Initialize Prometheus metrics
 	metrics := observability.NewMetrics("moderation")
 
+	// Publish circuit breaker transitions as a gauge and a webhook event
+	for name, state := range orchestrator.BreakerStates() {
+		metrics.CircuitBreakerState.WithLabelValues(name).Set(breakerStateValue(state))
+	}
+	orchestrator.OnBreakerStateChange(func(t classifier.BreakerTransition) {
+		metrics.CircuitBreakerState.WithLabelValues(t.Provider).Set(breakerStateValue(t.To))
+		event := models.ProviderBreakerEvent{
+			Provider:      t.Provider,
+			PreviousState: t.From,
+			State:         t.To,
+			Forced:        t.Forced,
+			ChangedAt:     time.Now().UTC(),
+		}
+		go func() {
+			bgCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+			webhookDispatcher.Dispatch(bgCtx, models.EventProviderBreakerChanged, event)
+		}()
+	})
+
 	// Initialize async worker pool for background moderation jobs
 	asyncPool := newAsyncWorkerPool(1000, 5, logger)
 
 	// Create HTTP server
-	router := setupRouter(cfg, logger, db, hfClient, evaluator, evidenceWriter, redisCache, orchestrator, webhookDispatcher, textNormalizer, langDetector, llmProvider, behaviorScorer, metrics, asyncPool)
+	router := setupRouter(cfg, logger, db, hfClient, evaluator, evidenceWriter, redisCache, orchestrator, webhookDispatcher, normalizers, langDetector, llmProvider, promptStore, behaviorScorer, shadowRecorder, metrics, asyncPool)
 	srv := &http.Server{
 		Addr:              fmt.Sprintf(":%s", cfg.ModerationPort),
 		Handler:           router,
@@ -220,6 +374,9 @@ func main() {
 	<-quit
 
 	logger.Info("shutting down moderation service")
+	lexiconCancel()     // stop background lexicon reloads
+	ensembleCancel()    // stop background ensemble weight refreshes
+	calibrationCancel() // stop background calibration reloads
 
 	// Graceful shutdown: stop accepting new HTTP requests first
 	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -236,7 +393,7 @@ func main() {
 	logger.Info("moderation service stopped")
 }
 
-func setupRouter(cfg *config.Config, logger *zap.Logger, db *database.PostgresDB, hfClient *client.HuggingFaceClient, evaluator *engine.Evaluator, evidenceWriter *evidence.Writer, redisCache *cache.RedisCache, orchestrator *classifier.Orchestrator, webhookDispatcher *webhook.Dispatcher, textNormalizer *normalizer.Normalizer, langDetector *langdetect.Detector, llmProvider *classifier.LLMProvider, behaviorScorer *behavior.Scorer, metrics *observability.Metrics, asyncPool *asyncWorkerPool) *gin.Engine {
+func setupRouter(cfg *config.Config, logger *zap.Logger, db *database.PostgresDB, hfClient *client.HuggingFaceClient, evaluator *engine.Evaluator, evidenceWriter *evidence.Writer, redisCache *cache.RedisCache, orchestrator *classifier.Orchestrator, webhookDispatcher *webhook.Dispatcher, normalizers *normalizer.Registry, langDetector *langdetect.Detector, llmProvider *classifier.LLMProvider, promptStore *prompt.Store, behaviorScorer *behavior.Scorer, shadowRecorder *shadow.Recorder, metrics *observability.Metrics, asyncPool *asyncWorkerPool) *gin.Engine {
 	if cfg.Environment == "production" {
 		gin.SetMode(gin.ReleaseMode)
 	}
@@ -264,16 +421,146 @@ func setupRouter(cfg *config.Config, logger *zap.Logger, db *database.PostgresDB
 	} else {
 		logger.Warn("INTERNAL_SERVICE_TOKEN not configured - internal endpoints are unprotected (development mode only)")
 	}
-	api.POST("/moderate", moderateHandler(db, orchestrator, evaluator, evidenceWriter, redisCache, webhookDispatcher, cfg, logger, textNormalizer, langDetector, llmProvider, behaviorScorer, metrics))
+	syncHandler := moderateHandler(db, orchestrator, evaluator, evidenceWriter, redisCache, webhookDispatcher, cfg, logger, normalizers, langDetector, llmProvider, promptStore, behaviorScorer, shadowRecorder, metrics)
+	api.POST("/moderate", syncHandler)
+	api.POST("/moderate/conversation", conversationModerateHandler(db, orchestrator, evaluator, evidenceWriter, redisCache, webhookDispatcher, cfg, logger, normalizers, langDetector, llmProvider, promptStore, behaviorScorer, shadowRecorder, metrics))
+
+	// Classification without recording a decision, for policy test suites
+	api.POST("/classify", classifyHandler(orchestrator, evaluator, redisCache, cfg, logger, normalizers, langDetector, llmProvider, promptStore, metrics))
 
 	// Batch and async endpoints use idempotency middleware to prevent duplicate processing
 	idempotencyMW := middleware.IdempotencyMiddleware(redisCache, logger)
-	api.POST("/moderate/batch", idempotencyMW, batchModerateHandler(db, orchestrator, evaluator, evidenceWriter, redisCache, webhookDispatcher, cfg, logger, textNormalizer, langDetector, llmProvider, behaviorScorer, metrics))
-	api.POST("/moderate/async", idempotencyMW, asyncModerateHandler(db, orchestrator, evaluator, evidenceWriter, redisCache, webhookDispatcher, cfg, logger, textNormalizer, langDetector, llmProvider, behaviorScorer, metrics, asyncPool))
+	api.POST("/moderate/batch", idempotencyMW, batchModerateHandler(db, orchestrator, evaluator, evidenceWriter, redisCache, webhookDispatcher, cfg, logger, normalizers, langDetector, llmProvider, behaviorScorer, shadowRecorder, metrics))
+	api.POST("/moderate/async", idempotencyMW, asyncModerateHandler(db, orchestrator, evaluator, evidenceWriter, redisCache, webhookDispatcher, cfg, logger, normalizers, langDetector, llmProvider, promptStore, behaviorScorer, shadowRecorder, metrics, asyncPool))
+
+	// Provider status and circuit breaker overrides - also require an operator API key
+	providers := api.Group("/providers")
+	providers.Use(middleware.AuthMiddleware(db.Pool, logger))
+	providers.GET("", middleware.RequireRole("admin", "moderator"), listProvidersHandler(orchestrator))
+	providers.POST("/:name/breaker", middleware.RequireRole("admin"), setBreakerModeHandler(orchestrator, logger))
 
 	return router
 }
 
+// listProvidersHandler reports health, circuit breaker state and counts for each provider.
+// Control: MOD-005 (Multi-Provider Classification Orchestration)
+func listProvidersHandler(orchestrator *classifier.Orchestrator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+		defer cancel()
+
+		statuses := orchestrator.ProviderStatuses(ctx)
+		c.JSON(http.StatusOK, gin.H{
+			"providers": statuses,
+			"total":     len(statuses),
+		})
+	}
+}
+
+// setBreakerModeHandler forces a provider's circuit breaker open or closed during
+// an incident, or returns it to automatic operation.
+func setBreakerModeHandler(orchestrator *classifier.Orchestrator, logger *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := middleware.MustGetUserID(c)
+		if userID == uuid.Nil {
+			return
+		}
+
+		var req models.SetBreakerModeRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			// SECURITY: Don't expose detailed parsing errors to clients
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+			return
+		}
+
+		name := c.Param("name")
+		if err := orchestrator.SetBreakerMode(name, classifier.BreakerMode(req.Mode)); err != nil {
+			if errors.Is(err, classifier.ErrUnknownProvider) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "provider not found"})
+				return
+			}
+			logger.Error("failed to set breaker mode", zap.String("provider", name), zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to set breaker mode"})
+			return
+		}
+
+		logger.Warn("provider circuit breaker overridden",
+			zap.String("provider", name),
+			zap.String("mode", req.Mode),
+			zap.String("user_id", userID.String()),
+		)
+		c.JSON(http.StatusOK, gin.H{"provider": name, "mode": req.Mode})
+	}
+}
+
+// reloadCalibration applies the active fitted calibration version when it has
+// changed, falling back to the static calibrator when none is active. Failures
+// keep the current calibrator.
+func reloadCalibration(ctx context.Context, orchestrator *classifier.Orchestrator, store *calibration.Store, static *classifier.Calibrator, logger *zap.Logger) {
+	active, err := store.Active(ctx)
+	if err != nil {
+		logger.Warn("failed to load active calibration", zap.Error(err))
+		return
+	}
+
+	current := orchestrator.CalibrationVersion()
+	if active == nil {
+		if current != nil {
+			orchestrator.SetCalibrator(static)
+			logger.Info("fitted calibration deactivated", zap.Int("previous_version", *current))
+		}
+		return
+	}
+	if current != nil && *current == active.Version {
+		return
+	}
+
+	cal, err := calibration.Calibrator(active)
+	if err != nil {
+		logger.Warn("failed to apply calibration", zap.Int("version", active.Version), zap.Error(err))
+		return
+	}
+	orchestrator.SetCalibrator(cal)
+	logger.Info("fitted calibration applied", zap.Int("version", active.Version), zap.String("method", active.Method))
+}
+
+// refreshEnsembleInputs reloads the weighted-ensemble weights from review
+// feedback and the thresholds of published policies used for early exit.
+// Failures keep the previous values.
+func refreshEnsembleInputs(ctx context.Context, orchestrator *classifier.Orchestrator, tracker *feedback.Tracker, evaluator *engine.Evaluator, logger *zap.Logger) {
+	weights, err := tracker.EnsembleWeights(ctx)
+	if err != nil {
+		logger.Warn("failed to refresh ensemble weights", zap.Error(err))
+	} else {
+		orchestrator.SetEnsembleWeights(weights)
+	}
+
+	published := models.PolicyStatusPublished
+	policies, err := evaluator.ListPolicies(ctx, &published)
+	if err != nil {
+		logger.Warn("failed to refresh ensemble decision thresholds", zap.Error(err))
+		return
+	}
+	thresholds := make(map[string][]float64)
+	for _, p := range policies {
+		for cat, t := range p.Thresholds {
+			thresholds[cat] = append(thresholds[cat], t)
+		}
+	}
+	orchestrator.SetDecisionThresholds(thresholds)
+}
+
+// breakerStateValue maps a breaker state name to the provider_circuit_breaker_state gauge value.
+func breakerStateValue(state string) float64 {
+	switch state {
+	case "half-open":
+		return 1
+	case "open":
+		return 2
+	}
+	return 0
+}
+
 func healthHandler(db *database.PostgresDB, hfClient *client.HuggingFaceClient, logger *zap.Logger) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
@@ -325,9 +612,242 @@ var sourcePattern = regexp.MustCompile(`^[a-zA-Z0-9\-]+$`)
 // classificationCacheTTL is how long cached classification results remain valid.
 const classificationCacheTTL = 15 * time.Minute
 
-func moderateHandler(db *database.PostgresDB, orchestrator *classifier.Orchestrator, evaluator *engine.Evaluator, evidenceWriter *evidence.Writer, redisCache *cache.RedisCache, webhookDispatcher *webhook.Dispatcher, cfg *config.Config, logger *zap.Logger, textNormalizer *normalizer.Normalizer, langDetector *langdetect.Detector, llmProvider *classifier.LLMProvider, behaviorScorer *behavior.Scorer, metrics *observability.Metrics) gin.HandlerFunc {
+// chunkedClassification is the aggregated classification of content that was
+// split into windows.
+type chunkedClassification struct {
+	scores         *models.CategoryScores
+	classResult    *classifier.ClassificationResult // first window a provider classified; nil when all were cached
+	ensembleResult *classifier.EnsembleResult       // merged across windows in ensemble mode
+	attributions   map[string]models.ChunkAttribution
+	spans          []classifier.TextSpan // byte offsets into the whole text
+	cacheHit       bool                  // every window was served from cache
+}
+
+// cachedChunk is a window's classification as cached under its
+// classify:chunk: key. Spans are relative to the window, so a cached window
+// contributes flagged spans the same as a classified one. Entries without
+// scores, cached before spans were, are treated as misses.
+type cachedChunk struct {
+	Scores *models.CategoryScores `json:"scores"`
+	Spans  []classifier.TextSpan  `json:"spans,omitempty"`
+}
+
+// translationSource returns the language content was translated from before
+// classification, or "" if it was classified untranslated. Cached scores come
+// without a classification result, so for them it is decided from the
+// detected languages; ensemble mode never translates.
+func translationSource(orchestrator *classifier.Orchestrator, classResult *classifier.ClassificationResult, cacheHit bool, langs []string) string {
+	if classResult != nil {
+		if classResult.Translated {
+			return classResult.SourceLanguage
+		}
+		return ""
+	}
+	if cacheHit && !orchestrator.IsEnsembleEnabled() {
+		return orchestrator.TranslationSource(langs)
+	}
+	return ""
+}
+
+// flaggedSpans maps spans found in the normalized content back to the original
+// content, as character offsets. Spans reported more than once, e.g. by
+// overlapping windows, are listed once with their highest score.
+func flaggedSpans(content string, alignment *normalizer.Alignment, spans []classifier.TextSpan) []models.FlaggedSpan {
+	if len(spans) == 0 {
+		return nil
+	}
+	type spanKey struct {
+		category, source string
+		start, end       int
+	}
+	index := make(map[spanKey]int, len(spans))
+	var flagged []models.FlaggedSpan
+	for _, span := range spans {
+		start, end := alignment.Original(span.Start, span.End)
+		if start >= end {
+			continue
+		}
+		key := spanKey{span.Category, span.Source, start, end}
+		if i, ok := index[key]; ok {
+			flagged[i].Score = max(flagged[i].Score, span.Score)
+			continue
+		}
+		index[key] = len(flagged)
+		flagged = append(flagged, models.FlaggedSpan{
+			Category: span.Category,
+			Start:    utf8.RuneCountInString(content[:start]),
+			End:      utf8.RuneCountInString(content[:end]),
+			Text:     content[start:end],
+			Score:    span.Score,
+			Source:   span.Source,
+		})
+	}
+	sort.SliceStable(flagged, func(i, j int) bool {
+		if flagged[i].Start != flagged[j].Start {
+			return flagged[i].Start < flagged[j].Start
+		}
+		return flagged[i].End < flagged[j].End
+	})
+	return flagged
+}
+
+// newInjectionScreen screens submissions for prompt injection, remembering
+// model-based scores alongside cached classifications when Redis is available.
+func newInjectionScreen(redisCache *cache.RedisCache) *injection.Screen {
+	if redisCache == nil {
+		return injection.NewScreen(nil, 0)
+	}
+	return injection.NewScreen(redisCache, classificationCacheTTL)
+}
+
+// requestPolicy returns the policy a request is evaluated against and how it
+// was chosen: the requested policy, or else the policy selected by scope
+// routes or as the default. It fails closed: a requested policy that does not
+// exist or is not in force is rejected rather than replaced, and a request no
+// policy can be resolved for fails rather than being allowed. Errors are
+// *moderationError.
+func requestPolicy(ctx context.Context, evaluator *engine.Evaluator, logger *zap.Logger, policyID *uuid.UUID, resolveReq *engine.ResolveRequest) (*models.Policy, *models.PolicyResolution, error) {
+	if policyID != nil {
+		policy, err := evaluator.GetPolicyInForce(ctx, *policyID)
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			return nil, nil, &moderationError{status: http.StatusNotFound, message: "policy not found"}
+		case errors.Is(err, engine.ErrPolicyNotInForce):
+			return nil, nil, &moderationError{status: http.StatusConflict, message: "policy is not in force"}
+		case err != nil:
+			logger.Error("failed to get requested policy",
+				zap.String("requested_policy_id", policyID.String()),
+				zap.Error(err),
+			)
+			return nil, nil, &moderationError{status: http.StatusInternalServerError, message: "failed to get policy"}
+		}
+		return policy, &models.PolicyResolution{PolicyID: policy.ID, Method: models.ResolvedByRequest}, nil
+	}
+
+	policy, resolution, err := evaluator.ResolvePolicy(ctx, resolveReq)
+	if err != nil {
+		logger.Error("failed to resolve policy", zap.Error(err))
+		return nil, nil, &moderationError{status: http.StatusInternalServerError, message: "failed to resolve policy"}
+	}
+	return policy, resolution, nil
+}
+
+// policyNormalizer returns the Normalizer for the normalization profile a
+// request selects, or else its policy's default profile, and the profile key.
+func policyNormalizer(normalizers *normalizer.Registry, requested string, policy *models.Policy) (*normalizer.Normalizer, string, error) {
+	name := requested
+	if name == "" && policy.NormalizationProfile != nil {
+		name = *policy.NormalizationProfile
+	}
+	profile, err := normalizer.ResolveProfile(name, policy.NormalizationProfiles)
+	if err != nil {
+		return nil, "", err
+	}
+	return normalizers.Get(profile)
+}
+
+// normalizedHash hashes normalized content for deduplication and caching.
+// Content normalized with any profile but the standard one is hashed together
+// with the profile key, so identical text under different profiles is never
+// conflated; standard hashes match those recorded before profiles existed.
+func normalizedHash(normalized, profileKey string) string {
+	data := normalized
+	if profileKey != normalizer.ProfileStandard {
+		data = profileKey + "\x00" + normalized
+	}
+	h := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(h[:])
+}
+
+// classifyChunks classifies the windows of long content concurrently and
+// aggregates their scores with the configured strategy. Scores are cached per
+// window, so an edited post only reclassifies the windows that changed.
+// chunks are the windows of text, normalized with the profile profileKey.
+// Control: MOD-004 (Latency Optimization and Caching)
+func classifyChunks(ctx context.Context, orchestrator *classifier.Orchestrator, redisCache *cache.RedisCache, cfg *config.Config, logger *zap.Logger, text string, chunks []classifier.Chunk, langs []string, calibrationVersion *int, profileKey string) (*chunkedClassification, error) {
+	windowScores := make([]*models.CategoryScores, len(chunks))
+	windowResults := make([]*classifier.ClassificationResult, len(chunks))
+	keys := make([]string, len(chunks))
+	var misses []classifier.Chunk
+	var missIndexes []int
+	for i, chunk := range chunks {
+		keys[i] = "classify:chunk:" + normalizedHash(chunk.Text, profileKey)
+		if calibrationVersion != nil {
+			keys[i] += fmt.Sprintf(":cal%d", *calibrationVersion)
+		}
+		if redisCache != nil {
+			if cached, err := redisCache.Get(ctx, keys[i]); err == nil {
+				var entry cachedChunk
+				if json.Unmarshal([]byte(cached), &entry) == nil && entry.Scores != nil {
+					windowScores[i] = entry.Scores
+					windowResults[i] = &classifier.ClassificationResult{Spans: entry.Spans}
+					continue
+				}
+			}
+		}
+		misses = append(misses, chunk)
+		missIndexes = append(missIndexes, i)
+	}
+
+	result := &chunkedClassification{cacheHit: len(misses) == 0}
+	var ensembleResults []*classifier.EnsembleResult
+	if len(misses) > 0 {
+		if orchestrator.IsEnsembleEnabled() {
+			results, err := orchestrator.ClassifyEnsembleChunks(ctx, misses, cfg.ChunkConcurrency)
+			if err != nil {
+				return nil, err
+			}
+			for j, r := range results {
+				windowScores[missIndexes[j]] = r.CombinedScores
+			}
+			if len(results[0].ProviderResults) > 0 {
+				result.classResult = &results[0].ProviderResults[0]
+			}
+			ensembleResults = results
+		} else {
+			results, err := orchestrator.ClassifyChunks(ctx, misses, langs, cfg.ChunkConcurrency)
+			if err != nil {
+				return nil, err
+			}
+			for j, r := range results {
+				windowScores[missIndexes[j]] = r.Scores
+				windowResults[missIndexes[j]] = r
+			}
+			result.classResult = results[0]
+		}
+
+		if redisCache != nil {
+			for _, i := range missIndexes {
+				entry := cachedChunk{Scores: windowScores[i]}
+				if windowResults[i] != nil {
+					entry.Spans = windowResults[i].Spans
+				}
+				if entryJSON, err := json.Marshal(entry); err == nil {
+					if err := redisCache.Set(ctx, keys[i], string(entryJSON), classificationCacheTTL); err != nil {
+						logger.Warn("failed to cache chunk classification result", zap.Error(err))
+					}
+				}
+			}
+		}
+	}
+
+	result.scores, result.attributions = classifier.AggregateChunks(chunks, windowScores, cfg.ChunkAggregation, cfg.ChunkTopK)
+	result.spans = classifier.ChunkSpans(text, chunks, windowResults)
+	if ensembleResults != nil {
+		result.ensembleResult = classifier.MergeEnsembleChunks(ensembleResults, result.scores)
+	}
+
+	logger.Debug("classified long content in windows",
+		zap.Int("chunks", len(chunks)),
+		zap.Int("cached", len(chunks)-len(misses)),
+	)
+	return result, nil
+}
+
+func moderateHandler(db *database.PostgresDB, orchestrator *classifier.Orchestrator, evaluator *engine.Evaluator, evidenceWriter *evidence.Writer, redisCache *cache.RedisCache, webhookDispatcher *webhook.Dispatcher, cfg *config.Config, logger *zap.Logger, normalizers *normalizer.Registry, langDetector *langdetect.Detector, llmProvider *classifier.LLMProvider, promptStore *prompt.Store, behaviorScorer *behavior.Scorer, shadowRecorder *shadow.Recorder, metrics *observability.Metrics) gin.HandlerFunc {
+	screen := newInjectionScreen(redisCache)
+
 	return func(c *gin.Context) {
-		moderationStart := time.Now()
 		var req models.ModerationRequest
 		if err := c.ShouldBindJSON(&req); err != nil {
 			// SECURITY: Don't expose detailed parsing errors to clients
@@ -336,310 +856,715 @@ func moderateHandler(db *database.PostgresDB, orchestrator *classifier.Orchestra
 			return
 		}
 
-		// Validate content length
+		response, err := moderateContent(c.Request.Context(), db, orchestrator, evaluator, evidenceWriter, redisCache, webhookDispatcher, cfg, logger, normalizers, langDetector, llmProvider, promptStore, behaviorScorer, shadowRecorder, metrics, screen, middleware.APIKeyHash(c), &req, nil)
+		if err != nil {
+			writeModerationError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, response)
+	}
+}
+
+// classifyHandler classifies content for a policy exactly as a moderation
+// request applying that policy would be, without recording a submission or
+// decision. The policy may be in any status, so drafts can be tested before
+// they are published.
+// Control: POL-001 (Policy definition and versioning)
+func classifyHandler(orchestrator *classifier.Orchestrator, evaluator *engine.Evaluator, redisCache *cache.RedisCache, cfg *config.Config, logger *zap.Logger, normalizers *normalizer.Registry, langDetector *langdetect.Detector, llmProvider *classifier.LLMProvider, promptStore *prompt.Store, metrics *observability.Metrics) gin.HandlerFunc {
+	screen := newInjectionScreen(redisCache)
+
+	return func(c *gin.Context) {
+		var req models.ClassifyRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			logger.Debug("invalid request body", zap.Error(err))
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+			return
+		}
 		if len(req.Content) > cfg.MaxContentLength {
-			c.JSON(http.StatusBadRequest, gin.H{
-				"error": fmt.Sprintf("content exceeds maximum length of %d characters", cfg.MaxContentLength),
-			})
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("content exceeds maximum length of %d characters", cfg.MaxContentLength)})
 			return
 		}
 
-		// Validate source field
-		if req.Source != "" {
-			if len(req.Source) > 100 {
-				c.JSON(http.StatusBadRequest, gin.H{"error": "source field exceeds maximum length of 100 characters"})
-				return
-			}
-			if !sourcePattern.MatchString(req.Source) {
-				c.JSON(http.StatusBadRequest, gin.H{"error": "source field must contain only alphanumeric characters and hyphens"})
-				return
-			}
+		ctx := c.Request.Context()
+		policy, err := evaluator.GetPolicyByID(ctx, req.PolicyID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "policy not found"})
+			return
+		}
+		if err != nil {
+			logger.Error("failed to get policy", zap.String("policy_id", req.PolicyID.String()), zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get policy"})
+			return
 		}
 
-		// Validate context_metadata
-		if req.ContextMetadata != nil {
-			if len(req.ContextMetadata) > 10 {
-				c.JSON(http.StatusBadRequest, gin.H{"error": "context_metadata exceeds maximum of 10 keys"})
-				return
-			}
-			metaBytes, err := json.Marshal(req.ContextMetadata)
-			if err == nil && len(metaBytes) > 1024 {
-				c.JSON(http.StatusBadRequest, gin.H{"error": "context_metadata exceeds maximum size of 1KB"})
-				return
-			}
+		textNormalizer, profileKey, err := policyNormalizer(normalizers, "", policy)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
 		}
 
-		ctx := c.Request.Context()
+		classified, err := classifyContent(ctx, orchestrator, redisCache, cfg, logger, langDetector, llmProvider, promptStore, metrics, screen, policy, textNormalizer.Normalize(req.Content), profileKey, nil)
+		if err != nil {
+			writeModerationError(c, err)
+			return
+		}
 
-		// Normalize text to defeat Unicode evasion before hashing and classification
-		normalizedContent := textNormalizer.Normalize(req.Content)
+		response := models.ClassifyResponse{
+			CategoryScores:   *classified.scores,
+			Signals:          classified.signals,
+			DetectedLanguage: classified.langResult.Language,
+			Translated:       classified.sourceLanguage != "",
+			SourceLanguage:   classified.sourceLanguage,
+		}
+		if len(classified.langResult.Languages) > 1 {
+			response.DetectedLanguages = classified.langResult.Codes()
+		}
+		if ensembleResult := classified.ensembleResult; ensembleResult != nil && ensembleResult.HasDisagreement {
+			response.EnsembleDisagreement = true
+		}
+		c.JSON(http.StatusOK, response)
+	}
+}
 
-		// Hash normalized content for deduplication
-		hash := sha256.Sum256([]byte(normalizedContent))
-		contentHash := hex.EncodeToString(hash[:])
+// moderationError is a failed moderation with the status and message returned
+// to the client.
+type moderationError struct {
+	status  int
+	message string
+}
+
+func (e *moderationError) Error() string { return e.message }
 
-		// Create submission record
-		submission := &models.TextSubmission{
-			ID:              uuid.New(),
-			ContentHash:     contentHash,
-			ContextMetadata: req.ContextMetadata,
-			Source:          &req.Source,
+// writeModerationError responds with the status and message of a
+// moderationError, or 500 for any other error.
+func writeModerationError(c *gin.Context, err error) {
+	var modErr *moderationError
+	if errors.As(err, &modErr) {
+		c.JSON(modErr.status, gin.H{"error": modErr.message})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+}
+
+// moderateContent runs the moderation pipeline for a request: it classifies
+// the content, evaluates it against policy, records the decision and its
+// evidence, and returns the response. conversation is the thread the content
+// was posted in, or nil outside a conversation.
+// Control: MOD-001 (Automated classification)
+func moderateContent(ctx context.Context, db *database.PostgresDB, orchestrator *classifier.Orchestrator, evaluator *engine.Evaluator, evidenceWriter *evidence.Writer, redisCache *cache.RedisCache, webhookDispatcher *webhook.Dispatcher, cfg *config.Config, logger *zap.Logger, normalizers *normalizer.Registry, langDetector *langdetect.Detector, llmProvider *classifier.LLMProvider, promptStore *prompt.Store, behaviorScorer *behavior.Scorer, shadowRecorder *shadow.Recorder, metrics *observability.Metrics, screen *injection.Screen, keyHash string, req *models.ModerationRequest, conversation *conversationContext) (*models.ModerationResponse, error) {
+	moderationStart := time.Now()
+
+	// Validate content length
+	if len(req.Content) > cfg.MaxContentLength {
+		return nil, &moderationError{status: http.StatusBadRequest, message: fmt.Sprintf("content exceeds maximum length of %d characters", cfg.MaxContentLength)}
+	}
+
+	// Validate source field
+	if req.Source != "" {
+		if len(req.Source) > 100 {
+			return nil, &moderationError{status: http.StatusBadRequest, message: "source field exceeds maximum length of 100 characters"}
 		}
+		if !sourcePattern.MatchString(req.Source) {
+			return nil, &moderationError{status: http.StatusBadRequest, message: "source field must contain only alphanumeric characters and hyphens"}
+		}
+	}
 
-		query := `
-			INSERT INTO text_submissions (id, content_hash, context_metadata, source)
-			VALUES ($1, $2, $3, $4)
-			RETURNING created_at
-		`
-		err := db.Pool.QueryRow(ctx, query, submission.ID, submission.ContentHash, submission.ContextMetadata, submission.Source).Scan(&submission.CreatedAt)
-		if err != nil {
-			logger.Error("failed to create submission", zap.Error(err))
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create submission"})
-			return
+	// Validate context_metadata
+	if req.ContextMetadata != nil {
+		if len(req.ContextMetadata) > 10 {
+			return nil, &moderationError{status: http.StatusBadRequest, message: "context_metadata exceeds maximum of 10 keys"}
+		}
+		metaBytes, err := json.Marshal(req.ContextMetadata)
+		if err == nil && len(metaBytes) > 1024 {
+			return nil, &moderationError{status: http.StatusBadRequest, message: "context_metadata exceeds maximum size of 1KB"}
 		}
+	}
 
-		// Check cache for classification results by content hash
-		// Control: MOD-004 (Latency Optimization and Caching)
-		var scores *models.CategoryScores
-		cacheKey := "classify:" + contentHash
-		cacheHit := false
+	// Get policy (provided, or resolved by scope routes); it selects the normalization profile
+	policy, resolution, err := requestPolicy(ctx, evaluator, logger, req.PolicyID, &engine.ResolveRequest{
+		Source:          req.Source,
+		ContextMetadata: req.ContextMetadata,
+		APIKeyHash:      keyHash,
+	})
+	if err != nil {
+		return nil, err
+	}
 
-		if redisCache != nil {
-			cached, err := redisCache.Get(ctx, cacheKey)
-			if err == nil {
-				var cachedScores models.CategoryScores
-				if json.Unmarshal([]byte(cached), &cachedScores) == nil {
-					scores = &cachedScores
-					cacheHit = true
-					metrics.ClassificationCacheHits.Inc()
-					logger.Debug("classification cache hit", zap.String("content_hash", contentHash))
-				}
-			}
+	textNormalizer, profileKey, err := policyNormalizer(normalizers, req.NormalizationProfile, policy)
+	if err != nil {
+		return nil, &moderationError{status: http.StatusBadRequest, message: err.Error()}
+	}
+
+	// Normalize text to defeat Unicode evasion before hashing and classification,
+	// keeping the alignment to map flagged spans back to the original
+	normalizedContent, alignment := textNormalizer.NormalizeWithAlignment(req.Content)
+
+	// Hash normalized content for deduplication
+	contentHash := normalizedHash(normalizedContent, profileKey)
+
+	// The prior messages of a conversation are normalized with the same profile
+	if conversation != nil {
+		conversation.normalize(textNormalizer)
+	}
+
+	// Create submission record
+	submission := &models.TextSubmission{
+		ID:              uuid.New(),
+		ContentHash:     contentHash,
+		ContextMetadata: req.ContextMetadata,
+		Source:          &req.Source,
+	}
+
+	query := `
+		INSERT INTO text_submissions (id, content_hash, context_metadata, source)
+		VALUES ($1, $2, $3, $4)
+		RETURNING created_at
+	`
+	err = db.Pool.QueryRow(ctx, query, submission.ID, submission.ContentHash, submission.ContextMetadata, submission.Source).Scan(&submission.CreatedAt)
+	if err != nil {
+		logger.Error("failed to create submission", zap.Error(err))
+		return nil, &moderationError{status: http.StatusInternalServerError, message: "failed to create submission"}
+	}
+
+	classified, err := classifyContent(ctx, orchestrator, redisCache, cfg, logger, langDetector, llmProvider, promptStore, metrics, screen, policy, normalizedContent, profileKey, conversation)
+	if err != nil {
+		return nil, err
+	}
+
+	// Determine model info (from orchestrator result or default for cache hits)
+	modelName := "s-nlp/roberta_toxicity_classifier"
+	modelVersion := "v1"
+	var routedProvider *string
+	var routingBucket *int
+	var rawScores *models.CategoryScores
+	if classResult := classified.classResult; classResult != nil {
+		modelName = classResult.ModelName
+		modelVersion = classResult.ModelVersion
+		routedProvider = &classResult.ProviderName
+		routingBucket = classResult.RoutingBucket
+		// Raw scores of one window do not describe chunked content
+		if classified.ensembleResult == nil && classified.chunkCount == 1 {
+			rawScores = classResult.RawScores
 		}
+	}
+	sourceLanguage := classified.sourceLanguage
 
-		// Detect language (needed for response and language-aware classification)
-		langResult := langDetector.Detect(normalizedContent)
+	// Evaluate against policy with context metadata and trust score
+	evalOpts := &engine.EvaluationOptions{
+		ContextMetadata: req.ContextMetadata,
+		Signals:         classified.signals,
+		Translated:      sourceLanguage != "",
+		SourceLanguage:  sourceLanguage,
+		Language:        classified.langResult.Language,
+		Languages:       classified.langResult.Codes(),
+	}
 
-		// Classify normalized text using orchestrator if not cached
-		var classResult *classifier.ClassificationResult
-		var ensembleResult *classifier.EnsembleResult
-		if !cacheHit {
-			metrics.ClassificationCacheMisses.Inc()
-			if orchestrator.IsEnsembleEnabled() {
-				// Ensemble mode: run multiple providers in parallel
-				ensembleResult, err = orchestrator.ClassifyEnsemble(ctx, normalizedContent)
-				if err != nil {
-					logger.Error("failed to classify text (ensemble)", zap.Error(err))
-					c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to classify text"})
-					return
-				}
-				scores = ensembleResult.CombinedScores
-				if len(ensembleResult.ProviderResults) > 0 {
-					classResult = &ensembleResult.ProviderResults[0]
-				}
-			} else {
-				classResult, err = orchestrator.ClassifyWithLanguage(ctx, normalizedContent, langResult.Language)
-				if err != nil {
-					logger.Error("failed to classify text", zap.Error(err))
-					c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to classify text"})
-					return
-				}
-				scores = classResult.Scores
-			}
+	// Lookup user trust score if user_id is in context metadata
+	var userID string
+	if req.ContextMetadata != nil {
+		if uid, ok := req.ContextMetadata["user_id"]; ok {
+			userID = fmt.Sprintf("%v", uid)
+			trustScore := behaviorScorer.GetTrustScore(ctx, userID)
+			evalOpts.TrustScore = &trustScore
+		}
+	}
 
-			// Store in cache
-			if redisCache != nil {
-				if scoresJSON, err := json.Marshal(scores); err == nil {
-					if err := redisCache.Set(ctx, cacheKey, string(scoresJSON), classificationCacheTTL); err != nil {
-						logger.Warn("failed to cache classification result", zap.Error(err))
-					}
-				}
-			}
+	evalResult, err := evaluator.EvaluateScores(ctx, classified.scores, policy.ID, evalOpts)
+	if err != nil {
+		logger.Error("failed to evaluate policy", zap.Error(err))
+		return nil, &moderationError{status: http.StatusInternalServerError, message: "failed to evaluate policy"}
+	}
+	action := evalResult.Action
+
+	// Create decision record and evidence atomically in a transaction
+	decision := &models.ModerationDecision{
+		ID:                 uuid.New(),
+		SubmissionID:       submission.ID,
+		ModelName:          modelName,
+		ModelVersion:       modelVersion,
+		CategoryScores:     *classified.scores,
+		PolicyID:           &policy.ID,
+		PolicyVersion:      &policy.Version,
+		AutomatedAction:    action,
+		ProviderName:       routedProvider,
+		RoutingBucket:      routingBucket,
+		CalibrationVersion: classified.calibrationVersion,
+		RawCategoryScores:  rawScores,
+		Explanation:        classified.explanation,
+		ExplanationDetails: classified.explanationDetails,
+		Signals:            classified.signals,
+		Translated:         sourceLanguage != "",
+	}
+	if decision.Translated {
+		decision.SourceLanguage = &sourceLanguage
+	}
+
+	tx, err := evidenceWriter.BeginTx(ctx)
+	if err != nil {
+		logger.Error("failed to begin transaction", zap.Error(err))
+		return nil, &moderationError{status: http.StatusInternalServerError, message: "internal error"}
+	}
+	defer tx.Rollback(ctx)
+
+	decisionQuery := `
+		INSERT INTO moderation_decisions (
+			id, submission_id, model_name, model_version, category_scores,
+			policy_id, policy_version, automated_action, provider_name, routing_bucket,
+			calibration_version, raw_category_scores, explanation, explanation_details, signals,
+			translated, source_language
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
+		RETURNING created_at
+	`
+	err = tx.QueryRow(ctx, decisionQuery,
+		decision.ID, decision.SubmissionID, decision.ModelName, decision.ModelVersion,
+		decision.CategoryScores, decision.PolicyID, decision.PolicyVersion, decision.AutomatedAction,
+		decision.ProviderName, decision.RoutingBucket,
+		decision.CalibrationVersion, decision.RawCategoryScores,
+		decision.Explanation, decision.ExplanationDetails, decision.Signals,
+		decision.Translated, decision.SourceLanguage,
+	).Scan(&decision.CreatedAt)
+	if err != nil {
+		logger.Error("failed to create decision", zap.Error(err))
+		return nil, &moderationError{status: http.StatusInternalServerError, message: "failed to create decision"}
+	}
+
+	// Write evidence record within the same transaction
+	evidenceRecord := &models.EvidenceRecord{
+		ID:                   uuid.New(),
+		ControlID:            "MOD-001",
+		PolicyID:             decision.PolicyID,
+		PolicyVersion:        decision.PolicyVersion,
+		DecisionID:           &decision.ID,
+		ModelName:            &decision.ModelName,
+		ModelVersion:         &decision.ModelVersion,
+		CategoryScores:       &decision.CategoryScores,
+		AutomatedAction:      &decision.AutomatedAction,
+		Immutable:            true,
+		NormalizationProfile: &profileKey,
+	}
+	if promptTemplate := classified.promptTemplate; promptTemplate != nil {
+		evidenceRecord.PromptTemplateID = &promptTemplate.ID
+		evidenceRecord.PromptTemplateVersion = &promptTemplate.Version
+	}
+	if conversation != nil {
+		evidenceRecord.ContextHashes = conversation.hashes
+	}
+	if err := evidenceWriter.WriteEvidenceInTx(ctx, tx, evidenceRecord); err != nil {
+		logger.Error("failed to write evidence in transaction", zap.Error(err))
+		return nil, &moderationError{status: http.StatusInternalServerError, message: "failed to record evidence"}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		logger.Error("failed to commit transaction", zap.Error(err))
+		return nil, &moderationError{status: http.StatusInternalServerError, message: "internal error"}
+	}
+
+	// Evaluate shadow providers against the same content without affecting the action
+	runShadowClassification(orchestrator, shadowRecorder, decision.ID, normalizedContent)
+
+	// Auto-escalate on ensemble disagreement
+	if ensembleResult := classified.ensembleResult; ensembleResult != nil && ensembleResult.HasDisagreement && action != models.ActionBlock {
+		action = models.ActionEscalate
+		logger.Info("auto-escalated due to ensemble disagreement",
+			zap.Strings("disagreed_categories", ensembleResult.DisagreedCategories),
+		)
+	}
+
+	// Prepare response
+	requiresReview := action == models.ActionEscalate
+	response := models.ModerationResponse{
+		DecisionID:           decision.ID,
+		SubmissionID:         submission.ID,
+		Action:               action,
+		CategoryScores:       *classified.scores,
+		RequiresReview:       requiresReview,
+		DetectedLanguage:     classified.langResult.Language,
+		Explanation:          decision.Explanation,
+		ExplanationDetails:   decision.ExplanationDetails,
+		Signals:              decision.Signals,
+		ChunkAttributions:    classified.chunkAttributions,
+		FlaggedSpans:         flaggedSpans(req.Content, alignment, classified.spans),
+		NormalizationProfile: profileKey,
+	}
+	if len(classified.langResult.Languages) > 1 {
+		response.DetectedLanguages = classified.langResult.Codes()
+	}
+	if decision.Translated {
+		response.Translated = true
+		response.SourceLanguage = sourceLanguage
+	}
+	if classified.chunkCount > 1 {
+		response.ChunkCount = classified.chunkCount
+	}
+	if conversation != nil {
+		response.ContextHashes = conversation.hashes
+		response.ContextUsed = &classified.contextUsed
+	}
+
+	response.PolicyApplied = &policy.Name
+	response.PolicyVersion = &policy.Version
+	response.PolicyResolution = resolution
+
+	// Record moderation metrics
+	providerName := "cache"
+	if classified.classResult != nil {
+		providerName = classified.classResult.ProviderName
+	}
+	metrics.ModerationTotal.WithLabelValues(string(action), providerName).Inc()
+	metrics.ModerationActions.WithLabelValues(string(action)).Inc()
+	cacheHitStr := "false"
+	if classified.cacheHit {
+		cacheHitStr = "true"
+	}
+	metrics.ModerationDuration.WithLabelValues(providerName, cacheHitStr).Observe(time.Since(moderationStart).Seconds())
+
+	// Dispatch webhook events and record behavior asynchronously (non-blocking)
+	go func() {
+		bgCtx := context.Background()
+		webhookDispatcher.Dispatch(bgCtx, models.EventModerationCompleted, response)
+		if requiresReview {
+			webhookDispatcher.Dispatch(bgCtx, models.EventReviewRequired, response)
+		}
+		// Record user behavior outcome
+		if userID != "" {
+			behaviorScorer.RecordOutcome(bgCtx, userID, string(action))
 		}
+	}()
 
-		// LLM second-pass for ambiguous scores (0.3-0.7 range)
-		if llmProvider != nil && !cacheHit && classifier.IsAmbiguous(scores, 0.3, 0.7) {
-			llmScores, llmErr := llmProvider.Classify(ctx, normalizedContent)
-			if llmErr != nil {
-				logger.Warn("LLM second-pass failed, using primary scores", zap.Error(llmErr))
-			} else {
-				scores = classifier.MergeAmbiguousScores(scores, llmScores, 0.3, 0.7)
-				logger.Debug("LLM second-pass merged ambiguous scores")
+	return &response, nil
+}
+
+// contentClassification is the classification of normalized content for a
+// policy, with the signals its evaluation depends on.
+type contentClassification struct {
+	scores             *models.CategoryScores
+	classResult        *classifier.ClassificationResult // nil for cache hits
+	ensembleResult     *classifier.EnsembleResult       // nil outside ensemble mode
+	chunkCount         int
+	chunkAttributions  map[string]models.ChunkAttribution
+	spans              []classifier.TextSpan
+	cacheHit           bool
+	calibrationVersion *int
+	langResult         langdetect.DetectionResult
+	sourceLanguage     string // language the content was translated from; "" if untranslated
+	signals            map[string]float64
+	explanation        *string
+	explanationDetails *models.DecisionExplanation
+	promptTemplate     *models.PromptTemplate // template of the LLM second pass; nil when it did not run
+	contextUsed        bool                   // a classifier read the conversation context
+}
+
+// classifyContent classifies normalized content for a policy the way every
+// moderation request is classified: cached or chunked, by the orchestrator,
+// with the LLM second pass and prompt-injection screening. Nothing is
+// recorded. conversation is the thread the content was posted in, or nil.
+// Control: MOD-001 (Automated classification)
+func classifyContent(ctx context.Context, orchestrator *classifier.Orchestrator, redisCache *cache.RedisCache, cfg *config.Config, logger *zap.Logger, langDetector *langdetect.Detector, llmProvider *classifier.LLMProvider, promptStore *prompt.Store, metrics *observability.Metrics, screen *injection.Screen, policy *models.Policy, normalizedContent, profileKey string, conversation *conversationContext) (*contentClassification, error) {
+	// Check cache for classification results by content hash
+	// Control: MOD-004 (Latency Optimization and Caching)
+	// The calibration version is part of the key so a newly activated
+	// calibration is not masked by scores cached under the previous one.
+	var scores *models.CategoryScores
+	var err error
+	contentHash := normalizedHash(normalizedContent, profileKey)
+	calibrationVersion := orchestrator.CalibrationVersion()
+	cacheKey := "classify:" + contentHash
+	if calibrationVersion != nil {
+		cacheKey += fmt.Sprintf(":cal%d", *calibrationVersion)
+	}
+	cacheHit := false
+
+	// Content longer than one window is classified window by window, since
+	// providers truncate long input and would never see its end
+	chunks := classifier.SplitChunks(normalizedContent, cfg.ChunkMaxTokens, cfg.ChunkOverlapTokens)
+
+	// Conversation results depend on the thread and skip the cache, which
+	// would also skip the context-aware LLM pass
+	useCache := redisCache != nil && conversation == nil
+
+	if useCache && len(chunks) == 1 {
+		cached, err := redisCache.Get(ctx, cacheKey)
+		if err == nil {
+			var cachedScores models.CategoryScores
+			if json.Unmarshal([]byte(cached), &cachedScores) == nil {
+				scores = &cachedScores
+				cacheHit = true
+				metrics.ClassificationCacheHits.Inc()
+				logger.Debug("classification cache hit", zap.String("content_hash", contentHash))
 			}
 		}
+	}
 
-		// Determine model info (from orchestrator result or default for cache hits)
-		modelName := "s-nlp/roberta_toxicity_classifier"
-		modelVersion := "v1"
-		if classResult != nil {
-			modelName = classResult.ModelName
-			modelVersion = classResult.ModelVersion
-		}
+	// Detect language (needed for response and language-aware classification)
+	langResult := langDetector.Detect(normalizedContent)
 
-		// Get policy (use provided or default)
-		var policy *models.Policy
-		if req.PolicyID != nil {
-			policy, err = evaluator.GetPolicyByID(ctx, *req.PolicyID)
+	// Classify normalized text using orchestrator if not cached
+	var classResult *classifier.ClassificationResult
+	var ensembleResult *classifier.EnsembleResult
+	var chunkAttributions map[string]models.ChunkAttribution
+	var spans []classifier.TextSpan
+	if len(chunks) > 1 {
+		chunkCache := redisCache
+		if !useCache {
+			chunkCache = nil
+		}
+		chunked, err := classifyChunks(ctx, orchestrator, chunkCache, cfg, logger, normalizedContent, chunks, langResult.Codes(), calibrationVersion, profileKey)
+		if err != nil {
+			logger.Error("failed to classify text (chunked)", zap.Error(err))
+			return nil, &moderationError{status: http.StatusInternalServerError, message: "failed to classify text"}
+		}
+		scores = chunked.scores
+		classResult = chunked.classResult
+		ensembleResult = chunked.ensembleResult
+		chunkAttributions = chunked.attributions
+		spans = chunked.spans
+		cacheHit = chunked.cacheHit
+		if cacheHit {
+			metrics.ClassificationCacheHits.Inc()
+		} else {
+			metrics.ClassificationCacheMisses.Inc()
+		}
+	} else if !cacheHit {
+		metrics.ClassificationCacheMisses.Inc()
+		if orchestrator.IsEnsembleEnabled() {
+			// Ensemble mode: run multiple providers in parallel
+			ensembleResult, err = orchestrator.ClassifyEnsemble(ctx, normalizedContent)
 			if err != nil {
-				logger.Warn("requested policy not found, falling back to default",
-					zap.String("requested_policy_id", req.PolicyID.String()),
-					zap.Error(err),
-				)
-				policy, err = evaluator.GetDefaultPolicy(ctx)
+				logger.Error("failed to classify text (ensemble)", zap.Error(err))
+				return nil, &moderationError{status: http.StatusInternalServerError, message: "failed to classify text"}
+			}
+			scores = ensembleResult.CombinedScores
+			if len(ensembleResult.ProviderResults) > 0 {
+				classResult = &ensembleResult.ProviderResults[0]
+			}
+		} else if conversation != nil {
+			message := models.ConversationMessage{AuthorID: conversation.authorID, Content: normalizedContent}
+			classResult, err = orchestrator.ClassifyWithContext(ctx, message, conversation.history, langResult.Codes())
+			if err != nil {
+				logger.Error("failed to classify text (conversation)", zap.Error(err))
+				return nil, &moderationError{status: http.StatusInternalServerError, message: "failed to classify text"}
 			}
+			scores = classResult.Scores
+			spans = classResult.Spans
 		} else {
-			policy, err = evaluator.GetDefaultPolicy(ctx)
+			classResult, err = orchestrator.ClassifyWithLanguage(ctx, normalizedContent, langResult.Codes())
+			if err != nil {
+				logger.Error("failed to classify text", zap.Error(err))
+				return nil, &moderationError{status: http.StatusInternalServerError, message: "failed to classify text"}
+			}
+			scores = classResult.Scores
+			spans = classResult.Spans
 		}
-		if err != nil {
-			logger.Warn("no policy found, defaulting to allow", zap.Error(err))
-			policy = &models.Policy{
-				ID:      uuid.New(),
-				Name:    "default",
-				Version: 1,
+
+		// Store in cache
+		if useCache {
+			if scoresJSON, err := json.Marshal(scores); err == nil {
+				if err := redisCache.Set(ctx, cacheKey, string(scoresJSON), classificationCacheTTL); err != nil {
+					logger.Warn("failed to cache classification result", zap.Error(err))
+				}
 			}
 		}
+	}
 
-		// Evaluate against policy with context metadata and trust score
-		var action models.PolicyAction
-		evalOpts := &engine.EvaluationOptions{
-			ContextMetadata: req.ContextMetadata,
+	// LLM second-pass for ambiguous scores (0.3-0.7 range), prompted with the
+	// applied policy's published template when it has one
+	var explanationDetails *models.DecisionExplanation
+	var explanation *string
+	var promptTemplate *models.PromptTemplate
+	var signals map[string]float64
+	// A conversation whose context no provider has read yet always gets the
+	// LLM pass, since a reply can look benign without its thread
+	contextUsed := classResult != nil && classResult.UsedContext
+	contextPass := conversation != nil && len(conversation.history) > 0 && !contextUsed
+
+	// Content that tries to manipulate the LLM is escalated by the policy
+	// instead of trusting the scores it would produce. Every submission is
+	// screened, including cache hits that skip the LLM pass. Conversation
+	// context is sent to the LLM too, so it is screened as well.
+	llmInput := normalizedContent
+	if conversation != nil {
+		for _, m := range conversation.history {
+			llmInput += "\n" + m.Content
 		}
+	}
+	screened := screen.Check(ctx, llmInput)
+	injectionScore := screened.Score
+
+	if llmProvider != nil && !cacheHit && (contextPass || classifier.IsAmbiguous(scores, 0.3, 0.7)) {
+		if screened.Detected() {
+			logger.Warn("prompt injection detected, skipping LLM second-pass",
+				zap.Float64("score", screened.Score),
+				zap.Strings("rules", screened.Rules),
+			)
+		} else {
+			promptTemplate, err = promptStore.PublishedTemplate(ctx, policy.ID)
+			if err != nil {
+				logger.Warn("failed to load prompt template, using built-in prompt", zap.Error(err))
+				promptTemplate = nil
+			}
 
-		// Lookup user trust score if user_id is in context metadata
-		var userID string
-		if req.ContextMetadata != nil {
-			if uid, ok := req.ContextMetadata["user_id"]; ok {
-				userID = fmt.Sprintf("%v", uid)
-				trustScore := behaviorScorer.GetTrustScore(ctx, userID)
-				evalOpts.TrustScore = &trustScore
+			// The model-based check runs alongside classification
+			modelCheck := make(chan float64, 1)
+			if cfg.PromptInjectionModelCheck {
+				go func() {
+					score, err := llmProvider.DetectInjection(ctx, llmInput)
+					if err != nil {
+						logger.Warn("LLM prompt injection check failed, using heuristic only", zap.Error(err))
+					}
+					modelCheck <- score
+				}()
+			} else {
+				modelCheck <- 0
 			}
-		}
 
-		if policy.Name != "default" {
-			evalResult, err := evaluator.EvaluateScores(ctx, scores, policy.ID, evalOpts)
-			if err != nil {
-				logger.Error("failed to evaluate policy", zap.Error(err))
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to evaluate policy"})
-				return
+			var llmScores *models.CategoryScores
+			var llmExplanation *models.DecisionExplanation
+			var llmErr error
+			if conversation != nil {
+				message := models.ConversationMessage{AuthorID: conversation.authorID, Content: normalizedContent}
+				llmScores, llmExplanation, llmErr = llmProvider.ClassifyConversation(ctx, promptTemplate, message, conversation.history)
+			} else {
+				llmScores, llmExplanation, llmErr = llmProvider.ClassifyWithTemplate(ctx, promptTemplate, normalizedContent)
 			}
-			action = evalResult.Action
-		} else {
-			action = models.ActionAllow
-		}
+			modelScore := <-modelCheck
+			if err := screen.Remember(ctx, llmInput, modelScore); err != nil {
+				logger.Warn("failed to remember prompt injection score", zap.Error(err))
+			}
+			injectionScore = injection.Combine(screened.Score, modelScore)
 
-		// Create decision record and evidence atomically in a transaction
-		decision := &models.ModerationDecision{
-			ID:              uuid.New(),
-			SubmissionID:    submission.ID,
-			ModelName:       modelName,
-			ModelVersion:    modelVersion,
-			CategoryScores:  *scores,
-			PolicyID:        &policy.ID,
-			PolicyVersion:   &policy.Version,
-			AutomatedAction: action,
+			switch {
+			case injectionScore >= injection.DefaultThreshold:
+				logger.Warn("prompt injection detected by LLM check, discarding LLM scores",
+					zap.Float64("score", injectionScore),
+				)
+				promptTemplate = nil
+			case llmErr != nil:
+				logger.Warn("LLM second-pass failed, using primary scores", zap.Error(llmErr))
+			default:
+				scores = classifier.MergeAmbiguousScores(scores, llmScores, 0.3, 0.7)
+				if contextPass {
+					scores = classifier.MergeContextScores(scores, llmScores)
+					contextUsed = true
+				}
+				if llmExplanation != nil {
+					explanationDetails = llmExplanation
+					summary := classifier.FormatExplanation(llmExplanation)
+					explanation = &summary
+					spans = append(spans, classifier.PhraseSpans(normalizedContent, llmScores, llmExplanation, llmProvider.Name())...)
+				}
+				logger.Debug("LLM second-pass merged ambiguous scores")
+			}
 		}
+	}
+	if injectionScore > 0 {
+		signals = map[string]float64{injection.Signal: injectionScore}
+	}
+	if contextPass && !contextUsed {
+		logger.Warn("no context-aware classifier read the conversation, message classified without context",
+			zap.Int("context_messages", len(conversation.history)),
+		)
+	}
 
-		tx, err := evidenceWriter.BeginTx(ctx)
-		if err != nil {
-			logger.Error("failed to begin transaction", zap.Error(err))
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
-			return
-		}
-		defer tx.Rollback(ctx)
-
-		decisionQuery := `
-			INSERT INTO moderation_decisions (
-				id, submission_id, model_name, model_version, category_scores,
-				policy_id, policy_version, automated_action
-			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-			RETURNING created_at
-		`
-		err = tx.QueryRow(ctx, decisionQuery,
-			decision.ID, decision.SubmissionID, decision.ModelName, decision.ModelVersion,
-			decision.CategoryScores, decision.PolicyID, decision.PolicyVersion, decision.AutomatedAction,
-		).Scan(&decision.CreatedAt)
-		if err != nil {
-			logger.Error("failed to create decision", zap.Error(err))
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create decision"})
-			return
-		}
+	if classResult != nil {
+		calibrationVersion = classResult.CalibrationVersion
+	}
 
-		// Write evidence record within the same transaction
-		evidenceRecord := &models.EvidenceRecord{
-			ID:              uuid.New(),
-			ControlID:       "MOD-001",
-			PolicyID:        decision.PolicyID,
-			PolicyVersion:   decision.PolicyVersion,
-			DecisionID:      &decision.ID,
-			ModelName:       &decision.ModelName,
-			ModelVersion:    &decision.ModelVersion,
-			CategoryScores:  &decision.CategoryScores,
-			AutomatedAction: &decision.AutomatedAction,
-			Immutable:       true,
-		}
-		if err := evidenceWriter.WriteEvidenceInTx(ctx, tx, evidenceRecord); err != nil {
-			logger.Error("failed to write evidence in transaction", zap.Error(err))
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to record evidence"})
-			return
-		}
+	return &contentClassification{
+		scores:             scores,
+		classResult:        classResult,
+		ensembleResult:     ensembleResult,
+		chunkCount:         len(chunks),
+		chunkAttributions:  chunkAttributions,
+		spans:              spans,
+		cacheHit:           cacheHit,
+		calibrationVersion: calibrationVersion,
+		langResult:         langResult,
+		sourceLanguage:     translationSource(orchestrator, classResult, cacheHit, langResult.Codes()),
+		signals:            signals,
+		explanation:        explanation,
+		explanationDetails: explanationDetails,
+		promptTemplate:     promptTemplate,
+		contextUsed:        contextUsed,
+	}, nil
+}
 
-		if err := tx.Commit(ctx); err != nil {
-			logger.Error("failed to commit transaction", zap.Error(err))
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
-			return
-		}
+// conversationContext is the thread a message is moderated in.
+type conversationContext struct {
+	authorID string
+	messages []models.ConversationMessage // as submitted, oldest first
+	history  []models.ConversationMessage // normalized, oldest first
+	hashes   []string                     // per message, recorded in evidence
+}
 
-		// Auto-escalate on ensemble disagreement
-		if ensembleResult != nil && ensembleResult.HasDisagreement && action != models.ActionBlock {
-			action = models.ActionEscalate
-			logger.Info("auto-escalated due to ensemble disagreement",
-				zap.Strings("disagreed_categories", ensembleResult.DisagreedCategories),
-			)
-		}
+// normalize normalizes the thread with the normalizer of the message's
+// profile and hashes each message.
+func (cc *conversationContext) normalize(textNormalizer *normalizer.Normalizer) {
+	for _, m := range cc.messages {
+		normalized := textNormalizer.Normalize(m.Content)
+		cc.history = append(cc.history, models.ConversationMessage{AuthorID: m.AuthorID, Content: normalized})
+		cc.hashes = append(cc.hashes, conversationMessageHash(m.AuthorID, normalized))
+	}
+}
 
-		// Prepare response
-		requiresReview := action == models.ActionEscalate
-		response := models.ModerationResponse{
-			DecisionID:       decision.ID,
-			SubmissionID:     submission.ID,
-			Action:           action,
-			CategoryScores:   *scores,
-			RequiresReview:   requiresReview,
-			DetectedLanguage: langResult.Language,
-		}
+// conversationMessageHash identifies a context message by its author and
+// normalized content.
+func conversationMessageHash(authorID, normalizedContent string) string {
+	h := sha256.Sum256([]byte(authorID + "\x00" + normalizedContent))
+	return hex.EncodeToString(h[:])
+}
 
-		if policy.Name != "default" {
-			response.PolicyApplied = &policy.Name
-			response.PolicyVersion = &policy.Version
+// conversationModerateHandler moderates a message in the context of the prior
+// messages in its thread. Only the message is evaluated against policy and
+// stored as the submission; the context is passed to context-aware providers
+// and the LLM second pass, and its hashes are recorded in evidence. The
+// response reports whether any classifier actually read the context.
+// Control: MOD-001 (Automated classification)
+func conversationModerateHandler(db *database.PostgresDB, orchestrator *classifier.Orchestrator, evaluator *engine.Evaluator, evidenceWriter *evidence.Writer, redisCache *cache.RedisCache, webhookDispatcher *webhook.Dispatcher, cfg *config.Config, logger *zap.Logger, normalizers *normalizer.Registry, langDetector *langdetect.Detector, llmProvider *classifier.LLMProvider, promptStore *prompt.Store, behaviorScorer *behavior.Scorer, shadowRecorder *shadow.Recorder, metrics *observability.Metrics) gin.HandlerFunc {
+	screen := newInjectionScreen(redisCache)
+
+	return func(c *gin.Context) {
+		var req models.ConversationModerationRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			// SECURITY: Don't expose detailed parsing errors to clients
+			logger.Debug("invalid request body", zap.Error(err))
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+			return
 		}
 
-		// Record moderation metrics
-		providerName := "cache"
-		if classResult != nil {
-			providerName = classResult.ProviderName
+		if len(req.Context) > cfg.ConversationMaxContext {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": fmt.Sprintf("context exceeds maximum of %d messages", cfg.ConversationMaxContext),
+			})
+			return
 		}
-		metrics.ModerationTotal.WithLabelValues(string(action), providerName).Inc()
-		metrics.ModerationActions.WithLabelValues(string(action)).Inc()
-		cacheHitStr := "false"
-		if cacheHit {
-			cacheHitStr = "true"
+		for _, m := range append([]models.ConversationMessage{req.Message}, req.Context...) {
+			if len(m.AuthorID) > 100 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "author_id exceeds maximum length of 100 characters"})
+				return
+			}
+		}
+		for _, m := range req.Context {
+			if len(m.Content) > cfg.MaxContentLength {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": fmt.Sprintf("context message exceeds maximum length of %d characters", cfg.MaxContentLength),
+				})
+				return
+			}
 		}
-		metrics.ModerationDuration.WithLabelValues(providerName, cacheHitStr).Observe(time.Since(moderationStart).Seconds())
 
+		conversation := &conversationContext{authorID: req.Message.AuthorID, messages: req.Context}
+		response, err := moderateContent(c.Request.Context(), db, orchestrator, evaluator, evidenceWriter, redisCache, webhookDispatcher, cfg, logger, normalizers, langDetector, llmProvider, promptStore, behaviorScorer, shadowRecorder, metrics, screen, middleware.APIKeyHash(c), &models.ModerationRequest{
+			Content:              req.Message.Content,
+			ContextMetadata:      req.ContextMetadata,
+			Source:               req.Source,
+			PolicyID:             req.PolicyID,
+			NormalizationProfile: req.NormalizationProfile,
+		}, conversation)
+		if err != nil {
+			writeModerationError(c, err)
+			return
+		}
 		c.JSON(http.StatusOK, response)
-
-		// Dispatch webhook events and record behavior asynchronously (non-blocking)
-		go func() {
-			bgCtx := context.Background()
-			webhookDispatcher.Dispatch(bgCtx, models.EventModerationCompleted, response)
-			if requiresReview {
-				webhookDispatcher.Dispatch(bgCtx, models.EventReviewRequired, response)
-			}
-			// Record user behavior outcome
-			if userID != "" {
-				behaviorScorer.RecordOutcome(bgCtx, userID, string(action))
-			}
-		}()
 	}
 }
 
@@ -649,7 +1574,9 @@ const maxBatchSize = 100
 // batchWorkerPool controls concurrent classification requests.
 const batchWorkerPool = 10
 
-func batchModerateHandler(db *database.PostgresDB, orchestrator *classifier.Orchestrator, evaluator *engine.Evaluator, evidenceWriter *evidence.Writer, redisCache *cache.RedisCache, webhookDispatcher *webhook.Dispatcher, cfg *config.Config, logger *zap.Logger, textNormalizer *normalizer.Normalizer, langDetector *langdetect.Detector, llmProvider *classifier.LLMProvider, behaviorScorer *behavior.Scorer, metrics *observability.Metrics) gin.HandlerFunc {
+func batchModerateHandler(db *database.PostgresDB, orchestrator *classifier.Orchestrator, evaluator *engine.Evaluator, evidenceWriter *evidence.Writer, redisCache *cache.RedisCache, webhookDispatcher *webhook.Dispatcher, cfg *config.Config, logger *zap.Logger, normalizers *normalizer.Registry, langDetector *langdetect.Detector, llmProvider *classifier.LLMProvider, behaviorScorer *behavior.Scorer, shadowRecorder *shadow.Recorder, metrics *observability.Metrics) gin.HandlerFunc {
+	screen := newInjectionScreen(redisCache)
+
 	return func(c *gin.Context) {
 		var req models.BatchModerationRequest
 		if err := c.ShouldBindJSON(&req); err != nil {
@@ -669,6 +1596,7 @@ func batchModerateHandler(db *database.PostgresDB, orchestrator *classifier.Orch
 		}
 
 		ctx := c.Request.Context()
+		keyHash := middleware.APIKeyHash(c)
 		results := make([]models.BatchModerationResult, len(req.Items))
 
 		// Process items concurrently with a worker pool
@@ -682,7 +1610,7 @@ func batchModerateHandler(db *database.PostgresDB, orchestrator *classifier.Orch
 				sem <- struct{}{}
 				defer func() { <-sem }()
 
-				result := processBatchItem(ctx, db, orchestrator, evaluator, evidenceWriter, redisCache, cfg, logger, textNormalizer, langDetector, llmProvider, behaviorScorer, item)
+				result := processBatchItem(ctx, db, orchestrator, evaluator, evidenceWriter, redisCache, cfg, logger, normalizers, langDetector, llmProvider, behaviorScorer, shadowRecorder, screen, keyHash, item)
 				results[idx] = result
 			}(i, item)
 		}
@@ -715,7 +1643,7 @@ func batchModerateHandler(db *database.PostgresDB, orchestrator *classifier.Orch
 	}
 }
 
-func processBatchItem(ctx context.Context, db *database.PostgresDB, orchestrator *classifier.Orchestrator, evaluator *engine.Evaluator, evidenceWriter *evidence.Writer, redisCache *cache.RedisCache, cfg *config.Config, logger *zap.Logger, textNormalizer *normalizer.Normalizer, langDetector *langdetect.Detector, llmProvider *classifier.LLMProvider, behaviorScorer *behavior.Scorer, item models.BatchModerationItem) models.BatchModerationResult {
+func processBatchItem(ctx context.Context, db *database.PostgresDB, orchestrator *classifier.Orchestrator, evaluator *engine.Evaluator, evidenceWriter *evidence.Writer, redisCache *cache.RedisCache, cfg *config.Config, logger *zap.Logger, normalizers *normalizer.Registry, langDetector *langdetect.Detector, llmProvider *classifier.LLMProvider, behaviorScorer *behavior.Scorer, shadowRecorder *shadow.Recorder, screen *injection.Screen, keyHash string, item models.BatchModerationItem) models.BatchModerationResult {
 	result := models.BatchModerationResult{ItemID: item.ID}
 
 	if len(item.Content) > cfg.MaxContentLength {
@@ -723,12 +1651,29 @@ func processBatchItem(ctx context.Context, db *database.PostgresDB, orchestrator
 		return result
 	}
 
+	// Get policy (provided, or resolved by scope routes), which selects the normalization profile
+	policy, resolution, err := requestPolicy(ctx, evaluator, logger, item.PolicyID, &engine.ResolveRequest{
+		Source:          item.Source,
+		ContextMetadata: item.ContextMetadata,
+		APIKeyHash:      keyHash,
+	})
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.PolicyResolution = resolution
+
+	textNormalizer, profileKey, err := policyNormalizer(normalizers, item.NormalizationProfile, policy)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
 	// Normalize text to defeat Unicode evasion
 	normalizedContent := textNormalizer.Normalize(item.Content)
 
 	// Hash normalized content
-	hash := sha256.Sum256([]byte(normalizedContent))
-	contentHash := hex.EncodeToString(hash[:])
+	contentHash := normalizedHash(normalizedContent, profileKey)
 
 	// Create submission
 	submission := &models.TextSubmission{
@@ -746,10 +1691,15 @@ func processBatchItem(ctx context.Context, db *database.PostgresDB, orchestrator
 
 	// Check cache
 	var scores *models.CategoryScores
+	calibrationVersion := orchestrator.CalibrationVersion()
 	cacheKey := "classify:" + contentHash
+	if calibrationVersion != nil {
+		cacheKey += fmt.Sprintf(":cal%d", *calibrationVersion)
+	}
 	var classResult *classifier.ClassificationResult
+	chunks := classifier.SplitChunks(normalizedContent, cfg.ChunkMaxTokens, cfg.ChunkOverlapTokens)
 
-	if redisCache != nil {
+	if redisCache != nil && len(chunks) == 1 {
 		cached, err := redisCache.Get(ctx, cacheKey)
 		if err == nil {
 			var cachedScores models.CategoryScores
@@ -759,10 +1709,18 @@ func processBatchItem(ctx context.Context, db *database.PostgresDB, orchestrator
 		}
 	}
 
-	if scores == nil {
+	langResult := langDetector.Detect(normalizedContent)
+	if len(chunks) > 1 {
+		chunked, err := classifyChunks(ctx, orchestrator, redisCache, cfg, logger, normalizedContent, chunks, langResult.Codes(), calibrationVersion, profileKey)
+		if err != nil {
+			result.Error = "classification failed"
+			return result
+		}
+		scores = chunked.scores
+		classResult = chunked.classResult
+	} else if scores == nil {
 		var err error
-		langResult := langDetector.Detect(normalizedContent)
-		classResult, err = orchestrator.ClassifyWithLanguage(ctx, normalizedContent, langResult.Language)
+		classResult, err = orchestrator.ClassifyWithLanguage(ctx, normalizedContent, langResult.Codes())
 		if err != nil {
 			result.Error = "classification failed"
 			return result
@@ -776,43 +1734,46 @@ func processBatchItem(ctx context.Context, db *database.PostgresDB, orchestrator
 		}
 	}
 
-	// Get policy
-	var policy *models.Policy
-	var err error
-	if item.PolicyID != nil {
-		policy, err = evaluator.GetPolicyByID(ctx, *item.PolicyID)
-		if err != nil {
-			policy, err = evaluator.GetDefaultPolicy(ctx)
-		}
-	} else {
-		policy, err = evaluator.GetDefaultPolicy(ctx)
-	}
-	if err != nil {
-		policy = &models.Policy{ID: uuid.New(), Name: "default", Version: 1}
+	sourceLanguage := translationSource(orchestrator, classResult, classResult == nil, langResult.Codes())
+
+	// Screen for prompt injection like single requests. Batch items get no LLM
+	// pass, so the heuristic and remembered model-based scores apply.
+	var signals map[string]float64
+	if screened := screen.Check(ctx, normalizedContent); screened.Score > 0 {
+		signals = map[string]float64{injection.Signal: screened.Score}
 	}
 
 	// Evaluate with context metadata
-	var action models.PolicyAction
 	evalOpts := &engine.EvaluationOptions{
 		ContextMetadata: item.ContextMetadata,
+		Signals:         signals,
+		Translated:      sourceLanguage != "",
+		SourceLanguage:  sourceLanguage,
+		Language:        langResult.Language,
+		Languages:       langResult.Codes(),
 	}
-	if policy.Name != "default" {
-		evalResult, err := evaluator.EvaluateScores(ctx, scores, policy.ID, evalOpts)
-		if err != nil {
-			result.Error = "policy evaluation failed"
-			return result
-		}
-		action = evalResult.Action
-	} else {
-		action = models.ActionAllow
+	evalResult, err := evaluator.EvaluateScores(ctx, scores, policy.ID, evalOpts)
+	if err != nil {
+		result.Error = "policy evaluation failed"
+		return result
 	}
+	action := evalResult.Action
 
 	// Create decision
 	modelName := "s-nlp/roberta_toxicity_classifier"
 	modelVersion := "v1"
+	var routedProvider *string
+	var routingBucket *int
+	var rawScores *models.CategoryScores
 	if classResult != nil {
 		modelName = classResult.ModelName
 		modelVersion = classResult.ModelVersion
+		routedProvider = &classResult.ProviderName
+		routingBucket = classResult.RoutingBucket
+		calibrationVersion = classResult.CalibrationVersion
+		if len(chunks) == 1 {
+			rawScores = classResult.RawScores
+		}
 	}
 
 	decision := &models.ModerationDecision{
@@ -820,6 +1781,12 @@ func processBatchItem(ctx context.Context, db *database.PostgresDB, orchestrator
 		ModelName: modelName, ModelVersion: modelVersion,
 		CategoryScores: *scores, PolicyID: &policy.ID,
 		PolicyVersion: &policy.Version, AutomatedAction: action,
+		ProviderName: routedProvider, RoutingBucket: routingBucket,
+		CalibrationVersion: calibrationVersion, RawCategoryScores: rawScores,
+		Signals: signals, Translated: sourceLanguage != "",
+	}
+	if decision.Translated {
+		decision.SourceLanguage = &sourceLanguage
 	}
 
 	tx, err := evidenceWriter.BeginTx(ctx)
@@ -829,8 +1796,8 @@ func processBatchItem(ctx context.Context, db *database.PostgresDB, orchestrator
 	}
 	defer tx.Rollback(ctx)
 
-	decisionQuery := `INSERT INTO moderation_decisions (id, submission_id, model_name, model_version, category_scores, policy_id, policy_version, automated_action) VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING created_at`
-	if err := tx.QueryRow(ctx, decisionQuery, decision.ID, decision.SubmissionID, decision.ModelName, decision.ModelVersion, decision.CategoryScores, decision.PolicyID, decision.PolicyVersion, decision.AutomatedAction).Scan(&decision.CreatedAt); err != nil {
+	decisionQuery := `INSERT INTO moderation_decisions (id, submission_id, model_name, model_version, category_scores, policy_id, policy_version, automated_action, provider_name, routing_bucket, calibration_version, raw_category_scores, signals, translated, source_language) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15) RETURNING created_at`
+	if err := tx.QueryRow(ctx, decisionQuery, decision.ID, decision.SubmissionID, decision.ModelName, decision.ModelVersion, decision.CategoryScores, decision.PolicyID, decision.PolicyVersion, decision.AutomatedAction, decision.ProviderName, decision.RoutingBucket, decision.CalibrationVersion, decision.RawCategoryScores, decision.Signals, decision.Translated, decision.SourceLanguage).Scan(&decision.CreatedAt); err != nil {
 		result.Error = "failed to create decision"
 		return result
 	}
@@ -841,6 +1808,7 @@ func processBatchItem(ctx context.Context, db *database.PostgresDB, orchestrator
 		DecisionID: &decision.ID, ModelName: &decision.ModelName,
 		ModelVersion: &decision.ModelVersion, CategoryScores: &decision.CategoryScores,
 		AutomatedAction: &decision.AutomatedAction, Immutable: true,
+		NormalizationProfile: &profileKey,
 	}
 	if err := evidenceWriter.WriteEvidenceInTx(ctx, tx, evidenceRecord); err != nil {
 		result.Error = "failed to record evidence"
@@ -852,13 +1820,53 @@ func processBatchItem(ctx context.Context, db *database.PostgresDB, orchestrator
 		return result
 	}
 
+	runShadowClassification(orchestrator, shadowRecorder, decision.ID, normalizedContent)
+
 	result.DecisionID = decision.ID
 	result.Action = action
 	result.CategoryScores = scores
+	result.Signals = signals
 	result.RequiresReview = action == models.ActionEscalate
 	return result
 }
 
+// shadowTimeout bounds background shadow classification so slow shadow
+// providers cannot pile up goroutines.
+const shadowTimeout = 30 * time.Second
+
+// runShadowClassification invokes shadow providers in the background and records
+// their scores against the decision. It never affects the returned action.
+// Control: MOD-005 (Multi-Provider Classification Orchestration)
+func runShadowClassification(orchestrator *classifier.Orchestrator, recorder *shadow.Recorder, decisionID uuid.UUID, text string) {
+	if recorder == nil || !orchestrator.HasShadowProviders() {
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), shadowTimeout)
+		defer cancel()
+
+		results := orchestrator.ClassifyShadow(ctx, text)
+		records := make([]models.ShadowClassification, 0, len(results))
+		for _, r := range results {
+			rec := models.ShadowClassification{
+				DecisionID:     decisionID,
+				ProviderName:   r.ProviderName,
+				ModelName:      r.ModelName,
+				ModelVersion:   r.ModelVersion,
+				CategoryScores: r.Scores,
+				LatencyMs:      int(r.Latency.Milliseconds()),
+			}
+			if r.Err != nil {
+				msg := r.Err.Error()
+				rec.Error = &msg
+			}
+			records = append(records, rec)
+		}
+		recorder.Record(ctx, records)
+	}()
+}
+
 // --- Async Moderation Worker Pool ---
 
 // asyncJob represents a pending async moderation request.
@@ -866,6 +1874,8 @@ type asyncJob struct {
 	RequestID   uuid.UUID
 	Request     models.ModerationRequest
 	CallbackURL string
+	// APIKeyHash identifies the submitter's API key, which policy routes may match on
+	APIKeyHash string
 }
 
 // asyncWorkerPool processes async moderation jobs with graceful shutdown.
@@ -911,9 +1921,9 @@ func (p *asyncWorkerPool) shutdown() {
 
 // --- Async Moderation Handler ---
 
-func asyncModerateHandler(db *database.PostgresDB, orchestrator *classifier.Orchestrator, evaluator *engine.Evaluator, evidenceWriter *evidence.Writer, redisCache *cache.RedisCache, webhookDispatcher *webhook.Dispatcher, cfg *config.Config, logger *zap.Logger, textNormalizer *normalizer.Normalizer, langDetector *langdetect.Detector, llmProvider *classifier.LLMProvider, behaviorScorer *behavior.Scorer, metrics *observability.Metrics, pool *asyncWorkerPool) gin.HandlerFunc {
+func asyncModerateHandler(db *database.PostgresDB, orchestrator *classifier.Orchestrator, evaluator *engine.Evaluator, evidenceWriter *evidence.Writer, redisCache *cache.RedisCache, webhookDispatcher *webhook.Dispatcher, cfg *config.Config, logger *zap.Logger, normalizers *normalizer.Registry, langDetector *langdetect.Detector, llmProvider *classifier.LLMProvider, promptStore *prompt.Store, behaviorScorer *behavior.Scorer, shadowRecorder *shadow.Recorder, metrics *observability.Metrics, pool *asyncWorkerPool) gin.HandlerFunc {
 	// Create the sync handler to reuse the moderation pipeline
-	syncHandler := moderateHandler(db, orchestrator, evaluator, evidenceWriter, redisCache, webhookDispatcher, cfg, logger, textNormalizer, langDetector, llmProvider, behaviorScorer, metrics)
+	syncHandler := moderateHandler(db, orchestrator, evaluator, evidenceWriter, redisCache, webhookDispatcher, cfg, logger, normalizers, langDetector, llmProvider, promptStore, behaviorScorer, shadowRecorder, metrics)
 
 	// Start workers that process async jobs
 	pool.start(5, func(job asyncJob) {
@@ -922,10 +1932,11 @@ func asyncModerateHandler(db *database.PostgresDB, orchestrator *classifier.Orch
 
 		// Build a sync moderation request and capture the response
 		syncReq := models.ModerationRequest{
-			Content:         job.Request.Content,
-			ContextMetadata: job.Request.ContextMetadata,
-			Source:          job.Request.Source,
-			PolicyID:        job.Request.PolicyID,
+			Content:              job.Request.Content,
+			ContextMetadata:      job.Request.ContextMetadata,
+			Source:               job.Request.Source,
+			PolicyID:             job.Request.PolicyID,
+			NormalizationProfile: job.Request.NormalizationProfile,
 		}
 
 		recorder := &responseRecorder{body: new(bytes.Buffer)}
@@ -935,6 +1946,9 @@ func asyncModerateHandler(db *database.PostgresDB, orchestrator *classifier.Orch
 		bodyBytes, _ := json.Marshal(syncReq)
 		fakeCtx.Request.Body = io.NopCloser(bytes.NewReader(bodyBytes))
 		fakeCtx.Request.Header.Set("Content-Type", "application/json")
+		if job.APIKeyHash != "" {
+			fakeCtx.Request.Header.Set(middleware.APIKeyHashHeader, job.APIKeyHash)
+		}
 
 		syncHandler(fakeCtx)
 
@@ -982,12 +1996,14 @@ func asyncModerateHandler(db *database.PostgresDB, orchestrator *classifier.Orch
 		job := asyncJob{
 			RequestID:   requestID,
 			Request: models.ModerationRequest{
-				Content:         req.Content,
-				ContextMetadata: req.ContextMetadata,
-				Source:          req.Source,
-				PolicyID:        req.PolicyID,
+				Content:              req.Content,
+				ContextMetadata:      req.ContextMetadata,
+				Source:               req.Source,
+				PolicyID:             req.PolicyID,
+				NormalizationProfile: req.NormalizationProfile,
 			},
 			CallbackURL: req.CallbackURL,
+			APIKeyHash:  middleware.APIKeyHash(c),
 		}
 
 		if !pool.submit(job) {
@@ -2,22 +2,56 @@ package engine
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sync"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/proth1/text-moderator/internal/models"
+	"github.com/proth1/text-moderator/internal/prompt"
 	"go.uber.org/zap"
 )
 
 // Control: POL-001 (Deterministic policy evaluation)
 
+// ErrPolicyNotInForce is returned when content would be evaluated against a
+// policy that is not published, or whose scheduled publish has not taken
+// effect yet.
+var ErrPolicyNotInForce = errors.New("policy is not in force")
+
 // EvaluationOptions provides optional context for policy evaluation.
 type EvaluationOptions struct {
 	// ContextMetadata from the moderation request (e.g., audience, platform).
 	ContextMetadata map[string]interface{}
 	// TrustScore from user behavioral scoring (0.0-1.0, nil if not available).
 	TrustScore *float64
+	// Signals are detector scores outside the category scores (e.g. prompt_injection), 0.0-1.0.
+	Signals map[string]float64
+	// Translated is set when the scores are for a machine translation of the
+	// content; SourceLanguage is the language it was translated from.
+	Translated     bool
+	SourceLanguage string
+	// Language is the primary detected language and Languages every detected
+	// language, ranked (ISO 639-1). Only policy rules use them.
+	Language  string
+	Languages []string
+}
+
+// signalRule is the threshold and action applied to a signal when the policy
+// does not configure one.
+type signalRule struct {
+	threshold float64
+	action    models.PolicyAction
+}
+
+// defaultSignalRules apply to every policy. Content that tries to manipulate
+// the LLM classifier is escalated to a human, since the scores it produced
+// cannot be trusted either way.
+var defaultSignalRules = map[string]signalRule{
+	"prompt_injection": {threshold: 0.5, action: models.ActionEscalate},
 }
 
 // ContextOverride defines a threshold adjustment based on context metadata matching.
@@ -30,6 +64,10 @@ type ContextOverride struct {
 type Evaluator struct {
 	db     *pgxpool.Pool
 	logger *zap.Logger
+
+	// Compiled rules per policy ID; a policy version's rules never change
+	rulesMu sync.RWMutex
+	rules   map[uuid.UUID][]compiledRule
 }
 
 // NewEvaluator creates a new policy evaluator
@@ -37,6 +75,7 @@ func NewEvaluator(db *pgxpool.Pool, logger *zap.Logger) *Evaluator {
 	return &Evaluator{
 		db:     db,
 		logger: logger,
+		rules:  make(map[uuid.UUID][]compiledRule),
 	}
 }
 
@@ -45,19 +84,38 @@ func (e *Evaluator) Pool() *pgxpool.Pool {
 	return e.db
 }
 
-// EvaluateScores evaluates category scores against a policy.
+// EvaluateScores evaluates category scores against a policy, returning
+// ErrPolicyNotInForce unless it is in force (see GetPolicyInForce).
 // Pass nil for opts if no context or trust score adjustments are needed.
 func (e *Evaluator) EvaluateScores(ctx context.Context, scores *models.CategoryScores, policyID uuid.UUID, opts *EvaluationOptions) (*models.PolicyEvaluationResponse, error) {
 	// Fetch policy from database
-	policy, err := e.getPolicy(ctx, policyID)
+	policy, err := e.GetPolicyInForce(ctx, policyID)
 	if err != nil {
+		if errors.Is(err, ErrPolicyNotInForce) {
+			return nil, err
+		}
 		return nil, fmt.Errorf("failed to get policy: %w", err)
 	}
 
-	if policy.Status != models.PolicyStatusPublished {
-		return nil, fmt.Errorf("policy %s is not published (status: %s)", policyID, policy.Status)
+	result, err := e.evaluatePolicy(policy, scores, opts)
+	if err != nil {
+		return nil, err
 	}
 
+	e.logger.Info("policy evaluation completed",
+		zap.String("policy_id", policyID.String()),
+		zap.String("policy_name", policy.Name),
+		zap.Int("policy_version", policy.Version),
+		zap.String("action", string(result.Action)),
+		zap.Strings("triggered_rules", result.TriggeredRules),
+	)
+
+	return result, nil
+}
+
+// evaluatePolicy evaluates category scores against a policy whatever its
+// status, so drafts can be simulated before they are published.
+func (e *Evaluator) evaluatePolicy(policy *models.Policy, scores *models.CategoryScores, opts *EvaluationOptions) (*models.PolicyEvaluationResponse, error) {
 	// Build effective thresholds (start from policy defaults)
 	effectiveThresholds := make(map[string]float64, len(policy.Thresholds))
 	for k, v := range policy.Thresholds {
@@ -69,6 +127,12 @@ func (e *Evaluator) EvaluateScores(ctx context.Context, scores *models.CategoryS
 		e.applyContextOverrides(effectiveThresholds, policy.Scope, opts.ContextMetadata)
 	}
 
+	// Apply the policy's adjustments for machine-translated content
+	var translatedAction models.PolicyAction
+	if opts != nil && opts.Translated {
+		translatedAction = e.applyTranslatedOverrides(effectiveThresholds, policy.Scope)
+	}
+
 	// Apply trust score adjustments (low trust = stricter thresholds)
 	if opts != nil && opts.TrustScore != nil {
 		trust := *opts.TrustScore
@@ -88,17 +152,7 @@ func (e *Evaluator) EvaluateScores(ctx context.Context, scores *models.CategoryS
 	triggeredRules := []string{}
 	highestAction := models.ActionAllow
 
-	categories := map[string]float64{
-		"toxicity":        scores.Toxicity,
-		"hate":            scores.Hate,
-		"harassment":      scores.Harassment,
-		"sexual_content":  scores.SexualContent,
-		"violence":        scores.Violence,
-		"profanity":       scores.Profanity,
-		"self_harm":       scores.SelfHarm,
-		"spam":            scores.Spam,
-		"pii":             scores.PII,
-	}
+	categories := scores.ToMap()
 
 	for category, score := range categories {
 		threshold, hasThreshold := effectiveThresholds[category]
@@ -121,13 +175,65 @@ func (e *Evaluator) EvaluateScores(ctx context.Context, scores *models.CategoryS
 		}
 	}
 
-	e.logger.Info("policy evaluation completed",
-		zap.String("policy_id", policyID.String()),
-		zap.String("policy_name", policy.Name),
-		zap.Int("policy_version", policy.Version),
-		zap.String("action", string(highestAction)),
-		zap.Strings("triggered_rules", triggeredRules),
-	)
+	// Evaluate detector signals; a policy threshold or action for a signal
+	// overrides the default rule. Their action is a floor, applied after the
+	// expression rules so a final rule cannot cancel it
+	signalAction := models.ActionAllow
+	if opts != nil {
+		for signal, score := range opts.Signals {
+			rule, hasRule := defaultSignalRules[signal]
+			if threshold, ok := effectiveThresholds[signal]; ok {
+				rule.threshold = threshold
+				hasRule = true
+			}
+			if !hasRule || score < rule.threshold {
+				continue
+			}
+			if actionStr, ok := policy.Actions[signal]; ok {
+				rule.action = models.PolicyAction(actionStr)
+			}
+
+			triggeredRules = append(triggeredRules, fmt.Sprintf("%s >= %.2f", signal, rule.threshold))
+			if actionPriority(rule.action) > actionPriority(signalAction) {
+				signalAction = rule.action
+			}
+		}
+	}
+
+	// Evaluate the policy's expression rules in order. A matching final rule
+	// decides the action regardless of thresholds, but not below the signal
+	// and translated floors
+	if len(policy.Rules) > 0 {
+		rules, err := e.policyRules(policy)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compile policy rules: %w", err)
+		}
+		outcome := evaluateRules(rules, ruleVariables(categories, opts))
+		for ruleID, err := range outcome.errors {
+			e.logger.Warn("policy rule evaluation failed",
+				zap.String("policy_id", policy.ID.String()),
+				zap.String("rule_id", ruleID),
+				zap.Error(err),
+			)
+		}
+		triggeredRules = append(triggeredRules, outcome.triggered...)
+		if outcome.final || actionPriority(outcome.action) > actionPriority(highestAction) {
+			highestAction = outcome.action
+		}
+	}
+
+	// A signal action is a floor: content that tripped a detector gets at least this action
+	if actionPriority(signalAction) > actionPriority(highestAction) {
+		highestAction = signalAction
+	}
+
+	// A translated action is a floor: translated content gets at least this action
+	if translatedAction != "" {
+		triggeredRules = append(triggeredRules, fmt.Sprintf("translated from %s", opts.SourceLanguage))
+		if actionPriority(translatedAction) > actionPriority(highestAction) {
+			highestAction = translatedAction
+		}
+	}
 
 	return &models.PolicyEvaluationResponse{
 		Action:         highestAction,
@@ -137,6 +243,25 @@ func (e *Evaluator) EvaluateScores(ctx context.Context, scores *models.CategoryS
 	}, nil
 }
 
+// policyRules returns the policy's compiled rules, compiling them on first use.
+func (e *Evaluator) policyRules(policy *models.Policy) ([]compiledRule, error) {
+	e.rulesMu.RLock()
+	rules, ok := e.rules[policy.ID]
+	e.rulesMu.RUnlock()
+	if ok {
+		return rules, nil
+	}
+
+	rules, err := compileRules(policy.Rules)
+	if err != nil {
+		return nil, err
+	}
+	e.rulesMu.Lock()
+	e.rules[policy.ID] = rules
+	e.rulesMu.Unlock()
+	return rules, nil
+}
+
 // applyContextOverrides adjusts thresholds based on policy scope context_overrides and request metadata.
 func (e *Evaluator) applyContextOverrides(thresholds map[string]float64, scope map[string]interface{}, metadata map[string]interface{}) {
 	if scope == nil {
@@ -183,30 +308,53 @@ func (e *Evaluator) applyContextOverrides(thresholds map[string]float64, scope m
 		}
 
 		// Apply threshold adjustments
-		adjustmentsRaw, ok := override["threshold_adjustments"]
-		if !ok {
-			continue
-		}
-		adjustments, ok := adjustmentsRaw.(map[string]interface{})
+		applyThresholdAdjustments(thresholds, override["threshold_adjustments"])
+	}
+}
+
+// applyTranslatedOverrides applies the policy scope's "translated" settings to
+// content classified from a machine translation, which is less reliable than
+// classifying the original. threshold_adjustments shift thresholds (negative
+// is stricter) and action, if set, is the least severe action translated
+// content receives, e.g. "escalate" to send it all to review. It returns that
+// action, or "" if none is set.
+func (e *Evaluator) applyTranslatedOverrides(thresholds map[string]float64, scope map[string]interface{}) models.PolicyAction {
+	if scope == nil {
+		return ""
+	}
+	translated, ok := scope["translated"].(map[string]interface{})
+	if !ok {
+		return ""
+	}
+
+	applyThresholdAdjustments(thresholds, translated["threshold_adjustments"])
+
+	action, _ := translated["action"].(string)
+	return models.PolicyAction(action)
+}
+
+// applyThresholdAdjustments adds each adjustment to the matching threshold,
+// keeping the result within 0.05-1.0. Categories without a threshold are left alone.
+func applyThresholdAdjustments(thresholds map[string]float64, adjustmentsRaw interface{}) {
+	adjustments, ok := adjustmentsRaw.(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	for category, adjRaw := range adjustments {
+		adj, ok := adjRaw.(float64)
 		if !ok {
 			continue
 		}
-
-		for category, adjRaw := range adjustments {
-			adj, ok := adjRaw.(float64)
-			if !ok {
-				continue
+		if currentThreshold, exists := thresholds[category]; exists {
+			newThreshold := currentThreshold + adj
+			if newThreshold < 0.05 {
+				newThreshold = 0.05
 			}
-			if currentThreshold, exists := thresholds[category]; exists {
-				newThreshold := currentThreshold + adj
-				if newThreshold < 0.05 {
-					newThreshold = 0.05
-				}
-				if newThreshold > 1.0 {
-					newThreshold = 1.0
-				}
-				thresholds[category] = newThreshold
+			if newThreshold > 1.0 {
+				newThreshold = 1.0
 			}
+			thresholds[category] = newThreshold
 		}
 	}
 }
@@ -216,47 +364,105 @@ func (e *Evaluator) GetPolicyByID(ctx context.Context, policyID uuid.UUID) (*mod
 	return e.getPolicy(ctx, policyID)
 }
 
-// getPolicy retrieves a policy from the database
-func (e *Evaluator) getPolicy(ctx context.Context, policyID uuid.UUID) (*models.Policy, error) {
-	query := `
-		SELECT id, name, version, thresholds, actions, scope, status, effective_date, created_at, created_by
+// GetPolicyInForce retrieves a policy to evaluate content against by ID. It
+// returns ErrPolicyNotInForce unless the policy is published, has taken
+// effect and has not been superseded by a later version that has.
+func (e *Evaluator) GetPolicyInForce(ctx context.Context, policyID uuid.UUID) (*models.Policy, error) {
+	policy, err := e.getPolicy(ctx, policyID)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := e.db.Query(ctx, `
+		SELECT `+policyColumns+`
 		FROM policies
-		WHERE id = $1
-	`
+		WHERE name = $1 AND id <> $2 AND status = 'published'
+	`, policy.Name, policy.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query policy versions: %w", err)
+	}
+	defer rows.Close()
 
-	var policy models.Policy
-	err := e.db.QueryRow(ctx, query, policyID).Scan(
-		&policy.ID,
-		&policy.Name,
-		&policy.Version,
-		&policy.Thresholds,
-		&policy.Actions,
-		&policy.Scope,
-		&policy.Status,
-		&policy.EffectiveDate,
-		&policy.CreatedAt,
-		&policy.CreatedBy,
-	)
+	var versions []*models.Policy
+	for rows.Next() {
+		version, err := scanPolicy(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan policy: %w", err)
+		}
+		versions = append(versions, version)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query policy versions: %w", err)
+	}
 
+	if err := checkInForce(policy, versions, time.Now()); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// checkInForce returns ErrPolicyNotInForce unless the policy is published
+// with an effective date no later than now, and none of the other published
+// versions of the policy has taken effect after it. A scheduled publish leaves
+// the policy published with a future effective date, and the versions it
+// replaces published until then.
+func checkInForce(policy *models.Policy, versions []*models.Policy, now time.Time) error {
+	if policy.Status != models.PolicyStatusPublished {
+		return fmt.Errorf("%w: policy %s is %s", ErrPolicyNotInForce, policy.ID, policy.Status)
+	}
+	if !hasTakenEffect(policy, now) {
+		return fmt.Errorf("%w: policy %s takes effect at %s", ErrPolicyNotInForce, policy.ID, policy.EffectiveDate.UTC().Format(time.RFC3339))
+	}
+	for _, version := range versions {
+		if version.ID != policy.ID && version.Status == models.PolicyStatusPublished && hasTakenEffect(version, now) && effectiveAfter(version, policy) {
+			return fmt.Errorf("%w: policy %s is superseded by version %d", ErrPolicyNotInForce, policy.ID, version.Version)
+		}
+	}
+	return nil
+}
+
+// hasTakenEffect reports whether a published policy's effective date has passed.
+func hasTakenEffect(policy *models.Policy, now time.Time) bool {
+	return policy.EffectiveDate == nil || !policy.EffectiveDate.After(now)
+}
+
+// effectiveAfter reports whether policy a took effect after b, in the order
+// inForcePolicies picks the latest version by: effective date, or creation
+// time without one, then creation time.
+func effectiveAfter(a, b *models.Policy) bool {
+	aAt, bAt := a.CreatedAt, b.CreatedAt
+	if a.EffectiveDate != nil {
+		aAt = *a.EffectiveDate
+	}
+	if b.EffectiveDate != nil {
+		bAt = *b.EffectiveDate
+	}
+	if !aAt.Equal(bAt) {
+		return aAt.After(bAt)
+	}
+	return a.CreatedAt.After(b.CreatedAt)
+}
+
+// getPolicy retrieves a policy from the database
+func (e *Evaluator) getPolicy(ctx context.Context, policyID uuid.UUID) (*models.Policy, error) {
+	query := `SELECT ` + policyColumns + ` FROM policies WHERE id = $1`
+
+	policy, err := scanPolicy(e.db.QueryRow(ctx, query, policyID))
 	if err != nil {
 		return nil, fmt.Errorf("failed to query policy: %w", err)
 	}
 
-	return &policy, nil
+	return policy, nil
 }
 
-// GetDefaultPolicy retrieves the default published policy
-func (e *Evaluator) GetDefaultPolicy(ctx context.Context) (*models.Policy, error) {
-	query := `
-		SELECT id, name, version, thresholds, actions, scope, status, effective_date, created_at, created_by
-		FROM policies
-		WHERE status = 'published'
-		ORDER BY created_at DESC
-		LIMIT 1
-	`
+// policyColumns are the policies columns read by scanPolicy, in order.
+const policyColumns = `id, name, version, thresholds, actions, scope, status, effective_date, created_at, created_by,
+		published_at, published_by, normalization_profile, normalization_profiles, rules`
 
+// scanPolicy scans a row of policyColumns followed by any extra columns.
+func scanPolicy(row pgx.Row, extra ...interface{}) (*models.Policy, error) {
 	var policy models.Policy
-	err := e.db.QueryRow(ctx, query).Scan(
+	dest := []interface{}{
 		&policy.ID,
 		&policy.Name,
 		&policy.Version,
@@ -267,12 +473,15 @@ func (e *Evaluator) GetDefaultPolicy(ctx context.Context) (*models.Policy, error
 		&policy.EffectiveDate,
 		&policy.CreatedAt,
 		&policy.CreatedBy,
-	)
-
-	if err != nil {
-		return nil, fmt.Errorf("no default policy found: %w", err)
+		&policy.PublishedAt,
+		&policy.PublishedBy,
+		&policy.NormalizationProfile,
+		&policy.NormalizationProfiles,
+		&policy.Rules,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
 	}
-
 	return &policy, nil
 }
 
@@ -292,12 +501,20 @@ func actionPriority(action models.PolicyAction) int {
 	}
 }
 
-// CreatePolicy creates a new policy in the database
+// CreatePolicy creates a new policy in the database. A new version of an
+// existing policy starts with the published prompt template of the latest
+// earlier version that has one.
 func (e *Evaluator) CreatePolicy(ctx context.Context, req *models.CreatePolicyRequest, createdBy uuid.UUID) (*models.Policy, error) {
+	tx, err := e.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
 	// Check if policy with this name already exists
 	var maxVersion int
 	versionQuery := `SELECT COALESCE(MAX(version), 0) FROM policies WHERE name = $1`
-	err := e.db.QueryRow(ctx, versionQuery, req.Name).Scan(&maxVersion)
+	err = tx.QueryRow(ctx, versionQuery, req.Name).Scan(&maxVersion)
 	if err != nil {
 		return nil, fmt.Errorf("failed to check existing policy versions: %w", err)
 	}
@@ -306,23 +523,27 @@ func (e *Evaluator) CreatePolicy(ctx context.Context, req *models.CreatePolicyRe
 
 	// Create policy
 	policy := &models.Policy{
-		ID:         uuid.New(),
-		Name:       req.Name,
-		Version:    newVersion,
-		Thresholds: req.Thresholds,
-		Actions:    req.Actions,
-		Scope:      req.Scope,
-		Status:     models.PolicyStatusDraft,
-		CreatedBy:  &createdBy,
+		ID:                    uuid.New(),
+		Name:                  req.Name,
+		Version:               newVersion,
+		Thresholds:            req.Thresholds,
+		Actions:               req.Actions,
+		Scope:                 req.Scope,
+		Status:                models.PolicyStatusDraft,
+		CreatedBy:             &createdBy,
+		NormalizationProfile:  req.NormalizationProfile,
+		NormalizationProfiles: req.NormalizationProfiles,
+		Rules:                 req.Rules,
 	}
 
 	query := `
-		INSERT INTO policies (id, name, version, thresholds, actions, scope, status, created_by)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		INSERT INTO policies (id, name, version, thresholds, actions, scope, status, created_by,
+			normalization_profile, normalization_profiles, rules)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 		RETURNING created_at
 	`
 
-	err = e.db.QueryRow(ctx, query,
+	err = tx.QueryRow(ctx, query,
 		policy.ID,
 		policy.Name,
 		policy.Version,
@@ -331,27 +552,52 @@ func (e *Evaluator) CreatePolicy(ctx context.Context, req *models.CreatePolicyRe
 		policy.Scope,
 		policy.Status,
 		policy.CreatedBy,
+		policy.NormalizationProfile,
+		policy.NormalizationProfiles,
+		policy.Rules,
 	).Scan(&policy.CreatedAt)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to create policy: %w", err)
 	}
 
+	carried, err := prompt.CarryForwardTemplate(ctx, tx, policy.ID, policy.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit policy: %w", err)
+	}
+
 	e.logger.Info("policy created",
 		zap.String("policy_id", policy.ID.String()),
 		zap.String("name", policy.Name),
 		zap.Int("version", policy.Version),
+		zap.Bool("prompt_template_carried_forward", carried),
 	)
 
 	return policy, nil
 }
 
-// ListPolicies retrieves policies with optional filtering
+// ListPolicies retrieves policies with optional filtering. Listing published
+// policies leaves out versions superseded by a later version that has taken
+// effect, which stay published until archived.
 func (e *Evaluator) ListPolicies(ctx context.Context, status *models.PolicyStatus) ([]models.Policy, error) {
 	query := `
-		SELECT id, name, version, thresholds, actions, scope, status, effective_date, created_at, created_by
+		SELECT ` + policyColumns + `
 		FROM policies
 		WHERE ($1::policy_status IS NULL OR status = $1)
+		  AND ($1::policy_status IS DISTINCT FROM 'published' OR NOT EXISTS (
+			SELECT 1
+			FROM policies later
+			WHERE later.name = policies.name
+			  AND later.id <> policies.id
+			  AND later.status = 'published'
+			  AND (later.effective_date IS NULL OR later.effective_date <= NOW())
+			  AND (COALESCE(later.effective_date, later.created_at), later.created_at)
+			    > (COALESCE(policies.effective_date, policies.created_at), policies.created_at)
+		  ))
 		ORDER BY created_at DESC
 	`
 
@@ -363,23 +609,11 @@ func (e *Evaluator) ListPolicies(ctx context.Context, status *models.PolicyStatu
 
 	var policies []models.Policy
 	for rows.Next() {
-		var policy models.Policy
-		err := rows.Scan(
-			&policy.ID,
-			&policy.Name,
-			&policy.Version,
-			&policy.Thresholds,
-			&policy.Actions,
-			&policy.Scope,
-			&policy.Status,
-			&policy.EffectiveDate,
-			&policy.CreatedAt,
-			&policy.CreatedBy,
-		)
+		policy, err := scanPolicy(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan policy: %w", err)
 		}
-		policies = append(policies, policy)
+		policies = append(policies, *policy)
 	}
 
 	return policies, nil
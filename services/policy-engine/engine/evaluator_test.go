@@ -0,0 +1,71 @@
+package engine
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/proth1/text-moderator/internal/models"
+)
+
+func TestCheckInForce(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	past := now.Add(-time.Hour)
+	future := now.Add(time.Hour)
+
+	tests := []struct {
+		name      string
+		status    models.PolicyStatus
+		effective *time.Time
+		wantErr   bool
+	}{
+		{"published", models.PolicyStatusPublished, &past, false},
+		{"published without effective date", models.PolicyStatusPublished, nil, false},
+		{"takes effect now", models.PolicyStatusPublished, &now, false},
+		{"scheduled", models.PolicyStatusPublished, &future, true},
+		{"draft", models.PolicyStatusDraft, nil, true},
+		{"archived", models.PolicyStatusArchived, &past, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := &models.Policy{ID: uuid.New(), Status: tt.status, EffectiveDate: tt.effective}
+			err := checkInForce(policy, nil, now)
+			if tt.wantErr {
+				if !errors.Is(err, ErrPolicyNotInForce) {
+					t.Errorf("got error %v, want ErrPolicyNotInForce", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("got error %v, want none", err)
+			}
+		})
+	}
+}
+
+func TestCheckInForce_ScheduledVersionSupersedesOnceEffective(t *testing.T) {
+	published := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	scheduledFor := published.Add(24 * time.Hour)
+
+	v1 := &models.Policy{ID: uuid.New(), Version: 1, Status: models.PolicyStatusPublished, EffectiveDate: &published, CreatedAt: published.Add(-time.Hour)}
+	v2 := &models.Policy{ID: uuid.New(), Version: 2, Status: models.PolicyStatusPublished, EffectiveDate: &scheduledFor, CreatedAt: published.Add(time.Hour)}
+	versions := []*models.Policy{v1, v2}
+
+	before := scheduledFor.Add(-time.Minute)
+	if err := checkInForce(v1, versions, before); err != nil {
+		t.Errorf("v1 before v2 takes effect: got error %v, want none", err)
+	}
+	if err := checkInForce(v2, versions, before); !errors.Is(err, ErrPolicyNotInForce) {
+		t.Errorf("v2 before it takes effect: got error %v, want ErrPolicyNotInForce", err)
+	}
+
+	after := scheduledFor.Add(time.Minute)
+	if err := checkInForce(v1, versions, after); !errors.Is(err, ErrPolicyNotInForce) {
+		t.Errorf("v1 after v2 takes effect: got error %v, want ErrPolicyNotInForce", err)
+	}
+	if err := checkInForce(v2, versions, after); err != nil {
+		t.Errorf("v2 after it takes effect: got error %v, want none", err)
+	}
+}
@@ -0,0 +1,292 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/proth1/text-moderator/internal/evidence"
+	"github.com/proth1/text-moderator/internal/models"
+	"go.uber.org/zap"
+)
+
+// Control: POL-001 (Policy lifecycle management)
+
+var (
+	// ErrInvalidTransition is returned for a transition the policy's status does not allow.
+	ErrInvalidTransition = errors.New("invalid policy transition")
+	// ErrNoRollbackTarget is returned when rolling back a policy with no
+	// earlier version that was ever published.
+	ErrNoRollbackTarget = errors.New("no previously published version to roll back to")
+)
+
+// policyTransitions is the policy lifecycle state machine: the status each
+// transition leaves a policy in, by its current status. Archived policies are
+// terminal, except that a rollback puts an earlier archived version back in force.
+var policyTransitions = map[models.PolicyStatus]map[models.PolicyTransition]models.PolicyStatus{
+	models.PolicyStatusDraft: {
+		models.TransitionPublish: models.PolicyStatusPublished,
+		models.TransitionArchive: models.PolicyStatusArchived,
+	},
+	models.PolicyStatusPublished: {
+		models.TransitionArchive:  models.PolicyStatusArchived,
+		models.TransitionRollback: models.PolicyStatusArchived,
+	},
+}
+
+// nextStatus returns the status transition t leaves a policy in status from in.
+func nextStatus(from models.PolicyStatus, t models.PolicyTransition) (models.PolicyStatus, error) {
+	to, ok := policyTransitions[from][t]
+	if !ok {
+		return "", fmt.Errorf("%w: cannot %s a policy that is %s", ErrInvalidTransition, t, from)
+	}
+	return to, nil
+}
+
+// Lifecycle performs policy lifecycle transitions. Each transition runs in one
+// transaction with a POL-001 evidence record for every policy it changes.
+type Lifecycle struct {
+	db       *pgxpool.Pool
+	evidence *evidence.Writer
+	suites   *TestSuites
+	logger   *zap.Logger
+}
+
+// NewLifecycle creates a new policy lifecycle manager
+func NewLifecycle(db *pgxpool.Pool, evidenceWriter *evidence.Writer, suites *TestSuites, logger *zap.Logger) *Lifecycle {
+	return &Lifecycle{
+		db:       db,
+		evidence: evidenceWriter,
+		suites:   suites,
+		logger:   logger,
+	}
+}
+
+// Publish publishes a draft policy. With an effective date in the future the
+// publish is scheduled: the policy is not in force until then, and the
+// versions it replaces stay in force meanwhile. Once it takes effect they are
+// superseded: still published until archived, but no longer in force or
+// listed as published. Otherwise the policy takes effect immediately and its
+// other published versions are archived.
+//
+// The policy's test suite gates the publish: its content is classified before
+// the policy is locked, then every case is evaluated against the locked
+// policy. If any case fails, the publish is blocked with a *TestSuiteError.
+func (l *Lifecycle) Publish(ctx context.Context, policyID uuid.UUID, effectiveDate *time.Time, actorID uuid.UUID) (*models.PolicyUpdatedEvent, error) {
+	prepared, err := l.suites.PrepareForPublish(ctx, policyID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	scheduled := effectiveDate != nil && effectiveDate.After(now)
+	effective := now
+	if scheduled {
+		effective = effectiveDate.UTC()
+	}
+
+	var testRun *models.PolicyTestRun
+	event, err := l.run(ctx, policyID, models.TransitionPublish, actorID, func(tx pgx.Tx, policy *models.Policy, event *models.PolicyUpdatedEvent) error {
+		var err error
+		testRun, err = l.suites.RunForPublish(policy, prepared, actorID)
+		var suiteErr *TestSuiteError
+		if errors.As(err, &suiteErr) {
+			testRun = suiteErr.Run
+		}
+		if err != nil {
+			return err
+		}
+
+		policy.EffectiveDate = &effective
+		policy.PublishedAt = &now
+		policy.PublishedBy = &actorID
+		event.Scheduled = scheduled
+		event.TestRun = testRun
+		if scheduled {
+			return nil
+		}
+		archived, err := l.archiveOtherVersions(ctx, tx, policy.Name, []uuid.UUID{policy.ID}, models.TransitionPublish, actorID)
+		event.Archived = archived
+		return err
+	})
+
+	// Every run is kept, whether or not the publish went through, once the
+	// transaction has ended and released the policy
+	if testRun != nil {
+		if recordErr := l.suites.RecordRun(ctx, testRun); recordErr != nil {
+			l.logger.Error("failed to record publish test run",
+				zap.String("policy_id", policyID.String()),
+				zap.Error(recordErr),
+			)
+		}
+	}
+	return event, err
+}
+
+// Archive archives a draft or published policy. Archiving the policy in force
+// leaves the previously effective published policy as the default.
+func (l *Lifecycle) Archive(ctx context.Context, policyID uuid.UUID, actorID uuid.UUID) (*models.PolicyUpdatedEvent, error) {
+	return l.run(ctx, policyID, models.TransitionArchive, actorID, nil)
+}
+
+// Rollback archives a published policy and puts the latest earlier version
+// that was ever published back in force, effective immediately. Any other
+// published versions of the policy are archived. Rollbacks do not run the
+// test suite, so a bad publish can always be undone.
+func (l *Lifecycle) Rollback(ctx context.Context, policyID uuid.UUID, actorID uuid.UUID) (*models.PolicyUpdatedEvent, error) {
+	return l.run(ctx, policyID, models.TransitionRollback, actorID, func(tx pgx.Tx, policy *models.Policy, event *models.PolicyUpdatedEvent) error {
+		target, err := scanPolicy(tx.QueryRow(ctx, `
+			SELECT `+policyColumns+`
+			FROM policies
+			WHERE name = $1 AND version < $2 AND status IN ('published', 'archived') AND published_at IS NOT NULL
+			ORDER BY version DESC
+			LIMIT 1
+			FOR UPDATE
+		`, policy.Name, policy.Version))
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrNoRollbackTarget
+		}
+		if err != nil {
+			return fmt.Errorf("failed to query rollback target: %w", err)
+		}
+
+		archived, err := l.archiveOtherVersions(ctx, tx, policy.Name, []uuid.UUID{policy.ID, target.ID}, models.TransitionRollback, actorID)
+		if err != nil {
+			return err
+		}
+		event.Archived = archived
+
+		now := time.Now().UTC()
+		target.Status = models.PolicyStatusPublished
+		target.EffectiveDate = &now
+		target.PublishedAt = &now
+		target.PublishedBy = &actorID
+		if err := l.save(ctx, tx, target, models.TransitionRollback, actorID); err != nil {
+			return err
+		}
+		event.Restored = target
+		return nil
+	})
+}
+
+// run locks the policy, moves it to the status the transition leads to and
+// saves it in one transaction with the transition's side effects, which apply
+// performs and which may update the policy before it is saved. A transition
+// of a published policy that would leave no default policy, or more than one,
+// is rejected with ErrNoDefaultPolicy or ErrMultipleDefaultPolicies.
+func (l *Lifecycle) run(ctx context.Context, policyID uuid.UUID, t models.PolicyTransition, actorID uuid.UUID, apply func(tx pgx.Tx, policy *models.Policy, event *models.PolicyUpdatedEvent) error) (*models.PolicyUpdatedEvent, error) {
+	tx, err := l.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	policy, err := scanPolicy(tx.QueryRow(ctx, `SELECT `+policyColumns+` FROM policies WHERE id = $1 FOR UPDATE`, policyID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query policy: %w", err)
+	}
+
+	to, err := nextStatus(policy.Status, t)
+	if err != nil {
+		return nil, err
+	}
+
+	event := &models.PolicyUpdatedEvent{
+		Transition:     t,
+		PreviousStatus: policy.Status,
+		ChangedBy:      actorID,
+		ChangedAt:      time.Now().UTC(),
+	}
+	policy.Status = to
+
+	if apply != nil {
+		if err := apply(tx, policy, event); err != nil {
+			return nil, err
+		}
+	}
+	if err := l.save(ctx, tx, policy, t, actorID); err != nil {
+		return nil, err
+	}
+
+	// Requests no scope route matches need a default policy to fall back to
+	if event.PreviousStatus == models.PolicyStatusPublished || to == models.PolicyStatusPublished {
+		if err := requireDefaultPolicy(ctx, tx); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit policy %s: %w", t, err)
+	}
+
+	event.Policy = *policy
+	l.logger.Info("policy transitioned",
+		zap.String("policy_id", policy.ID.String()),
+		zap.String("name", policy.Name),
+		zap.Int("version", policy.Version),
+		zap.String("transition", string(t)),
+		zap.String("status", string(policy.Status)),
+		zap.Bool("scheduled", event.Scheduled),
+	)
+
+	return event, nil
+}
+
+// archiveOtherVersions archives the published versions of the named policy
+// except those in keep, returning their IDs.
+func (l *Lifecycle) archiveOtherVersions(ctx context.Context, tx pgx.Tx, name string, keep []uuid.UUID, t models.PolicyTransition, actorID uuid.UUID) ([]uuid.UUID, error) {
+	rows, err := tx.Query(ctx, `
+		SELECT `+policyColumns+`
+		FROM policies
+		WHERE name = $1 AND id <> ALL($2) AND status = 'published'
+		ORDER BY version
+		FOR UPDATE
+	`, name, keep)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query published versions: %w", err)
+	}
+	var others []*models.Policy
+	for rows.Next() {
+		other, err := scanPolicy(rows)
+		if err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan policy: %w", err)
+		}
+		others = append(others, other)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query published versions: %w", err)
+	}
+
+	var archived []uuid.UUID
+	for _, other := range others {
+		other.Status = models.PolicyStatusArchived
+		if err := l.save(ctx, tx, other, t, actorID); err != nil {
+			return nil, err
+		}
+		archived = append(archived, other.ID)
+	}
+	return archived, nil
+}
+
+// save writes the policy's lifecycle fields and its evidence record.
+func (l *Lifecycle) save(ctx context.Context, tx pgx.Tx, policy *models.Policy, t models.PolicyTransition, actorID uuid.UUID) error {
+	_, err := tx.Exec(ctx, `
+		UPDATE policies
+		SET status = $2, effective_date = $3, published_at = $4, published_by = $5
+		WHERE id = $1
+	`, policy.ID, policy.Status, policy.EffectiveDate, policy.PublishedAt, policy.PublishedBy)
+	if err != nil {
+		return fmt.Errorf("failed to update policy status: %w", err)
+	}
+
+	if err := l.evidence.RecordPolicyTransition(ctx, tx, policy, t, actorID); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package engine
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/proth1/text-moderator/internal/models"
+)
+
+func TestNextStatus(t *testing.T) {
+	tests := []struct {
+		from       models.PolicyStatus
+		transition models.PolicyTransition
+		want       models.PolicyStatus
+		wantErr    bool
+	}{
+		{models.PolicyStatusDraft, models.TransitionPublish, models.PolicyStatusPublished, false},
+		{models.PolicyStatusDraft, models.TransitionArchive, models.PolicyStatusArchived, false},
+		{models.PolicyStatusDraft, models.TransitionRollback, "", true},
+		{models.PolicyStatusPublished, models.TransitionPublish, "", true},
+		{models.PolicyStatusPublished, models.TransitionArchive, models.PolicyStatusArchived, false},
+		{models.PolicyStatusPublished, models.TransitionRollback, models.PolicyStatusArchived, false},
+		{models.PolicyStatusArchived, models.TransitionPublish, "", true},
+		{models.PolicyStatusArchived, models.TransitionArchive, "", true},
+		{models.PolicyStatusArchived, models.TransitionRollback, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.from)+"/"+string(tt.transition), func(t *testing.T) {
+			got, err := nextStatus(tt.from, tt.transition)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidTransition) {
+					t.Errorf("got error %v, want ErrInvalidTransition", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("got error %v, want none", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,383 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/proth1/text-moderator/internal/models"
+	"go.uber.org/zap"
+)
+
+// Control: POL-003 (Regional Policy Resolution)
+//
+// A policy's scope routes requests to it:
+//
+//	"scope": {
+//	  "default": true,
+//	  "routes": [
+//	    {"id": "eu-kids", "match": {"region": "EU", "audience": ["kids", "teens"]}, "priority": 10},
+//	    {"id": "partner", "match": {"api_key_owner": "<user id>"}}
+//	  ]
+//	}
+//
+// A route matches when every key in match does: "source" is the request's
+// source, "api_key_owner" the ID of the user whose API key made the request,
+// and any other key a context metadata value. A list matches any of its
+// values. Among matching routes the highest priority wins, then the route
+// with the most conditions, then the policy name and route order. Requests no
+// route matches get the default policy, which must be set explicitly.
+
+// Route match keys that are not context metadata
+const (
+	matchSource      = "source"
+	matchAPIKeyOwner = "api_key_owner"
+)
+
+var (
+	// ErrNoDefaultPolicy is returned when no policy in force is marked as the
+	// default, by resolution or by a transition that would leave none now or
+	// once a scheduled publish takes effect.
+	ErrNoDefaultPolicy = errors.New(`no default policy: a published policy must have "default": true in its scope`)
+	// ErrMultipleDefaultPolicies is returned by a transition that would leave
+	// more than one policy in force marked as the default, now or once a
+	// scheduled publish takes effect.
+	ErrMultipleDefaultPolicies = errors.New(`multiple default policies: only one policy in force may have "default": true in its scope`)
+	// ErrInvalidScope is returned for a policy scope with malformed routes.
+	ErrInvalidScope = errors.New("invalid policy scope")
+)
+
+// ResolveRequest holds the request attributes policy routes match on.
+type ResolveRequest struct {
+	Source          string
+	ContextMetadata map[string]interface{}
+	// APIKeyHash is the hash of the request's API key (see
+	// middleware.HashAPIKey), empty if it has none.
+	APIKeyHash string
+}
+
+// policyRoute is a parsed scope route. match holds the accepted values of
+// each key, formatted for comparison.
+type policyRoute struct {
+	id       string
+	match    map[string][]string
+	raw      map[string]interface{}
+	priority int
+}
+
+// scopedPolicy is a policy with its parsed routes.
+type scopedPolicy struct {
+	policy    *models.Policy
+	routes    []policyRoute
+	isDefault bool
+}
+
+// parseScope reads the default flag and routes of a policy scope.
+func parseScope(scope map[string]interface{}) ([]policyRoute, bool, error) {
+	isDefault := false
+	if raw, ok := scope["default"]; ok {
+		b, ok := raw.(bool)
+		if !ok {
+			return nil, false, fmt.Errorf("%w: default must be true or false", ErrInvalidScope)
+		}
+		isDefault = b
+	}
+
+	raw, ok := scope["routes"]
+	if !ok {
+		return nil, isDefault, nil
+	}
+	list, ok := raw.([]interface{})
+	if !ok {
+		return nil, false, fmt.Errorf("%w: routes must be a list", ErrInvalidScope)
+	}
+
+	routes := make([]policyRoute, 0, len(list))
+	seen := make(map[string]bool, len(list))
+	for i, item := range list {
+		obj, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, false, fmt.Errorf("%w: route %d must be an object", ErrInvalidScope, i)
+		}
+		id, _ := obj["id"].(string)
+		if id == "" {
+			return nil, false, fmt.Errorf("%w: route %d needs an id", ErrInvalidScope, i)
+		}
+		if seen[id] {
+			return nil, false, fmt.Errorf("%w: duplicate route id %q", ErrInvalidScope, id)
+		}
+		seen[id] = true
+
+		route := policyRoute{id: id, match: make(map[string][]string)}
+		route.raw, _ = obj["match"].(map[string]interface{})
+		if len(route.raw) == 0 {
+			return nil, false, fmt.Errorf("%w: route %q needs at least one match condition", ErrInvalidScope, id)
+		}
+		for key, value := range route.raw {
+			values, ok := matchValues(value)
+			if !ok {
+				return nil, false, fmt.Errorf("%w: route %q: %s must be a value or a non-empty list of values", ErrInvalidScope, id, key)
+			}
+			route.match[key] = values
+		}
+
+		if p, ok := obj["priority"]; ok {
+			f, ok := p.(float64)
+			if !ok || f != math.Trunc(f) {
+				return nil, false, fmt.Errorf("%w: route %q: priority must be an integer", ErrInvalidScope, id)
+			}
+			route.priority = int(f)
+		}
+		routes = append(routes, route)
+	}
+	return routes, isDefault, nil
+}
+
+// matchValues formats a match condition's accepted values the way request
+// attributes are formatted.
+func matchValues(value interface{}) ([]string, bool) {
+	switch v := value.(type) {
+	case string, float64, bool:
+		return []string{fmt.Sprintf("%v", v)}, true
+	case []interface{}:
+		if len(v) == 0 {
+			return nil, false
+		}
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			switch item.(type) {
+			case string, float64, bool:
+				values = append(values, fmt.Sprintf("%v", item))
+			default:
+				return nil, false
+			}
+		}
+		return values, true
+	}
+	return nil, false
+}
+
+// matches reports whether every condition of the route holds for the
+// request attributes.
+func (r *policyRoute) matches(attrs map[string]string) bool {
+	for key, values := range r.match {
+		actual, ok := attrs[key]
+		if !ok {
+			return false
+		}
+		found := false
+		for _, v := range values {
+			if v == actual {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// requestAttributes returns the values route conditions are compared with.
+// Source and API key owner take precedence over metadata keys of the same name.
+func requestAttributes(req *ResolveRequest, owner *uuid.UUID) map[string]string {
+	attrs := make(map[string]string, len(req.ContextMetadata)+2)
+	for key, value := range req.ContextMetadata {
+		attrs[key] = fmt.Sprintf("%v", value)
+	}
+	delete(attrs, matchSource)
+	delete(attrs, matchAPIKeyOwner)
+	if req.Source != "" {
+		attrs[matchSource] = req.Source
+	}
+	if owner != nil {
+		attrs[matchAPIKeyOwner] = owner.String()
+	}
+	return attrs
+}
+
+// resolvePolicy picks the policy for a request from the policies in force:
+// the policy of the best matching route, or else the first default policy.
+// policies are ordered by when they took effect, most recent first.
+func resolvePolicy(policies []scopedPolicy, attrs map[string]string) (*models.Policy, *models.PolicyResolution, error) {
+	type candidate struct {
+		policy *models.Policy
+		route  *policyRoute
+		index  int
+	}
+	var matched []candidate
+	for _, sp := range policies {
+		for i := range sp.routes {
+			if sp.routes[i].matches(attrs) {
+				matched = append(matched, candidate{policy: sp.policy, route: &sp.routes[i], index: i})
+			}
+		}
+	}
+
+	if len(matched) > 0 {
+		sort.SliceStable(matched, func(i, j int) bool {
+			a, b := matched[i], matched[j]
+			if a.route.priority != b.route.priority {
+				return a.route.priority > b.route.priority
+			}
+			if len(a.route.match) != len(b.route.match) {
+				return len(a.route.match) > len(b.route.match)
+			}
+			if a.policy.Name != b.policy.Name {
+				return a.policy.Name < b.policy.Name
+			}
+			return a.index < b.index
+		})
+		best := matched[0]
+		return best.policy, &models.PolicyResolution{
+			PolicyID: best.policy.ID,
+			Method:   models.ResolvedByRoute,
+			RouteID:  best.route.id,
+			Match:    best.route.raw,
+			Priority: best.route.priority,
+		}, nil
+	}
+
+	for _, sp := range policies {
+		if sp.isDefault {
+			return sp.policy, &models.PolicyResolution{PolicyID: sp.policy.ID, Method: models.ResolvedByDefault}, nil
+		}
+	}
+	return nil, nil, ErrNoDefaultPolicy
+}
+
+// ResolvePolicy picks the policy that applies to a request from the latest
+// published version of each policy in force, by the routes in their scopes,
+// falling back to the explicit default policy.
+func (e *Evaluator) ResolvePolicy(ctx context.Context, req *ResolveRequest) (*models.Policy, *models.PolicyResolution, error) {
+	policies, err := e.inForcePolicies(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// The API key owner is only looked up if a route can match on it
+	var owner *uuid.UUID
+	if req.APIKeyHash != "" && routesMatchOn(policies, matchAPIKeyOwner) {
+		var id uuid.UUID
+		err := e.db.QueryRow(ctx, `SELECT id FROM users WHERE api_key_hash = $1`, req.APIKeyHash).Scan(&id)
+		switch {
+		case err == nil:
+			owner = &id
+		case !errors.Is(err, pgx.ErrNoRows):
+			return nil, nil, fmt.Errorf("failed to query API key owner: %w", err)
+		}
+	}
+
+	return resolvePolicy(policies, requestAttributes(req, owner))
+}
+
+// inForcePolicies returns the latest published version of each policy that
+// has taken effect, with its routes, most recently effective first. Policies
+// with a malformed scope are skipped.
+func (e *Evaluator) inForcePolicies(ctx context.Context) ([]scopedPolicy, error) {
+	rows, err := e.db.Query(ctx, `
+		SELECT `+policyColumns+`
+		FROM (
+			SELECT DISTINCT ON (name) *
+			FROM policies
+			WHERE status = 'published' AND (effective_date IS NULL OR effective_date <= NOW())
+			ORDER BY name, COALESCE(effective_date, created_at) DESC, created_at DESC
+		) latest
+		ORDER BY COALESCE(effective_date, created_at) DESC, created_at DESC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query policies in force: %w", err)
+	}
+	defer rows.Close()
+
+	var policies []scopedPolicy
+	for rows.Next() {
+		policy, err := scanPolicy(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan policy: %w", err)
+		}
+		routes, isDefault, err := parseScope(policy.Scope)
+		if err != nil {
+			e.logger.Warn("skipping policy with invalid scope",
+				zap.String("policy_id", policy.ID.String()),
+				zap.Error(err),
+			)
+			continue
+		}
+		policies = append(policies, scopedPolicy{policy: policy, routes: routes, isDefault: isDefault})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query policies in force: %w", err)
+	}
+
+	return policies, nil
+}
+
+// routesMatchOn reports whether any route has a condition on key.
+func routesMatchOn(policies []scopedPolicy, key string) bool {
+	for _, sp := range policies {
+		for _, route := range sp.routes {
+			if _, ok := route.match[key]; ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// requireDefaultPolicy returns ErrNoDefaultPolicy unless a policy marked as
+// the default is in force now, in the same set inForcePolicies resolves from,
+// and again each time a scheduled publish takes effect, so a scheduled version
+// cannot leave the service without a default later. It returns
+// ErrMultipleDefaultPolicies if more than one is, since resolution would then
+// silently pick whichever took effect last.
+func requireDefaultPolicy(ctx context.Context, tx pgx.Tx) error {
+	// The first instant, from now through each scheduled effective date, at
+	// which the latest effective versions of policies do not include exactly
+	// one marked as the default
+	var at time.Time
+	var immediate bool
+	var defaults int
+	err := tx.QueryRow(ctx, `
+		SELECT checkpoint.at, checkpoint.at = NOW(), marked.defaults
+		FROM (
+			SELECT NOW() AS at
+			UNION
+			SELECT effective_date FROM policies WHERE status = 'published' AND effective_date > NOW()
+		) checkpoint
+		CROSS JOIN LATERAL (
+			SELECT COUNT(*) AS defaults
+			FROM (
+				SELECT DISTINCT ON (name) scope
+				FROM policies
+				WHERE status = 'published' AND (effective_date IS NULL OR effective_date <= checkpoint.at)
+				ORDER BY name, COALESCE(effective_date, created_at) DESC, created_at DESC
+			) latest
+			WHERE scope->'default' = 'true'::jsonb
+		) marked
+		WHERE marked.defaults <> 1
+		ORDER BY checkpoint.at
+		LIMIT 1
+	`).Scan(&at, &immediate, &defaults)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to check default policy: %w", err)
+	}
+
+	violation := ErrNoDefaultPolicy
+	if defaults > 1 {
+		violation = ErrMultipleDefaultPolicies
+	}
+	if immediate {
+		return violation
+	}
+	return fmt.Errorf("%w once the publish scheduled for %s takes effect", violation, at.UTC().Format(time.RFC3339))
+}
@@ -0,0 +1,143 @@
+package engine
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/proth1/text-moderator/internal/models"
+)
+
+func TestParseScope(t *testing.T) {
+	route := func(id string, match interface{}) map[string]interface{} {
+		return map[string]interface{}{"id": id, "match": match}
+	}
+
+	tests := []struct {
+		name        string
+		scope       map[string]interface{}
+		wantRoutes  int
+		wantDefault bool
+		wantErr     bool
+	}{
+		{"empty", nil, 0, false, false},
+		{"default only", map[string]interface{}{"default": true}, 0, true, false},
+		{"routes", map[string]interface{}{"routes": []interface{}{
+			route("eu", map[string]interface{}{"region": "EU"}),
+			route("kids", map[string]interface{}{"audience": []interface{}{"kids", "teens"}, "verified": true}),
+		}}, 2, false, false},
+		{"default not a bool", map[string]interface{}{"default": "yes"}, 0, false, true},
+		{"routes not a list", map[string]interface{}{"routes": "eu"}, 0, false, true},
+		{"route without id", map[string]interface{}{"routes": []interface{}{route("", map[string]interface{}{"region": "EU"})}}, 0, false, true},
+		{"duplicate id", map[string]interface{}{"routes": []interface{}{
+			route("eu", map[string]interface{}{"region": "EU"}),
+			route("eu", map[string]interface{}{"region": "UK"}),
+		}}, 0, false, true},
+		{"no conditions", map[string]interface{}{"routes": []interface{}{route("all", map[string]interface{}{})}}, 0, false, true},
+		{"empty list", map[string]interface{}{"routes": []interface{}{route("eu", map[string]interface{}{"region": []interface{}{}})}}, 0, false, true},
+		{"nested value", map[string]interface{}{"routes": []interface{}{route("eu", map[string]interface{}{"region": map[string]interface{}{"in": "EU"}})}}, 0, false, true},
+		{"fractional priority", map[string]interface{}{"routes": []interface{}{
+			map[string]interface{}{"id": "eu", "match": map[string]interface{}{"region": "EU"}, "priority": 1.5},
+		}}, 0, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			routes, isDefault, err := parseScope(tt.scope)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidScope) {
+					t.Errorf("got error %v, want ErrInvalidScope", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("got error %v, want none", err)
+			}
+			if len(routes) != tt.wantRoutes || isDefault != tt.wantDefault {
+				t.Errorf("got %d routes, default %v, want %d and %v", len(routes), isDefault, tt.wantRoutes, tt.wantDefault)
+			}
+		})
+	}
+}
+
+func TestResolvePolicy(t *testing.T) {
+	scoped := func(name string, isDefault bool, routes ...interface{}) scopedPolicy {
+		policy := &models.Policy{ID: uuid.New(), Name: name}
+		parsed, _, err := parseScope(map[string]interface{}{"routes": routes})
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		return scopedPolicy{policy: policy, routes: parsed, isDefault: isDefault}
+	}
+	route := func(id string, priority float64, match map[string]interface{}) map[string]interface{} {
+		return map[string]interface{}{"id": id, "match": match, "priority": priority}
+	}
+
+	policies := []scopedPolicy{
+		scoped("Standard", true),
+		scoped("EU", false,
+			route("eu", 0, map[string]interface{}{"region": "EU"}),
+			route("eu-kids", 0, map[string]interface{}{"region": "EU", "audience": []interface{}{"kids", "teens"}}),
+		),
+		scoped("Kids", false, route("kids", 5, map[string]interface{}{"audience": "kids"})),
+		scoped("Forum", false, route("forum", 0, map[string]interface{}{"source": "forum"})),
+		scoped("Archive", true),
+	}
+
+	tests := []struct {
+		name       string
+		attrs      map[string]string
+		wantPolicy string
+		wantRoute  string
+		wantMethod models.PolicyResolutionMethod
+	}{
+		{"no match uses the first default", map[string]string{"region": "US"}, "Standard", "", models.ResolvedByDefault},
+		{"single match", map[string]string{"region": "EU"}, "EU", "eu", models.ResolvedByRoute},
+		{"more conditions win", map[string]string{"region": "EU", "audience": "teens"}, "EU", "eu-kids", models.ResolvedByRoute},
+		{"priority wins", map[string]string{"region": "EU", "audience": "kids"}, "Kids", "kids", models.ResolvedByRoute},
+		{"source", map[string]string{"source": "forum"}, "Forum", "forum", models.ResolvedByRoute},
+		{"name breaks ties", map[string]string{"source": "forum", "region": "EU"}, "EU", "eu", models.ResolvedByRoute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy, resolution, err := resolvePolicy(policies, tt.attrs)
+			if err != nil {
+				t.Fatalf("got error %v, want none", err)
+			}
+			if policy.Name != tt.wantPolicy || resolution.PolicyID != policy.ID {
+				t.Errorf("got policy %s (resolved %s), want %s", policy.Name, resolution.PolicyID, tt.wantPolicy)
+			}
+			if resolution.Method != tt.wantMethod || resolution.RouteID != tt.wantRoute {
+				t.Errorf("got %s route %q, want %s route %q", resolution.Method, resolution.RouteID, tt.wantMethod, tt.wantRoute)
+			}
+		})
+	}
+
+	if _, _, err := resolvePolicy(policies[1:4], map[string]string{"region": "US"}); !errors.Is(err, ErrNoDefaultPolicy) {
+		t.Errorf("got error %v without a default, want ErrNoDefaultPolicy", err)
+	}
+}
+
+func TestRequestAttributes(t *testing.T) {
+	owner := uuid.New()
+	attrs := requestAttributes(&ResolveRequest{
+		Source:          "forum",
+		ContextMetadata: map[string]interface{}{"region": "EU", "age": float64(12), "source": "spoofed", "api_key_owner": "spoofed"},
+	}, &owner)
+
+	want := map[string]string{"region": "EU", "age": "12", "source": "forum", "api_key_owner": owner.String()}
+	if len(attrs) != len(want) {
+		t.Errorf("got %v, want %v", attrs, want)
+	}
+	for key, value := range want {
+		if attrs[key] != value {
+			t.Errorf("%s: got %q, want %q", key, attrs[key], value)
+		}
+	}
+
+	attrs = requestAttributes(&ResolveRequest{ContextMetadata: map[string]interface{}{"source": "spoofed"}}, nil)
+	if _, ok := attrs["source"]; ok {
+		t.Errorf("got source %q from metadata, want none", attrs["source"])
+	}
+}
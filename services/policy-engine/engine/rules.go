@@ -0,0 +1,126 @@
+package engine
+
+import (
+	"fmt"
+
+	"github.com/proth1/text-moderator/internal/expr"
+	"github.com/proth1/text-moderator/internal/models"
+)
+
+// ruleEnv declares the variables policy rule expressions may reference:
+// every category score by name (hate, toxicity, ...), detector signals,
+// request context metadata, the user's trust score and the detected
+// languages.
+var ruleEnv = func() expr.Env {
+	env := expr.Env{
+		"signals":     expr.Map,    // e.g. signals.prompt_injection
+		"context":     expr.Map,    // request context metadata, e.g. context.audience
+		"trust_score": expr.Number, // 0.0-1.0, null when unknown
+		"language":    expr.String, // primary detected language, ISO 639-1
+		"languages":   expr.List,   // every detected language, ranked
+		"translated":  expr.Bool,   // scores are for a machine translation
+	}
+	for _, category := range models.Categories {
+		env[category] = expr.Number
+	}
+	return env
+}()
+
+// compiledRule is a policy rule with its compiled expression.
+type compiledRule struct {
+	models.PolicyRule
+	program *expr.Program
+}
+
+// compileRules validates and compiles a policy's rules: IDs must be unique and
+// non-empty, actions valid, and expressions must type-check against ruleEnv.
+func compileRules(rules []models.PolicyRule) ([]compiledRule, error) {
+	compiled := make([]compiledRule, 0, len(rules))
+	seen := make(map[string]bool, len(rules))
+	for i, rule := range rules {
+		if rule.ID == "" {
+			return nil, fmt.Errorf("rule %d: id must not be empty", i)
+		}
+		if seen[rule.ID] {
+			return nil, fmt.Errorf("duplicate rule id %q", rule.ID)
+		}
+		seen[rule.ID] = true
+		if !validAction(rule.Action) {
+			return nil, fmt.Errorf("rule %q: invalid action %q", rule.ID, rule.Action)
+		}
+		program, err := expr.Compile(rule.Expression, ruleEnv)
+		if err != nil {
+			return nil, fmt.Errorf("rule %q: %w", rule.ID, err)
+		}
+		compiled = append(compiled, compiledRule{PolicyRule: rule, program: program})
+	}
+	return compiled, nil
+}
+
+// ruleVariables builds the variables rule expressions are evaluated with.
+// Rules see the scores as classified, before any threshold adjustments.
+func ruleVariables(categories map[string]float64, opts *EvaluationOptions) map[string]interface{} {
+	vars := make(map[string]interface{}, len(categories)+len(ruleEnv))
+	for category, score := range categories {
+		vars[category] = score
+	}
+	if opts == nil {
+		return vars
+	}
+	vars["signals"] = opts.Signals
+	vars["context"] = opts.ContextMetadata
+	vars["trust_score"] = opts.TrustScore
+	vars["languages"] = opts.Languages
+	vars["translated"] = opts.Translated
+	if opts.Language != "" {
+		vars["language"] = opts.Language
+	}
+	return vars
+}
+
+// ruleOutcome is the result of evaluating a policy's rules.
+type ruleOutcome struct {
+	triggered []string // IDs of matching rules, in order
+	action    models.PolicyAction
+	final     bool // a final rule matched and decided the action
+	errors    map[string]error
+}
+
+// evaluateRules evaluates rules in order. Matching rules contribute their
+// action by severity, and a matching final rule decides the action and stops
+// evaluation. A rule that fails at evaluation, e.g. comparing a context value
+// of the wrong type, does not match.
+func evaluateRules(rules []compiledRule, vars map[string]interface{}) ruleOutcome {
+	out := ruleOutcome{action: models.ActionAllow}
+	for _, rule := range rules {
+		matched, err := rule.program.Eval(vars)
+		if err != nil {
+			if out.errors == nil {
+				out.errors = make(map[string]error)
+			}
+			out.errors[rule.ID] = err
+			continue
+		}
+		if !matched {
+			continue
+		}
+		out.triggered = append(out.triggered, rule.ID)
+		if rule.Final {
+			out.action = rule.Action
+			out.final = true
+			return out
+		}
+		if actionPriority(rule.Action) > actionPriority(out.action) {
+			out.action = rule.Action
+		}
+	}
+	return out
+}
+
+func validAction(action models.PolicyAction) bool {
+	switch action {
+	case models.ActionAllow, models.ActionWarn, models.ActionEscalate, models.ActionBlock:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,169 @@
+package engine
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/proth1/text-moderator/internal/models"
+	"go.uber.org/zap"
+)
+
+func TestCompileRules_Validation(t *testing.T) {
+	tests := []struct {
+		name      string
+		rules     []models.PolicyRule
+		wantError string
+	}{
+		{"valid", []models.PolicyRule{
+			{ID: "kids-hate", Expression: `hate > 0.6 && context.audience == "kids"`, Action: models.ActionBlock},
+			{ID: "spammy-abuse", Expression: `toxicity > 0.4 && spam > 0.4`, Action: models.ActionEscalate},
+			{ID: "low-trust", Expression: `trust_score < 0.2 && language in ["en", "es"] && signals.prompt_injection > 0.3`, Action: models.ActionWarn},
+		}, ""},
+		{"empty id", []models.PolicyRule{{Expression: "hate > 0.5", Action: models.ActionBlock}}, "id must not be empty"},
+		{"duplicate id", []models.PolicyRule{
+			{ID: "a", Expression: "hate > 0.5", Action: models.ActionBlock},
+			{ID: "a", Expression: "spam > 0.5", Action: models.ActionWarn},
+		}, `duplicate rule id "a"`},
+		{"invalid action", []models.PolicyRule{{ID: "a", Expression: "hate > 0.5", Action: "ban"}}, "invalid action"},
+		{"unknown category", []models.PolicyRule{{ID: "a", Expression: "hatred > 0.5", Action: models.ActionBlock}}, `rule "a": position 1: undeclared reference to "hatred"`},
+		{"not boolean", []models.PolicyRule{{ID: "a", Expression: "hate + spam", Action: models.ActionBlock}}, "must be a bool"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := compileRules(tt.rules)
+			if tt.wantError == "" {
+				if err != nil {
+					t.Errorf("got error %v, want none", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantError) {
+				t.Errorf("got error %v, want %q", err, tt.wantError)
+			}
+		})
+	}
+}
+
+func TestEvaluateRules(t *testing.T) {
+	rules, err := compileRules([]models.PolicyRule{
+		{ID: "trusted-channel", Expression: `context.channel == "moderators"`, Action: models.ActionAllow, Final: true},
+		{ID: "kids-hate", Expression: `hate > 0.6 && context.audience == "kids"`, Action: models.ActionBlock},
+		{ID: "spammy-abuse", Expression: `toxicity > 0.4 && spam > 0.4`, Action: models.ActionEscalate},
+		{ID: "low-trust", Expression: `trust_score < 0.3`, Action: models.ActionWarn},
+		{ID: "bad-type", Expression: `context.audience > 3`, Action: models.ActionBlock},
+	})
+	if err != nil {
+		t.Fatalf("compile failed: %v", err)
+	}
+
+	low := 0.1
+	tests := []struct {
+		name          string
+		scores        models.CategoryScores
+		opts          *EvaluationOptions
+		wantAction    models.PolicyAction
+		wantTriggered []string
+		wantFinal     bool
+	}{
+		{
+			name:       "nothing matches",
+			scores:     models.CategoryScores{Hate: 0.9},
+			opts:       &EvaluationOptions{ContextMetadata: map[string]interface{}{"audience": "adults"}},
+			wantAction: models.ActionAllow,
+		},
+		{
+			name:          "most severe matching action",
+			scores:        models.CategoryScores{Hate: 0.7, Toxicity: 0.5, Spam: 0.5},
+			opts:          &EvaluationOptions{ContextMetadata: map[string]interface{}{"audience": "kids"}},
+			wantAction:    models.ActionBlock,
+			wantTriggered: []string{"kids-hate", "spammy-abuse"},
+		},
+		{
+			name:          "trust score",
+			scores:        models.CategoryScores{},
+			opts:          &EvaluationOptions{TrustScore: &low},
+			wantAction:    models.ActionWarn,
+			wantTriggered: []string{"low-trust"},
+		},
+		{
+			name:          "final rule stops evaluation",
+			scores:        models.CategoryScores{Hate: 0.9},
+			opts:          &EvaluationOptions{ContextMetadata: map[string]interface{}{"audience": "kids", "channel": "moderators"}},
+			wantAction:    models.ActionAllow,
+			wantTriggered: []string{"trusted-channel"},
+			wantFinal:     true,
+		},
+		{
+			name:       "no options",
+			scores:     models.CategoryScores{Hate: 0.9},
+			wantAction: models.ActionAllow,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := evaluateRules(rules, ruleVariables(tt.scores.ToMap(), tt.opts))
+			if out.action != tt.wantAction {
+				t.Errorf("got action %s, want %s", out.action, tt.wantAction)
+			}
+			if strings.Join(out.triggered, ",") != strings.Join(tt.wantTriggered, ",") {
+				t.Errorf("got triggered %v, want %v", out.triggered, tt.wantTriggered)
+			}
+			if out.final != tt.wantFinal {
+				t.Errorf("got final %v, want %v", out.final, tt.wantFinal)
+			}
+		})
+	}
+}
+
+func TestEvaluateRules_ReportsErrors(t *testing.T) {
+	rules, err := compileRules([]models.PolicyRule{
+		{ID: "bad-type", Expression: `context.audience > 3`, Action: models.ActionBlock},
+	})
+	if err != nil {
+		t.Fatalf("compile failed: %v", err)
+	}
+	vars := ruleVariables((&models.CategoryScores{}).ToMap(), &EvaluationOptions{
+		ContextMetadata: map[string]interface{}{"audience": "kids"},
+	})
+	out := evaluateRules(rules, vars)
+	if out.errors["bad-type"] == nil || len(out.triggered) != 0 {
+		t.Errorf("got errors %v triggered %v, want the rule reported and not matched", out.errors, out.triggered)
+	}
+}
+
+func TestEvaluatePolicy_FinalRuleCannotCancelSignalEscalation(t *testing.T) {
+	evaluator := NewEvaluator(nil, zap.NewNop())
+	policy := &models.Policy{
+		ID:         uuid.New(),
+		Thresholds: map[string]float64{"toxicity": 0.8},
+		Actions:    map[string]models.PolicyAction{"toxicity": models.ActionBlock},
+		Rules: []models.PolicyRule{
+			{ID: "trusted-channel", Expression: `context.channel == "moderators"`, Action: models.ActionAllow, Final: true},
+		},
+	}
+	opts := &EvaluationOptions{
+		ContextMetadata: map[string]interface{}{"channel": "moderators"},
+		Signals:         map[string]float64{"prompt_injection": 0.9},
+	}
+
+	result, err := evaluator.evaluatePolicy(policy, &models.CategoryScores{Toxicity: 0.9}, opts)
+	if err != nil {
+		t.Fatalf("evaluate failed: %v", err)
+	}
+	if result.Action != models.ActionEscalate {
+		t.Errorf("got action %s, want the prompt injection escalation to survive the final allow rule", result.Action)
+	}
+
+	// Without a signal the final rule still overrides the thresholds
+	opts.Signals = nil
+	result, err = evaluator.evaluatePolicy(policy, &models.CategoryScores{Toxicity: 0.9}, opts)
+	if err != nil {
+		t.Fatalf("evaluate failed: %v", err)
+	}
+	if result.Action != models.ActionAllow {
+		t.Errorf("got action %s, want the final rule to allow", result.Action)
+	}
+}
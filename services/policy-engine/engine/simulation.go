@@ -0,0 +1,462 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/proth1/text-moderator/internal/feedback"
+	"github.com/proth1/text-moderator/internal/models"
+	"go.uber.org/zap"
+)
+
+// Control: POL-001 (Policy definition and versioning)
+
+const (
+	// defaultSimulationLimit and maxSimulationLimit bound the number of
+	// decisions a simulation replays
+	defaultSimulationLimit = 10000
+	maxSimulationLimit     = 100000
+
+	// simulationTimeout bounds a simulation run
+	simulationTimeout = 30 * time.Minute
+
+	// simulationHeartbeat is how often a running simulation records that it
+	// is alive. A running simulation without a heartbeat for
+	// simulationStaleAfter was orphaned by a restart.
+	simulationHeartbeat  = 30 * time.Second
+	simulationStaleAfter = 3 * simulationHeartbeat
+)
+
+// ErrInvalidSimulation is returned for a simulation request with an invalid
+// time range or limit.
+var ErrInvalidSimulation = errors.New("invalid simulation request")
+
+// errSimulationFinished is returned when completing a simulation that is no
+// longer running, because it was failed as orphaned or past its deadline.
+var errSimulationFinished = errors.New("policy simulation is no longer running")
+
+// Simulator backtests policies against historical moderation decisions,
+// replaying their stored category scores and context through the policy.
+type Simulator struct {
+	evaluator *Evaluator
+	logger    *zap.Logger
+}
+
+// NewSimulator creates a new policy simulator
+func NewSimulator(evaluator *Evaluator, logger *zap.Logger) *Simulator {
+	return &Simulator{
+		evaluator: evaluator,
+		logger:    logger,
+	}
+}
+
+// validateSimulationRequest checks the time range and limit, defaulting the limit.
+func validateSimulationRequest(req *models.SimulatePolicyRequest) error {
+	if !req.To.After(req.From) {
+		return fmt.Errorf("%w: to must be after from", ErrInvalidSimulation)
+	}
+	if req.Limit < 0 || req.Limit > maxSimulationLimit {
+		return fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidSimulation, maxSimulationLimit)
+	}
+	if req.Limit == 0 {
+		req.Limit = defaultSimulationLimit
+	}
+	return nil
+}
+
+// Start records a simulation of the policy and runs it in the background.
+// Any policy can be simulated, though it is usually a draft.
+func (s *Simulator) Start(ctx context.Context, policyID uuid.UUID, req *models.SimulatePolicyRequest, createdBy uuid.UUID) (*models.PolicySimulation, error) {
+	if err := validateSimulationRequest(req); err != nil {
+		return nil, err
+	}
+
+	policy, err := s.evaluator.getPolicy(ctx, policyID)
+	if err != nil {
+		return nil, err
+	}
+
+	sim := &models.PolicySimulation{
+		ID:            uuid.New(),
+		PolicyID:      policy.ID,
+		PolicyVersion: policy.Version,
+		From:          req.From.UTC(),
+		To:            req.To.UTC(),
+		Limit:         req.Limit,
+		Status:        models.SimulationRunning,
+		CreatedBy:     &createdBy,
+	}
+	deadline := time.Now().Add(simulationTimeout)
+
+	err = s.evaluator.db.QueryRow(ctx, `
+		INSERT INTO policy_simulations (id, policy_id, policy_version, range_start, range_end, decision_limit, status, created_by, deadline_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING created_at
+	`, sim.ID, sim.PolicyID, sim.PolicyVersion, sim.From, sim.To, sim.Limit, sim.Status, sim.CreatedBy, deadline).Scan(&sim.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create policy simulation: %w", err)
+	}
+
+	s.logger.Info("policy simulation started",
+		zap.String("simulation_id", sim.ID.String()),
+		zap.String("policy_id", policy.ID.String()),
+		zap.Time("from", sim.From),
+		zap.Time("to", sim.To),
+	)
+
+	go s.run(sim, policy, deadline)
+
+	return sim, nil
+}
+
+// run replays the simulation's decisions and records the result, recording a
+// heartbeat until it finishes. The run is cancelled at its deadline. A panic
+// while replaying fails the simulation instead of the process.
+func (s *Simulator) run(sim *models.PolicySimulation, policy *models.Policy, deadline time.Time) {
+	ctx, cancel := context.WithDeadline(context.Background(), deadline)
+	defer cancel()
+
+	defer func() {
+		if r := recover(); r != nil {
+			s.fail(sim, fmt.Errorf("panic: %v", r))
+		}
+	}()
+
+	go s.heartbeat(ctx, sim.ID)
+
+	tally, flips, err := s.replay(ctx, sim, policy)
+	if err == nil {
+		err = s.complete(ctx, sim, tally.finish(), flips)
+	}
+	if err != nil {
+		s.fail(sim, err)
+		return
+	}
+
+	s.logger.Info("policy simulation completed",
+		zap.String("simulation_id", sim.ID.String()),
+		zap.Int("decisions", tally.summary.Decisions),
+		zap.Int("flipped", tally.summary.Flipped),
+	)
+}
+
+// fail records that a running simulation failed.
+func (s *Simulator) fail(sim *models.PolicySimulation, err error) {
+	s.logger.Error("policy simulation failed", zap.String("simulation_id", sim.ID.String()), zap.Error(err))
+	// The run's context may have timed out, so the failure is recorded with its own
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	// SECURITY: Don't expose internal error details through the simulation
+	if _, updateErr := s.evaluator.db.Exec(ctx,
+		`UPDATE policy_simulations SET status = $2, error = $3, completed_at = NOW() WHERE id = $1 AND status = $4`,
+		sim.ID, models.SimulationFailed, "failed to replay decisions", models.SimulationRunning,
+	); updateErr != nil {
+		s.logger.Error("failed to record policy simulation failure", zap.Error(updateErr))
+	}
+}
+
+// heartbeat records that the simulation is still running until ctx is done.
+func (s *Simulator) heartbeat(ctx context.Context, id uuid.UUID) {
+	ticker := time.NewTicker(simulationHeartbeat)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.evaluator.db.Exec(ctx,
+				`UPDATE policy_simulations SET heartbeat_at = NOW() WHERE id = $1 AND status = $2`,
+				id, models.SimulationRunning,
+			); err != nil && ctx.Err() == nil {
+				s.logger.Warn("failed to record policy simulation heartbeat",
+					zap.String("simulation_id", id.String()), zap.Error(err))
+			}
+		}
+	}
+}
+
+// FailAbandoned marks running simulations as failed once they pass their
+// deadline or stop recording a heartbeat, as when the process running them
+// restarted. It returns the number of simulations failed.
+func (s *Simulator) FailAbandoned(ctx context.Context) (int64, error) {
+	tag, err := s.evaluator.db.Exec(ctx, `
+		UPDATE policy_simulations
+		SET status = $1, error = $2, completed_at = NOW()
+		WHERE status = $3
+		  AND (deadline_at <= NOW() OR heartbeat_at < NOW() - make_interval(secs => $4))
+	`, models.SimulationFailed, "simulation was interrupted before completing", models.SimulationRunning, simulationStaleAfter.Seconds())
+	if err != nil {
+		return 0, fmt.Errorf("failed to fail abandoned policy simulations: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// SweepAbandoned fails abandoned simulations now and then periodically until
+// ctx is done.
+func (s *Simulator) SweepAbandoned(ctx context.Context) {
+	ticker := time.NewTicker(simulationStaleAfter)
+	defer ticker.Stop()
+	for {
+		failed, err := s.FailAbandoned(ctx)
+		if err != nil && ctx.Err() == nil {
+			s.logger.Warn("failed to sweep abandoned policy simulations", zap.Error(err))
+		} else if failed > 0 {
+			s.logger.Warn("failed abandoned policy simulations", zap.Int64("count", failed))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// replay evaluates the policy against each decision in the simulation's range,
+// with the context metadata of its submission and its latest human review.
+// Trust scores are not stored with decisions, so they are not replayed.
+func (s *Simulator) replay(ctx context.Context, sim *models.PolicySimulation, policy *models.Policy) (*simulationTally, []models.SimulationFlip, error) {
+	rows, err := s.evaluator.db.Query(ctx, `
+		SELECT d.id, d.created_at, d.category_scores, d.automated_action, d.signals,
+		       d.translated, d.source_language, d.detected_language, s.context_metadata,
+		       (SELECT r.action FROM review_actions r WHERE r.decision_id = d.id ORDER BY r.created_at DESC LIMIT 1)
+		FROM moderation_decisions d
+		JOIN text_submissions s ON s.id = d.submission_id
+		WHERE d.created_at >= $1 AND d.created_at < $2
+		ORDER BY d.created_at
+		LIMIT $3
+	`, sim.From, sim.To, sim.Limit)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to query decisions: %w", err)
+	}
+	defer rows.Close()
+
+	tally := newSimulationTally()
+	var flips []models.SimulationFlip
+	for rows.Next() {
+		var (
+			flip           models.SimulationFlip
+			scores         models.CategoryScores
+			signals        map[string]float64
+			translated     bool
+			sourceLanguage *string
+			language       *string
+			metadata       map[string]interface{}
+		)
+		if err := rows.Scan(&flip.DecisionID, &flip.DecidedAt, &scores, &flip.OriginalAction, &signals,
+			&translated, &sourceLanguage, &language, &metadata, &flip.ReviewAction); err != nil {
+			return nil, nil, fmt.Errorf("failed to scan decision: %w", err)
+		}
+
+		opts := &EvaluationOptions{
+			ContextMetadata: metadata,
+			Signals:         signals,
+			Translated:      translated,
+		}
+		if sourceLanguage != nil {
+			opts.SourceLanguage = *sourceLanguage
+		}
+		if language != nil && *language != "" {
+			opts.Language = *language
+			opts.Languages = []string{*language}
+		}
+
+		result, err := s.evaluator.evaluatePolicy(policy, &scores, opts)
+		if err != nil {
+			tally.summary.Errors++
+			continue
+		}
+
+		flip.SimulatedAction = result.Action
+		flip.TriggeredRules = result.TriggeredRules
+		if tally.add(flip.OriginalAction, flip.SimulatedAction, flip.ReviewAction) {
+			flips = append(flips, flip)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("failed to query decisions: %w", err)
+	}
+
+	return tally, flips, nil
+}
+
+// complete stores the flipped decisions and summary of a finished simulation.
+func (s *Simulator) complete(ctx context.Context, sim *models.PolicySimulation, summary *models.SimulationSummary, flips []models.SimulationFlip) error {
+	tx, err := s.evaluator.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.CopyFrom(ctx,
+		pgx.Identifier{"policy_simulation_flips"},
+		[]string{"simulation_id", "decision_id", "decided_at", "original_action", "simulated_action", "triggered_rules", "review_action"},
+		pgx.CopyFromSlice(len(flips), func(i int) ([]interface{}, error) {
+			f := flips[i]
+			var review *string
+			if f.ReviewAction != nil {
+				action := string(*f.ReviewAction)
+				review = &action
+			}
+			return []interface{}{sim.ID, f.DecisionID, f.DecidedAt, string(f.OriginalAction), string(f.SimulatedAction), f.TriggeredRules, review}, nil
+		}),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to store flipped decisions: %w", err)
+	}
+
+	tag, err := tx.Exec(ctx,
+		`UPDATE policy_simulations SET status = $2, summary = $3, completed_at = NOW() WHERE id = $1 AND status = $4`,
+		sim.ID, models.SimulationCompleted, summary, models.SimulationRunning,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to complete policy simulation: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return errSimulationFinished
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit policy simulation: %w", err)
+	}
+	return nil
+}
+
+// GetSimulation returns a simulation with its summary once completed.
+func (s *Simulator) GetSimulation(ctx context.Context, id uuid.UUID) (*models.PolicySimulation, error) {
+	var sim models.PolicySimulation
+	err := s.evaluator.db.QueryRow(ctx, `
+		SELECT id, policy_id, policy_version, range_start, range_end, decision_limit, status, summary, error,
+		       created_by, created_at, completed_at
+		FROM policy_simulations
+		WHERE id = $1
+	`, id).Scan(
+		&sim.ID, &sim.PolicyID, &sim.PolicyVersion, &sim.From, &sim.To, &sim.Limit, &sim.Status,
+		&sim.Summary, &sim.Error, &sim.CreatedBy, &sim.CreatedAt, &sim.CompletedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query policy simulation: %w", err)
+	}
+	return &sim, nil
+}
+
+// ListFlips returns a page of the simulation's flipped decisions, oldest first.
+func (s *Simulator) ListFlips(ctx context.Context, id uuid.UUID, limit, offset int) (*models.SimulationFlipPage, error) {
+	page := &models.SimulationFlipPage{Flips: []models.SimulationFlip{}, Limit: limit, Offset: offset}
+
+	err := s.evaluator.db.QueryRow(ctx,
+		`SELECT COUNT(*) FROM policy_simulation_flips WHERE simulation_id = $1`, id,
+	).Scan(&page.Total)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count flipped decisions: %w", err)
+	}
+
+	err = s.EachFlip(ctx, id, limit, offset, func(flip *models.SimulationFlip) error {
+		page.Flips = append(page.Flips, *flip)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+// EachFlip calls fn with the simulation's flipped decisions, oldest first,
+// skipping offset and stopping after limit if limit is positive.
+func (s *Simulator) EachFlip(ctx context.Context, id uuid.UUID, limit, offset int, fn func(*models.SimulationFlip) error) error {
+	var limitArg *int
+	if limit > 0 {
+		limitArg = &limit
+	}
+
+	rows, err := s.evaluator.db.Query(ctx, `
+		SELECT decision_id, decided_at, original_action, simulated_action, triggered_rules, review_action
+		FROM policy_simulation_flips
+		WHERE simulation_id = $1
+		ORDER BY decided_at, decision_id
+		LIMIT $2 OFFSET $3
+	`, id, limitArg, offset)
+	if err != nil {
+		return fmt.Errorf("failed to query flipped decisions: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var flip models.SimulationFlip
+		if err := rows.Scan(&flip.DecisionID, &flip.DecidedAt, &flip.OriginalAction, &flip.SimulatedAction,
+			&flip.TriggeredRules, &flip.ReviewAction); err != nil {
+			return fmt.Errorf("failed to scan flipped decision: %w", err)
+		}
+		if err := fn(&flip); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to query flipped decisions: %w", err)
+	}
+	return nil
+}
+
+// simulationTally accumulates a simulation's summary.
+type simulationTally struct {
+	summary models.SimulationSummary
+}
+
+func newSimulationTally() *simulationTally {
+	return &simulationTally{summary: models.SimulationSummary{
+		OriginalActions:  make(map[models.PolicyAction]int),
+		SimulatedActions: make(map[models.PolicyAction]int),
+		ActionShift:      make(map[models.PolicyAction]int),
+		Flips:            make(map[string]int),
+	}}
+}
+
+// add counts a replayed decision and reports whether its action flipped.
+// A conclusive review counts towards agreement: the reviewer's verdict on
+// whether the content is harmful is compared with each action.
+func (t *simulationTally) add(original, simulated models.PolicyAction, review *models.ReviewActionType) bool {
+	s := &t.summary
+	s.Decisions++
+	s.OriginalActions[original]++
+	s.SimulatedActions[simulated]++
+
+	if review != nil {
+		if outcome := feedback.ReviewOutcome(*review); outcome != "uncertain" {
+			harmful := feedback.IsHarmful(original, outcome)
+			s.Review.Reviewed++
+			if (original != models.ActionAllow) == harmful {
+				s.Review.OriginalAgreed++
+			}
+			if (simulated != models.ActionAllow) == harmful {
+				s.Review.SimulatedAgreed++
+			}
+		}
+	}
+
+	if original == simulated {
+		return false
+	}
+	s.Flipped++
+	s.Flips[string(original)+"->"+string(simulated)]++
+	return true
+}
+
+// finish computes the action shift and agreement rates.
+func (t *simulationTally) finish() *models.SimulationSummary {
+	s := &t.summary
+	for action, n := range s.SimulatedActions {
+		s.ActionShift[action] += n
+	}
+	for action, n := range s.OriginalActions {
+		s.ActionShift[action] -= n
+	}
+	if s.Review.Reviewed > 0 {
+		s.Review.OriginalAgreement = float64(s.Review.OriginalAgreed) / float64(s.Review.Reviewed)
+		s.Review.SimulatedAgreement = float64(s.Review.SimulatedAgreed) / float64(s.Review.Reviewed)
+	}
+	return s
+}
@@ -0,0 +1,105 @@
+package engine
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/proth1/text-moderator/internal/models"
+)
+
+func TestSimulationTally(t *testing.T) {
+	approve := models.ReviewActionApprove
+	reject := models.ReviewActionReject
+	escalate := models.ReviewActionEscalate
+
+	decisions := []struct {
+		original  models.PolicyAction
+		simulated models.PolicyAction
+		review    *models.ReviewActionType
+		flipped   bool
+	}{
+		{models.ActionAllow, models.ActionAllow, nil, false},
+		{models.ActionAllow, models.ActionBlock, &reject, true},      // reviewer found it harmful
+		{models.ActionWarn, models.ActionBlock, &approve, true},      // reviewer agreed it was harmful
+		{models.ActionBlock, models.ActionAllow, &reject, true},      // reviewer found it harmless
+		{models.ActionBlock, models.ActionBlock, &approve, false},    // both agree
+		{models.ActionEscalate, models.ActionBlock, &escalate, true}, // inconclusive review
+	}
+
+	tally := newSimulationTally()
+	for i, d := range decisions {
+		if got := tally.add(d.original, d.simulated, d.review); got != d.flipped {
+			t.Errorf("decision %d: got flipped %v, want %v", i, got, d.flipped)
+		}
+	}
+	summary := tally.finish()
+
+	if summary.Decisions != 6 || summary.Flipped != 4 {
+		t.Errorf("got %d decisions, %d flipped, want 6 and 4", summary.Decisions, summary.Flipped)
+	}
+
+	wantShift := map[models.PolicyAction]int{
+		models.ActionAllow:    0,
+		models.ActionWarn:     -1,
+		models.ActionBlock:    2,
+		models.ActionEscalate: -1,
+	}
+	for action, want := range wantShift {
+		if got := summary.ActionShift[action]; got != want {
+			t.Errorf("shift %s: got %d, want %d", action, got, want)
+		}
+	}
+
+	wantFlips := map[string]int{"allow->block": 1, "warn->block": 1, "block->allow": 1, "escalate->block": 1}
+	for flip, want := range wantFlips {
+		if got := summary.Flips[flip]; got != want {
+			t.Errorf("flips %s: got %d, want %d", flip, got, want)
+		}
+	}
+
+	review := summary.Review
+	if review.Reviewed != 4 || review.OriginalAgreed != 2 || review.SimulatedAgreed != 4 {
+		t.Errorf("got review %+v, want 4 reviewed, 2 originally agreed, 4 agreed when simulated", review)
+	}
+	if review.OriginalAgreement != 0.5 || review.SimulatedAgreement != 1 {
+		t.Errorf("got agreement %v and %v, want 0.5 and 1", review.OriginalAgreement, review.SimulatedAgreement)
+	}
+}
+
+func TestValidateSimulationRequest(t *testing.T) {
+	from := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+
+	tests := []struct {
+		name      string
+		req       models.SimulatePolicyRequest
+		wantErr   bool
+		wantLimit int
+	}{
+		{"default limit", models.SimulatePolicyRequest{From: from, To: to}, false, defaultSimulationLimit},
+		{"explicit limit", models.SimulatePolicyRequest{From: from, To: to, Limit: 500}, false, 500},
+		{"empty range", models.SimulatePolicyRequest{From: from, To: from}, true, 0},
+		{"reversed range", models.SimulatePolicyRequest{From: to, To: from}, true, 0},
+		{"negative limit", models.SimulatePolicyRequest{From: from, To: to, Limit: -1}, true, 0},
+		{"limit too large", models.SimulatePolicyRequest{From: from, To: to, Limit: maxSimulationLimit + 1}, true, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateSimulationRequest(&tt.req)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidSimulation) {
+					t.Errorf("got error %v, want ErrInvalidSimulation", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("got error %v, want none", err)
+			}
+			if tt.req.Limit != tt.wantLimit {
+				t.Errorf("got limit %d, want %d", tt.req.Limit, tt.wantLimit)
+			}
+		})
+	}
+}
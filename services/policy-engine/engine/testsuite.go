@@ -0,0 +1,486 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/proth1/text-moderator/internal/models"
+	"go.uber.org/zap"
+)
+
+// Control: POL-001 (Policy definition and versioning)
+
+const (
+	// maxTestCases bounds the size of a policy's test suite
+	maxTestCases = 200
+
+	// testCaseConcurrency bounds the test cases run at once
+	testCaseConcurrency = 8
+
+	// testSuiteTimeout bounds a suite run, which a publish waits for
+	testSuiteTimeout = 20 * time.Second
+
+	// maxTestRuns bounds the runs listed for a policy
+	maxTestRuns = 50
+)
+
+var (
+	// ErrInvalidTestCase is returned for a test case without exactly one of
+	// content and scores, or with an unknown expected action.
+	ErrInvalidTestCase = errors.New("invalid policy test case")
+	// ErrDuplicateTestCase is returned when the policy already has a test case
+	// with the same name.
+	ErrDuplicateTestCase = errors.New("policy test case already exists")
+	// ErrTestSuiteFailed is wrapped by TestSuiteError.
+	ErrTestSuiteFailed = errors.New("policy test suite failed")
+	// ErrPolicyChanged is returned when a policy's definition no longer
+	// matches the one its test suite was classified for.
+	ErrPolicyChanged = errors.New("policy changed while its test suite ran")
+)
+
+// TestSuiteError is returned when a publish is blocked because the policy
+// failed its test suite. Run is the failed run.
+type TestSuiteError struct {
+	Run *models.PolicyTestRun
+}
+
+func (e *TestSuiteError) Error() string {
+	return fmt.Sprintf("%s: %d of %d test cases failed", ErrTestSuiteFailed, e.Run.Failed, e.Run.Total)
+}
+
+func (e *TestSuiteError) Unwrap() error {
+	return ErrTestSuiteFailed
+}
+
+// TextClassifier classifies the content of test cases for a policy exactly
+// as a moderation request applying the policy would be classified, including
+// normalization with its default profile.
+type TextClassifier interface {
+	Classify(ctx context.Context, policyID uuid.UUID, content string) (*models.ClassifyResponse, error)
+}
+
+// TestSuites stores the golden test cases attached to policies and runs them.
+// Content is classified by the moderation pipeline, so a suite sees the
+// providers, calibration, routing and LLM second pass production uses.
+type TestSuites struct {
+	evaluator  *Evaluator
+	classifier TextClassifier
+	logger     *zap.Logger
+}
+
+// NewTestSuites creates a new policy test suite runner
+func NewTestSuites(evaluator *Evaluator, textClassifier TextClassifier, logger *zap.Logger) *TestSuites {
+	return &TestSuites{
+		evaluator:  evaluator,
+		classifier: textClassifier,
+		logger:     logger,
+	}
+}
+
+// validateTestCase checks that a test case has exactly one input and a known
+// expected action.
+func validateTestCase(req *models.CreatePolicyTestCaseRequest) error {
+	if (req.Content == nil) == (req.Scores == nil) {
+		return fmt.Errorf("%w: exactly one of content and scores is required", ErrInvalidTestCase)
+	}
+	if req.Content != nil && strings.TrimSpace(*req.Content) == "" {
+		return fmt.Errorf("%w: content must not be empty", ErrInvalidTestCase)
+	}
+	if !validAction(req.ExpectedAction) {
+		return fmt.Errorf("%w: unknown expected action %q", ErrInvalidTestCase, req.ExpectedAction)
+	}
+	return nil
+}
+
+// CreateCase attaches a test case to the named policy. It returns
+// pgx.ErrNoRows if no version of the policy exists.
+func (s *TestSuites) CreateCase(ctx context.Context, policyName string, req *models.CreatePolicyTestCaseRequest, createdBy uuid.UUID) (*models.PolicyTestCase, error) {
+	if err := validateTestCase(req); err != nil {
+		return nil, err
+	}
+
+	var exists bool
+	var count int
+	err := s.evaluator.db.QueryRow(ctx, `
+		SELECT EXISTS (SELECT 1 FROM policies WHERE name = $1),
+		       (SELECT COUNT(*) FROM policy_test_cases WHERE policy_name = $1)
+	`, policyName).Scan(&exists, &count)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query policy test suite: %w", err)
+	}
+	if !exists {
+		return nil, fmt.Errorf("failed to query policy: %w", pgx.ErrNoRows)
+	}
+	if count >= maxTestCases {
+		return nil, fmt.Errorf("%w: a policy can have at most %d test cases", ErrInvalidTestCase, maxTestCases)
+	}
+
+	tc := &models.PolicyTestCase{
+		ID:              uuid.New(),
+		PolicyName:      policyName,
+		Name:            req.Name,
+		Content:         req.Content,
+		Scores:          req.Scores,
+		ContextMetadata: req.ContextMetadata,
+		ExpectedAction:  req.ExpectedAction,
+		CreatedBy:       &createdBy,
+	}
+
+	err = s.evaluator.db.QueryRow(ctx, `
+		INSERT INTO policy_test_cases (id, policy_name, name, content, scores, context_metadata, expected_action, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (policy_name, name) DO NOTHING
+		RETURNING created_at
+	`, tc.ID, tc.PolicyName, tc.Name, tc.Content, tc.Scores, tc.ContextMetadata, tc.ExpectedAction, tc.CreatedBy).Scan(&tc.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("%w: %q", ErrDuplicateTestCase, req.Name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create policy test case: %w", err)
+	}
+
+	s.logger.Info("policy test case created",
+		zap.String("test_case_id", tc.ID.String()),
+		zap.String("policy_name", policyName),
+		zap.String("name", tc.Name),
+	)
+
+	return tc, nil
+}
+
+// ListCases returns the test cases attached to the named policy, oldest first.
+func (s *TestSuites) ListCases(ctx context.Context, policyName string) ([]models.PolicyTestCase, error) {
+	rows, err := s.evaluator.db.Query(ctx, `
+		SELECT id, policy_name, name, content, scores, context_metadata, expected_action, created_by, created_at
+		FROM policy_test_cases
+		WHERE policy_name = $1
+		ORDER BY created_at, name
+	`, policyName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query policy test cases: %w", err)
+	}
+	defer rows.Close()
+
+	cases := []models.PolicyTestCase{}
+	for rows.Next() {
+		var tc models.PolicyTestCase
+		if err := rows.Scan(&tc.ID, &tc.PolicyName, &tc.Name, &tc.Content, &tc.Scores, &tc.ContextMetadata,
+			&tc.ExpectedAction, &tc.CreatedBy, &tc.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan policy test case: %w", err)
+		}
+		cases = append(cases, tc)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query policy test cases: %w", err)
+	}
+
+	return cases, nil
+}
+
+// DeleteCase removes a test case from the named policy. It returns
+// pgx.ErrNoRows if the policy has no such test case.
+func (s *TestSuites) DeleteCase(ctx context.Context, policyName string, caseID uuid.UUID) error {
+	tag, err := s.evaluator.db.Exec(ctx,
+		`DELETE FROM policy_test_cases WHERE id = $1 AND policy_name = $2`, caseID, policyName,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to delete policy test case: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("failed to delete policy test case: %w", pgx.ErrNoRows)
+	}
+
+	s.logger.Info("policy test case deleted",
+		zap.String("test_case_id", caseID.String()),
+		zap.String("policy_name", policyName),
+	)
+	return nil
+}
+
+// Run runs the policy's test suite on demand and records the run.
+func (s *TestSuites) Run(ctx context.Context, policyID uuid.UUID, actorID uuid.UUID) (*models.PolicyTestRun, error) {
+	policy, err := s.evaluator.getPolicy(ctx, policyID)
+	if err != nil {
+		return nil, err
+	}
+	return s.run(ctx, policy, models.TestTriggerManual, actorID)
+}
+
+// PreparedSuite is a policy's test suite with its content already
+// classified, ready to be evaluated against the policy.
+type PreparedSuite struct {
+	policy *models.Policy
+	cases  []models.PolicyTestCase
+	inputs []caseInput
+}
+
+// PrepareForPublish reads the policy and classifies the content cases of its
+// test suite. Classification calls out to the moderation service and can take
+// up to testSuiteTimeout, so it runs before Publish locks the policy. A policy
+// that cannot be published is rejected with ErrInvalidTransition first.
+func (s *TestSuites) PrepareForPublish(ctx context.Context, policyID uuid.UUID) (*PreparedSuite, error) {
+	policy, err := s.evaluator.getPolicy(ctx, policyID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := nextStatus(policy.Status, models.TransitionPublish); err != nil {
+		return nil, err
+	}
+	cases, err := s.ListCases(ctx, policy.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	runCtx, cancel := context.WithTimeout(ctx, testSuiteTimeout)
+	inputs := s.classifyCases(runCtx, policy, cases)
+	cancel()
+
+	return &PreparedSuite{policy: policy, cases: cases, inputs: inputs}, nil
+}
+
+// RunForPublish evaluates a prepared test suite against the policy being
+// published, returning a *TestSuiteError if any case failed. Publish runs it
+// with the policy locked, so the suite tests exactly the state that is
+// published; it returns ErrPolicyChanged if the locked policy is not the one
+// the suite was prepared for. The run is not recorded, since a run references
+// its policy and recording it would wait for that lock. The caller records it
+// with RecordRun once the lock is released.
+func (s *TestSuites) RunForPublish(policy *models.Policy, prepared *PreparedSuite, actorID uuid.UUID) (*models.PolicyTestRun, error) {
+	if !sameDefinition(prepared.policy, policy) {
+		return nil, fmt.Errorf("%w: policy %s", ErrPolicyChanged, policy.ID)
+	}
+
+	run := newTestRun(policy, models.TestTriggerPublish, s.evaluateCases(policy, prepared.cases, prepared.inputs))
+	run.ID = uuid.New()
+	run.CreatedBy = &actorID
+	if !run.Passed {
+		return nil, &TestSuiteError{Run: run}
+	}
+	return run, nil
+}
+
+// sameDefinition reports whether two reads of a policy define the same
+// evaluation: the same version with the same thresholds, actions, scope, rules
+// and normalization profiles. Lifecycle fields are ignored.
+func sameDefinition(a, b *models.Policy) bool {
+	return a.ID == b.ID &&
+		a.Version == b.Version &&
+		reflect.DeepEqual(a.Thresholds, b.Thresholds) &&
+		reflect.DeepEqual(a.Actions, b.Actions) &&
+		reflect.DeepEqual(a.Scope, b.Scope) &&
+		reflect.DeepEqual(a.Rules, b.Rules) &&
+		reflect.DeepEqual(a.NormalizationProfile, b.NormalizationProfile) &&
+		reflect.DeepEqual(a.NormalizationProfiles, b.NormalizationProfiles)
+}
+
+// RecordRun records a test suite run returned by RunForPublish.
+func (s *TestSuites) RecordRun(ctx context.Context, run *models.PolicyTestRun) error {
+	err := s.evaluator.db.QueryRow(ctx, `
+		INSERT INTO policy_test_runs (id, policy_id, policy_version, trigger, passed, total, failed, results, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING created_at
+	`, run.ID, run.PolicyID, run.PolicyVersion, run.Trigger, run.Passed, run.Total, run.Failed, run.Results, run.CreatedBy).Scan(&run.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record policy test run: %w", err)
+	}
+
+	s.logger.Info("policy test suite run",
+		zap.String("test_run_id", run.ID.String()),
+		zap.String("policy_id", run.PolicyID.String()),
+		zap.String("trigger", string(run.Trigger)),
+		zap.Int("total", run.Total),
+		zap.Int("failed", run.Failed),
+	)
+	return nil
+}
+
+// run runs every test case attached to the policy's name against it and
+// records the run.
+func (s *TestSuites) run(ctx context.Context, policy *models.Policy, trigger models.PolicyTestTrigger, actorID uuid.UUID) (*models.PolicyTestRun, error) {
+	run, err := s.runSuite(ctx, policy, trigger, actorID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.RecordRun(ctx, run); err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+// runSuite runs every test case attached to the policy's name against it.
+func (s *TestSuites) runSuite(ctx context.Context, policy *models.Policy, trigger models.PolicyTestTrigger, actorID uuid.UUID) (*models.PolicyTestRun, error) {
+	cases, err := s.ListCases(ctx, policy.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	runCtx, cancel := context.WithTimeout(ctx, testSuiteTimeout)
+	results := s.runCases(runCtx, policy, cases)
+	cancel()
+
+	run := newTestRun(policy, trigger, results)
+	run.ID = uuid.New()
+	run.CreatedBy = &actorID
+	return run, nil
+}
+
+// runCases runs the test cases against the policy. Results are in case order.
+func (s *TestSuites) runCases(ctx context.Context, policy *models.Policy, cases []models.PolicyTestCase) []models.PolicyTestResult {
+	return s.evaluateCases(policy, cases, s.classifyCases(ctx, policy, cases))
+}
+
+// caseInput is what a test case is evaluated with: its scores and evaluation
+// options, or the error that kept its content from being classified.
+type caseInput struct {
+	scores       *models.CategoryScores
+	opts         *EvaluationOptions
+	disagreement bool // the moderation ensemble disagreed on the content
+	err          string
+}
+
+// classifyCases classifies the content of the test cases for the policy, at
+// most testCaseConcurrency at a time. Inputs are in case order.
+func (s *TestSuites) classifyCases(ctx context.Context, policy *models.Policy, cases []models.PolicyTestCase) []caseInput {
+	inputs := make([]caseInput, len(cases))
+	sem := make(chan struct{}, testCaseConcurrency)
+	var wg sync.WaitGroup
+	for i := range cases {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			inputs[i] = s.classifyCase(ctx, policy, &cases[i])
+		}(i)
+	}
+	wg.Wait()
+
+	return inputs
+}
+
+// classifyCase returns the input of one test case, classifying its content
+// when it has no scores.
+func (s *TestSuites) classifyCase(ctx context.Context, policy *models.Policy, tc *models.PolicyTestCase) caseInput {
+	input := caseInput{
+		scores: tc.Scores,
+		opts:   &EvaluationOptions{ContextMetadata: tc.ContextMetadata},
+	}
+	if tc.Content == nil {
+		return input
+	}
+
+	classified, err := s.classifier.Classify(ctx, policy.ID, *tc.Content)
+	if err != nil {
+		s.logger.Warn("failed to classify policy test case",
+			zap.String("test_case_id", tc.ID.String()), zap.Error(err))
+		input.err = "failed to classify content"
+		return input
+	}
+	input.scores = &classified.CategoryScores
+	input.opts.Signals = classified.Signals
+	input.opts.Translated = classified.Translated
+	input.opts.SourceLanguage = classified.SourceLanguage
+	input.opts.Language = classified.DetectedLanguage
+	input.opts.Languages = classified.DetectedLanguages
+	if len(input.opts.Languages) == 0 && classified.DetectedLanguage != "" {
+		input.opts.Languages = []string{classified.DetectedLanguage}
+	}
+	input.disagreement = classified.EnsembleDisagreement
+	return input
+}
+
+// evaluateCases evaluates the test cases against the policy with their
+// inputs. Results are in case order.
+func (s *TestSuites) evaluateCases(policy *models.Policy, cases []models.PolicyTestCase, inputs []caseInput) []models.PolicyTestResult {
+	results := make([]models.PolicyTestResult, len(cases))
+	for i := range cases {
+		results[i] = s.evaluateCase(policy, &cases[i], inputs[i])
+	}
+	return results
+}
+
+// evaluateCase evaluates one test case, failing it if its content could not
+// be classified.
+func (s *TestSuites) evaluateCase(policy *models.Policy, tc *models.PolicyTestCase, input caseInput) models.PolicyTestResult {
+	result := models.PolicyTestResult{
+		CaseID:         tc.ID,
+		Name:           tc.Name,
+		ExpectedAction: tc.ExpectedAction,
+	}
+	if input.err != "" {
+		result.Error = input.err
+		return result
+	}
+
+	evaluation, err := s.evaluator.evaluatePolicy(policy, input.scores, input.opts)
+	if err != nil {
+		result.Error = "failed to evaluate policy"
+		return result
+	}
+
+	// Moderation escalates content its ensemble providers disagree on
+	action := evaluation.Action
+	if input.disagreement && action != models.ActionBlock {
+		action = models.ActionEscalate
+	}
+
+	result.Action = &action
+	result.TriggeredRules = evaluation.TriggeredRules
+	result.Passed = action == tc.ExpectedAction
+	return result
+}
+
+// newTestRun tallies the results of a suite run. A suite with no test cases passes.
+func newTestRun(policy *models.Policy, trigger models.PolicyTestTrigger, results []models.PolicyTestResult) *models.PolicyTestRun {
+	run := &models.PolicyTestRun{
+		PolicyID:      policy.ID,
+		PolicyVersion: policy.Version,
+		Trigger:       trigger,
+		Total:         len(results),
+		Results:       results,
+	}
+	for _, result := range results {
+		if !result.Passed {
+			run.Failed++
+		}
+	}
+	run.Passed = run.Failed == 0
+	return run
+}
+
+// ListRuns returns the latest test suite runs of a policy, newest first.
+func (s *TestSuites) ListRuns(ctx context.Context, policyID uuid.UUID) ([]models.PolicyTestRun, error) {
+	rows, err := s.evaluator.db.Query(ctx, `
+		SELECT id, policy_id, policy_version, trigger, passed, total, failed, results, created_by, created_at
+		FROM policy_test_runs
+		WHERE policy_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2
+	`, policyID, maxTestRuns)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query policy test runs: %w", err)
+	}
+	defer rows.Close()
+
+	runs := []models.PolicyTestRun{}
+	for rows.Next() {
+		var run models.PolicyTestRun
+		if err := rows.Scan(&run.ID, &run.PolicyID, &run.PolicyVersion, &run.Trigger, &run.Passed, &run.Total,
+			&run.Failed, &run.Results, &run.CreatedBy, &run.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan policy test run: %w", err)
+		}
+		runs = append(runs, run)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query policy test runs: %w", err)
+	}
+
+	return runs, nil
+}
@@ -0,0 +1,26 @@
+package engine
+
+import (
+	"github.com/proth1/text-moderator/internal/models"
+	"github.com/proth1/text-moderator/internal/normalizer"
+)
+
+// ValidatePolicyRequest checks the parts of a policy creation request that
+// binding cannot: the policy's normalization profiles, the default profile it
+// names, that its rules compile, and the routes in its scope.
+func ValidatePolicyRequest(req *models.CreatePolicyRequest) error {
+	defaultProfile := ""
+	if req.NormalizationProfile != nil {
+		defaultProfile = *req.NormalizationProfile
+	}
+	if err := normalizer.ValidateProfiles(defaultProfile, req.NormalizationProfiles); err != nil {
+		return err
+	}
+	if _, err := compileRules(req.Rules); err != nil {
+		return err
+	}
+	if _, _, err := parseScope(req.Scope); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,239 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/proth1/text-moderator/internal/models"
+)
+
+// Control: POL-001 (Policy definition and versioning)
+
+// versionColumns are policyColumns followed by the emails of the users who
+// created and last published the policy.
+const versionColumns = policyColumns + `,
+		(SELECT email FROM users WHERE users.id = policies.created_by),
+		(SELECT email FROM users WHERE users.id = policies.published_by)`
+
+// ListPolicyVersions returns every version of the named policy, newest first,
+// with who created and last published each.
+func (e *Evaluator) ListPolicyVersions(ctx context.Context, name string) ([]models.PolicyVersion, error) {
+	query := `
+		SELECT ` + versionColumns + `
+		FROM policies
+		WHERE name = $1
+		ORDER BY version DESC
+	`
+
+	rows, err := e.db.Query(ctx, query, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query policy versions: %w", err)
+	}
+	defer rows.Close()
+
+	var versions []models.PolicyVersion
+	for rows.Next() {
+		version, err := scanPolicyVersion(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan policy version: %w", err)
+		}
+		versions = append(versions, *version)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query policy versions: %w", err)
+	}
+
+	return versions, nil
+}
+
+// DiffPolicies returns the changes that turn policy fromID into policy toID.
+// The policies are usually versions of the same policy, but need not be.
+func (e *Evaluator) DiffPolicies(ctx context.Context, fromID, toID uuid.UUID) (*models.PolicyDiff, error) {
+	query := `SELECT ` + versionColumns + ` FROM policies WHERE id = $1`
+
+	from, err := scanPolicyVersion(e.db.QueryRow(ctx, query, fromID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query policy: %w", err)
+	}
+	to, err := scanPolicyVersion(e.db.QueryRow(ctx, query, toID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query policy: %w", err)
+	}
+
+	diff := diffPolicies(&from.Policy, &to.Policy)
+	diff.From = *from
+	diff.To = *to
+	return diff, nil
+}
+
+// scanPolicyVersion scans a row of versionColumns.
+func scanPolicyVersion(row pgx.Row) (*models.PolicyVersion, error) {
+	var authorEmail, publisherEmail *string
+	policy, err := scanPolicy(row, &authorEmail, &publisherEmail)
+	if err != nil {
+		return nil, err
+	}
+
+	version := &models.PolicyVersion{Policy: *policy}
+	if policy.CreatedBy != nil && authorEmail != nil {
+		version.Author = &models.PolicyAuthor{ID: *policy.CreatedBy, Email: *authorEmail}
+	}
+	if policy.PublishedBy != nil && publisherEmail != nil {
+		version.Publisher = &models.PolicyAuthor{ID: *policy.PublishedBy, Email: *publisherEmail}
+	}
+	return version, nil
+}
+
+// diffPolicies compares the thresholds, actions, scope and rules of two
+// policies. Every list is empty rather than nil when nothing changed, and
+// sorted by category, path or, for rules, their order in to.
+func diffPolicies(from, to *models.Policy) *models.PolicyDiff {
+	diff := &models.PolicyDiff{
+		Thresholds: []models.ThresholdChange{},
+		Actions:    []models.ActionChange{},
+		Scope:      []models.ScopeChange{},
+		Rules:      diffRules(from.Rules, to.Rules),
+	}
+
+	for _, category := range unionKeys(from.Thresholds, to.Thresholds) {
+		oldValue, inFrom := from.Thresholds[category]
+		newValue, inTo := to.Thresholds[category]
+		if change, ok := changeType(inFrom, inTo, oldValue == newValue); ok {
+			c := models.ThresholdChange{Category: category, Change: change}
+			if inFrom {
+				c.From = &oldValue
+			}
+			if inTo {
+				c.To = &newValue
+			}
+			diff.Thresholds = append(diff.Thresholds, c)
+		}
+	}
+
+	for _, category := range unionKeys(from.Actions, to.Actions) {
+		oldAction, inFrom := from.Actions[category]
+		newAction, inTo := to.Actions[category]
+		if change, ok := changeType(inFrom, inTo, oldAction == newAction); ok {
+			c := models.ActionChange{Category: category, Change: change}
+			if inFrom {
+				c.From = &oldAction
+			}
+			if inTo {
+				c.To = &newAction
+			}
+			diff.Actions = append(diff.Actions, c)
+		}
+	}
+
+	diffScope("", from.Scope, to.Scope, &diff.Scope)
+
+	return diff
+}
+
+// diffScope appends the changes between two scope values at path. Nested
+// objects are compared key by key; any other values, including lists, as a whole.
+func diffScope(path string, from, to map[string]interface{}, changes *[]models.ScopeChange) {
+	for _, key := range unionKeys(from, to) {
+		keyPath := key
+		if path != "" {
+			keyPath = path + "." + key
+		}
+		oldValue, inFrom := from[key]
+		newValue, inTo := to[key]
+
+		oldMap, oldIsMap := oldValue.(map[string]interface{})
+		newMap, newIsMap := newValue.(map[string]interface{})
+		if oldIsMap && newIsMap {
+			diffScope(keyPath, oldMap, newMap, changes)
+			continue
+		}
+
+		if change, ok := changeType(inFrom, inTo, reflect.DeepEqual(oldValue, newValue)); ok {
+			*changes = append(*changes, models.ScopeChange{Path: keyPath, Change: change, From: oldValue, To: newValue})
+		}
+	}
+}
+
+// diffRules compares rules by ID. A rule whose definition is unchanged but
+// whose position relative to the other rules kept is different is moved,
+// since rules are evaluated in order.
+func diffRules(from, to []models.PolicyRule) []models.RuleChange {
+	changes := []models.RuleChange{}
+
+	oldRules := make(map[string]models.PolicyRule, len(from))
+	for _, rule := range from {
+		oldRules[rule.ID] = rule
+	}
+	newRules := make(map[string]models.PolicyRule, len(to))
+	for _, rule := range to {
+		newRules[rule.ID] = rule
+	}
+
+	// Positions among the rules present in both versions
+	oldPosition := make(map[string]int)
+	for _, rule := range from {
+		if _, ok := newRules[rule.ID]; ok {
+			oldPosition[rule.ID] = len(oldPosition)
+		}
+	}
+	kept := 0
+
+	for _, rule := range to {
+		newRule := rule
+		oldRule, ok := oldRules[rule.ID]
+		switch {
+		case !ok:
+			changes = append(changes, models.RuleChange{ID: rule.ID, Change: models.PolicyChangeAdded, To: &newRule})
+			continue
+		case oldRule != newRule:
+			changes = append(changes, models.RuleChange{ID: rule.ID, Change: models.PolicyChangeChanged, From: &oldRule, To: &newRule})
+		case oldPosition[rule.ID] != kept:
+			changes = append(changes, models.RuleChange{ID: rule.ID, Change: models.PolicyChangeMoved, From: &oldRule, To: &newRule})
+		}
+		kept++
+	}
+
+	for _, rule := range from {
+		if _, ok := newRules[rule.ID]; !ok {
+			oldRule := rule
+			changes = append(changes, models.RuleChange{ID: rule.ID, Change: models.PolicyChangeRemoved, From: &oldRule})
+		}
+	}
+
+	return changes
+}
+
+// changeType classifies a setting by whether it is present in each version and
+// whether its values are equal, reporting false if it is unchanged.
+func changeType(inFrom, inTo, equal bool) (models.PolicyChangeType, bool) {
+	switch {
+	case !inFrom && inTo:
+		return models.PolicyChangeAdded, true
+	case inFrom && !inTo:
+		return models.PolicyChangeRemoved, true
+	case inFrom && inTo && !equal:
+		return models.PolicyChangeChanged, true
+	}
+	return "", false
+}
+
+// unionKeys returns the keys of both maps, sorted.
+func unionKeys[V any](a, b map[string]V) []string {
+	seen := make(map[string]bool, len(a)+len(b))
+	keys := make([]string, 0, len(a)+len(b))
+	for _, m := range []map[string]V{a, b} {
+		for k := range m {
+			if !seen[k] {
+				seen[k] = true
+				keys = append(keys, k)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,98 @@
+package engine
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/proth1/text-moderator/internal/models"
+)
+
+func TestDiffPolicies(t *testing.T) {
+	from := &models.Policy{
+		Thresholds: map[string]float64{"toxicity": 0.8, "hate": 0.7, "spam": 0.9},
+		Actions:    map[string]models.PolicyAction{"toxicity": models.ActionWarn, "hate": models.ActionBlock, "spam": models.ActionWarn},
+		Scope: map[string]interface{}{
+			"region":     "EU",
+			"translated": map[string]interface{}{"action": "warn", "threshold_adjustments": map[string]interface{}{"hate": -0.1}},
+			"platforms":  []interface{}{"web"},
+		},
+		Rules: []models.PolicyRule{
+			{ID: "a", Expression: "hate > 0.5", Action: models.ActionBlock},
+			{ID: "b", Expression: "spam > 0.5", Action: models.ActionWarn},
+			{ID: "c", Expression: "toxicity > 0.5", Action: models.ActionWarn},
+			{ID: "d", Expression: "pii > 0.5", Action: models.ActionEscalate},
+		},
+	}
+	to := &models.Policy{
+		Thresholds: map[string]float64{"toxicity": 0.7, "hate": 0.7, "harassment": 0.75},
+		Actions:    map[string]models.PolicyAction{"toxicity": models.ActionBlock, "hate": models.ActionBlock, "harassment": models.ActionWarn},
+		Scope: map[string]interface{}{
+			"region":     "EU",
+			"translated": map[string]interface{}{"action": "escalate", "threshold_adjustments": map[string]interface{}{"hate": -0.1}},
+			"platforms":  []interface{}{"web", "ios"},
+			"audience":   "kids",
+		},
+		Rules: []models.PolicyRule{
+			{ID: "b", Expression: "spam > 0.5", Action: models.ActionWarn},
+			{ID: "a", Expression: "hate > 0.5", Action: models.ActionBlock},
+			{ID: "c", Expression: "toxicity > 0.6", Action: models.ActionWarn},
+			{ID: "e", Expression: "violence > 0.5", Action: models.ActionBlock},
+		},
+	}
+
+	diff := diffPolicies(from, to)
+
+	got, err := json.Marshal(diff.Thresholds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `[{"category":"harassment","change":"added","to":0.75},` +
+		`{"category":"spam","change":"removed","from":0.9},` +
+		`{"category":"toxicity","change":"changed","from":0.8,"to":0.7}]`
+	if string(got) != want {
+		t.Errorf("thresholds: got %s, want %s", got, want)
+	}
+
+	got, _ = json.Marshal(diff.Actions)
+	want = `[{"category":"harassment","change":"added","to":"warn"},` +
+		`{"category":"spam","change":"removed","from":"warn"},` +
+		`{"category":"toxicity","change":"changed","from":"warn","to":"block"}]`
+	if string(got) != want {
+		t.Errorf("actions: got %s, want %s", got, want)
+	}
+
+	got, _ = json.Marshal(diff.Scope)
+	want = `[{"path":"audience","change":"added","to":"kids"},` +
+		`{"path":"platforms","change":"changed","from":["web"],"to":["web","ios"]},` +
+		`{"path":"translated.action","change":"changed","from":"warn","to":"escalate"}]`
+	if string(got) != want {
+		t.Errorf("scope: got %s, want %s", got, want)
+	}
+
+	var rules []string
+	for _, c := range diff.Rules {
+		rules = append(rules, c.ID+":"+string(c.Change))
+	}
+	got, _ = json.Marshal(rules)
+	want = `["b:moved","a:moved","c:changed","e:added","d:removed"]`
+	if string(got) != want {
+		t.Errorf("rules: got %s, want %s", got, want)
+	}
+}
+
+func TestDiffPolicies_Unchanged(t *testing.T) {
+	policy := &models.Policy{
+		Thresholds: map[string]float64{"hate": 0.7},
+		Actions:    map[string]models.PolicyAction{"hate": models.ActionBlock},
+		Scope:      map[string]interface{}{"region": "EU"},
+		Rules:      []models.PolicyRule{{ID: "a", Expression: "hate > 0.5", Action: models.ActionBlock}},
+	}
+
+	diff := diffPolicies(policy, policy)
+	if len(diff.Thresholds)+len(diff.Actions)+len(diff.Scope)+len(diff.Rules) != 0 {
+		t.Errorf("got changes %+v, want none", diff)
+	}
+	if diff.Thresholds == nil || diff.Actions == nil || diff.Scope == nil || diff.Rules == nil {
+		t.Error("got nil change lists, want empty lists")
+	}
+}
@@ -2,20 +2,32 @@ package main
 
 import (
 	"context"
+	"encoding/csv"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/proth1/text-moderator/internal/classifier"
 	"github.com/proth1/text-moderator/internal/config"
 	"github.com/proth1/text-moderator/internal/database"
+	"github.com/proth1/text-moderator/internal/evidence"
+	"github.com/proth1/text-moderator/internal/lexicon"
 	"github.com/proth1/text-moderator/internal/middleware"
 	"github.com/proth1/text-moderator/internal/models"
 	"github.com/proth1/text-moderator/internal/observability"
+	"github.com/proth1/text-moderator/internal/prompt"
+	"github.com/proth1/text-moderator/internal/webhook"
+	"github.com/proth1/text-moderator/services/moderation/client"
 	"github.com/proth1/text-moderator/services/policy-engine/engine"
 	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 	"go.uber.org/zap"
@@ -60,6 +72,36 @@ func main() {
 	// Initialize policy evaluator
 	evaluator := engine.NewEvaluator(db.Pool, logger)
 
+	// Initialize policy test suites, classifying raw content test cases
+	// through the moderation service so they see the production pipeline
+	moderationClient := client.NewModerationClient(client.ModerationConfig{
+		BaseURL:      cfg.ModerationBaseURL(),
+		ServiceToken: cfg.InternalServiceToken,
+		Timeout:      15 * time.Second,
+	}, logger)
+	testSuites := engine.NewTestSuites(evaluator, moderationClient, logger)
+
+	// Initialize policy lifecycle transitions, recorded as POL-001 evidence
+	// and gated on publish by the policy's test suite
+	evidenceWriter := evidence.NewWriter(db.Pool, logger)
+	lifecycle := engine.NewLifecycle(db.Pool, evidenceWriter, testSuites, logger)
+
+	// Initialize policy simulation against historical decisions, failing
+	// simulations abandoned by a restart or past their deadline
+	simulator := engine.NewSimulator(evaluator, logger)
+	sweepCtx, sweepCancel := context.WithCancel(context.Background())
+	defer sweepCancel()
+	go simulator.SweepAbandoned(sweepCtx)
+
+	// Initialize webhook dispatcher for policy.updated events
+	webhookDispatcher := webhook.NewDispatcher(db.Pool, logger)
+
+	// Initialize lexicon store for the offline classification provider
+	lexiconStore := lexicon.NewStore(db.Pool, logger)
+
+	// Initialize prompt template store for policy-aware LLM classification
+	promptStore := prompt.NewStore(db.Pool, logger)
+
 	// Initialize distributed tracing
 	tracingShutdown, err := observability.InitTracing(context.Background(), "policy-engine", cfg.Version, cfg.OTLPEndpoint, logger)
 	if err != nil {
@@ -72,7 +114,7 @@ func main() {
 	metrics := observability.NewMetrics("policy-engine")
 
 	// Create HTTP server
-	router := setupRouter(cfg, logger, db, evaluator, metrics)
+	router := setupRouter(cfg, logger, db, evaluator, lifecycle, simulator, testSuites, webhookDispatcher, lexiconStore, promptStore, metrics)
 	srv := &http.Server{
 		Addr:              fmt.Sprintf(":%s", cfg.PolicyEnginePort),
 		Handler:           router,
@@ -108,7 +150,7 @@ func main() {
 	logger.Info("policy-engine service stopped")
 }
 
-func setupRouter(cfg *config.Config, logger *zap.Logger, db *database.PostgresDB, evaluator *engine.Evaluator, metrics *observability.Metrics) *gin.Engine {
+func setupRouter(cfg *config.Config, logger *zap.Logger, db *database.PostgresDB, evaluator *engine.Evaluator, lifecycle *engine.Lifecycle, simulator *engine.Simulator, testSuites *engine.TestSuites, webhookDispatcher *webhook.Dispatcher, lexiconStore *lexicon.Store, promptStore *prompt.Store, metrics *observability.Metrics) *gin.Engine {
 	if cfg.Environment == "production" {
 		gin.SetMode(gin.ReleaseMode)
 	}
@@ -131,7 +173,39 @@ func setupRouter(cfg *config.Config, logger *zap.Logger, db *database.PostgresDB
 		api.GET("/policies", listPoliciesHandler(evaluator))
 		api.POST("/policies", middleware.RequireRole("admin"), createPolicyHandler(evaluator))
 		api.GET("/policies/:id", getPolicyHandler(evaluator))
+		api.GET("/policies/by-name/:name/versions", listPolicyVersionsHandler(evaluator))
+		api.GET("/policies/:id/diff", diffPolicyHandler(evaluator))
 		api.POST("/policies/:id/evaluate", evaluatePolicyHandler(evaluator, metrics))
+
+		// Policy lifecycle transitions
+		api.POST("/policies/:id/publish", middleware.RequireRole("admin"), publishPolicyHandler(lifecycle, webhookDispatcher, logger))
+		api.POST("/policies/:id/archive", middleware.RequireRole("admin"), archivePolicyHandler(lifecycle, webhookDispatcher, logger))
+		api.POST("/policies/:id/rollback", middleware.RequireRole("admin"), rollbackPolicyHandler(lifecycle, webhookDispatcher, logger))
+
+		// Policy simulation against historical decisions
+		api.POST("/policies/:id/simulate", middleware.RequireRole("admin"), simulatePolicyHandler(simulator))
+		api.GET("/simulations/:id", middleware.RequireRole("admin"), getSimulationHandler(simulator))
+		api.GET("/simulations/:id/flips", middleware.RequireRole("admin"), listSimulationFlipsHandler(simulator))
+		api.GET("/simulations/:id/export", middleware.RequireRole("admin"), exportSimulationHandler(simulator, logger))
+
+		// Golden test suites, attached to a policy by name and run before each publish
+		api.GET("/policies/by-name/:name/test-cases", middleware.RequireRole("admin"), listPolicyTestCasesHandler(testSuites))
+		api.POST("/policies/by-name/:name/test-cases", middleware.RequireRole("admin"), createPolicyTestCaseHandler(testSuites))
+		api.DELETE("/policies/by-name/:name/test-cases/:case_id", middleware.RequireRole("admin"), deletePolicyTestCaseHandler(testSuites))
+		api.POST("/policies/:id/test-runs", middleware.RequireRole("admin"), runPolicyTestsHandler(testSuites))
+		api.GET("/policies/:id/test-runs", middleware.RequireRole("admin"), listPolicyTestRunsHandler(testSuites))
+
+		// Versioned LLM prompt templates per policy
+		api.GET("/policies/:id/prompt-templates", listPromptTemplatesHandler(promptStore))
+		api.POST("/policies/:id/prompt-templates", middleware.RequireRole("admin"), createPromptTemplateHandler(promptStore))
+		api.GET("/prompt-templates/:id", getPromptTemplateHandler(promptStore))
+		api.POST("/prompt-templates/:id/publish", middleware.RequireRole("admin"), publishPromptTemplateHandler(promptStore))
+
+		// Lexicon lists for the offline classification provider
+		api.GET("/lexicons", listLexiconsHandler(lexiconStore))
+		api.POST("/lexicons", middleware.RequireRole("admin"), createLexiconHandler(lexiconStore))
+		api.GET("/lexicons/:id", getLexiconHandler(lexiconStore))
+		api.POST("/lexicons/:id/publish", middleware.RequireRole("admin"), publishLexiconHandler(lexiconStore))
 	}
 
 	return router
@@ -205,6 +279,11 @@ func createPolicyHandler(evaluator *engine.Evaluator) gin.HandlerFunc {
 			return
 		}
 
+		if err := engine.ValidatePolicyRequest(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
 		ctx := c.Request.Context()
 		userID := middleware.MustGetUserID(c)
 
@@ -228,26 +307,7 @@ func getPolicyHandler(evaluator *engine.Evaluator) gin.HandlerFunc {
 
 		ctx := c.Request.Context()
 
-		query := `
-			SELECT id, name, version, thresholds, actions, scope, status, effective_date, created_at, created_by
-			FROM policies
-			WHERE id = $1
-		`
-
-		var policy models.Policy
-		err = evaluator.Pool().QueryRow(ctx, query, policyID).Scan(
-			&policy.ID,
-			&policy.Name,
-			&policy.Version,
-			&policy.Thresholds,
-			&policy.Actions,
-			&policy.Scope,
-			&policy.Status,
-			&policy.EffectiveDate,
-			&policy.CreatedAt,
-			&policy.CreatedBy,
-		)
-
+		policy, err := evaluator.GetPolicyByID(ctx, policyID)
 		if err != nil {
 			c.JSON(http.StatusNotFound, gin.H{"error": "policy not found"})
 			return
@@ -257,6 +317,50 @@ func getPolicyHandler(evaluator *engine.Evaluator) gin.HandlerFunc {
 	}
 }
 
+func listPolicyVersionsHandler(evaluator *engine.Evaluator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		versions, err := evaluator.ListPolicyVersions(c.Request.Context(), c.Param("name"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list policy versions"})
+			return
+		}
+		if len(versions) == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "policy not found"})
+			return
+		}
+
+		c.JSON(http.StatusOK, versions)
+	}
+}
+
+func diffPolicyHandler(evaluator *engine.Evaluator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		policyID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid policy ID"})
+			return
+		}
+		againstID, err := uuid.Parse(c.Query("against"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "against must be a policy ID"})
+			return
+		}
+
+		// The diff lists the changes from the against policy to this one
+		diff, err := evaluator.DiffPolicies(c.Request.Context(), againstID, policyID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "policy not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to diff policies"})
+			return
+		}
+
+		c.JSON(http.StatusOK, diff)
+	}
+}
+
 func evaluatePolicyHandler(evaluator *engine.Evaluator, metrics *observability.Metrics) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		evalStart := time.Now()
@@ -276,8 +380,20 @@ func evaluatePolicyHandler(evaluator *engine.Evaluator, metrics *observability.M
 
 		ctx := c.Request.Context()
 
-		result, err := evaluator.EvaluateScores(ctx, &req.CategoryScores, policyID, nil)
-		if err != nil {
+		opts := &engine.EvaluationOptions{
+			ContextMetadata: req.ContextMetadata,
+			TrustScore:      req.TrustScore,
+			Language:        req.Language,
+		}
+		result, err := evaluator.EvaluateScores(ctx, &req.CategoryScores, policyID, opts)
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			c.JSON(http.StatusNotFound, gin.H{"error": "policy not found"})
+			return
+		case errors.Is(err, engine.ErrPolicyNotInForce):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		case err != nil:
 			// SECURITY: Don't expose internal error details
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to evaluate policy"})
 			return
@@ -289,3 +405,509 @@ func evaluatePolicyHandler(evaluator *engine.Evaluator, metrics *observability.M
 		c.JSON(http.StatusOK, result)
 	}
 }
+
+func publishPolicyHandler(lifecycle *engine.Lifecycle, webhookDispatcher *webhook.Dispatcher, logger *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		policyID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid policy ID"})
+			return
+		}
+
+		// The body is optional; without an effective date the policy takes effect immediately
+		var req models.PublishPolicyRequest
+		if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+			return
+		}
+
+		event, err := lifecycle.Publish(c.Request.Context(), policyID, req.EffectiveDate, middleware.MustGetUserID(c))
+		respondPolicyTransition(c, webhookDispatcher, logger, models.TransitionPublish, event, err)
+	}
+}
+
+func archivePolicyHandler(lifecycle *engine.Lifecycle, webhookDispatcher *webhook.Dispatcher, logger *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		policyID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid policy ID"})
+			return
+		}
+
+		event, err := lifecycle.Archive(c.Request.Context(), policyID, middleware.MustGetUserID(c))
+		respondPolicyTransition(c, webhookDispatcher, logger, models.TransitionArchive, event, err)
+	}
+}
+
+func rollbackPolicyHandler(lifecycle *engine.Lifecycle, webhookDispatcher *webhook.Dispatcher, logger *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		policyID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid policy ID"})
+			return
+		}
+
+		event, err := lifecycle.Rollback(c.Request.Context(), policyID, middleware.MustGetUserID(c))
+		respondPolicyTransition(c, webhookDispatcher, logger, models.TransitionRollback, event, err)
+	}
+}
+
+// respondPolicyTransition writes the result of a policy transition and
+// dispatches the policy.updated webhook when it succeeded.
+func respondPolicyTransition(c *gin.Context, webhookDispatcher *webhook.Dispatcher, logger *zap.Logger, transition models.PolicyTransition, event *models.PolicyUpdatedEvent, err error) {
+	if err != nil {
+		var suiteErr *engine.TestSuiteError
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			c.JSON(http.StatusNotFound, gin.H{"error": "policy not found"})
+		case errors.Is(err, engine.ErrInvalidTransition), errors.Is(err, engine.ErrNoRollbackTarget), errors.Is(err, engine.ErrNoDefaultPolicy),
+			errors.Is(err, engine.ErrMultipleDefaultPolicies), errors.Is(err, engine.ErrPolicyChanged):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.As(err, &suiteErr):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "test_run": suiteErr.Run})
+		default:
+			logger.Error("policy transition failed", zap.String("transition", string(transition)), zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to %s policy", transition)})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, event)
+
+	go func() {
+		bgCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		webhookDispatcher.Dispatch(bgCtx, models.EventPolicyUpdated, event)
+	}()
+}
+
+func simulatePolicyHandler(simulator *engine.Simulator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		policyID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid policy ID"})
+			return
+		}
+
+		var req models.SimulatePolicyRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+			return
+		}
+
+		sim, err := simulator.Start(c.Request.Context(), policyID, &req, middleware.MustGetUserID(c))
+		if err != nil {
+			switch {
+			case errors.Is(err, engine.ErrInvalidSimulation):
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			case errors.Is(err, pgx.ErrNoRows):
+				c.JSON(http.StatusNotFound, gin.H{"error": "policy not found"})
+			default:
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to start simulation"})
+			}
+			return
+		}
+
+		c.JSON(http.StatusAccepted, sim)
+	}
+}
+
+func getSimulationHandler(simulator *engine.Simulator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		sim, ok := lookupSimulation(c, simulator)
+		if !ok {
+			return
+		}
+
+		c.JSON(http.StatusOK, sim)
+	}
+}
+
+func listSimulationFlipsHandler(simulator *engine.Simulator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		sim, ok := lookupSimulation(c, simulator)
+		if !ok {
+			return
+		}
+
+		// Parse pagination with safe defaults and max limits
+		limit := 100
+		offset := 0
+		if l := c.Query("limit"); l != "" {
+			if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 && parsed <= 500 {
+				limit = parsed
+			}
+		}
+		if o := c.Query("offset"); o != "" {
+			if parsed, err := strconv.Atoi(o); err == nil && parsed >= 0 {
+				offset = parsed
+			}
+		}
+
+		page, err := simulator.ListFlips(c.Request.Context(), sim.ID, limit, offset)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list flipped decisions"})
+			return
+		}
+
+		c.JSON(http.StatusOK, page)
+	}
+}
+
+func exportSimulationHandler(simulator *engine.Simulator, logger *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		sim, ok := lookupSimulation(c, simulator)
+		if !ok {
+			return
+		}
+		if sim.Status != models.SimulationCompleted {
+			c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("simulation is %s", sim.Status)})
+			return
+		}
+
+		// Set headers for CSV download
+		c.Header("Content-Type", "text/csv")
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=simulation_%s.csv", sim.ID))
+		c.Status(http.StatusOK)
+
+		w := csv.NewWriter(c.Writer)
+		w.Write([]string{"decision_id", "decided_at", "original_action", "simulated_action", "triggered_rules", "review_action"})
+		err := simulator.EachFlip(c.Request.Context(), sim.ID, 0, 0, func(flip *models.SimulationFlip) error {
+			review := ""
+			if flip.ReviewAction != nil {
+				review = string(*flip.ReviewAction)
+			}
+			return w.Write([]string{
+				flip.DecisionID.String(),
+				flip.DecidedAt.Format(time.RFC3339),
+				string(flip.OriginalAction),
+				string(flip.SimulatedAction),
+				strings.Join(flip.TriggeredRules, "; "),
+				review,
+			})
+		})
+		w.Flush()
+		if err == nil {
+			err = w.Error()
+		}
+		if err != nil {
+			// The response has started, so the export is cut short
+			logger.Error("failed to export simulation", zap.String("simulation_id", sim.ID.String()), zap.Error(err))
+		}
+	}
+}
+
+// lookupSimulation loads the simulation named by the :id parameter, writing
+// the error response if it cannot.
+func lookupSimulation(c *gin.Context, simulator *engine.Simulator) (*models.PolicySimulation, bool) {
+	simulationID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid simulation ID"})
+		return nil, false
+	}
+
+	sim, err := simulator.GetSimulation(c.Request.Context(), simulationID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "simulation not found"})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get simulation"})
+		return nil, false
+	}
+	return sim, true
+}
+
+func listPolicyTestCasesHandler(testSuites *engine.TestSuites) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		cases, err := testSuites.ListCases(c.Request.Context(), c.Param("name"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list test cases"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"test_cases": cases,
+			"count":      len(cases),
+		})
+	}
+}
+
+func createPolicyTestCaseHandler(testSuites *engine.TestSuites) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req models.CreatePolicyTestCaseRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			// SECURITY: Don't expose detailed parsing errors to clients
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+			return
+		}
+
+		tc, err := testSuites.CreateCase(c.Request.Context(), c.Param("name"), &req, middleware.MustGetUserID(c))
+		if err != nil {
+			switch {
+			case errors.Is(err, engine.ErrInvalidTestCase):
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			case errors.Is(err, engine.ErrDuplicateTestCase):
+				c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			case errors.Is(err, pgx.ErrNoRows):
+				c.JSON(http.StatusNotFound, gin.H{"error": "policy not found"})
+			default:
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create test case"})
+			}
+			return
+		}
+
+		c.JSON(http.StatusCreated, tc)
+	}
+}
+
+func deletePolicyTestCaseHandler(testSuites *engine.TestSuites) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		caseID, err := uuid.Parse(c.Param("case_id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid test case ID"})
+			return
+		}
+
+		if err := testSuites.DeleteCase(c.Request.Context(), c.Param("name"), caseID); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "test case not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete test case"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"status": "deleted"})
+	}
+}
+
+func runPolicyTestsHandler(testSuites *engine.TestSuites) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		policyID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid policy ID"})
+			return
+		}
+
+		run, err := testSuites.Run(c.Request.Context(), policyID, middleware.MustGetUserID(c))
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "policy not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to run test suite"})
+			return
+		}
+
+		c.JSON(http.StatusCreated, run)
+	}
+}
+
+func listPolicyTestRunsHandler(testSuites *engine.TestSuites) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		policyID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid policy ID"})
+			return
+		}
+
+		runs, err := testSuites.ListRuns(c.Request.Context(), policyID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list test runs"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"test_runs": runs,
+			"count":     len(runs),
+		})
+	}
+}
+
+func listLexiconsHandler(store *lexicon.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		// Optional status filter
+		var status *models.PolicyStatus
+		if statusParam := c.Query("status"); statusParam != "" {
+			s := models.PolicyStatus(statusParam)
+			status = &s
+		}
+
+		lists, err := store.ListLists(ctx, status)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list lexicons"})
+			return
+		}
+
+		c.JSON(http.StatusOK, lists)
+	}
+}
+
+func createLexiconHandler(store *lexicon.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req models.CreateLexiconRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			// SECURITY: Don't expose detailed parsing errors to clients
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+			return
+		}
+
+		if err := lexicon.ValidateRequest(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx := c.Request.Context()
+		userID := middleware.MustGetUserID(c)
+
+		list, err := store.CreateList(ctx, &req, userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create lexicon"})
+			return
+		}
+
+		c.JSON(http.StatusCreated, list)
+	}
+}
+
+func getLexiconHandler(store *lexicon.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		listID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid lexicon ID"})
+			return
+		}
+
+		list, err := store.GetList(c.Request.Context(), listID)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "lexicon not found"})
+			return
+		}
+
+		c.JSON(http.StatusOK, list)
+	}
+}
+
+func publishLexiconHandler(store *lexicon.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		listID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid lexicon ID"})
+			return
+		}
+
+		list, err := store.PublishList(c.Request.Context(), listID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "lexicon not found"})
+				return
+			}
+			if errors.Is(err, lexicon.ErrNotDraft) {
+				c.JSON(http.StatusConflict, gin.H{"error": "only a draft lexicon can be published"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to publish lexicon"})
+			return
+		}
+
+		c.JSON(http.StatusOK, list)
+	}
+}
+
+func listPromptTemplatesHandler(store *prompt.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		policyID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid policy ID"})
+			return
+		}
+
+		templates, err := store.ListTemplates(c.Request.Context(), policyID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list prompt templates"})
+			return
+		}
+
+		c.JSON(http.StatusOK, templates)
+	}
+}
+
+func createPromptTemplateHandler(store *prompt.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		policyID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid policy ID"})
+			return
+		}
+
+		var req models.CreatePromptTemplateRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			// SECURITY: Don't expose detailed parsing errors to clients
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+			return
+		}
+
+		if err := classifier.ValidatePromptTemplate(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx := c.Request.Context()
+		userID := middleware.MustGetUserID(c)
+
+		template, err := store.CreateTemplate(ctx, policyID, &req, userID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "policy not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create prompt template"})
+			return
+		}
+
+		c.JSON(http.StatusCreated, template)
+	}
+}
+
+func getPromptTemplateHandler(store *prompt.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		templateID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid prompt template ID"})
+			return
+		}
+
+		template, err := store.GetTemplate(c.Request.Context(), templateID)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "prompt template not found"})
+			return
+		}
+
+		c.JSON(http.StatusOK, template)
+	}
+}
+
+func publishPromptTemplateHandler(store *prompt.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		templateID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid prompt template ID"})
+			return
+		}
+
+		template, err := store.PublishTemplate(c.Request.Context(), templateID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "prompt template not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to publish prompt template"})
+			return
+		}
+
+		c.JSON(http.StatusOK, template)
+	}
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
@@ -13,6 +14,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/proth1/text-moderator/internal/calibration"
 	"github.com/proth1/text-moderator/internal/compliance"
 	"github.com/proth1/text-moderator/internal/config"
 	"github.com/proth1/text-moderator/internal/apikey"
@@ -21,6 +23,7 @@ import (
 	"github.com/proth1/text-moderator/internal/fairness"
 	"github.com/proth1/text-moderator/internal/feedback"
 	"github.com/proth1/text-moderator/internal/retention"
+	"github.com/proth1/text-moderator/internal/shadow"
 	"github.com/proth1/text-moderator/internal/middleware"
 	"github.com/proth1/text-moderator/internal/models"
 	"github.com/proth1/text-moderator/internal/observability"
@@ -197,6 +200,13 @@ func setupRouter(cfg *config.Config, logger *zap.Logger, db *database.PostgresDB
 
 		// Fairness and bias detection
 		api.GET("/reports/fairness", middleware.RequireRole("admin"), fairnessReportHandler(db, logger))
+		api.GET("/reports/shadow", middleware.RequireRole("admin"), shadowReportHandler(db, logger))
+
+		// Score calibration fitted from review outcomes
+		calibrationStore := calibration.NewStore(db.Pool, logger)
+		api.GET("/calibrations", middleware.RequireRole("admin"), listCalibrationsHandler(calibrationStore, logger))
+		api.POST("/calibrations/fit", middleware.RequireRole("admin"), fitCalibrationHandler(calibrationStore, logger))
+		api.POST("/calibrations/:id/activate", middleware.RequireRole("admin"), activateCalibrationHandler(calibrationStore, logger))
 
 		// GDPR erasure endpoint
 		api.DELETE("/submissions/:hash", middleware.RequireRole("admin"), erasureHandler(purger, logger))
@@ -436,7 +446,7 @@ func getReviewHandler(db *database.PostgresDB, logger *zap.Logger) gin.HandlerFu
 			SELECT
 				d.id, d.submission_id, d.model_name, d.model_version, d.category_scores,
 				d.policy_id, d.policy_version, d.automated_action, d.confidence,
-				d.explanation, d.created_at,
+				d.explanation, d.explanation_details, d.signals, d.translated, d.source_language, d.created_at,
 				s.content_hash, s.context_metadata, s.source
 			FROM moderation_decisions d
 			JOIN text_submissions s ON s.id = d.submission_id
@@ -456,6 +466,10 @@ func getReviewHandler(db *database.PostgresDB, logger *zap.Logger) gin.HandlerFu
 			&decision.AutomatedAction,
 			&decision.Confidence,
 			&decision.Explanation,
+			&decision.ExplanationDetails,
+			&decision.Signals,
+			&decision.Translated,
+			&decision.SourceLanguage,
 			&decision.CreatedAt,
 			&submission.ContentHash,
 			&submission.ContextMetadata,
@@ -496,7 +510,7 @@ func submitReviewActionHandler(db *database.PostgresDB, evidenceWriter *evidence
 		var decision models.ModerationDecision
 		decisionQuery := `
 			SELECT id, submission_id, model_name, model_version, category_scores,
-			       policy_id, policy_version, automated_action
+			       policy_id, policy_version, automated_action, provider_name
 			FROM moderation_decisions
 			WHERE id = $1
 		`
@@ -509,6 +523,7 @@ func submitReviewActionHandler(db *database.PostgresDB, evidenceWriter *evidence
 			&decision.PolicyID,
 			&decision.PolicyVersion,
 			&decision.AutomatedAction,
+			&decision.ProviderName,
 		)
 		if err != nil {
 			logger.Error("failed to get decision", zap.Error(err))
@@ -647,6 +662,7 @@ func createWebhookHandler(dispatcher *webhook.Dispatcher, logger *zap.Logger) gi
 			string(models.EventReviewRequired):      true,
 			string(models.EventReviewCompleted):      true,
 			string(models.EventPolicyUpdated):        true,
+			string(models.EventProviderBreakerChanged): true,
 		}
 		for _, et := range req.EventTypes {
 			if !validTypes[et] {
@@ -871,3 +887,102 @@ func fairnessReportHandler(db *database.PostgresDB, logger *zap.Logger) gin.Hand
 		c.JSON(http.StatusOK, report)
 	}
 }
+
+func shadowReportHandler(db *database.PostgresDB, logger *zap.Logger) gin.HandlerFunc {
+	recorder := shadow.NewRecorder(db.Pool, logger)
+	return func(c *gin.Context) {
+		windowStr := c.DefaultQuery("window", "24h")
+		window, err := time.ParseDuration(windowStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid window duration"})
+			return
+		}
+		if window > 720*time.Hour { // max 30 days
+			c.JSON(http.StatusBadRequest, gin.H{"error": "window cannot exceed 720h"})
+			return
+		}
+
+		threshold, err := strconv.ParseFloat(c.DefaultQuery("threshold", "0.5"), 64)
+		if err != nil || threshold <= 0 || threshold > 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "threshold must be between 0 and 1"})
+			return
+		}
+
+		report, err := recorder.GenerateReport(c.Request.Context(), window, threshold)
+		if err != nil {
+			logger.Error("failed to generate shadow report", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate report"})
+			return
+		}
+
+		c.JSON(http.StatusOK, report)
+	}
+}
+
+func listCalibrationsHandler(store *calibration.Store, logger *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		versions, err := store.List(c.Request.Context())
+		if err != nil {
+			logger.Error("failed to list calibrations", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list calibrations"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"calibrations": versions, "total": len(versions)})
+	}
+}
+
+// fitCalibrationHandler fits a new calibration version from reviewed decisions.
+// Control: MOD-005 (Multi-Provider Classification Orchestration)
+func fitCalibrationHandler(store *calibration.Store, logger *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := middleware.MustGetUserID(c)
+		if userID == uuid.Nil {
+			return
+		}
+
+		var req models.FitCalibrationRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			// SECURITY: Don't expose detailed parsing errors to clients
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+			return
+		}
+		if req.WindowDays < 0 || req.WindowDays > 365 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "window_days must be between 1 and 365"})
+			return
+		}
+
+		version, err := store.Fit(c.Request.Context(), &req, userID)
+		if err != nil {
+			if errors.Is(err, calibration.ErrInsufficientData) {
+				c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+				return
+			}
+			logger.Error("failed to fit calibration", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fit calibration"})
+			return
+		}
+		c.JSON(http.StatusCreated, version)
+	}
+}
+
+func activateCalibrationHandler(store *calibration.Store, logger *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid calibration ID"})
+			return
+		}
+
+		version, err := store.Activate(c.Request.Context(), id)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "calibration not found"})
+				return
+			}
+			logger.Error("failed to activate calibration", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to activate calibration"})
+			return
+		}
+		c.JSON(http.StatusOK, version)
+	}
+}
@@ -66,3 +66,85 @@ Feature: Policy Engine
     Then the policy status should be "archived"
     And the policy should no longer be used for new moderation decisions
     And historical evidence should still reference the archived policy
+
+  Scenario: Schedule a policy publish
+    Given a published policy "Standard Guidelines" version 1
+    And a draft policy "Standard Guidelines" version 2 exists
+    When I publish version 2 with an effective date tomorrow
+    Then version 2 should be published and scheduled
+    And version 1 should still be used for new moderation decisions
+    And version 2 should be used once its effective date has passed
+
+  Scenario: Roll back a policy
+    Given a published policy "Standard Guidelines" version 1
+    And version 2 of "Standard Guidelines" has been published
+    When I roll back version 2
+    Then version 2 should be archived
+    And version 1 should be published and used for new moderation decisions
+
+  Scenario: Invalid policy transitions are rejected
+    Given an archived policy "Old Policy" exists
+    When I publish the policy
+    Then the request should be rejected with 409 Conflict
+    And the error should indicate "cannot publish a policy that is archived"
+
+  Scenario: Policy transitions are audited
+    Given a draft policy "Test Policy" exists
+    And a webhook is subscribed to "policy.updated"
+    When I publish the policy
+    Then a POL-001 evidence record should record the "publish" transition and my user ID
+    And the webhook should receive a "policy.updated" event with transition "publish"
+
+  Scenario: Review a policy's version history and changes
+    Given a published policy "Standard Guidelines" version 1
+    And version 2 of "Standard Guidelines" changes the toxicity threshold from 0.8 to 0.7
+    When I list the versions of "Standard Guidelines"
+    Then I should see versions 2 and 1 with their authors
+    When I diff version 2 against version 1
+    Then the diff should show toxicity "changed" from 0.8 to 0.7
+    And the diff should name the author of each version
+
+  Scenario: Simulate a draft policy against historical decisions
+    Given a published policy "Standard Guidelines" version 1
+    And moderation decisions were made under version 1 last week
+    And a draft policy "Standard Guidelines" version 2 lowers the toxicity threshold
+    When I simulate version 2 over last week's decisions
+    Then the simulation should complete with a summary of action shifts
+    And the summary should report agreement with human reviewers before and after
+    And I should be able to page through and export the decisions that would flip
+    And no moderation decisions should be changed
+
+  Scenario: A policy's test suite gates publishing
+    Given a published policy "Standard Guidelines" version 1
+    And "Standard Guidelines" has a test case with toxicity 0.85 expected to "block"
+    And a draft policy "Standard Guidelines" version 2 raises the toxicity threshold to 0.9
+    When I run the test suite of version 2
+    Then the test run should fail with the case's action "allow"
+    When I publish version 2
+    Then the request should be rejected with 409 Conflict
+    And the response should include the failed test run
+    And version 1 should still be used for new moderation decisions
+
+  Scenario: A passing test suite is recorded with the publish
+    Given a draft policy "Test Policy" exists
+    And "Test Policy" has a test case with content "have a nice day" expected to "allow"
+    When I publish the policy
+    Then the policy status should be "published"
+    And the policy.updated event should include the passed test run
+
+  Scenario: Requests are routed to a policy by its scope
+    Given a published policy "Standard Guidelines" marked as the default
+    And a published policy "EU Kids" with a route "eu-kids" matching region "EU" and audience "kids"
+    When I submit content for moderation with context metadata region "EU" and audience "kids"
+    Then the decision should be made with policy "EU Kids"
+    And the policy resolution should name route "eu-kids" and its conditions
+    When I submit content for moderation with context metadata region "US"
+    Then the decision should be made with policy "Standard Guidelines"
+    And the policy resolution method should be "default"
+
+  Scenario: The last default policy cannot be archived
+    Given a published policy "Standard Guidelines" marked as the default
+    And no other published policy is marked as the default
+    When I archive "Standard Guidelines"
+    Then the request should be rejected with 409 Conflict
+    And the policy status should be "published"
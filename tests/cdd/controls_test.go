@@ -278,6 +278,19 @@ func TestPOL003_RegionalResolution(t *testing.T) {
 			}
 		}
 	})
+
+	t.Run("a published policy is the explicit default", func(t *testing.T) {
+		query := "SELECT COUNT(*) FROM policies WHERE scope->'default' = 'true'::jsonb AND status = $1"
+		var count int
+		err := db.Pool.QueryRow(ctx, query, "published").Scan(&count)
+		if err != nil {
+			t.Fatalf("Failed to query policies: %v", err)
+		}
+
+		if count < 1 {
+			t.Error("Should have a published default policy for requests no route matches")
+		}
+	})
 }
 
 // TestGOV002_HumanReview verifies GOV-002: Human-in-the-Loop Review
@@ -15,12 +15,12 @@ INSERT INTO policies (id, name, version, thresholds, actions, scope, status, eff
 ('b0000000-0000-0000-0000-000000000001', 'Standard Community Guidelines', 1,
  '{"toxicity": 0.8, "hate": 0.7, "harassment": 0.75, "sexual_content": 0.8, "violence": 0.85, "profanity": 0.9}'::jsonb,
  '{"toxicity": "block", "hate": "block", "harassment": "warn", "sexual_content": "block", "violence": "block", "profanity": "warn"}'::jsonb,
- '{"region": "global", "content_type": "user_generated"}'::jsonb,
+ '{"region": "global", "content_type": "user_generated", "default": true}'::jsonb,
  'published', NOW(), NOW(), 'a0000000-0000-0000-0000-000000000001'),
 ('b0000000-0000-0000-0000-000000000002', 'Youth Safe Mode', 1,
  '{"toxicity": 0.5, "hate": 0.4, "harassment": 0.5, "sexual_content": 0.3, "violence": 0.4, "profanity": 0.6}'::jsonb,
  '{"toxicity": "block", "hate": "block", "harassment": "block", "sexual_content": "block", "violence": "block", "profanity": "warn"}'::jsonb,
- '{"region": "global", "content_type": "youth", "age_group": "under_13"}'::jsonb,
+ '{"region": "global", "content_type": "youth", "age_group": "under_13", "routes": [{"id": "under-13", "match": {"age_group": "under_13"}}]}'::jsonb,
  'published', NOW(), NOW(), 'a0000000-0000-0000-0000-000000000001'),
 ('b0000000-0000-0000-0000-000000000003', 'Relaxed Forum Policy', 1,
  '{"toxicity": 0.95, "hate": 0.85, "harassment": 0.9, "sexual_content": 0.9, "violence": 0.95, "profanity": 0.99}'::jsonb,
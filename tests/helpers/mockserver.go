@@ -8,6 +8,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
 )
 
@@ -155,3 +156,120 @@ func (m *MockServer) SetRateLimit(enable bool) {
 func (m *MockServer) URL() string {
 	return m.Server.URL
 }
+
+// LLMMockRequest records a chat completion request received by LLMMockServer
+type LLMMockRequest struct {
+	Model          string
+	ResponseFormat string // response_format.type, empty when not requested
+	Authorization  string
+	Prompt         string
+}
+
+// LLMMockServer stands in for a self-hosted OpenAI-compatible chat completions
+// server (vLLM, Ollama) serving /v1/chat/completions
+type LLMMockServer struct {
+	Server *httptest.Server
+
+	// APIKey, when set, is required as a bearer token
+	APIKey string
+
+	// Reply is returned as the assistant message content
+	Reply string
+
+	// SupportedFormats lists the response_format types the server accepts;
+	// requests using any other type are rejected with 400. Nil accepts all.
+	SupportedFormats map[string]bool
+
+	// MaxPromptLength, when set, rejects longer prompts with 400 as exceeding
+	// the model's context length
+	MaxPromptLength int
+
+	mu       sync.Mutex
+	requests []LLMMockRequest
+}
+
+// SetupLLMMockServer creates a mock OpenAI-compatible LLM server returning reply
+func SetupLLMMockServer(t *testing.T, reply string) (*LLMMockServer, func()) {
+	t.Helper()
+
+	mock := &LLMMockServer{Reply: reply}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/chat/completions", mock.handleChatCompletion)
+	mock.Server = httptest.NewServer(mux)
+
+	return mock, mock.Server.Close
+}
+
+func (m *LLMMockServer) handleChatCompletion(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var reqBody struct {
+		Model    string `json:"model"`
+		Messages []struct {
+			Role    string `json:"role"`
+			Content string `json:"content"`
+		} `json:"messages"`
+		ResponseFormat *struct {
+			Type string `json:"type"`
+		} `json:"response_format"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&reqBody); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	recorded := LLMMockRequest{Model: reqBody.Model, Authorization: r.Header.Get("Authorization")}
+	if reqBody.ResponseFormat != nil {
+		recorded.ResponseFormat = reqBody.ResponseFormat.Type
+	}
+	if len(reqBody.Messages) > 0 {
+		recorded.Prompt = reqBody.Messages[len(reqBody.Messages)-1].Content
+	}
+
+	m.mu.Lock()
+	m.requests = append(m.requests, recorded)
+	apiKey, reply, supported, maxPrompt := m.APIKey, m.Reply, m.SupportedFormats, m.MaxPromptLength
+	m.mu.Unlock()
+
+	if apiKey != "" && recorded.Authorization != "Bearer "+apiKey {
+		http.Error(w, `{"error":{"message":"invalid api key"}}`, http.StatusUnauthorized)
+		return
+	}
+	if recorded.ResponseFormat != "" && supported != nil && !supported[recorded.ResponseFormat] {
+		http.Error(w, `{"error":{"message":"unsupported response_format"}}`, http.StatusBadRequest)
+		return
+	}
+	if maxPrompt > 0 && len(recorded.Prompt) > maxPrompt {
+		http.Error(w, `{"error":{"message":"maximum context length exceeded"}}`, http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"id":     "chatcmpl-mock",
+		"object": "chat.completion",
+		"model":  reqBody.Model,
+		"choices": []map[string]interface{}{
+			{
+				"index":         0,
+				"message":       map[string]string{"role": "assistant", "content": reply},
+				"finish_reason": "stop",
+			},
+		},
+	})
+}
+
+// Requests returns the chat completion requests received so far
+func (m *LLMMockServer) Requests() []LLMMockRequest {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]LLMMockRequest(nil), m.requests...)
+}
+
+// BaseURL returns the OpenAI-style API root, e.g. http://127.0.0.1:1234/v1
+func (m *LLMMockServer) BaseURL() string {
+	return m.Server.URL + "/v1"
+}